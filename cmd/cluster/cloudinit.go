@@ -0,0 +1,64 @@
+package cluster
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mrgb7/playground/types"
+)
+
+//go:embed cloudinit/default.yaml
+var cloudInitFS embed.FS
+
+const defaultCloudInitFile = "cloudinit/default.yaml"
+
+// resolveCloudInit returns the cloud-init file to launch nodes with for a config's
+// CloudInit setting: the embedded default when empty, none when CloudInitNone
+func resolveCloudInit(setting string) (string, error) {
+	switch setting {
+	case types.CloudInitNone:
+		return "", nil
+	case "":
+		return writeDefaultCloudInit()
+	}
+	return setting, nil
+}
+
+// normalizeCloudInit makes a cloud-init file path absolute so scaling the cluster from
+// another directory finds it
+func normalizeCloudInit(config *types.ClusterConfig) error {
+	if config.CloudInit == "" || config.CloudInit == types.CloudInitNone {
+		return nil
+	}
+	abs, err := filepath.Abs(config.CloudInit)
+	if err != nil {
+		return fmt.Errorf("invalid cloud-init path: %w", err)
+	}
+	config.CloudInit = abs
+	return nil
+}
+
+// writeDefaultCloudInit writes the embedded cloud-init below the home directory, where a
+// snap-confined multipass can read it, and returns its path
+func writeDefaultCloudInit() (string, error) {
+	content, err := cloudInitFS.ReadFile(defaultCloudInitFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read default cloud-init: %w", err)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	dir := filepath.Join(home, ".playground", "cloud-init")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create cloud-init directory: %w", err)
+	}
+	path := filepath.Join(dir, "default.yaml")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write default cloud-init: %w", err)
+	}
+	return path, nil
+}
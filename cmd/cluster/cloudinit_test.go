@@ -0,0 +1,48 @@
+package cluster
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mrgb7/playground/types"
+)
+
+func TestResolveCloudInit(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	defaultPath := filepath.Join(home, ".playground", "cloud-init", "default.yaml")
+
+	tests := []struct {
+		name     string
+		setting  string
+		expected string
+	}{
+		{name: "default", setting: "", expected: defaultPath},
+		{name: "none", setting: types.CloudInitNone, expected: ""},
+		{name: "custom file", setting: "/tmp/init.yaml", expected: "/tmp/init.yaml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := resolveCloudInit(tt.setting)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if path != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, path)
+			}
+		})
+	}
+
+	content, err := os.ReadFile(defaultPath)
+	if err != nil {
+		t.Fatalf("default cloud-init not written: %v", err)
+	}
+	for _, want := range []string{"#cloud-config", "curl", "jq", "net.ipv4.ip_forward"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("default cloud-init does not contain %q", want)
+		}
+	}
+}
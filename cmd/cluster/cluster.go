@@ -17,4 +17,13 @@ func init() {
 	ClusterCmd.AddCommand(deleteCmd)
 	ClusterCmd.AddCommand(cleanCmd)
 	ClusterCmd.AddCommand(listCmd)
+	ClusterCmd.AddCommand(scaleCmd)
+	ClusterCmd.AddCommand(kubeconfigCmd)
+	ClusterCmd.AddCommand(execCmd)
+	ClusterCmd.AddCommand(openCmd)
+	ClusterCmd.AddCommand(endpointsCmd)
+	ClusterCmd.AddCommand(exportCmd)
+	ClusterCmd.AddCommand(importCmd)
+	ClusterCmd.AddCommand(mountCmd)
+	ClusterCmd.AddCommand(unmountCmd)
 }
@@ -0,0 +1,95 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mrgb7/playground/internal/multipass"
+	"github.com/mrgb7/playground/internal/plugins"
+	"github.com/mrgb7/playground/pkg/logger"
+	"github.com/mrgb7/playground/types"
+)
+
+// coreComponents are the plugins --with-core-component installs
+var coreComponents = []string{"nginx-ingress", "cert-manager", "load-balancer"}
+
+// setupCoreComponents installs the core components on a freshly created cluster when
+// requested. A failure is only logged since the cluster itself is usable and the
+// components can be added as plugins later.
+func setupCoreComponents(ctx context.Context, client multipass.Client, config *types.ClusterConfig) {
+	if !config.WithCoreComponents || ctx.Err() != nil {
+		return
+	}
+	if err := installClusterCoreComponents(ctx, client, config.Name); err != nil {
+		logger.Errorln("Failed to install core components: %v", err)
+		logger.Infoln("Add them later with: playground cluster plugin add --name <plugin> --cluster %s", config.Name)
+	}
+}
+
+func installClusterCoreComponents(ctx context.Context, client multipass.Client, clusterName string) error {
+	masterNodeName := fmt.Sprintf("%s-master", clusterName)
+	kubeConfig, err := getKubeConfig(ctx, client, masterNodeName)
+	if err != nil {
+		return err
+	}
+	masterIP, err := client.GetNodeIP(masterNodeName)
+	if err != nil {
+		return fmt.Errorf("failed to get master IP: %w", err)
+	}
+	return installCoreComponents(ctx, kubeConfig, masterIP, clusterName)
+}
+
+// installCoreComponents installs the core components and the plugins they depend on in
+// dependency order, waiting for each to become ready before installing the next
+func installCoreComponents(ctx context.Context, kubeConfig, clusterIP, clusterName string) error {
+	pluginsList, err := plugins.CreatePluginsList(kubeConfig, clusterIP, clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to create plugins list: %w", err)
+	}
+	pluginMap := make(map[string]plugins.Plugin, len(pluginsList))
+	for _, plugin := range pluginsList {
+		pluginMap[plugin.GetName()] = plugin
+	}
+
+	dependencyPlugins, err := plugins.CreateDependencyPluginsList(kubeConfig, clusterIP, clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to create dependency plugins list: %w", err)
+	}
+	installOrder, err := coreInstallOrder(dependencyPlugins, plugins.GetInstalledPlugins(kubeConfig))
+	if err != nil {
+		return err
+	}
+	logger.Infoln("Core component installation order: %v", installOrder)
+	return installInOrder(ctx, kubeConfig, clusterName, installOrder, pluginMap)
+}
+
+// installInOrder installs the named plugins one after the other. Once ctx is cancelled
+// no further installs are started.
+func installInOrder(ctx context.Context, kubeConfig, clusterName string, installOrder []string,
+	pluginMap map[string]plugins.Plugin) error {
+	for _, name := range installOrder {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("core component %s not installed: %w", name, err)
+		}
+		plugin, ok := pluginMap[name]
+		if !ok {
+			return fmt.Errorf("plugin %s not found", name)
+		}
+		logger.Infoln("Installing core component: %s", name)
+		if err := plugin.Install(kubeConfig, clusterName, true); err != nil {
+			return fmt.Errorf("failed to install core component %s: %w", name, err)
+		}
+		logger.Successln("Successfully installed %s", name)
+	}
+	return nil
+}
+
+// coreInstallOrder resolves the order the core components not yet installed are installed in
+func coreInstallOrder(dependencyPlugins []plugins.DependencyPlugin, installed []string) ([]string, error) {
+	installOrder, err := plugins.NewDependencyValidator(dependencyPlugins).
+		ValidateInstallation(coreComponents, installed)
+	if err != nil {
+		return nil, fmt.Errorf("dependency validation failed: %w", err)
+	}
+	return installOrder, nil
+}
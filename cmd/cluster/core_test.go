@@ -0,0 +1,96 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/mrgb7/playground/internal/plugins"
+)
+
+func TestCoreInstallOrder(t *testing.T) {
+	dependencyPlugins := []plugins.DependencyPlugin{
+		plugins.NewNginx(""),
+		plugins.NewCertManager(""),
+		&plugins.LoadBalancer{},
+		plugins.NewDashboard("", "dev"),
+	}
+
+	tests := []struct {
+		name      string
+		installed []string
+		expected  []string
+	}{
+		{
+			name:     "fresh cluster",
+			expected: []string{"cert-manager", "load-balancer", "nginx-ingress"},
+		},
+		{
+			name:      "load balancer already installed",
+			installed: []string{"load-balancer"},
+			expected:  []string{"cert-manager", "nginx-ingress"},
+		},
+		{
+			name:      "everything installed",
+			installed: []string{"cert-manager", "load-balancer", "nginx-ingress"},
+			expected:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := coreInstallOrder(dependencyPlugins, tt.installed)
+			if err != nil {
+				t.Fatalf("coreInstallOrder: %v", err)
+			}
+			// plugins without a dependency between them may come in any order
+			sorted := slices.Sorted(slices.Values(got))
+			if !reflect.DeepEqual(sorted, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+			lb, nginx := slices.Index(got, "load-balancer"), slices.Index(got, "nginx-ingress")
+			if lb != -1 && nginx != -1 && lb > nginx {
+				t.Errorf("expected load-balancer to be installed before nginx-ingress, got %v", got)
+			}
+		})
+	}
+}
+
+func TestCoreInstallOrderMissingPlugin(t *testing.T) {
+	_, err := coreInstallOrder([]plugins.DependencyPlugin{plugins.NewNginx("")}, nil)
+	if err == nil {
+		t.Error("expected an error when the core components aren't all known")
+	}
+}
+
+// fakeCorePlugin counts its installs and cancels cancel, if set, when installed
+type fakeCorePlugin struct {
+	plugins.Plugin
+	installs int
+	cancel   context.CancelFunc
+}
+
+func (f *fakeCorePlugin) Install(string, string, ...bool) error {
+	f.installs++
+	if f.cancel != nil {
+		f.cancel()
+	}
+	return nil
+}
+
+func TestInstallInOrderStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	first, second := &fakeCorePlugin{cancel: cancel}, &fakeCorePlugin{}
+	pluginMap := map[string]plugins.Plugin{"load-balancer": first, "nginx-ingress": second}
+
+	err := installInOrder(ctx, "kubeconfig", "dev", []string{"load-balancer", "nginx-ingress"}, pluginMap)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if first.installs != 1 || second.installs != 0 {
+		t.Errorf("expected only the install before the cancellation, got %d and %d", first.installs, second.installs)
+	}
+}
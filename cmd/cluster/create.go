@@ -1,23 +1,34 @@
 package cluster
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/mrgb7/playground/internal/k8s"
 	"github.com/mrgb7/playground/internal/multipass"
+	"github.com/mrgb7/playground/internal/state"
+	"github.com/mrgb7/playground/internal/validator"
 	"github.com/mrgb7/playground/pkg/logger"
 	"github.com/mrgb7/playground/types"
 	"github.com/spf13/cobra"
-	"k8s.io/client-go/tools/clientcmd"
-	"k8s.io/client-go/tools/clientcmd/api"
-	"k8s.io/client-go/util/homedir"
+	corev1 "k8s.io/api/core/v1"
 )
 
 // ClusterConfig holds the configuration for cluster creation
 
+var (
+	errClusterExists = errors.New("already a playground cluster")
+	errNameCollision = errors.New("name collides with existing VM")
+)
+
 // workerError represents an error that occurred while configuring a worker node
 type workerError struct {
 	nodeName string
@@ -34,17 +45,35 @@ var (
 	workerCPUs         int
 	workerMemory       string
 	workerDisk         string
+	workerLabels       []string
+	workerTaints       []string
+	skipValidation     bool
+	k3sVersion         string
+	k3sArgs            []string
+	multipassArgs      []string
+	mounts             []string
+	mountMasterOnly    bool
+	resume             bool
+	cloudInit          string
+	image              string
+	domain             string
+	createTimeout      time.Duration
+	createOutput       string
+	maxParallel        int
 )
 
 const (
-	K3sCreateMasterCmd = `curl -sfL https://get.k3s.io | sh -s - --disable=servicelb --disable=traefik`
-	GetAccessTokenCmd  = `sudo cat /var/lib/rancher/k3s/server/node-token` //nolint:gosec
-	K3sCreateWorkerCmd = `curl -sfL https://get.k3s.io | K3S_URL=https://%s:6443 K3S_TOKEN=%s  sh -`
-	KubeConfigCmd      = `sudo cat /etc/rancher/k3s/k3s.yaml`
-	K3sInstallTimeout  = 300 // seconds - timeout for K3s installation
-	DefaultMasterCPUs  = 2   // default number of CPUs for master node
-	DefaultWorkerCPUs  = 2   // default number of CPUs for worker nodes
-
+	K3sInstallScriptURL  = "https://get.k3s.io"
+	K3sDefaultServerArgs = "--disable=servicelb --disable=traefik"
+	GetAccessTokenCmd    = `sudo cat /var/lib/rancher/k3s/server/node-token` //nolint:gosec
+	KubeConfigCmd        = `sudo cat /etc/rancher/k3s/k3s.yaml`
+	K3sInstallTimeout    = 300 // seconds - timeout for K3s installation
+	DefaultMasterCPUs    = 2   // default number of CPUs for master node
+	DefaultWorkerCPUs    = 2   // default number of CPUs for worker nodes
+	NodeReadyTimeout     = 5 * time.Minute
+	DefaultMaxParallel   = 3 // default number of nodes created or joined at the same time
+	K3sServerActiveCmd   = `systemctl is-active --quiet k3s`
+	K3sAgentActiveCmd    = `systemctl is-active --quiet k3s-agent`
 )
 
 var createCmd = &cobra.Command{
@@ -52,6 +81,14 @@ var createCmd = &cobra.Command{
 	Short: "Create a new cluster",
 	Long:  `Create a new cluster with the specified configurations`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if createOutput != "text" && createOutput != "json" {
+			logger.Errorln("Unknown output format %q, expected text or json", createOutput)
+			return
+		}
+		if createOutput == "json" {
+			// stdout only carries the validation report
+			logger.SetConsole(os.Stderr)
+		}
 		config := &types.ClusterConfig{
 			Name:               cCreateName,
 			Size:               cCreateSize,
@@ -62,16 +99,26 @@ var createCmd = &cobra.Command{
 			WorkerCPUs:         workerCPUs,
 			WorkerMemory:       workerMemory,
 			WorkerDisk:         workerDisk,
+			WorkerLabels:       workerLabels,
+			WorkerTaints:       workerTaints,
+			K3sVersion:         k3sVersion,
+			K3sArgs:            k3sArgs,
+			MultipassArgs:      multipassArgs,
+			Mounts:             mounts,
+			MountMasterOnly:    mountMasterOnly,
+			CloudInit:          cloudInit,
+			Image:              image,
+			Domain:             domain,
 		}
 
-		if err := createCluster(config); err != nil {
+		if err := createCluster(cmd.Context(), config); err != nil {
 			logger.Errorf("Failed to create cluster: %v", err)
 			return
 		}
 	},
 }
 
-func createCluster(config *types.ClusterConfig) error {
+func createCluster(ctx context.Context, config *types.ClusterConfig) error {
 	client := multipass.NewMultipassClient()
 
 	if !client.IsMultipassInstalled() {
@@ -84,56 +131,384 @@ func createCluster(config *types.ClusterConfig) error {
 	if err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
-	if cl.IsExists() {
-		return fmt.Errorf("cluster '%s' already exists", config.Name)
+	if err := normalizeMounts(config); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	if err := normalizeCloudInit(config); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	if maxParallel < 1 {
+		return fmt.Errorf("validation failed: --max-parallel must be at least 1, got %d", maxParallel)
+	}
+
+	ctx, cancel := withCreateTimeout(ctx, createTimeout)
+	defer cancel()
+	if resume {
+		nodes, err := client.ListNodes(config.Name)
+		if err != nil {
+			return fmt.Errorf("failed to list cluster nodes: %w", err)
+		}
+		if len(nodes) > 0 {
+			return resumeCluster(ctx, client, config, nodes)
+		}
+		logger.Infoln("No nodes of cluster '%s' found, creating it from scratch", config.Name)
+	} else {
+		instances, err := client.ListInstances()
+		if err != nil {
+			return fmt.Errorf("failed to list multipass instances: %w", err)
+		}
+		_, stateErr := state.Load(config.Name)
+		if err := checkNameAvailable(config.Name, instances, stateErr == nil); err != nil {
+			return err
+		}
+	}
+	report, err := preflight(config, skipValidation, validator.GetHostResources)
+	if report != nil && createOutput == "json" {
+		if err := writeValidationReport(os.Stdout, report); err != nil {
+			logger.Warnln("Failed to write validation report: %v", err)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := executeClusterCreation(ctx, client, config); err != nil {
+		return err
 	}
 
-	return executeClusterCreation(client, config)
+	if err := state.Save(*config); err != nil {
+		logger.Warnln("Failed to save cluster state: %v", err)
+	}
+	return nil
 }
 
-func executeClusterCreation(client multipass.Client, config *types.ClusterConfig) error {
-	var wg sync.WaitGroup
+// checkNameAvailable fails when a multipass instance is named like a node of the cluster,
+// since creating the cluster would collide with it and deleting the cluster would remove
+// it. hasState tells whether playground created the cluster, to tell it apart from
+// unrelated VMs.
+func checkNameAvailable(name string, instances []multipass.MultiPassListItem, hasState bool) error {
+	var collisions []string
+	for _, instance := range instances {
+		if isClusterInstance(name, instance.Name) {
+			collisions = append(collisions, instance.Name)
+		}
+	}
+	if len(collisions) == 0 {
+		return nil
+	}
+	if hasState {
+		return fmt.Errorf("cluster '%s' is %w (use --resume to finish a partial create)", name, errClusterExists)
+	}
+	return fmt.Errorf("cluster '%s': %w %s, choose another name or remove the VM "+
+		"(use --resume if it is left from an interrupted create)", name, errNameCollision,
+		strings.Join(collisions, ", "))
+}
+
+// withCreateTimeout bounds ctx by timeout, leaving it unbounded when timeout is zero
+func withCreateTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
 
-	if err := client.CreateCluster(
-		config.Name, config.Size, config.MasterCPUs, config.MasterMemory, config.MasterDisk,
-		config.WorkerCPUs, config.WorkerMemory, config.WorkerDisk, &wg,
-	); err != nil {
-		return fmt.Errorf("failed to create cluster: %w", err)
+// preflight checks the cluster fits on the host, returning the checks run. Multipass VMs
+// get their own IPs, so no host ports are checked. When the host can't be probed no
+// checks are run.
+func preflight(config *types.ClusterConfig, skip bool,
+	hostResources func() (validator.HostResources, error)) (*validator.ValidationResult, error) {
+	if skip {
+		logger.Warnln("Skipping host resource validation")
+		return nil, nil
 	}
 
+	result := validator.NewValidationResult()
+	host, err := hostResources()
+	if err != nil {
+		logger.Warnln("Could not check host resources: %v", err)
+	} else {
+		req, err := validator.CalculateResourceRequirements(*config)
+		if err != nil {
+			return nil, fmt.Errorf("validation failed: %w", err)
+		}
+		result.Merge(validator.ValidateResources(req, host))
+	}
+
+	for _, r := range result.Recommendations {
+		logger.Warnln("Recommendation: %s", r)
+	}
+	if !result.Valid {
+		return result, fmt.Errorf("host validation failed (use --skip-validation to create anyway): %s",
+			strings.Join(result.Failures(), "; "))
+	}
+	return result, nil
+}
+
+// writeValidationReport writes the preflight checks to w as indented JSON
+func writeValidationReport(w io.Writer, report *validator.ValidationResult) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// executeClusterCreation provisions the cluster and removes its VMs again when ctx is
+// cancelled part way through, e.g. by Ctrl-C or once --create-timeout expires
+func executeClusterCreation(ctx context.Context, client multipass.Client, config *types.ClusterConfig) error {
+	err := provisionCluster(ctx, client, config)
+	if err != nil && ctx.Err() != nil {
+		reason := "interrupted"
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			reason = "timed out"
+		}
+		logger.Warnln("Cluster creation %s, removing nodes of cluster '%s'", reason, config.Name)
+		var wg sync.WaitGroup
+		if cleanupErr := client.DeleteCluster(config.Name, &wg); cleanupErr != nil {
+			logger.Errorln("Failed to clean up cluster %s: %v", config.Name, cleanupErr)
+		}
+		return fmt.Errorf("cluster creation %s: %w", reason, ctx.Err())
+	}
+	if err != nil {
+		return err
+	}
+	setupCoreComponents(ctx, client, config)
+	return nil
+}
+
+func provisionCluster(ctx context.Context, client multipass.Client, config *types.ClusterConfig) error {
+	cloudInit, err := resolveCloudInit(config.CloudInit)
+	if err != nil {
+		return err
+	}
 	masterNodeName := fmt.Sprintf("%s-master", config.Name)
+	if err := createClusterNodes(ctx, client, config, masterNodeName, cloudInit); err != nil {
+		return err
+	}
+
+	// Mount host directories before installing K3s so workloads can use them right away
+	if len(config.Mounts) > 0 {
+		nodes := []string{masterNodeName}
+		for i := 1; i < config.Size; i++ {
+			nodes = append(nodes, types.WorkerNodeName(config.Name, i))
+		}
+		if err := mountHostPaths(client, config, nodes); err != nil {
+			logger.Warnln("Failed to mount host directories: %v", err)
+		}
+	}
 
 	// Install K3s on master node
-	if err := installMasterNode(client, masterNodeName); err != nil {
+	if err := installMasterNode(ctx, client, masterNodeName, config); err != nil {
 		return fmt.Errorf("failed to install K3s on master: %w", err)
 	}
 
 	// Get access token and master IP
-	accessToken, masterIP, err := getMasterCredentials(client, masterNodeName)
+	accessToken, masterIP, err := getMasterCredentials(ctx, client, masterNodeName)
 	if err != nil {
 		return fmt.Errorf("failed to get master credentials: %w", err)
 	}
 
 	// Configure worker nodes
-	workerErrors := configureWorkerNodes(client, config, masterIP, accessToken)
+	workerErrors := configureWorkerNodes(ctx, client, config, masterIP, accessToken)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	workerErrors = verifyWorkerJoins(ctx, client, masterNodeName, workerNodeNames(config), workerErrors)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
 	// Report results
 	reportClusterCreationResults(config, workerErrors)
 
 	// Update kubeconfig
-	return updateKubeConfig(client, masterNodeName, config.Name)
+	if err := updateKubeConfig(ctx, client, masterNodeName, config.Name); err != nil {
+		return err
+	}
+
+	// Apply worker labels and taints
+	applyWorkerScheduling(ctx, client, config, masterNodeName, workerNodeNames(config), workerErrors)
+	return nil
+}
+
+// resumePlan lists the steps left to finish a partially created cluster
+type resumePlan struct {
+	createMaster  bool
+	installMaster bool
+	createWorkers []string
+	joinWorkers   []string
+}
+
+// planResume diffs the nodes multipass reports for a cluster against config. Missing nodes
+// are created, and k3s is installed wherever installed reports it absent. A master created
+// anew has a new join token, so every worker is joined to it again.
+func planResume(config *types.ClusterConfig, nodes []string, installed func(nodeName string) bool) resumePlan {
+	present := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		present[node] = true
+	}
+
+	var plan resumePlan
+	masterNodeName := fmt.Sprintf("%s-master", config.Name)
+	plan.createMaster = !present[masterNodeName]
+	plan.installMaster = plan.createMaster || !installed(masterNodeName)
+
+	for i := 1; i < config.Size; i++ {
+		nodeName := types.WorkerNodeName(config.Name, i)
+		switch {
+		case !present[nodeName]:
+			plan.createWorkers = append(plan.createWorkers, nodeName)
+			plan.joinWorkers = append(plan.joinWorkers, nodeName)
+		case plan.installMaster || !installed(nodeName):
+			plan.joinWorkers = append(plan.joinWorkers, nodeName)
+		}
+	}
+	return plan
+}
+
+// k3sActive reports whether the k3s server or agent service is running on a node
+func k3sActive(client multipass.Client, masterNodeName string) func(nodeName string) bool {
+	return func(nodeName string) bool {
+		cmd := K3sAgentActiveCmd
+		if nodeName == masterNodeName {
+			cmd = K3sServerActiveCmd
+		}
+		_, err := client.ExecuteShell(nodeName, cmd)
+		return err == nil
+	}
 }
 
-func installMasterNode(client multipass.Client, masterNodeName string) error {
-	std, err := client.ExecuteShellWithTimeout(masterNodeName, K3sCreateMasterCmd, K3sInstallTimeout)
+// resumeCluster finishes creating a cluster of which only some nodes exist. Unlike a
+// fresh create, the nodes are kept when ctx is cancelled so the create can be resumed again.
+func resumeCluster(ctx context.Context, client multipass.Client, config *types.ClusterConfig, nodes []string) error {
+	masterNodeName := fmt.Sprintf("%s-master", config.Name)
+	plan := planResume(config, nodes, k3sActive(client, masterNodeName))
+	logger.Infoln("Resuming cluster '%s': %d of %d nodes exist", config.Name, len(nodes), config.Size)
+
+	cloudInit, err := resolveCloudInit(config.CloudInit)
+	if err != nil {
+		return err
+	}
+
+	created := make([]string, 0, len(plan.createWorkers)+1)
+	if plan.createMaster {
+		err := client.CreateNode(masterNodeName, config.Image, config.MasterCPUs, config.MasterMemory,
+			config.MasterDisk, cloudInit, config.MultipassArgs...)
+		if err != nil {
+			return fmt.Errorf("failed to create master node: %w", err)
+		}
+		created = append(created, masterNodeName)
+	}
+
+	var mu sync.Mutex
+	failed := forEachNode(ctx, plan.createWorkers, maxParallel, func(nodeName string) error {
+		err := client.CreateNode(nodeName, config.Image, config.WorkerCPUs, config.WorkerMemory,
+			config.WorkerDisk, cloudInit, config.MultipassArgs...)
+		if err != nil {
+			logger.Errorln("Failed to create worker node %s: %v", nodeName, err)
+			return err
+		}
+		mu.Lock()
+		created = append(created, nodeName)
+		mu.Unlock()
+		return nil
+	})
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if len(config.Mounts) > 0 && len(created) > 0 {
+		if err := mountHostPaths(client, config, created); err != nil {
+			logger.Warnln("Failed to mount host directories: %v", err)
+		}
+	}
+
+	if plan.installMaster {
+		if err := installMasterNode(ctx, client, masterNodeName, config); err != nil {
+			return fmt.Errorf("failed to install K3s on master: %w", err)
+		}
+	} else {
+		logger.Infoln("K3s is already running on %s, skipping install", masterNodeName)
+	}
+
+	accessToken, masterIP, err := getMasterCredentials(ctx, client, masterNodeName)
+	if err != nil {
+		return fmt.Errorf("failed to get master credentials: %w", err)
+	}
+
+	toJoin := make([]string, 0, len(plan.joinWorkers))
+	for _, nodeName := range plan.joinWorkers {
+		if !slices.ContainsFunc(failed, func(we workerError) bool { return we.nodeName == nodeName }) {
+			toJoin = append(toJoin, nodeName)
+		}
+	}
+	workerErrors := joinWorkers(ctx, client, toJoin, k3sWorkerInstallCmd(config.K3sVersion, masterIP, accessToken),
+		maxParallel)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	workerErrors = verifyWorkerJoins(ctx, client, masterNodeName, toJoin, workerErrors)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	workerErrors = append(workerErrors, failed...)
+
+	reportClusterCreationResults(config, workerErrors)
+	if err := updateKubeConfig(ctx, client, masterNodeName, config.Name); err != nil {
+		return err
+	}
+	applyWorkerScheduling(ctx, client, config, masterNodeName, workerNodeNames(config), workerErrors)
+	setupCoreComponents(ctx, client, config)
+
+	if err := state.Save(*config); err != nil {
+		logger.Warnln("Failed to save cluster state: %v", err)
+	}
+	return nil
+}
+
+// createClusterNodes launches the master and worker VMs, at most maxParallel at a time.
+// When any of them fails the VMs of the cluster are removed again.
+func createClusterNodes(ctx context.Context, client multipass.Client, config *types.ClusterConfig,
+	masterNodeName, cloudInit string) error {
+	nodeNames := append([]string{masterNodeName}, workerNodeNames(config)...)
+	failed := forEachNode(ctx, nodeNames, maxParallel, func(nodeName string) error {
+		if nodeName == masterNodeName {
+			return client.CreateNode(nodeName, config.Image, config.MasterCPUs, config.MasterMemory,
+				config.MasterDisk, cloudInit, config.MultipassArgs...)
+		}
+		return client.CreateNode(nodeName, config.Image, config.WorkerCPUs, config.WorkerMemory,
+			config.WorkerDisk, cloudInit, config.MultipassArgs...)
+	})
+	// an interrupted create is cleaned up by executeClusterCreation
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	errs := make([]error, 0, len(failed))
+	for _, we := range failed {
+		logger.Errorln("Failed to create node %s: %v", we.nodeName, we.err)
+		errs = append(errs, fmt.Errorf("node %s: %w", we.nodeName, we.err))
+	}
+	logger.Errorln("Error during cluster creation for '%s', attempting cleanup.", config.Name)
+	var wg sync.WaitGroup
+	if cleanupErr := client.DeleteCluster(config.Name, &wg); cleanupErr != nil {
+		logger.Errorln("Failed to clean up cluster %s: %v", config.Name, cleanupErr)
+	}
+	return fmt.Errorf("failed to create cluster: %w", errors.Join(errs...))
+}
+
+func installMasterNode(ctx context.Context, client multipass.Client, masterNodeName string,
+	config *types.ClusterConfig) error {
+	std, err := executeK3sInstall(ctx, client, masterNodeName, k3sMasterInstallCmd(config.K3sVersion, config.K3sArgs))
 	if err != nil || std == "" {
 		return fmt.Errorf("failed to create k3s on master: %w", err)
 	}
 	return nil
 }
 
-func getMasterCredentials(client multipass.Client, masterNodeName string) (string, string, error) {
-	accessToken, err := client.ExecuteShell(masterNodeName, GetAccessTokenCmd)
+func getMasterCredentials(ctx context.Context, client multipass.Client, masterNodeName string) (string, string, error) {
+	accessToken, err := client.ExecuteShellContext(ctx, masterNodeName, GetAccessTokenCmd)
 	if err != nil || accessToken == "" {
 		return "", "", fmt.Errorf("failed to get access token: %w", err)
 	}
@@ -147,39 +522,150 @@ func getMasterCredentials(client multipass.Client, masterNodeName string) (strin
 	return accessToken, masterIP, nil
 }
 
-func configureWorkerNodes(client multipass.Client, config *types.ClusterConfig, masterIP, accessToken string) []workerError {
+// k3sMasterInstallCmd builds the master install command, pinning the k3s release when version
+// is set and appending args as extra server arguments
+func k3sMasterInstallCmd(version string, args []string) string {
+	parts := []string{"curl -sfL " + K3sInstallScriptURL, "|"}
+	if version != "" {
+		parts = append(parts, "INSTALL_K3S_VERSION="+version)
+	}
+	parts = append(parts, "sh -s -", K3sDefaultServerArgs)
+	for _, arg := range args {
+		parts = append(parts, shellQuote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+// k3sWorkerInstallCmd builds the command joining a worker to the master, pinning the k3s
+// release when version is set so workers match the master
+func k3sWorkerInstallCmd(version, masterIP, accessToken string) string {
+	parts := []string{"curl -sfL " + K3sInstallScriptURL, "|"}
+	if version != "" {
+		parts = append(parts, "INSTALL_K3S_VERSION="+version)
+	}
+	parts = append(parts, fmt.Sprintf("K3S_URL=https://%s:6443", masterIP), "K3S_TOKEN="+accessToken, "sh -")
+	return strings.Join(parts, " ")
+}
+
+// shellQuote wraps s in single quotes so it is passed to the install script as one argument
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// executeK3sInstall runs a K3s install command, bounded by K3sInstallTimeout and ctx
+func executeK3sInstall(ctx context.Context, client multipass.Client, nodeName, command string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, K3sInstallTimeout*time.Second)
+	defer cancel()
+	return client.ExecuteShellContext(ctx, nodeName, command)
+}
+
+func configureWorkerNodes(ctx context.Context, client multipass.Client, config *types.ClusterConfig,
+	masterIP, accessToken string) []workerError {
+	return joinWorkers(ctx, client, workerNodeNames(config),
+		k3sWorkerInstallCmd(config.K3sVersion, masterIP, accessToken), maxParallel)
+}
+
+func workerNodeNames(config *types.ClusterConfig) []string {
+	nodeNames := make([]string, 0, config.Size-1)
+	for i := 1; i < config.Size; i++ {
+		nodeNames = append(nodeNames, types.WorkerNodeName(config.Name, i))
+	}
+	return nodeNames
+}
+
+// joinWorkers runs the worker install command on the given nodes, at most limit at a
+// time, to join them to the master. Once ctx is cancelled no further installs are started.
+func joinWorkers(ctx context.Context, client multipass.Client, nodeNames []string, installCmd string,
+	limit int) []workerError {
+	return forEachNode(ctx, nodeNames, limit, func(nodeName string) error {
+		if _, err := executeK3sInstall(ctx, client, nodeName, installCmd); err != nil {
+			logger.Errorln("Failed to install K3S on worker node %s: %v", nodeName, err)
+			return err
+		}
+		logger.Successf("Installed K3S on worker node: %s\n", nodeName)
+		return nil
+	})
+}
+
+// forEachNode runs fn on the nodes concurrently, at most limit at a time, and returns the
+// errors by node. Once ctx is done no further calls are started and the nodes left fail
+// with the context error.
+func forEachNode(ctx context.Context, nodeNames []string, limit int, fn func(nodeName string) error) []workerError {
 	workerErrors := make([]workerError, 0)
-	var workerErrorsMutex sync.Mutex
+	var mu sync.Mutex
 	var wg sync.WaitGroup
+	sem := make(chan struct{}, max(limit, 1))
 
-	for i := 0; i < config.Size-1; i++ {
+	for _, nodeName := range nodeNames {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if err := ctx.Err(); err != nil {
+			mu.Lock()
+			workerErrors = append(workerErrors, workerError{nodeName: nodeName, err: err})
+			mu.Unlock()
+			continue
+		}
 		wg.Add(1)
-		go func(i int) {
+		go func(nodeName string) {
 			defer wg.Done()
-			nodeName := fmt.Sprintf("%s-worker-%d", config.Name, i+1)
-			_, err := client.ExecuteShellWithTimeout(
-				nodeName,
-				fmt.Sprintf(K3sCreateWorkerCmd, masterIP, accessToken),
-				K3sInstallTimeout,
-			)
-			if err != nil {
-				workerErrorsMutex.Lock()
-				workerErrors = append(workerErrors, workerError{
-					nodeName: nodeName,
-					err:      err,
-				})
-				workerErrorsMutex.Unlock()
-				logger.Errorln("Failed to install K3S on worker node %s: %v", nodeName, err)
-			} else {
-				logger.Successf("Successfully configured worker node: %s\n", nodeName)
+			defer func() { <-sem }()
+			if err := fn(nodeName); err != nil {
+				mu.Lock()
+				workerErrors = append(workerErrors, workerError{nodeName: nodeName, err: err})
+				mu.Unlock()
 			}
-		}(i)
+		}(nodeName)
 	}
 	wg.Wait()
 
 	return workerErrors
 }
 
+// verifyWorkerJoins waits for the workers of nodeNames whose install succeeded to become
+// Ready nodes, since a k3s agent can install cleanly yet fail to register, and adds those
+// that don't to workerErrors
+func verifyWorkerJoins(ctx context.Context, client multipass.Client, masterNodeName string,
+	nodeNames []string, workerErrors []workerError) []workerError {
+	joined := make([]string, 0, len(nodeNames))
+	for _, nodeName := range nodeNames {
+		if !slices.ContainsFunc(workerErrors, func(we workerError) bool { return we.nodeName == nodeName }) {
+			joined = append(joined, nodeName)
+		}
+	}
+	if len(joined) == 0 {
+		return workerErrors
+	}
+
+	kubeConfig, err := getKubeConfig(ctx, client, masterNodeName)
+	if err != nil {
+		logger.Warnln("Failed to get kubeconfig, cannot verify the worker nodes joined: %v", err)
+		return workerErrors
+	}
+	k8sClient, err := k8s.NewK8sClient(kubeConfig)
+	if err != nil {
+		logger.Warnln("Failed to create k8s client, cannot verify the worker nodes joined: %v", err)
+		return workerErrors
+	}
+	return appendUnreadyWorkers(ctx, k8sClient, joined, workerErrors, NodeReadyTimeout)
+}
+
+// appendUnreadyWorkers adds the workers of joined that aren't Ready nodes within timeout
+// to workerErrors
+func appendUnreadyWorkers(ctx context.Context, k8sClient *k8s.K8sClient, joined []string, workerErrors []workerError,
+	timeout time.Duration) []workerError {
+	logger.Infoln("Waiting for %d worker node(s) to register as Ready...", len(joined))
+	unready := k8sClient.WaitForNodesReady(ctx, joined, timeout)
+	for _, nodeName := range joined {
+		if err, ok := unready[nodeName]; ok {
+			logger.Errorln("Worker node %s failed to join the cluster: %v", nodeName, err)
+			workerErrors = append(workerErrors, workerError{nodeName: nodeName, err: err})
+		}
+	}
+	return workerErrors
+}
+
 func reportClusterCreationResults(config *types.ClusterConfig, workerErrors []workerError) {
 	if len(workerErrors) > 0 {
 		logger.Warnln("Some worker nodes failed to configure properly:")
@@ -193,23 +679,14 @@ func reportClusterCreationResults(config *types.ClusterConfig, workerErrors []wo
 	}
 }
 
-func updateKubeConfig(client multipass.Client, masterNodeName, clusterName string) error {
+func updateKubeConfig(ctx context.Context, client multipass.Client, masterNodeName, clusterName string) error {
 	logger.Infoln("Attempting to update kubeconfig...")
 
-	kubConfig, err := client.ExecuteShell(masterNodeName, KubeConfigCmd)
-	if err != nil || kubConfig == "" {
-		return fmt.Errorf("failed to get kube config: %w", err)
-	}
-
-	// Get master IP to replace 127.0.0.1 in kubeconfig
-	masterIP, err := client.GetNodeIP(masterNodeName)
+	kubConfig, err := getKubeConfig(ctx, client, masterNodeName)
 	if err != nil {
-		return fmt.Errorf("failed to get master IP: %w", err)
+		return err
 	}
 
-	// Replace localhost with master IP
-	kubConfig = strings.ReplaceAll(kubConfig, "127.0.0.1", masterIP)
-
 	if err := createKubeConfigFile(kubConfig, clusterName); err != nil {
 		logger.Errorln("Failed to update kubeconfig: %v", err)
 		logger.Warnln("Cluster created successfully, but kubeconfig update failed.")
@@ -221,85 +698,166 @@ func updateKubeConfig(client multipass.Client, masterNodeName, clusterName strin
 	return nil
 }
 
-func createKubeConfigFile(kubeConfig, clusterName string) error {
-	// Use client-go to properly parse the K3s kubeconfig format
-	newConfig, err := clientcmd.Load([]byte(kubeConfig))
+func getKubeConfig(ctx context.Context, client multipass.Client, masterNodeName string) (string, error) {
+	kubConfig, err := client.ExecuteShellContext(ctx, masterNodeName, KubeConfigCmd)
+	if err != nil || kubConfig == "" {
+		return "", fmt.Errorf("failed to get kube config: %w", err)
+	}
+
+	// Get master IP to replace 127.0.0.1 in kubeconfig
+	masterIP, err := client.GetNodeIP(masterNodeName)
 	if err != nil {
-		return fmt.Errorf("failed to parse new kubeconfig: %w", err)
+		return "", fmt.Errorf("failed to get master IP: %w", err)
 	}
 
-	// Update context and cluster names to include cluster name
-	contextName := fmt.Sprintf("%s-context", clusterName)
-	clusterKey := fmt.Sprintf("%s-cluster", clusterName)
-	userKey := fmt.Sprintf("%s-user", clusterName)
+	// Replace localhost with master IP
+	return strings.ReplaceAll(kubConfig, "127.0.0.1", masterIP), nil
+}
 
-	// Rename the default entries to use cluster-specific names
-	if cluster, exists := newConfig.Clusters["default"]; exists {
-		delete(newConfig.Clusters, "default")
-		newConfig.Clusters[clusterKey] = cluster
-	}
+// workerScheduling holds the labels and taints to apply to a single worker node
+type workerScheduling struct {
+	labels map[string]string
+	taints []corev1.Taint
+}
 
-	if authInfo, exists := newConfig.AuthInfos["default"]; exists {
-		delete(newConfig.AuthInfos, "default")
-		newConfig.AuthInfos[userKey] = authInfo
+// resolveWorkerScheduling maps the worker label and taint flags onto the given worker
+// nodes. Entries without a worker index apply to every one of them.
+func resolveWorkerScheduling(config *types.ClusterConfig, nodeNames []string) (map[string]*workerScheduling, error) {
+	result := make(map[string]*workerScheduling)
+	targets := func(index int) []string {
+		if index == 0 {
+			return nodeNames
+		}
+		if nodeName := types.WorkerNodeName(config.Name, index); slices.Contains(nodeNames, nodeName) {
+			return []string{nodeName}
+		}
+		return nil
 	}
-
-	if context, exists := newConfig.Contexts["default"]; exists {
-		delete(newConfig.Contexts, "default")
-		context.Cluster = clusterKey
-		context.AuthInfo = userKey
-		newConfig.Contexts[contextName] = context
+	entry := func(nodeName string) *workerScheduling {
+		if _, ok := result[nodeName]; !ok {
+			result[nodeName] = &workerScheduling{labels: map[string]string{}}
+		}
+		return result[nodeName]
 	}
 
-	// Set current context to the new cluster
-	newConfig.CurrentContext = contextName
-
-	kubeconfigPath := filepath.Join(homedir.HomeDir(), ".kube", "config")
-	var existingConfig *api.Config
-
-	if _, err := os.Stat(kubeconfigPath); os.IsNotExist(err) {
-		existingConfig = api.NewConfig()
-	} else {
-		existingConfig, err = clientcmd.LoadFromFile(kubeconfigPath)
+	for _, l := range config.WorkerLabels {
+		label, err := types.ParseNodeLabel(l)
 		if err != nil {
-			return fmt.Errorf("failed to load existing kubeconfig: %w", err)
+			return nil, err
+		}
+		for _, nodeName := range targets(label.WorkerIndex) {
+			entry(nodeName).labels[label.Key] = label.Value
 		}
 	}
 
-	// Merge configurations
-	for name, cluster := range newConfig.Clusters {
-		existingConfig.Clusters[name] = cluster
+	for _, t := range config.WorkerTaints {
+		taint, err := types.ParseNodeTaint(t)
+		if err != nil {
+			return nil, err
+		}
+		for _, nodeName := range targets(taint.WorkerIndex) {
+			e := entry(nodeName)
+			e.taints = append(e.taints, corev1.Taint{
+				Key:    taint.Key,
+				Value:  taint.Value,
+				Effect: corev1.TaintEffect(taint.Effect),
+			})
+		}
 	}
 
-	for name, authInfo := range newConfig.AuthInfos {
-		existingConfig.AuthInfos[name] = authInfo
+	return result, nil
+}
+
+// applyWorkerScheduling applies the worker labels and taints to the given worker nodes,
+// skipping the ones in workerErrors
+func applyWorkerScheduling(ctx context.Context, client multipass.Client, config *types.ClusterConfig,
+	masterNodeName string, nodeNames []string, workerErrors []workerError) {
+	if len(config.WorkerLabels) == 0 && len(config.WorkerTaints) == 0 {
+		return
 	}
 
-	for name, context := range newConfig.Contexts {
-		existingConfig.Contexts[name] = context
+	scheduling, err := resolveWorkerScheduling(config, nodeNames)
+	if err != nil {
+		logger.Errorln("Failed to resolve worker labels and taints: %v", err)
+		return
 	}
 
-	// Set current context to the new cluster
-	existingConfig.CurrentContext = contextName
+	kubeConfig, err := getKubeConfig(ctx, client, masterNodeName)
+	if err != nil {
+		logger.Errorln("Failed to get kubeconfig for worker labels and taints: %v", err)
+		return
+	}
+	k8sClient, err := k8s.NewK8sClient(kubeConfig)
+	if err != nil {
+		logger.Errorln("Failed to create k8s client for worker labels and taints: %v", err)
+		return
+	}
 
-	if err := clientcmd.WriteToFile(*existingConfig, kubeconfigPath); err != nil {
-		return fmt.Errorf("failed to write merged kubeconfig: %w", err)
+	failed := make(map[string]bool, len(workerErrors))
+	for _, we := range workerErrors {
+		failed[we.nodeName] = true
 	}
 
-	return nil
+	for nodeName, s := range scheduling {
+		if failed[nodeName] {
+			logger.Warnln("Skipping labels and taints for unconfigured worker node %s", nodeName)
+			continue
+		}
+		if err := k8sClient.WaitForNodeReady(nodeName, NodeReadyTimeout); err != nil {
+			logger.Errorln("Failed to wait for worker node %s: %v", nodeName, err)
+			continue
+		}
+		if err := k8sClient.LabelAndTaintNode(nodeName, s.labels, s.taints); err != nil {
+			logger.Errorln("Failed to apply labels and taints to %s: %v", nodeName, err)
+			continue
+		}
+		logger.Successln("Applied %d labels and %d taints to worker node %s", len(s.labels), len(s.taints), nodeName)
+	}
 }
 
 func init() {
 	createCmd.Flags().StringVarP(&cCreateName, "name", "n", "", "Name for the cluster (required)")
 	createCmd.Flags().IntVarP(&cCreateSize, "size", "s", 1, "Number of nodes in the cluster")
 	createCmd.Flags().BoolVarP(&withCoreComponents, "with-core-component", "c", false,
-		"Install core components (nginx,cert-manager)")
+		"Install core components (nginx-ingress, cert-manager, load-balancer) once the cluster is up")
 	createCmd.Flags().IntVarP(&masterCPUs, "master-cpus", "m", DefaultMasterCPUs, "Number of CPUs for the master node")
 	createCmd.Flags().StringVarP(&masterMemory, "master-memory", "M", "2G", "Memory for the master node")
 	createCmd.Flags().StringVarP(&masterDisk, "master-disk", "D", "20G", "Disk for the master node")
 	createCmd.Flags().IntVarP(&workerCPUs, "worker-cpus", "w", DefaultWorkerCPUs, "Number of CPUs for each worker node")
 	createCmd.Flags().StringVarP(&workerMemory, "worker-memory", "W", "2G", "Memory for each worker node")
 	createCmd.Flags().StringVarP(&workerDisk, "worker-disk", "d", "20G", "Disk for each worker node")
+	createCmd.Flags().StringArrayVar(&workerLabels, "worker-label", nil,
+		"Label for worker nodes as [index:]key=value, repeatable (no index applies to all workers)")
+	createCmd.Flags().StringArrayVar(&workerTaints, "worker-taint", nil,
+		"Taint for worker nodes as [index:]key=value:Effect, repeatable (no index applies to all workers)")
+	createCmd.Flags().BoolVar(&skipValidation, "skip-validation", false,
+		"Create the cluster even if the host looks short on memory or disk")
+	createCmd.Flags().StringVar(&k3sVersion, "k3s-version", "",
+		"k3s release to install, e.g. v1.30.4+k3s1 (defaults to the latest stable release)")
+	createCmd.Flags().StringArrayVar(&k3sArgs, "k3s-arg", nil,
+		"Extra k3s server argument for the master node, e.g. --k3s-arg=--disable=metrics-server, repeatable")
+	createCmd.Flags().StringArrayVar(&multipassArgs, "multipass-arg", nil,
+		"Extra argument appended verbatim to 'multipass launch' for every node, e.g. --multipass-arg=--bridged, "+
+			"repeatable. Name, CPUs, memory and disk can't be set this way; other misuse is your responsibility")
+	createCmd.Flags().StringArrayVar(&mounts, "mount", nil,
+		"Mount a host directory into the nodes as host/path:node/path, repeatable")
+	createCmd.Flags().BoolVar(&mountMasterOnly, "mount-master-only", false,
+		"Mount the --mount directories into the master node only")
+	createCmd.Flags().StringVar(&cloudInit, "cloud-init", "",
+		"cloud-init file to launch the nodes with, or "+types.CloudInitNone+
+			" for none (defaults to a built-in one installing curl and jq and tuning sysctls for k3s)")
+	createCmd.Flags().StringVar(&image, "image", "",
+		"Multipass image to launch the nodes from, e.g. 22.04 or jammy (defaults to the latest Ubuntu LTS)")
+	createCmd.Flags().StringVar(&domain, "domain", "",
+		"Domain ingress hostnames and TLS certificates use, e.g. dev.example.com (defaults to <name>.local)")
+	createCmd.Flags().BoolVar(&resume, "resume", false,
+		"Finish creating a cluster left partially created, reusing the nodes and k3s installs that exist")
+	createCmd.Flags().StringVarP(&createOutput, "output", "o", "text",
+		"Format of the host validation report: text, or json to print it on stdout with logs on stderr")
+	createCmd.Flags().IntVar(&maxParallel, "max-parallel", DefaultMaxParallel,
+		"Maximum number of nodes created or joined to the cluster at the same time")
+	createCmd.Flags().DurationVar(&createTimeout, "create-timeout", 0,
+		"Abort the create and remove its nodes if it takes longer than this, e.g. 20m (0 for no limit)")
 	if err := createCmd.MarkFlagRequired("name"); err != nil {
 		logger.Errorln("Failed to mark name flag as required: %v", err)
 	}
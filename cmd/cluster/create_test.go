@@ -1,23 +1,40 @@
 package cluster
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/mrgb7/playground/internal/k8s"
+	"github.com/mrgb7/playground/internal/multipass"
+	"github.com/mrgb7/playground/internal/validator"
 	"github.com/mrgb7/playground/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestConstants(t *testing.T) {
 	// Test that command constants are properly defined
-	if K3sCreateMasterCmd == "" {
-		t.Error("K3sCreateMasterCmd should not be empty")
+	if K3sInstallScriptURL == "" {
+		t.Error("K3sInstallScriptURL should not be empty")
 	}
 
 	if GetAccessTokenCmd == "" {
 		t.Error("GetAccessTokenCmd should not be empty")
 	}
 
-	if K3sCreateWorkerCmd == "" {
-		t.Error("K3sCreateWorkerCmd should not be empty")
+	if K3sDefaultServerArgs == "" {
+		t.Error("K3sDefaultServerArgs should not be empty")
 	}
 
 	if KubeConfigCmd == "" {
@@ -129,3 +146,645 @@ func TestValidateDiskFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestParseNodeLabelAndTaint(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		taint       bool
+		expectIndex int
+		expectError bool
+	}{
+		{"label all workers", "node-type=gpu", false, 0, false},
+		{"label indexed worker", "2:node-type=gpu", false, 2, false},
+		{"label prefixed key", "example.com/role=build", false, 0, false},
+		{"label missing value separator", "node-type", false, 0, true},
+		{"label zero index", "0:node-type=gpu", false, 0, true},
+		{"taint all workers", "dedicated=build:NoSchedule", true, 0, false},
+		{"taint indexed worker", "1:dedicated=build:NoExecute", true, 1, false},
+		{"taint without value", "dedicated:PreferNoSchedule", true, 0, false},
+		{"taint invalid effect", "dedicated=build:Never", true, 0, true},
+		{"taint missing effect", "dedicated=build", true, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var index int
+			var err error
+			if tt.taint {
+				var taint types.NodeTaint
+				taint, err = types.ParseNodeTaint(tt.value)
+				index = taint.WorkerIndex
+			} else {
+				var label types.NodeLabel
+				label, err = types.ParseNodeLabel(tt.value)
+				index = label.WorkerIndex
+			}
+			if tt.expectError && err == nil {
+				t.Errorf("Expected error for '%s' but got none", tt.value)
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error for '%s': %v", tt.value, err)
+			}
+			if !tt.expectError && index != tt.expectIndex {
+				t.Errorf("Expected worker index %d for '%s', got %d", tt.expectIndex, tt.value, index)
+			}
+		})
+	}
+}
+
+func TestResolveWorkerScheduling(t *testing.T) {
+	config := &types.ClusterConfig{
+		Name:         "test",
+		Size:         3,
+		WorkerLabels: []string{"env=dev", "2:node-type=gpu"},
+		WorkerTaints: []string{"2:dedicated=gpu:NoSchedule"},
+	}
+
+	scheduling, err := resolveWorkerScheduling(config, workerNodeNames(config))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(scheduling) != 2 {
+		t.Fatalf("Expected scheduling for 2 workers, got %d", len(scheduling))
+	}
+
+	first := scheduling["test-worker-1"]
+	if first == nil || first.labels["env"] != "dev" || len(first.labels) != 1 || len(first.taints) != 0 {
+		t.Errorf("Unexpected scheduling for test-worker-1: %+v", first)
+	}
+
+	second := scheduling["test-worker-2"]
+	if second == nil || second.labels["node-type"] != "gpu" || second.labels["env"] != "dev" {
+		t.Fatalf("Unexpected labels for test-worker-2: %+v", second)
+	}
+	if len(second.taints) != 1 || second.taints[0].Key != "dedicated" || second.taints[0].Effect != "NoSchedule" {
+		t.Errorf("Unexpected taints for test-worker-2: %+v", second.taints)
+	}
+}
+
+func TestValidateWorkerScheduling(t *testing.T) {
+	cl := types.NewCluster("test")
+	base := types.ClusterConfig{
+		Name: "test", Size: 2, MasterCPUs: 2, MasterMemory: "2G", MasterDisk: "20G",
+		WorkerCPUs: 2, WorkerMemory: "2G", WorkerDisk: "20G",
+	}
+
+	valid := base
+	valid.WorkerLabels = []string{"1:node-type=gpu"}
+	if err := cl.Validate(valid); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	outOfRange := base
+	outOfRange.WorkerTaints = []string{"2:dedicated=gpu:NoSchedule"}
+	if err := cl.Validate(outOfRange); err == nil {
+		t.Error("Expected error for taint targeting a missing worker but got none")
+	}
+
+	noWorkers := base
+	noWorkers.Size = 1
+	noWorkers.WorkerLabels = []string{"node-type=gpu"}
+	if err := cl.Validate(noWorkers); err == nil {
+		t.Error("Expected error for labels on a cluster without workers but got none")
+	}
+}
+
+// fakeMultipassClient records calls; methods not overridden panic through the nil embedded Client
+type fakeMultipassClient struct {
+	multipass.Client
+	mu       sync.Mutex
+	onCreate func()
+	// hang makes commands block until their context is done, like a stuck install
+	hang    bool
+	execs   []string
+	deleted []string
+}
+
+func (f *fakeMultipassClient) CreateNode(string, string, int, string, string, string, ...string) error {
+	if f.onCreate != nil {
+		f.onCreate()
+	}
+	return nil
+}
+
+func (f *fakeMultipassClient) DeleteCluster(clusterName string, _ *sync.WaitGroup) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deleted = append(f.deleted, clusterName)
+	return nil
+}
+
+func (f *fakeMultipassClient) ExecuteShellContext(ctx context.Context, name, _ string, _ ...string) (string, error) {
+	f.mu.Lock()
+	f.execs = append(f.execs, name)
+	f.mu.Unlock()
+	if f.hang {
+		<-ctx.Done()
+	}
+	return "ok", ctx.Err()
+}
+
+func TestJoinWorkersStopsWhenCancelled(t *testing.T) {
+	client := &fakeMultipassClient{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	nodes := []string{"dev-worker-1", "dev-worker-2"}
+	workerErrors := joinWorkers(ctx, client, nodes, k3sWorkerInstallCmd("", "10.0.0.1", "token"), DefaultMaxParallel)
+
+	if len(client.execs) != 0 {
+		t.Errorf("expected no worker installs after cancellation, got %v", client.execs)
+	}
+	if len(workerErrors) != len(nodes) {
+		t.Fatalf("expected %d worker errors, got %d", len(nodes), len(workerErrors))
+	}
+	for _, we := range workerErrors {
+		if !errors.Is(we.err, context.Canceled) {
+			t.Errorf("expected context.Canceled for %s, got %v", we.nodeName, we.err)
+		}
+	}
+}
+
+// countingClient tracks how many node operations run at the same time
+type countingClient struct {
+	multipass.Client
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+	fail        map[string]bool
+	mu          sync.Mutex
+	deleted     []string
+}
+
+func (c *countingClient) run(name string) error {
+	n := c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	for {
+		peak := c.maxInFlight.Load()
+		if n <= peak || c.maxInFlight.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	if c.fail[name] {
+		return fmt.Errorf("launch of %s failed", name)
+	}
+	return nil
+}
+
+func (c *countingClient) CreateNode(name, _ string, _ int, _, _, _ string, _ ...string) error {
+	return c.run(name)
+}
+
+func (c *countingClient) ExecuteShellContext(_ context.Context, name, _ string, _ ...string) (string, error) {
+	return "ok", c.run(name)
+}
+
+func (c *countingClient) DeleteCluster(clusterName string, _ *sync.WaitGroup) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deleted = append(c.deleted, clusterName)
+	return nil
+}
+
+func TestNodeOperationsRespectMaxParallel(t *testing.T) {
+	config := &types.ClusterConfig{Name: "dev", Size: 10}
+	nodes := workerNodeNames(config)
+
+	tests := []struct {
+		name  string
+		limit int
+		run   func(client *countingClient, limit int) error
+	}{
+		{
+			name:  "create nodes",
+			limit: 3,
+			run: func(client *countingClient, limit int) error {
+				maxParallel = limit
+				return createClusterNodes(context.Background(), client, config, "dev-master", "")
+			},
+		},
+		{
+			name:  "join workers",
+			limit: 2,
+			run: func(client *countingClient, limit int) error {
+				if errs := joinWorkers(context.Background(), client, nodes, "install", limit); len(errs) > 0 {
+					return fmt.Errorf("unexpected worker errors: %v", errs)
+				}
+				return nil
+			},
+		},
+	}
+
+	defer func(limit int) { maxParallel = limit }(maxParallel)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &countingClient{}
+			if err := tt.run(client, tt.limit); err != nil {
+				t.Fatal(err)
+			}
+			if peak := client.maxInFlight.Load(); peak > int32(tt.limit) || peak < 1 {
+				t.Errorf("expected at most %d concurrent operations, got %d", tt.limit, peak)
+			}
+		})
+	}
+}
+
+func TestCreateClusterNodesAggregatesErrors(t *testing.T) {
+	defer func(limit int) { maxParallel = limit }(maxParallel)
+	maxParallel = 2
+	client := &countingClient{fail: map[string]bool{"dev-worker-2": true, "dev-worker-4": true}}
+
+	err := createClusterNodes(context.Background(), client, &types.ClusterConfig{Name: "dev", Size: 5}, "dev-master", "")
+	if err == nil {
+		t.Fatal("expected the failed launches to be reported")
+	}
+	for _, node := range []string{"dev-worker-2", "dev-worker-4"} {
+		if !strings.Contains(err.Error(), node) {
+			t.Errorf("expected the error to mention %s, got %v", node, err)
+		}
+	}
+	if !reflect.DeepEqual(client.deleted, []string{"dev"}) {
+		t.Errorf("expected cluster dev to be cleaned up, got %v", client.deleted)
+	}
+}
+
+func TestExecuteClusterCreationCleansUpWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := &fakeMultipassClient{onCreate: cancel}
+
+	err := executeClusterCreation(ctx, client, &types.ClusterConfig{Name: "dev", Size: 3})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(client.execs) != 0 {
+		t.Errorf("expected no K3s installs after cancellation, got %v", client.execs)
+	}
+	if len(client.deleted) != 1 || client.deleted[0] != "dev" {
+		t.Errorf("expected cluster dev to be cleaned up, got %v", client.deleted)
+	}
+}
+
+func TestExecuteClusterCreationTimesOut(t *testing.T) {
+	ctx, cancel := withCreateTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	client := &fakeMultipassClient{hang: true}
+
+	err := executeClusterCreation(ctx, client, &types.ClusterConfig{Name: "dev", Size: 3})
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+	if !reflect.DeepEqual(client.execs, []string{"dev-master"}) {
+		t.Errorf("expected only the master install to start, got %v", client.execs)
+	}
+	if len(client.deleted) != 1 || client.deleted[0] != "dev" {
+		t.Errorf("expected cluster dev to be cleaned up, got %v", client.deleted)
+	}
+}
+
+func TestWithCreateTimeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		timeout     time.Duration
+		hasDeadline bool
+	}{
+		{name: "no limit", timeout: 0, hasDeadline: false},
+		{name: "bounded", timeout: time.Minute, hasDeadline: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := withCreateTimeout(context.Background(), tt.timeout)
+			defer cancel()
+			if _, ok := ctx.Deadline(); ok != tt.hasDeadline {
+				t.Errorf("expected deadline %v, got %v", tt.hasDeadline, ok)
+			}
+		})
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	config := &types.ClusterConfig{
+		Name: "dev", Size: 3,
+		MasterCPUs: 2, MasterMemory: "4G", MasterDisk: "20G",
+		WorkerCPUs: 2, WorkerMemory: "4G", WorkerDisk: "20G",
+	}
+	smallHost := func() (validator.HostResources, error) {
+		return validator.HostResources{CPUs: 4, MemoryMB: 4096, DiskMB: 100 * 1024}, nil
+	}
+	bigHost := func() (validator.HostResources, error) {
+		return validator.HostResources{CPUs: 16, MemoryMB: 64 * 1024, DiskMB: 500 * 1024}, nil
+	}
+	unknownHost := func() (validator.HostResources, error) {
+		return validator.HostResources{}, errors.New("unsupported")
+	}
+
+	tests := []struct {
+		name       string
+		skip       bool
+		host       func() (validator.HostResources, error)
+		wantErr    bool
+		wantChecks int
+	}{
+		{"under-resourced host is rejected", false, smallHost, true, 3},
+		{"skip validation bypasses the check", true, smallHost, false, 0},
+		{"enough resources", false, bigHost, false, 3},
+		{"unprobed host is not rejected", false, unknownHost, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := preflight(config, tt.skip, tt.host)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("preflight() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.skip {
+				if report != nil {
+					t.Errorf("expected no report when skipping validation, got %+v", report)
+				}
+				return
+			}
+			if report == nil || len(report.Checks) != tt.wantChecks {
+				t.Errorf("expected %d checks, got %+v", tt.wantChecks, report)
+			}
+		})
+	}
+}
+
+func TestWriteValidationReport(t *testing.T) {
+	report := validator.ValidateResources(
+		validator.ResourceRequirements{CPUs: 2, MemoryMB: 2048, DiskMB: 20 * 1024},
+		validator.HostResources{CPUs: 4, MemoryMB: 2048, DiskMB: 100 * 1024},
+	)
+
+	var buf bytes.Buffer
+	if err := writeValidationReport(&buf, report); err != nil {
+		t.Fatalf("writeValidationReport: %v", err)
+	}
+	var decoded validator.ValidationResult
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("expected the report to be valid JSON, got %q: %v", buf.String(), err)
+	}
+	if !reflect.DeepEqual(&decoded, report) {
+		t.Errorf("expected the report to round-trip, got %+v", decoded)
+	}
+}
+
+func TestK3sMasterInstallCmd(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  string
+		args     []string
+		expected string
+	}{
+		{"defaults", "", nil,
+			"curl -sfL https://get.k3s.io | sh -s - --disable=servicelb --disable=traefik"},
+		{"pinned version", "v1.30.4+k3s1", nil,
+			"curl -sfL https://get.k3s.io | INSTALL_K3S_VERSION=v1.30.4+k3s1 sh -s - --disable=servicelb --disable=traefik"},
+		{"extra args", "", []string{"--disable=metrics-server", "--node-label=env=dev"},
+			"curl -sfL https://get.k3s.io | sh -s - --disable=servicelb --disable=traefik " +
+				"'--disable=metrics-server' '--node-label=env=dev'"},
+		{"version and quoted arg", "v1.29.0-rc1+k3s1", []string{"--kubelet-arg=eviction-hard=memory.available<'100Mi'"},
+			"curl -sfL https://get.k3s.io | INSTALL_K3S_VERSION=v1.29.0-rc1+k3s1 sh -s - --disable=servicelb --disable=traefik " +
+				`'--kubelet-arg=eviction-hard=memory.available<'\''100Mi'\'''`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := k3sMasterInstallCmd(tt.version, tt.args); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestK3sWorkerInstallCmd(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  string
+		expected string
+	}{
+		{"latest", "", "curl -sfL https://get.k3s.io | K3S_URL=https://10.0.0.1:6443 K3S_TOKEN=token sh -"},
+		{"pinned version", "v1.30.4+k3s1",
+			"curl -sfL https://get.k3s.io | INSTALL_K3S_VERSION=v1.30.4+k3s1 K3S_URL=https://10.0.0.1:6443 K3S_TOKEN=token sh -"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := k3sWorkerInstallCmd(tt.version, "10.0.0.1", "token"); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestValidateK3sVersion(t *testing.T) {
+	tests := []struct {
+		version     string
+		expectError bool
+	}{
+		{"", false},
+		{"v1.30.4+k3s1", false},
+		{"v1.29.0-rc1+k3s1", false},
+		{"1.30.4+k3s1", true},
+		{"v1.30.4", true},
+		{"v1.30.4+k3s1; rm -rf /", true},
+		{"latest", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			err := types.ValidateK3sVersion(tt.version)
+			if tt.expectError && err == nil {
+				t.Errorf("Expected error for version %q but got none", tt.version)
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error for version %q: %v", tt.version, err)
+			}
+		})
+	}
+}
+
+func TestValidateDomain(t *testing.T) {
+	tests := []struct {
+		domain      string
+		expectError bool
+	}{
+		{"", false},
+		{"dev.example.com", false},
+		{"my-team.dev.example.io", false},
+		{"localdomain", true},
+		{"Dev.Example.com", true},
+		{"dev..example.com", true},
+		{"-dev.example.com", true},
+		{"dev.example.com.", true},
+		{"*.example.com", true},
+		{strings.Repeat("a", types.MaxDNSLabelLength) + ".example.com", false},
+		{strings.Repeat("a", types.MaxDNSLabelLength+1) + ".example.com", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			err := types.ValidateDomain(tt.domain)
+			if (err != nil) != tt.expectError {
+				t.Errorf("ValidateDomain(%q) error = %v, expectError %v", tt.domain, err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestValidateK3sArgs(t *testing.T) {
+	if err := types.ValidateK3sArgs([]string{"--disable=metrics-server", "--tls-san=dev.local"}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	for _, args := range [][]string{{"disable=metrics-server"}, {"--"}, {""}} {
+		if err := types.ValidateK3sArgs(args); err == nil {
+			t.Errorf("Expected error for args %q", args)
+		}
+	}
+}
+
+func TestPlanResume(t *testing.T) {
+	config := &types.ClusterConfig{Name: "dev", Size: 3}
+	tests := []struct {
+		name      string
+		nodes     []string
+		installed []string
+		expected  resumePlan
+	}{
+		{
+			name:      "complete cluster",
+			nodes:     []string{"dev-master", "dev-worker-1", "dev-worker-2"},
+			installed: []string{"dev-master", "dev-worker-1", "dev-worker-2"},
+			expected:  resumePlan{},
+		},
+		{
+			name:      "missing worker",
+			nodes:     []string{"dev-master", "dev-worker-1"},
+			installed: []string{"dev-master", "dev-worker-1"},
+			expected: resumePlan{
+				createWorkers: []string{"dev-worker-2"},
+				joinWorkers:   []string{"dev-worker-2"},
+			},
+		},
+		{
+			name:      "worker without k3s",
+			nodes:     []string{"dev-master", "dev-worker-1", "dev-worker-2"},
+			installed: []string{"dev-master", "dev-worker-2"},
+			expected:  resumePlan{joinWorkers: []string{"dev-worker-1"}},
+		},
+		{
+			name:      "master without k3s rejoins every worker",
+			nodes:     []string{"dev-master", "dev-worker-1"},
+			installed: []string{"dev-worker-1"},
+			expected: resumePlan{
+				installMaster: true,
+				createWorkers: []string{"dev-worker-2"},
+				joinWorkers:   []string{"dev-worker-1", "dev-worker-2"},
+			},
+		},
+		{
+			name:  "missing master",
+			nodes: []string{"dev-worker-1", "dev-worker-2"},
+			expected: resumePlan{
+				createMaster:  true,
+				installMaster: true,
+				joinWorkers:   []string{"dev-worker-1", "dev-worker-2"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installed := func(nodeName string) bool { return slices.Contains(tt.installed, nodeName) }
+			plan := planResume(config, tt.nodes, installed)
+			if !reflect.DeepEqual(plan, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, plan)
+			}
+		})
+	}
+}
+
+func TestCheckNameAvailable(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		hasState bool
+		expected error
+	}{
+		{
+			name:    "no instances",
+			payload: `{"list": []}`,
+		},
+		{
+			name:    "unrelated instances",
+			payload: `{"list": [{"name": "other-master", "state": "Running"}, {"name": "primary", "state": "Stopped"}]}`,
+		},
+		{
+			name:     "playground cluster",
+			payload:  `{"list": [{"name": "dev-master", "state": "Running"}, {"name": "dev-worker-1", "state": "Running"}]}`,
+			hasState: true,
+			expected: errClusterExists,
+		},
+		{
+			name:     "foreign VM named like a node",
+			payload:  `{"list": [{"name": "dev-master", "state": "Stopped"}]}`,
+			expected: errNameCollision,
+		},
+		{
+			name:     "foreign VM named like a worker",
+			payload:  `{"list": [{"name": "dev-worker-2", "state": "Running"}]}`,
+			expected: errNameCollision,
+		},
+		{
+			name:    "VMs sharing the prefix",
+			payload: `{"list": [{"name": "devbox", "state": "Running"}, {"name": "dev2-master", "state": "Running"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var list multipass.MultiPassList
+			if err := json.Unmarshal([]byte(tt.payload), &list); err != nil {
+				t.Fatalf("invalid payload: %v", err)
+			}
+			err := checkNameAvailable("dev", list.List, tt.hasState)
+			if tt.expected == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestAppendUnreadyWorkers(t *testing.T) {
+	ready := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "dev-worker-1"},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	k8sClient := &k8s.K8sClient{Clientset: fake.NewSimpleClientset(ready)}
+	workerErrors := []workerError{{nodeName: "dev-worker-3", err: errors.New("launch failed")}}
+
+	joined := []string{"dev-worker-1", "dev-worker-2"}
+	got := appendUnreadyWorkers(context.Background(), k8sClient, joined, workerErrors, 50*time.Millisecond)
+
+	names := make([]string, 0, len(got))
+	for _, we := range got {
+		names = append(names, we.nodeName)
+	}
+	if !reflect.DeepEqual(names, []string{"dev-worker-3", "dev-worker-2"}) {
+		t.Fatalf("expected dev-worker-2 to be appended after the existing error, got %v", names)
+	}
+	if got[1].err == nil {
+		t.Error("expected the unready worker to carry an error")
+	}
+}
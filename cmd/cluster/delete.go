@@ -1,12 +1,26 @@
 package cluster
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
 	"sync"
 
+	"github.com/mrgb7/playground/internal/k8s"
 	"github.com/mrgb7/playground/internal/multipass"
+	"github.com/mrgb7/playground/internal/state"
 	"github.com/mrgb7/playground/pkg/logger"
 	"github.com/mrgb7/playground/types"
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var (
+	cDeleteYes            bool
+	cDeleteKeepKubeConfig bool
+	cDeleteDrain          bool
 )
 
 var deleteCmd = &cobra.Command{
@@ -43,6 +57,22 @@ var deleteCmd = &cobra.Command{
 			logger.Errorln("Error: Cluster '%s' does not exist.", clusterToDelete)
 			return
 		}
+		if !confirmDelete(cmd.InOrStdin(), cmd.OutOrStdout(), clusterToDelete, cDeleteYes) {
+			logger.Infoln("Keeping cluster '%s'", clusterToDelete)
+			return
+		}
+		if st, err := state.Load(clusterToDelete); err == nil && len(st.Mounts) > 0 {
+			nodes, err := client.ListNodes(clusterToDelete)
+			if err == nil {
+				err = unmountHostPaths(client, &st.ClusterConfig, nodes)
+			}
+			if err != nil {
+				logger.Warnln("Failed to unmount host directories: %v", err)
+			}
+		}
+		if cDeleteDrain {
+			drainWorkers(cmd.Context(), client, clusterToDelete)
+		}
 		if err := client.DeleteCluster(clusterToDelete, &wg); err != nil {
 			logger.Errorln("Failed to delete cluster: %v", err)
 			return
@@ -55,6 +85,113 @@ var deleteCmd = &cobra.Command{
 			return
 		}
 
+		if cDeleteKeepKubeConfig {
+			logger.Infoln("Keeping the kubeconfig entries of cluster '%s'", clusterToDelete)
+		} else if err := removeKubeConfigEntries(clusterToDelete); err != nil {
+			logger.Warnln("Failed to remove cluster from kubeconfig: %v", err)
+		}
+
+		if err := state.Delete(clusterToDelete); err != nil {
+			logger.Warnln("Failed to remove cluster state: %v", err)
+		}
+
+		if err := verifyDeleted(client, clusterToDelete, defaultKubeConfigPath(), cDeleteKeepKubeConfig); err != nil {
+			logger.Errorln("%v", err)
+			return
+		}
+
 		logger.Successln("Successfully deleted cluster '%s'", clusterToDelete)
 	},
 }
+
+// confirmDelete asks whether a cluster should be deleted unless skip is set. Only y or yes
+// deletes it; an empty answer or no input keeps it.
+func confirmDelete(in io.Reader, out io.Writer, clusterName string, skip bool) bool {
+	if skip {
+		return true
+	}
+	ok, err := newPrompter(in, out).confirm(fmt.Sprintf("Delete cluster '%s' and all its nodes?", clusterName), false)
+	return err == nil && ok
+}
+
+// verifyDeleted checks a deleted cluster left nothing behind: no multipass instance,
+// including deleted ones purge failed to remove, and unless keepKubeConfig no context in
+// the kubeconfig at kubeConfigPath. Each leftover is logged and summarized in the error.
+func verifyDeleted(client multipass.Client, clusterName, kubeConfigPath string, keepKubeConfig bool) error {
+	var leftovers []string
+
+	instances, err := client.ListInstances()
+	if err != nil {
+		leftovers = append(leftovers, fmt.Sprintf("instances could not be listed: %v", err))
+	}
+	for _, instance := range instances {
+		if isClusterInstance(clusterName, instance.Name) {
+			leftovers = append(leftovers, fmt.Sprintf("instance %s is still listed as %s", instance.Name, instance.State))
+		}
+	}
+
+	if !keepKubeConfig {
+		if _, statErr := os.Stat(kubeConfigPath); statErr == nil {
+			config, err := clientcmd.LoadFromFile(kubeConfigPath)
+			switch {
+			case err != nil:
+				leftovers = append(leftovers, fmt.Sprintf("kubeconfig %s could not be read: %v", kubeConfigPath, err))
+			case config.Contexts[kubeContextName(clusterName)] != nil:
+				leftovers = append(leftovers, fmt.Sprintf("kubeconfig %s still has context %s",
+					kubeConfigPath, kubeContextName(clusterName)))
+			}
+		}
+	}
+
+	if len(leftovers) == 0 {
+		return nil
+	}
+	for _, leftover := range leftovers {
+		logger.Warnln("Leftover of cluster '%s': %s", clusterName, leftover)
+	}
+	return fmt.Errorf("cluster '%s' was not fully deleted, %d leftover(s): %s",
+		clusterName, len(leftovers), strings.Join(leftovers, "; "))
+}
+
+// isClusterInstance reports whether a multipass instance is the master or a worker of a
+// cluster, ignoring instances of clusters whose name merely starts with clusterName
+func isClusterInstance(clusterName, instanceName string) bool {
+	return instanceName == fmt.Sprintf("%s-master", clusterName) ||
+		len(workerIndices(clusterName, []string{instanceName})) > 0
+}
+
+// drainWorkers drains the workers of a cluster so their pods shut down gracefully before
+// the VMs are deleted. Failures are only logged since the cluster is deleted anyway.
+func drainWorkers(ctx context.Context, client multipass.Client, clusterName string) {
+	nodes, err := client.ListNodes(clusterName)
+	if err != nil {
+		logger.Warnln("Failed to list cluster nodes, skipping drain: %v", err)
+		return
+	}
+	kubeConfig, err := getKubeConfig(ctx, client, fmt.Sprintf("%s-master", clusterName))
+	if err != nil {
+		logger.Warnln("Failed to get kubeconfig, skipping drain: %v", err)
+		return
+	}
+	k8sClient, err := k8s.NewK8sClient(kubeConfig)
+	if err != nil {
+		logger.Warnln("Failed to create k8s client, skipping drain: %v", err)
+		return
+	}
+
+	for _, index := range workerIndices(clusterName, nodes) {
+		nodeName := types.WorkerNodeName(clusterName, index)
+		logger.Infoln("Draining worker node %s", nodeName)
+		if err := k8sClient.DrainNode(nodeName, DrainGracePeriod); err != nil {
+			logger.Warnln("Failed to drain worker node %s: %v", nodeName, err)
+		}
+	}
+}
+
+func init() {
+	deleteCmd.Flags().BoolVarP(&cDeleteYes, "yes", "y", false, "Delete the cluster without asking for confirmation")
+	deleteCmd.Flags().BoolVar(&cDeleteKeepKubeConfig, "keep-kubeconfig", false,
+		"Keep the cluster's kubeconfig entries, e.g. when the context name will be reused")
+	deleteCmd.Flags().BoolVar(&cDeleteDrain, "drain", false,
+		"Drain the worker nodes before deleting them so their pods shut down gracefully")
+}
@@ -0,0 +1,117 @@
+package cluster
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mrgb7/playground/internal/multipass"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestConfirmDelete(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		skip     bool
+		expected bool
+	}{
+		{"yes", "y\n", false, true},
+		{"full yes", "YES\n", false, true},
+		{"no", "n\n", false, false},
+		{"empty answer defaults to no", "\n", false, false},
+		{"invalid answer is asked again", "sure\ny\n", false, true},
+		{"no input", "", false, false},
+		{"--yes skips the prompt", "", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := confirmDelete(strings.NewReader(tt.input), io.Discard, "dev", tt.skip)
+			if got != tt.expected {
+				t.Errorf("input %q: expected %t, got %t", tt.input, tt.expected, got)
+			}
+		})
+	}
+}
+
+// fakeListClient reports a fixed instance list; other methods panic through the nil embedded Client
+type fakeListClient struct {
+	multipass.Client
+	instances []multipass.MultiPassListItem
+	err       error
+}
+
+func (f *fakeListClient) ListInstances() ([]multipass.MultiPassListItem, error) {
+	return f.instances, f.err
+}
+
+func TestVerifyDeleted(t *testing.T) {
+	renamed, err := renameKubeConfig(testK3sKubeConfig, "dev")
+	if err != nil {
+		t.Fatalf("renameKubeConfig: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		instances      []multipass.MultiPassListItem
+		listErr        error
+		kubeConfig     bool
+		keepKubeConfig bool
+		leftovers      []string
+	}{
+		{name: "nothing left"},
+		{
+			name: "other clusters are ignored",
+			instances: []multipass.MultiPassListItem{
+				{Name: "dev-2-master", State: "Running"},
+				{Name: "devbox", State: "Running"},
+				{Name: "dev-worker-x", State: "Running"},
+			},
+		},
+		{
+			name: "instance still listed",
+			instances: []multipass.MultiPassListItem{
+				{Name: "dev-master", State: "Deleted"},
+				{Name: "dev-worker-2", State: "Running"},
+			},
+			leftovers: []string{
+				"instance dev-master is still listed as Deleted",
+				"instance dev-worker-2 is still listed as Running",
+			},
+		},
+		{name: "listing fails", listErr: errors.New("multipass unavailable"), leftovers: []string{"multipass unavailable"}},
+		{name: "kubeconfig context left", kubeConfig: true, leftovers: []string{"still has context dev-context"}},
+		{name: "kubeconfig kept on purpose", kubeConfig: true, keepKubeConfig: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config")
+			if tt.kubeConfig {
+				if err := clientcmd.WriteToFile(*renamed, path); err != nil {
+					t.Fatalf("failed to write kubeconfig: %v", err)
+				}
+			}
+
+			client := &fakeListClient{instances: tt.instances, err: tt.listErr}
+			err := verifyDeleted(client, "dev", path, tt.keepKubeConfig)
+			if len(tt.leftovers) == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected leftovers %v to be reported", tt.leftovers)
+			}
+			for _, leftover := range tt.leftovers {
+				if !strings.Contains(err.Error(), leftover) {
+					t.Errorf("expected %q in %q", leftover, err.Error())
+				}
+			}
+		})
+	}
+}
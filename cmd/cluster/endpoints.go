@@ -0,0 +1,62 @@
+package cluster
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mrgb7/playground/internal/plugins"
+	"github.com/mrgb7/playground/pkg/logger"
+	"github.com/mrgb7/playground/types"
+	"github.com/spf13/cobra"
+)
+
+var cEndpointsName string
+
+var endpointsCmd = &cobra.Command{
+	Use:   "endpoints",
+	Short: "List the URLs of services exposed by installed plugins",
+	Long: `List the URLs the services of installed plugins are exposed at through the ingress
+plugin. URLs use https when the TLS plugin's issuer is available.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := types.ResolveCluster(cEndpointsName)
+		if err != nil {
+			logger.Errorln("%v", err)
+			return
+		}
+
+		pluginsList, err := plugins.CreatePluginsList(c.KubeConfig, c.MasterIP, c.Name)
+		if err != nil {
+			logger.Errorln("Failed to create plugins list: %v", err)
+			return
+		}
+
+		endpoints := clusterEndpoints(pluginsList, c.Name)
+		if len(endpoints) == 0 {
+			logger.Infoln("No endpoints found, install a plugin exposing a service such as argocd or dashboard")
+			return
+		}
+		for _, e := range endpoints {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", e.Name, e.URL)
+		}
+	},
+}
+
+// clusterEndpoints returns the endpoints of the installed plugins providing any
+func clusterEndpoints(pluginsList []plugins.Plugin, clusterName string) []plugins.Endpoint {
+	var endpoints []plugins.Endpoint
+	for _, p := range pluginsList {
+		provider, ok := p.(plugins.EndpointProvider)
+		if !ok || !strings.Contains(p.Status(), plugins.StatusRunning) {
+			continue
+		}
+		endpoints = append(endpoints, provider.GetEndpoints(clusterName)...)
+	}
+	return endpoints
+}
+
+func init() {
+	endpointsCmd.Flags().StringVarP(&cEndpointsName, "name", "n", "", "Name of the cluster")
+	if err := endpointsCmd.MarkFlagRequired("name"); err != nil {
+		logger.Errorln("Failed to mark name flag as required: %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mrgb7/playground/internal/plugins"
+)
+
+type fakePlugin struct {
+	name   string
+	status string
+}
+
+func (f *fakePlugin) GetName() string                                                { return f.name }
+func (f *fakePlugin) Install(kubeConfig, clusterName string, ensure ...bool) error   { return nil }
+func (f *fakePlugin) Uninstall(kubeConfig, clusterName string, ensure ...bool) error { return nil }
+func (f *fakePlugin) Status() string                                                 { return f.status }
+func (f *fakePlugin) GetOptions() plugins.PluginOptions                              { return plugins.PluginOptions{} }
+
+type fakeEndpointPlugin struct {
+	fakePlugin
+}
+
+func (f *fakeEndpointPlugin) GetEndpoints(clusterName string) []plugins.Endpoint {
+	url := "http://" + f.name + "." + clusterName + ".local"
+	return []plugins.Endpoint{{Name: f.name, URL: url, Protocol: plugins.ProtocolHTTP}}
+}
+
+func TestClusterEndpoints(t *testing.T) {
+	pluginsList := []plugins.Plugin{
+		&fakeEndpointPlugin{fakePlugin{name: "argocd", status: plugins.StatusRunning}},
+		&fakeEndpointPlugin{fakePlugin{name: "dashboard", status: plugins.StatusNotInstalled}},
+		&fakePlugin{name: "nginx-ingress", status: plugins.StatusRunning},
+		&fakeEndpointPlugin{fakePlugin{name: "demo", status: plugins.StatusRunning}},
+	}
+
+	expected := []plugins.Endpoint{
+		{Name: "argocd", URL: "http://argocd.dev.local", Protocol: plugins.ProtocolHTTP},
+		{Name: "demo", URL: "http://demo.dev.local", Protocol: plugins.ProtocolHTTP},
+	}
+	if endpoints := clusterEndpoints(pluginsList, "dev"); !reflect.DeepEqual(endpoints, expected) {
+		t.Errorf("expected %v, got %v", expected, endpoints)
+	}
+}
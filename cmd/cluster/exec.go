@@ -0,0 +1,142 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mrgb7/playground/internal/multipass"
+	"github.com/mrgb7/playground/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+const DefaultExecTimeout = 60
+
+var (
+	cExecName        string
+	execAll          bool
+	execWorkersOnly  bool
+	execTimeoutInSec int
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec [command]",
+	Short: "Run a shell command on cluster nodes",
+	Long: `Run a shell command on the master node, or with --all on every node of the cluster.
+Each line of output is prefixed with the name of the node it came from.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := multipass.NewMultipassClient()
+		if !client.IsMultipassInstalled() {
+			logger.Errorln("Error: Multipass is not installed or not in PATH. Please install Multipass first.")
+			return
+		}
+
+		nodes, err := resolveExecNodes(client, cExecName, execAll, execWorkersOnly)
+		if err != nil {
+			logger.Errorln("%v", err)
+			return
+		}
+
+		command := strings.Join(args, " ")
+		if err := execOnNodes(client, nodes, command, execTimeoutInSec, cmd.OutOrStdout()); err != nil {
+			logger.Errorln("%v", err)
+		}
+	},
+}
+
+// resolveExecNodes returns the nodes of the cluster to run on: the master by default,
+// every node with all, or only the workers with workersOnly
+func resolveExecNodes(client multipass.Client, clusterName string, all, workersOnly bool) ([]string, error) {
+	nodes, err := client.ListNodes(clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster nodes: %w", err)
+	}
+
+	master := fmt.Sprintf("%s-master", clusterName)
+	var workers []string
+	hasMaster := false
+	for _, node := range nodes {
+		switch {
+		case node == master:
+			hasMaster = true
+		case strings.HasPrefix(node, clusterName+"-worker-"):
+			workers = append(workers, node)
+		}
+	}
+	sort.Strings(workers)
+
+	if !hasMaster {
+		return nil, fmt.Errorf("cluster '%s' not found", clusterName)
+	}
+
+	switch {
+	case workersOnly:
+		if len(workers) == 0 {
+			return nil, fmt.Errorf("cluster '%s' has no worker nodes", clusterName)
+		}
+		return workers, nil
+	case all:
+		return append([]string{master}, workers...), nil
+	default:
+		return []string{master}, nil
+	}
+}
+
+// execOnNodes runs command concurrently on every node and writes each node's output to out
+// once it finishes, so lines of different nodes are not interleaved
+func execOnNodes(client multipass.Client, nodes []string, command string, timeoutSeconds int, out io.Writer) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	for _, node := range nodes {
+		wg.Add(1)
+		go func(node string) {
+			defer wg.Done()
+			output, err := client.ExecuteShellWithTimeout(node, command, timeoutSeconds)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if _, werr := io.WriteString(out, prefixLines(node, output)); werr != nil {
+				logger.Debugln("Failed to write output of node %s: %v", node, werr)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("command failed on node %s: %w", node, err))
+			}
+		}(node)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+func prefixLines(node, output string) string {
+	output = strings.TrimRight(output, "\n")
+	if output == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, line := range strings.Split(output, "\n") {
+		fmt.Fprintf(&b, "[%s] %s\n", node, line)
+	}
+	return b.String()
+}
+
+func init() {
+	flags := execCmd.Flags()
+	flags.StringVarP(&cExecName, "name", "n", "", "Name of the cluster")
+	flags.BoolVar(&execAll, "all", false, "Run the command on every node of the cluster")
+	flags.BoolVar(&execWorkersOnly, "workers-only", false, "Run the command on the worker nodes only")
+	flags.IntVar(&execTimeoutInSec, "timeout", DefaultExecTimeout, "Timeout in seconds for the command on each node")
+	execCmd.MarkFlagsMutuallyExclusive("all", "workers-only")
+	if err := execCmd.MarkFlagRequired("name"); err != nil {
+		logger.Errorln("Failed to mark name flag as required: %v", err)
+	}
+}
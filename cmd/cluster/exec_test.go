@@ -0,0 +1,121 @@
+package cluster
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mrgb7/playground/internal/multipass"
+)
+
+type fakeExecClient struct {
+	multipass.Client
+	mu      sync.Mutex
+	nodes   []string
+	outputs map[string]string
+	failing map[string]bool
+	ran     []string
+}
+
+func (f *fakeExecClient) ListNodes(string) ([]string, error) {
+	return f.nodes, nil
+}
+
+func (f *fakeExecClient) ExecuteShellWithTimeout(name, _ string, _ int, _ ...string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ran = append(f.ran, name)
+	if f.failing[name] {
+		return "", errors.New("exit status 1")
+	}
+	return f.outputs[name], nil
+}
+
+func TestResolveExecNodes(t *testing.T) {
+	client := &fakeExecClient{nodes: []string{"dev-worker-2", "dev-master", "dev-worker-1"}}
+
+	tests := []struct {
+		name        string
+		cluster     string
+		all         bool
+		workersOnly bool
+		expected    []string
+		expectError bool
+	}{
+		{"master by default", "dev", false, false, []string{"dev-master"}, false},
+		{"all nodes", "dev", true, false, []string{"dev-master", "dev-worker-1", "dev-worker-2"}, false},
+		{"workers only", "dev", false, true, []string{"dev-worker-1", "dev-worker-2"}, false},
+		{"unknown cluster", "prod", false, false, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveExecNodes(client, tt.cluster, tt.all, tt.workersOnly)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error, got nodes %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	if _, err := resolveExecNodes(&fakeExecClient{nodes: []string{"dev-master"}}, "dev", false, true); err == nil {
+		t.Error("expected error for --workers-only on a cluster without workers")
+	}
+}
+
+func TestExecOnNodes(t *testing.T) {
+	client := &fakeExecClient{
+		outputs: map[string]string{
+			"dev-master":   "up 1 day\nload 0.1\n",
+			"dev-worker-1": "up 2 days\n",
+		},
+		failing: map[string]bool{"dev-worker-2": true},
+	}
+	nodes := []string{"dev-master", "dev-worker-1", "dev-worker-2"}
+
+	var out bytes.Buffer
+	err := execOnNodes(client, nodes, "uptime", 10, &out)
+	if err == nil || !strings.Contains(err.Error(), "dev-worker-2") {
+		t.Errorf("expected error mentioning dev-worker-2, got %v", err)
+	}
+	if len(client.ran) != len(nodes) {
+		t.Errorf("expected command to run on %d nodes, ran on %v", len(nodes), client.ran)
+	}
+
+	for _, line := range []string{"[dev-master] up 1 day\n[dev-master] load 0.1\n", "[dev-worker-1] up 2 days\n"} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("expected output to contain %q, got %q", line, out.String())
+		}
+	}
+}
+
+func TestPrefixLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{"empty output", "", ""},
+		{"single line", "ok\n", "[n1] ok\n"},
+		{"multiple lines without trailing newline", "a\nb", "[n1] a\n[n1] b\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := prefixLines("n1", tt.output); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package cluster
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mrgb7/playground/internal/plugins"
+	"github.com/mrgb7/playground/internal/state"
+	"github.com/mrgb7/playground/pkg/logger"
+	"github.com/mrgb7/playground/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cExportName  string
+	exportOutput string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a cluster and its plugins to a definition file",
+	Long: `Write the node specs and k3s settings a cluster was created with, together with the
+installed plugins, their chart versions and override values, to a YAML file that
+'playground cluster import' recreates the cluster from. Prints to stdout without --output.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		st, err := state.Load(cExportName)
+		if err != nil {
+			logger.Errorln("No recorded configuration for cluster '%s', only clusters created with "+
+				"this version of playground can be exported: %v", cExportName, err)
+			return
+		}
+
+		c, err := types.ResolveCluster(cExportName)
+		if err != nil {
+			logger.Errorln("%v", err)
+			return
+		}
+
+		installed, err := installedPluginDefinitions(c)
+		if err != nil {
+			logger.Errorln("Failed to read installed plugins: %v", err)
+			return
+		}
+
+		def := buildDefinition(st.ClusterConfig, installed)
+		if exportOutput == "" {
+			data, err := def.Marshal()
+			if err != nil {
+				logger.Errorln("%v", err)
+				return
+			}
+			fmt.Fprint(cmd.OutOrStdout(), string(data))
+			return
+		}
+
+		if err := def.Save(exportOutput); err != nil {
+			logger.Errorln("%v", err)
+			return
+		}
+		logger.Successln("Exported cluster '%s' with %d plugins to %s", c.Name, len(def.Plugins), exportOutput)
+	},
+}
+
+// buildDefinition combines a cluster's config with its installed plugins. Host mounts and
+// cloud-init files point at files of this machine, so they are left out.
+func buildDefinition(config types.ClusterConfig, installed []state.PluginDefinition) *state.Definition {
+	if len(config.Mounts) > 0 {
+		logger.Warnln("Host mounts are specific to this machine and are not exported")
+		config.Mounts = nil
+		config.MountMasterOnly = false
+	}
+	if config.CloudInit != "" && config.CloudInit != types.CloudInitNone {
+		logger.Warnln("The cloud-init file is specific to this machine and is not exported")
+		config.CloudInit = ""
+	}
+
+	sort.Slice(installed, func(i, j int) bool {
+		return installed[i].Name < installed[j].Name
+	})
+	return &state.Definition{
+		APIVersion: state.DefinitionAPIVersion,
+		Cluster:    config,
+		Plugins:    installed,
+	}
+}
+
+// installedPluginDefinitions describes every plugin running on the cluster with the
+// chart version it was last installed at and its recorded override values
+func installedPluginDefinitions(c *types.Cluster) ([]state.PluginDefinition, error) {
+	pluginsList, err := plugins.CreatePluginsList(c.KubeConfig, c.MasterIP, c.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create plugins list: %w", err)
+	}
+
+	history, err := plugins.NewPluginHistory(c.KubeConfig)
+	if err != nil {
+		return nil, err
+	}
+	tracker, err := plugins.NewInstallerTracker(c.KubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create installer tracker: %w", err)
+	}
+
+	defs := make([]state.PluginDefinition, 0, len(pluginsList))
+	for _, plugin := range pluginsList {
+		if !plugins.IsPluginInstalled(plugin.Status()) {
+			continue
+		}
+
+		def := state.PluginDefinition{Name: plugin.GetName()}
+		events, err := history.GetEvents(plugin.GetName())
+		if err != nil {
+			logger.Warnln("Failed to read history of plugin %s: %v", plugin.GetName(), err)
+		}
+		def.Version = installedVersion(events, plugin.GetOptions().Version)
+
+		def.Values, err = tracker.GetPluginValues(plugin.GetName())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read values of plugin %s: %w", plugin.GetName(), err)
+		}
+		defs = append(defs, def)
+	}
+	return defs, nil
+}
+
+// installedVersion returns the version of the latest install, upgrade or rollback in
+// events, falling back to the version the plugin installs by default
+func installedVersion(events []plugins.HistoryEvent, fallback *string) string {
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Action == plugins.HistoryActionUninstall {
+			break
+		}
+		if events[i].Version != "" {
+			return events[i].Version
+		}
+	}
+	if fallback != nil {
+		return *fallback
+	}
+	return ""
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(&cExportName, "name", "n", "", "Name of the cluster (required)")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "File to write the definition to (default stdout)")
+	if err := exportCmd.MarkFlagRequired("name"); err != nil {
+		logger.Errorln("Failed to mark name flag as required: %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package cluster
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mrgb7/playground/internal/plugins"
+	"github.com/mrgb7/playground/internal/state"
+	"github.com/mrgb7/playground/types"
+)
+
+type fakeDefinedPlugin struct {
+	plugins.Plugin
+	version   string
+	values    map[string]interface{}
+	installed bool
+	invalid   bool
+}
+
+func (f *fakeDefinedPlugin) PinVersion(version string) { f.version = version }
+
+func (f *fakeDefinedPlugin) SetOverrideValues(values map[string]interface{}) { f.values = values }
+
+func (f *fakeDefinedPlugin) ValidateOverrideValues(values map[string]interface{}) error {
+	if f.invalid {
+		return errors.New("invalid")
+	}
+	return nil
+}
+
+func (f *fakeDefinedPlugin) Install(kubeConfig, clusterName string, ensure ...bool) error {
+	f.installed = true
+	return nil
+}
+
+type fakeFixedPlugin struct {
+	plugins.Plugin
+	installed bool
+}
+
+func (f *fakeFixedPlugin) Install(kubeConfig, clusterName string, ensure ...bool) error {
+	f.installed = true
+	return nil
+}
+
+func TestBuildDefinition(t *testing.T) {
+	config := types.ClusterConfig{
+		Name:            "dev",
+		Size:            2,
+		K3sVersion:      "v1.30.4+k3s1",
+		Mounts:          []string{"/home/me/src:/src"},
+		MountMasterOnly: true,
+	}
+	installed := []state.PluginDefinition{{Name: "nginx-ingress"}, {Name: "cert-manager"}}
+
+	def := buildDefinition(config, installed)
+
+	if def.APIVersion != state.DefinitionAPIVersion {
+		t.Errorf("expected apiVersion %d, got %d", state.DefinitionAPIVersion, def.APIVersion)
+	}
+	if def.Cluster.Mounts != nil || def.Cluster.MountMasterOnly {
+		t.Errorf("expected host mounts to be dropped, got %v", def.Cluster.Mounts)
+	}
+	if def.Cluster.K3sVersion != config.K3sVersion {
+		t.Errorf("expected k3s version %s, got %s", config.K3sVersion, def.Cluster.K3sVersion)
+	}
+	if def.Plugins[0].Name != "cert-manager" || def.Plugins[1].Name != "nginx-ingress" {
+		t.Errorf("expected plugins sorted by name, got %v", def.Plugins)
+	}
+}
+
+func TestInstalledVersion(t *testing.T) {
+	fallback := "1.0.0"
+	tests := []struct {
+		name     string
+		events   []plugins.HistoryEvent
+		expected string
+	}{
+		{"no history", nil, fallback},
+		{"latest upgrade", []plugins.HistoryEvent{
+			{Action: plugins.HistoryActionInstall, Version: "1.1.0"},
+			{Action: plugins.HistoryActionUpgrade, Version: "1.2.0"},
+		}, "1.2.0"},
+		{"reinstalled after uninstall", []plugins.HistoryEvent{
+			{Action: plugins.HistoryActionInstall, Version: "1.1.0"},
+			{Action: plugins.HistoryActionUninstall},
+			{Action: plugins.HistoryActionInstall, Version: "1.3.0"},
+		}, "1.3.0"},
+		{"uninstall is latest", []plugins.HistoryEvent{
+			{Action: plugins.HistoryActionInstall, Version: "1.1.0"},
+			{Action: plugins.HistoryActionUninstall},
+		}, fallback},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := installedVersion(tt.events, &fallback); got != tt.expected {
+				t.Errorf("expected version %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	if got := installedVersion(nil, nil); got != "" {
+		t.Errorf("expected no version without a fallback, got %s", got)
+	}
+}
+
+func TestImportedConfig(t *testing.T) {
+	def := &state.Definition{Cluster: types.ClusterConfig{Name: "dev", Size: 3}}
+
+	if got := importedConfig(def, ""); got.Name != "dev" {
+		t.Errorf("expected exported name, got %s", got.Name)
+	}
+	if got := importedConfig(def, "copy"); got.Name != "copy" || got.Size != 3 {
+		t.Errorf("expected renamed config of size 3, got %+v", got)
+	}
+	if def.Cluster.Name != "dev" {
+		t.Errorf("expected definition to be left unchanged, got %s", def.Cluster.Name)
+	}
+}
+
+func TestInstallDefinedPlugin(t *testing.T) {
+	values := map[string]interface{}{"replicas": 2}
+
+	plugin := &fakeDefinedPlugin{}
+	def := state.PluginDefinition{Name: "argocd", Version: "8.0.0", Values: values}
+	if err := installDefinedPlugin(plugin, def, "kubeconfig", "dev"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !plugin.installed || plugin.version != "8.0.0" || !reflect.DeepEqual(plugin.values, values) {
+		t.Errorf("expected pinned version and values to be installed, got %+v", plugin)
+	}
+
+	plugin = &fakeDefinedPlugin{invalid: true}
+	if err := installDefinedPlugin(plugin, def, "kubeconfig", "dev"); err == nil || plugin.installed {
+		t.Error("expected invalid values to fail before installing")
+	}
+
+	fixed := &fakeFixedPlugin{}
+	if err := installDefinedPlugin(fixed, state.PluginDefinition{Name: "ingress", Version: "1.0.0"},
+		"kubeconfig", "dev"); err != nil || !fixed.installed {
+		t.Errorf("expected a plugin without version pinning to install, got %v", err)
+	}
+	if err := installDefinedPlugin(&fakeFixedPlugin{}, def, "kubeconfig", "dev"); err == nil {
+		t.Error("expected values for a plugin without override support to fail")
+	}
+}
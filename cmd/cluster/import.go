@@ -0,0 +1,163 @@
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/mrgb7/playground/internal/installer"
+	"github.com/mrgb7/playground/internal/plugins"
+	"github.com/mrgb7/playground/internal/state"
+	"github.com/mrgb7/playground/pkg/logger"
+	"github.com/mrgb7/playground/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importFile string
+	importName string
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Create a cluster and its plugins from a definition file",
+	Long: `Create the cluster described by a file written with 'playground cluster export' and
+install its plugins at the exported chart versions with the exported override values.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		def, err := state.LoadDefinition(importFile)
+		if err != nil {
+			logger.Errorln("%v", err)
+			return
+		}
+
+		config := importedConfig(def, importName)
+		if err := createCluster(cmd.Context(), &config); err != nil {
+			logger.Errorf("Failed to create cluster: %v", err)
+			return
+		}
+		if len(def.Plugins) == 0 {
+			return
+		}
+
+		c, err := types.ResolveCluster(config.Name)
+		if err != nil {
+			logger.Errorln("%v", err)
+			return
+		}
+		if err := installDefinitionPlugins(c, def.Plugins); err != nil {
+			logger.Errorln("Failed to install plugins: %v", err)
+			return
+		}
+		logger.Successln("Imported cluster '%s' with %d plugins", c.Name, len(def.Plugins))
+	},
+}
+
+// importedConfig returns the cluster config of a definition, renamed when name is set
+func importedConfig(def *state.Definition, name string) types.ClusterConfig {
+	config := def.Cluster
+	if name != "" {
+		config.Name = name
+	}
+	return config
+}
+
+// installDefinitionPlugins installs the defined plugins and the dependencies they need
+// in dependency order
+func installDefinitionPlugins(c *types.Cluster, defs []state.PluginDefinition) error {
+	pluginsList, err := plugins.CreatePluginsList(c.KubeConfig, c.MasterIP, c.Name)
+	if err != nil {
+		return fmt.Errorf("failed to create plugins list: %w", err)
+	}
+	pluginMap := make(map[string]plugins.Plugin, len(pluginsList))
+	for _, plugin := range pluginsList {
+		pluginMap[plugin.GetName()] = plugin
+	}
+
+	defMap := make(map[string]state.PluginDefinition, len(defs))
+	names := make([]string, 0, len(defs))
+	for _, def := range defs {
+		if _, ok := pluginMap[def.Name]; !ok {
+			return fmt.Errorf("unknown plugin %s", def.Name)
+		}
+		defMap[def.Name] = def
+		names = append(names, def.Name)
+	}
+
+	dependencyPlugins, err := plugins.CreateDependencyPluginsList(c.KubeConfig, c.MasterIP, c.Name)
+	if err != nil {
+		return fmt.Errorf("failed to create dependency plugins list: %w", err)
+	}
+	installOrder, err := plugins.NewDependencyValidator(dependencyPlugins).
+		ValidateInstallation(names, plugins.GetInstalledPlugins(c.KubeConfig))
+	if err != nil {
+		return fmt.Errorf("dependency validation failed: %w", err)
+	}
+	logger.Infoln("Plugin installation order: %v", installOrder)
+
+	for _, name := range installOrder {
+		def, ok := defMap[name]
+		if !ok {
+			def = state.PluginDefinition{Name: name}
+		}
+
+		if def.Values, err = plugins.NormalizeOverrideValues(pluginMap[name], def.Values); err != nil {
+			return fmt.Errorf("invalid values for plugin %s: %w", name, err)
+		}
+
+		logger.Infoln("Installing plugin: %s", name)
+		if err := installDefinedPlugin(pluginMap[name], def, c.KubeConfig, c.Name); err != nil {
+			return fmt.Errorf("failed to install plugin %s: %w", name, err)
+		}
+		logger.Successln("Successfully installed %s", name)
+
+		if len(def.Values) > 0 {
+			recordPluginValues(c.KubeConfig, name, def.Values)
+		}
+	}
+	return nil
+}
+
+// installDefinedPlugin installs plugin at the version and with the values of its definition
+func installDefinedPlugin(plugin plugins.Plugin, def state.PluginDefinition, kubeConfig, clusterName string) error {
+	if def.Version != "" {
+		if pinnable, ok := plugin.(plugins.VersionPinnablePlugin); ok {
+			pinnable.PinVersion(def.Version)
+		}
+	}
+
+	if len(def.Values) > 0 {
+		overridable, ok := plugin.(plugins.OverridablePlugin)
+		if !ok {
+			return fmt.Errorf("plugin %s does not support override values", def.Name)
+		}
+		if validator, ok := plugin.(plugins.OverrideValidator); ok {
+			if err := validator.ValidateOverrideValues(def.Values); err != nil {
+				return fmt.Errorf("invalid values: %w", err)
+			}
+		}
+		overridable.SetOverrideValues(def.Values)
+	}
+
+	return plugin.Install(kubeConfig, clusterName, true)
+}
+
+// recordPluginValues stores the values a plugin was imported with, so later --override
+// installs reapply them like values given with --set
+func recordPluginValues(kubeConfig, pluginName string, values map[string]interface{}) {
+	tracker, err := plugins.NewInstallerTracker(kubeConfig)
+	if err != nil {
+		logger.Warnln("Failed to create installer tracker: %v", err)
+		return
+	}
+	if err := tracker.RecordPluginValues(pluginName, values); err != nil {
+		logger.Warnln("Failed to record values for plugin %s: %v", pluginName, err)
+	}
+}
+
+func init() {
+	importCmd.Flags().StringVarP(&importFile, "file", "f", "", "Cluster definition file to import (required)")
+	importCmd.Flags().StringVarP(&importName, "name", "n", "", "Create the cluster under this name instead of the exported one")
+	importCmd.Flags().IntVar(&installer.ArgoLocalPort, "argo-local-port", installer.DefaultLocalPort,
+		"Local port to port-forward ArgoCD to when plugins are installed through it")
+	if err := importCmd.MarkFlagRequired("file"); err != nil {
+		logger.Errorln("Failed to mark file flag as required: %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package cluster
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/mrgb7/playground/pkg/logger"
+	"github.com/mrgb7/playground/types"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/util/homedir"
+)
+
+var (
+	cKubeConfigName  string
+	kubeConfigRaw    bool
+	kubeConfigOutput string
+)
+
+var kubeconfigCmd = &cobra.Command{
+	Use:   "kubeconfig",
+	Short: "Export or merge the kubeconfig of a cluster",
+	Long: `Fetch the kubeconfig from the cluster's master node and merge it into ~/.kube/config,
+or into the file given with --output. Use --raw to print it instead.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := types.ResolveCluster(cKubeConfigName)
+		if err != nil {
+			logger.Errorln("%v", err)
+			return
+		}
+
+		if kubeConfigRaw {
+			fmt.Fprint(cmd.OutOrStdout(), c.KubeConfig)
+			return
+		}
+
+		path := kubeConfigOutput
+		if path == "" {
+			path = defaultKubeConfigPath()
+		}
+		if err := mergeKubeConfigFile(c.KubeConfig, c.Name, path); err != nil {
+			logger.Errorln("Failed to update kubeconfig: %v", err)
+			return
+		}
+		logger.Successln("Merged kubeconfig for cluster '%s' into %s (context %s)",
+			c.Name, path, kubeContextName(c.Name))
+	},
+}
+
+func defaultKubeConfigPath() string {
+	return filepath.Join(homedir.HomeDir(), ".kube", "config")
+}
+
+func kubeContextName(clusterName string) string {
+	return fmt.Sprintf("%s-context", clusterName)
+}
+
+func createKubeConfigFile(kubeConfig, clusterName string) error {
+	return mergeKubeConfigFile(kubeConfig, clusterName, defaultKubeConfigPath())
+}
+
+// renameKubeConfig parses a K3s kubeconfig and renames its "default" cluster, user
+// and context entries after the cluster so several clusters can share one file
+func renameKubeConfig(kubeConfig, clusterName string) (*api.Config, error) {
+	// Use client-go to properly parse the K3s kubeconfig format
+	newConfig, err := clientcmd.Load([]byte(kubeConfig))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse new kubeconfig: %w", err)
+	}
+
+	contextName := kubeContextName(clusterName)
+	clusterKey := fmt.Sprintf("%s-cluster", clusterName)
+	userKey := fmt.Sprintf("%s-user", clusterName)
+
+	// Rename the default entries to use cluster-specific names
+	if cluster, exists := newConfig.Clusters["default"]; exists {
+		delete(newConfig.Clusters, "default")
+		newConfig.Clusters[clusterKey] = cluster
+	}
+
+	if authInfo, exists := newConfig.AuthInfos["default"]; exists {
+		delete(newConfig.AuthInfos, "default")
+		newConfig.AuthInfos[userKey] = authInfo
+	}
+
+	if context, exists := newConfig.Contexts["default"]; exists {
+		delete(newConfig.Contexts, "default")
+		context.Cluster = clusterKey
+		context.AuthInfo = userKey
+		newConfig.Contexts[contextName] = context
+	}
+
+	newConfig.CurrentContext = contextName
+	return newConfig, nil
+}
+
+// mergeKubeConfigFile merges the renamed kubeconfig into the file at path, creating
+// it if needed, and makes the cluster the current context
+func mergeKubeConfigFile(kubeConfig, clusterName, path string) error {
+	newConfig, err := renameKubeConfig(kubeConfig, clusterName)
+	if err != nil {
+		return err
+	}
+
+	var existingConfig *api.Config
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		existingConfig = api.NewConfig()
+	} else {
+		existingConfig, err = clientcmd.LoadFromFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to load existing kubeconfig: %w", err)
+		}
+	}
+
+	// Merge configurations
+	for name, cluster := range newConfig.Clusters {
+		existingConfig.Clusters[name] = cluster
+	}
+
+	for name, authInfo := range newConfig.AuthInfos {
+		existingConfig.AuthInfos[name] = authInfo
+	}
+
+	for name, context := range newConfig.Contexts {
+		existingConfig.Contexts[name] = context
+	}
+
+	// Set current context to the new cluster
+	existingConfig.CurrentContext = newConfig.CurrentContext
+
+	if err := clientcmd.WriteToFile(*existingConfig, path); err != nil {
+		return fmt.Errorf("failed to write merged kubeconfig: %w", err)
+	}
+
+	return nil
+}
+
+func removeKubeConfigEntries(clusterName string) error {
+	return removeKubeConfigEntriesFromFile(clusterName, defaultKubeConfigPath())
+}
+
+// removeKubeConfigEntriesFromFile deletes the context, cluster and user that
+// mergeKubeConfigFile added for clusterName. If the current context pointed at the
+// cluster, it switches to the first remaining context or clears it.
+func removeKubeConfigEntriesFromFile(clusterName, path string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	config, err := clientcmd.LoadFromFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	contextName := kubeContextName(clusterName)
+	delete(config.Contexts, contextName)
+	delete(config.Clusters, fmt.Sprintf("%s-cluster", clusterName))
+	delete(config.AuthInfos, fmt.Sprintf("%s-user", clusterName))
+
+	if config.CurrentContext == contextName {
+		config.CurrentContext = ""
+		remaining := make([]string, 0, len(config.Contexts))
+		for name := range config.Contexts {
+			remaining = append(remaining, name)
+		}
+		if len(remaining) > 0 {
+			sort.Strings(remaining)
+			config.CurrentContext = remaining[0]
+			logger.Infoln("Switched current kubeconfig context to %s", config.CurrentContext)
+		}
+	}
+
+	if err := clientcmd.WriteToFile(*config, path); err != nil {
+		return fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	kubeconfigCmd.Flags().StringVarP(&cKubeConfigName, "name", "n", "", "Name of the cluster (required)")
+	kubeconfigCmd.Flags().BoolVar(&kubeConfigRaw, "raw", false, "Print the kubeconfig instead of merging it")
+	kubeconfigCmd.Flags().StringVarP(&kubeConfigOutput, "output", "o", "",
+		"Kubeconfig file to merge into (default ~/.kube/config)")
+	if err := kubeconfigCmd.MarkFlagRequired("name"); err != nil {
+		logger.Errorln("Failed to mark name flag as required: %v", err)
+	}
+}
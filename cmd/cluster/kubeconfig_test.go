@@ -0,0 +1,148 @@
+package cluster
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const testK3sKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://10.0.0.5:6443
+  name: default
+contexts:
+- context:
+    cluster: default
+    user: default
+  name: default
+current-context: default
+users:
+- name: default
+  user:
+    token: secret
+`
+
+func TestRenameKubeConfig(t *testing.T) {
+	cfg, err := renameKubeConfig(testK3sKubeConfig, "dev")
+	if err != nil {
+		t.Fatalf("renameKubeConfig: %v", err)
+	}
+
+	if _, ok := cfg.Clusters["dev-cluster"]; !ok {
+		t.Errorf("expected cluster dev-cluster, got %v", cfg.Clusters)
+	}
+	if _, ok := cfg.AuthInfos["dev-user"]; !ok {
+		t.Errorf("expected user dev-user, got %v", cfg.AuthInfos)
+	}
+	ctx, ok := cfg.Contexts["dev-context"]
+	if !ok {
+		t.Fatalf("expected context dev-context, got %v", cfg.Contexts)
+	}
+	if ctx.Cluster != "dev-cluster" || ctx.AuthInfo != "dev-user" {
+		t.Errorf("context points to %s/%s", ctx.Cluster, ctx.AuthInfo)
+	}
+	if cfg.CurrentContext != "dev-context" {
+		t.Errorf("expected current context dev-context, got %s", cfg.CurrentContext)
+	}
+	if _, ok := cfg.Clusters["default"]; ok {
+		t.Error("default cluster should have been renamed")
+	}
+}
+
+func TestMergeKubeConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".kube", "config")
+
+	if err := mergeKubeConfigFile(testK3sKubeConfig, "dev", path); err != nil {
+		t.Fatalf("first merge: %v", err)
+	}
+	if err := mergeKubeConfigFile(testK3sKubeConfig, "staging", path); err != nil {
+		t.Fatalf("second merge: %v", err)
+	}
+
+	cfg, err := clientcmd.LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("failed to load merged kubeconfig: %v", err)
+	}
+	for _, name := range []string{"dev-context", "staging-context"} {
+		if _, ok := cfg.Contexts[name]; !ok {
+			t.Errorf("expected context %s in merged kubeconfig", name)
+		}
+	}
+	if cfg.CurrentContext != "staging-context" {
+		t.Errorf("expected current context staging-context, got %s", cfg.CurrentContext)
+	}
+}
+
+func TestMergeKubeConfigFileInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := mergeKubeConfigFile("not: [valid", "dev", path); err == nil {
+		t.Error("expected error for invalid kubeconfig")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("kubeconfig should not be written on parse failure")
+	}
+}
+
+func TestRemoveKubeConfigEntries(t *testing.T) {
+	tests := []struct {
+		name            string
+		merge           []string
+		setCurrent      string
+		remove          string
+		expectedCurrent string
+	}{
+		{"switches to remaining context", []string{"alpha", "beta", "dev"}, "dev-context", "dev", "alpha-context"},
+		{"clears when nothing remains", []string{"dev"}, "dev-context", "dev", ""},
+		{"keeps unrelated current context", []string{"dev", "staging"}, "staging-context", "dev", "staging-context"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config")
+			for _, name := range tt.merge {
+				if err := mergeKubeConfigFile(testK3sKubeConfig, name, path); err != nil {
+					t.Fatalf("merge %s: %v", name, err)
+				}
+			}
+			cfg, err := clientcmd.LoadFromFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg.CurrentContext = tt.setCurrent
+			if err := clientcmd.WriteToFile(*cfg, path); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := removeKubeConfigEntriesFromFile(tt.remove, path); err != nil {
+				t.Fatalf("removeKubeConfigEntriesFromFile: %v", err)
+			}
+
+			cfg, err = clientcmd.LoadFromFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := cfg.Contexts[tt.remove+"-context"]; ok {
+				t.Error("context was not removed")
+			}
+			if _, ok := cfg.Clusters[tt.remove+"-cluster"]; ok {
+				t.Error("cluster was not removed")
+			}
+			if _, ok := cfg.AuthInfos[tt.remove+"-user"]; ok {
+				t.Error("user was not removed")
+			}
+			if cfg.CurrentContext != tt.expectedCurrent {
+				t.Errorf("expected current context %q, got %q", tt.expectedCurrent, cfg.CurrentContext)
+			}
+		})
+	}
+}
+
+func TestRemoveKubeConfigEntriesMissingFile(t *testing.T) {
+	if err := removeKubeConfigEntriesFromFile("dev", filepath.Join(t.TempDir(), "config")); err != nil {
+		t.Errorf("expected no error for missing kubeconfig, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package cluster
 
 import (
 	"github.com/mrgb7/playground/internal/multipass"
+	"github.com/mrgb7/playground/internal/state"
 	"github.com/mrgb7/playground/pkg/logger"
 	"github.com/spf13/cobra"
 )
@@ -31,7 +32,13 @@ var listCmd = &cobra.Command{
 
 		logger.Infoln("Available clusters:")
 		for _, cluster := range clusters {
-			logger.Infoln("  - %s", cluster)
+			st, err := state.Load(cluster)
+			if err != nil {
+				logger.Infoln("  - %s", cluster)
+				continue
+			}
+			logger.Infoln("  - %s (%d nodes, master %d CPU/%s, workers %d CPU/%s)", cluster,
+				st.Size, st.MasterCPUs, st.MasterMemory, st.WorkerCPUs, st.WorkerMemory)
 		}
 	},
 }
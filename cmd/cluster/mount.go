@@ -0,0 +1,193 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/mrgb7/playground/internal/multipass"
+	"github.com/mrgb7/playground/pkg/logger"
+	"github.com/mrgb7/playground/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cMountName string
+	cMountNode string
+)
+
+var mountCmd = &cobra.Command{
+	Use:   "mount host/path:node/path",
+	Short: "Mount a host directory into a node of a running cluster",
+	Long: `Mount a host directory into a node, the master unless --node is given. Mounting a
+target that is already mounted is not an error. The mount is not recorded with the
+cluster, so nodes added by scale don't get it.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := multipass.NewMultipassClient()
+		if err := mountIntoNode(client, cMountName, cMountNode, args[0]); err != nil {
+			logger.Errorln("Failed to mount: %v", err)
+			return
+		}
+	},
+}
+
+var unmountCmd = &cobra.Command{
+	Use:   "unmount node/path",
+	Short: "Remove a mount from a node of a running cluster",
+	Long:  `Remove the mount at node/path from a node, the master unless --node is given.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := multipass.NewMultipassClient()
+		if err := unmountFromNode(client, cMountName, cMountNode, args[0]); err != nil {
+			logger.Errorln("Failed to unmount: %v", err)
+			return
+		}
+	},
+}
+
+// mountIntoNode mounts the host directory of a host/path:node/path spec into a cluster node
+func mountIntoNode(client multipass.Client, clusterName, node, spec string) error {
+	mount, err := types.ParseNodeMount(spec)
+	if err != nil {
+		return err
+	}
+	if err := types.ValidateMountHostPath(mount.HostPath); err != nil {
+		return err
+	}
+	nodeName, err := resolveClusterNode(client, clusterName, node)
+	if err != nil {
+		return err
+	}
+
+	if err := client.Mount(nodeName, mount.HostPath, mount.NodePath); err != nil {
+		return err
+	}
+	logger.Successln("Mounted %s at %s:%s", mount.HostPath, nodeName, mount.NodePath)
+	return nil
+}
+
+// unmountFromNode removes the mount at nodePath from a cluster node
+func unmountFromNode(client multipass.Client, clusterName, node, nodePath string) error {
+	if !path.IsAbs(nodePath) {
+		return fmt.Errorf("node path %q must be absolute", nodePath)
+	}
+	nodeName, err := resolveClusterNode(client, clusterName, node)
+	if err != nil {
+		return err
+	}
+
+	if err := client.Unmount(nodeName, path.Clean(nodePath)); err != nil {
+		return err
+	}
+	logger.Successln("Unmounted %s:%s", nodeName, path.Clean(nodePath))
+	return nil
+}
+
+// resolveClusterNode returns the full name of a node of the cluster, accepting names
+// without the cluster prefix such as worker-1. An empty node selects the master.
+func resolveClusterNode(client multipass.Client, clusterName, node string) (string, error) {
+	if node == "" {
+		node = "master"
+	}
+	if !strings.HasPrefix(node, clusterName+"-") {
+		node = clusterName + "-" + node
+	}
+
+	nodes, err := client.ListNodes(clusterName)
+	if err != nil {
+		return "", fmt.Errorf("failed to list cluster nodes: %w", err)
+	}
+	if !slices.Contains(nodes, node) {
+		return "", fmt.Errorf("node '%s' is not part of cluster '%s'", node, clusterName)
+	}
+	return node, nil
+}
+
+// parseMounts parses the mount specs recorded in a cluster config
+func parseMounts(specs []string) ([]types.NodeMount, error) {
+	mounts := make([]types.NodeMount, 0, len(specs))
+	for _, spec := range specs {
+		mount, err := types.ParseNodeMount(spec)
+		if err != nil {
+			return nil, err
+		}
+		mounts = append(mounts, mount)
+	}
+	return mounts, nil
+}
+
+// normalizeMounts rewrites the mount specs with absolute host paths so they stay valid
+// when the cluster is scaled from another directory
+func normalizeMounts(config *types.ClusterConfig) error {
+	mounts, err := parseMounts(config.Mounts)
+	if err != nil {
+		return err
+	}
+	for i, mount := range mounts {
+		config.Mounts[i] = mount.String()
+	}
+	return nil
+}
+
+// mountedNodes filters nodes down to those host directories are mounted into
+func mountedNodes(config *types.ClusterConfig, nodes []string) []string {
+	if !config.MountMasterOnly {
+		return nodes
+	}
+	master := fmt.Sprintf("%s-master", config.Name)
+	for _, node := range nodes {
+		if node == master {
+			return []string{master}
+		}
+	}
+	return nil
+}
+
+// mountHostPaths mounts every recorded host directory into the given nodes
+func mountHostPaths(client multipass.Client, config *types.ClusterConfig, nodes []string) error {
+	mounts, err := parseMounts(config.Mounts)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, node := range mountedNodes(config, nodes) {
+		for _, mount := range mounts {
+			if err := client.Mount(node, mount.HostPath, mount.NodePath); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// unmountHostPaths removes the recorded mounts from the given nodes
+func unmountHostPaths(client multipass.Client, config *types.ClusterConfig, nodes []string) error {
+	mounts, err := parseMounts(config.Mounts)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, node := range mountedNodes(config, nodes) {
+		for _, mount := range mounts {
+			if err := client.Unmount(node, mount.NodePath); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func init() {
+	for _, c := range []*cobra.Command{mountCmd, unmountCmd} {
+		c.Flags().StringVarP(&cMountName, "name", "n", "", "Name of the cluster")
+		c.Flags().StringVar(&cMountNode, "node", "", "Node to use, e.g. worker-1 (defaults to the master)")
+		if err := c.MarkFlagRequired("name"); err != nil {
+			logger.Errorln("Failed to mark name flag as required: %v", err)
+		}
+	}
+}
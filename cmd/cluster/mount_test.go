@@ -0,0 +1,217 @@
+package cluster
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mrgb7/playground/internal/multipass"
+	"github.com/mrgb7/playground/types"
+)
+
+type fakeMountClient struct {
+	multipass.Client
+	mounted   []string
+	unmounted []string
+	failOn    string
+	nodes     []string
+}
+
+func (f *fakeMountClient) ListNodes(clusterName string) ([]string, error) {
+	return f.nodes, nil
+}
+
+func (f *fakeMountClient) Mount(name, source, target string) error {
+	if name == f.failOn {
+		return errors.New("mount failed")
+	}
+	f.mounted = append(f.mounted, source+" "+name+":"+target)
+	return nil
+}
+
+func (f *fakeMountClient) Unmount(name, target string) error {
+	f.unmounted = append(f.unmounted, name+":"+target)
+	return nil
+}
+
+func TestParseNodeMount(t *testing.T) {
+	abs, err := filepath.Abs("src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	absHome, err := filepath.Abs("/home/me/src")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		spec        string
+		expected    types.NodeMount
+		expectError bool
+	}{
+		{"/home/me/src:/src", types.NodeMount{HostPath: absHome, NodePath: "/src"}, false},
+		{"src:/mnt/src/", types.NodeMount{HostPath: abs, NodePath: "/mnt/src"}, false},
+		{"/home/me/src", types.NodeMount{}, true},
+		{"/home/me/src:", types.NodeMount{}, true},
+		{":/src", types.NodeMount{}, true},
+		{"/home/me/src:relative", types.NodeMount{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := types.ParseNodeMount(tt.spec)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error for %q", tt.spec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMountHostPaths(t *testing.T) {
+	hostDir := t.TempDir()
+	config := &types.ClusterConfig{Name: "dev", Mounts: []string{hostDir + ":/src"}}
+	nodes := []string{"dev-master", "dev-worker-1"}
+
+	client := &fakeMountClient{}
+	if err := mountHostPaths(client, config, nodes); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{hostDir + " dev-master:/src", hostDir + " dev-worker-1:/src"}
+	if !reflect.DeepEqual(client.mounted, expected) {
+		t.Errorf("expected mounts %v, got %v", expected, client.mounted)
+	}
+
+	config.MountMasterOnly = true
+	client = &fakeMountClient{}
+	if err := mountHostPaths(client, config, nodes); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(client.mounted, []string{hostDir + " dev-master:/src"}) {
+		t.Errorf("expected master only mount, got %v", client.mounted)
+	}
+	if err := mountHostPaths(client, config, []string{"dev-worker-2"}); err != nil || len(client.mounted) != 1 {
+		t.Errorf("expected no mounts on workers with master only, got %v, %v", client.mounted, err)
+	}
+
+	config.MountMasterOnly = false
+	client = &fakeMountClient{failOn: "dev-worker-1"}
+	if err := mountHostPaths(client, config, nodes); err == nil {
+		t.Error("expected error when a mount fails")
+	}
+	if len(client.mounted) != 1 {
+		t.Errorf("expected the other nodes to still be mounted, got %v", client.mounted)
+	}
+}
+
+func TestUnmountHostPaths(t *testing.T) {
+	config := &types.ClusterConfig{Name: "dev", Mounts: []string{"/gone:/src", "/data:/data"}}
+
+	client := &fakeMountClient{}
+	if err := unmountHostPaths(client, config, []string{"dev-master", "dev-worker-1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"dev-master:/src", "dev-master:/data", "dev-worker-1:/src", "dev-worker-1:/data"}
+	if !reflect.DeepEqual(client.unmounted, expected) {
+		t.Errorf("expected unmounts %v, got %v", expected, client.unmounted)
+	}
+}
+
+func TestNormalizeMounts(t *testing.T) {
+	abs, err := filepath.Abs("data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &types.ClusterConfig{Mounts: []string{"data:/data"}}
+	if err := normalizeMounts(config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.Mounts[0] != abs+":/data" {
+		t.Errorf("expected absolute host path, got %q", config.Mounts[0])
+	}
+}
+
+func TestValidateMountHostPath(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		hostPath    string
+		expectError bool
+	}{
+		{"existing directory", dir, false},
+		{"relative path", "src", true},
+		{"missing directory", filepath.Join(dir, "missing"), true},
+		{"file", file, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := types.ValidateMountHostPath(tt.hostPath)
+			if (err != nil) != tt.expectError {
+				t.Errorf("ValidateMountHostPath(%q) error = %v, expectError %v", tt.hostPath, err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestMountIntoNode(t *testing.T) {
+	hostDir := t.TempDir()
+	nodes := []string{"dev-master", "dev-worker-1"}
+
+	tests := []struct {
+		name        string
+		node        string
+		spec        string
+		expected    []string
+		expectError bool
+	}{
+		{name: "master by default", spec: hostDir + ":/src", expected: []string{hostDir + " dev-master:/src"}},
+		{name: "short node name", node: "worker-1", spec: hostDir + ":/src/",
+			expected: []string{hostDir + " dev-worker-1:/src"}},
+		{name: "full node name", node: "dev-worker-1", spec: hostDir + ":/src",
+			expected: []string{hostDir + " dev-worker-1:/src"}},
+		{name: "unknown node", node: "worker-2", spec: hostDir + ":/src", expectError: true},
+		{name: "missing host directory", spec: filepath.Join(hostDir, "missing") + ":/src", expectError: true},
+		{name: "relative node path", spec: hostDir + ":src", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeMountClient{nodes: nodes}
+			err := mountIntoNode(client, "dev", tt.node, tt.spec)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("mountIntoNode() error = %v, expectError %v", err, tt.expectError)
+			}
+			if !reflect.DeepEqual(client.mounted, tt.expected) {
+				t.Errorf("expected mounts %v, got %v", tt.expected, client.mounted)
+			}
+		})
+	}
+}
+
+func TestUnmountFromNode(t *testing.T) {
+	client := &fakeMountClient{nodes: []string{"dev-master"}}
+	if err := unmountFromNode(client, "dev", "", "/src/"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(client.unmounted, []string{"dev-master:/src"}) {
+		t.Errorf("expected master unmount, got %v", client.unmounted)
+	}
+	if err := unmountFromNode(client, "dev", "", "src"); err == nil {
+		t.Error("expected an error for a relative node path")
+	}
+}
@@ -0,0 +1,72 @@
+package cluster
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/mrgb7/playground/internal/plugins"
+	"github.com/mrgb7/playground/pkg/logger"
+	"github.com/mrgb7/playground/types"
+	"github.com/spf13/cobra"
+)
+
+var cOpenName string
+
+var openCmd = &cobra.Command{
+	Use:   "open [service]",
+	Short: "Open a service exposed by a plugin in the default browser",
+	Long: fmt.Sprintf(`Resolve the URL of a service from its ingress and open it in the default browser.
+Supported services: %s`, strings.Join(plugins.ExposedServices(), ", ")),
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := types.ResolveCluster(cOpenName)
+		if err != nil {
+			logger.Errorln("%v", err)
+			return
+		}
+
+		ingress, err := plugins.NewIngress(c.KubeConfig, c.Name)
+		if err != nil {
+			logger.Errorln("Failed to create ingress plugin: %v", err)
+			return
+		}
+
+		url, err := ingress.GetServiceURL(args[0])
+		if err != nil {
+			logger.Errorln("%v", err)
+			return
+		}
+
+		logger.Infoln("Opening %s", url)
+		if err := openBrowser(url); err != nil {
+			logger.Errorln("Failed to open browser: %v", err)
+			logger.Infoln("Open %s manually", url)
+		}
+	},
+}
+
+// browserCommand returns the command opening url in the default browser of the given OS
+func browserCommand(goos, url string) (string, []string) {
+	switch goos {
+	case "darwin":
+		return "open", []string{url}
+	case "windows":
+		return "rundll32", []string{"url.dll,FileProtocolHandler", url}
+	default:
+		return "xdg-open", []string{url}
+	}
+}
+
+func openBrowser(url string) error {
+	name, args := browserCommand(runtime.GOOS, url)
+	return exec.Command(name, args...).Start() //nolint:gosec
+}
+
+func init() {
+	openCmd.Flags().StringVarP(&cOpenName, "name", "n", "", "Name of the cluster")
+	if err := openCmd.MarkFlagRequired("name"); err != nil {
+		logger.Errorln("Failed to mark name flag as required: %v", err)
+	}
+}
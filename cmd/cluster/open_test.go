@@ -0,0 +1,33 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBrowserCommand(t *testing.T) {
+	const url = "https://argocd.dev.local"
+
+	tests := []struct {
+		goos         string
+		expectedName string
+		expectedArgs []string
+	}{
+		{"darwin", "open", []string{url}},
+		{"linux", "xdg-open", []string{url}},
+		{"freebsd", "xdg-open", []string{url}},
+		{"windows", "rundll32", []string{"url.dll,FileProtocolHandler", url}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			name, args := browserCommand(tt.goos, url)
+			if name != tt.expectedName {
+				t.Errorf("expected command %q, got %q", tt.expectedName, name)
+			}
+			if !reflect.DeepEqual(args, tt.expectedArgs) {
+				t.Errorf("expected args %v, got %v", tt.expectedArgs, args)
+			}
+		})
+	}
+}
@@ -1,15 +1,47 @@
 package plugin
 
 import (
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/mrgb7/playground/internal/k8s"
 	"github.com/mrgb7/playground/internal/plugins"
 	"github.com/mrgb7/playground/pkg/logger"
 	"github.com/mrgb7/playground/types"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
 )
 
 var (
-	pName string
-	cName string
+	pName         string
+	cName         string
+	lockfilePath  string
+	overrideMode  bool
+	setValues     []string
+	valuesFiles   []string
+	setFiles      []string
+	noDefaults    bool
+	issuerScope   string
+	pNamespace    string
+	dnsNames      []string
+	ipAddresses   []string
+	sharedCert    bool
+	rotateCA      bool
+	waitReady     bool
+	noWait        bool
+	repoUsername  string
+	repoPassword  string
+	valuesSHA256  string
+	offline       bool
+	installerType string
+)
+
+// Environment variables providing chart repository credentials when the flags are not set
+const (
+	RepoUsernameEnv = "PLAYGROUND_REPO_USERNAME"
+	RepoPasswordEnv = "PLAYGROUND_REPO_PASSWORD"
 )
 
 var addCmd = &cobra.Command{
@@ -17,63 +49,378 @@ var addCmd = &cobra.Command{
 	Short: "Add a new plugin",
 	Long:  `Add a new plugin to the cluster with automatic dependency resolution`,
 	Run: func(cmd *cobra.Command, args []string) {
-		c := types.Cluster{
-			Name: cName,
+		values := valueFlags{files: valuesFiles, setFiles: setFiles, sets: setValues}
+		if !values.empty() && !overrideMode {
+			logger.Errorln("--set, --set-file and --values require --override")
+			return
+		}
+		if noDefaults && !overrideMode {
+			logger.Errorln("--no-default-values requires --override")
+			return
 		}
 
-		ip := c.GetMasterIP()
-		if err := c.SetKubeConfig(); err != nil {
-			logger.Errorln("Failed to set kubeconfig: %v", err)
+		c, err := types.ResolveCluster(cName)
+		if err != nil {
+			logger.Errorln("%v", err)
 			return
 		}
 
-		installOrder, err := plugins.ValidateAndGetInstallOrder(pName, c.KubeConfig, ip, c.Name)
+		installLevels, err := plugins.ValidateAndGetInstallLevels(pName, c.KubeConfig, c.MasterIP, c.Name)
 		if err != nil {
 			logger.Errorln("Dependency validation failed: %v", err)
+			if hint := dependencyHint(err, c.Name); hint != "" {
+				logger.Infoln("%s", hint)
+			}
 			return
 		}
 
-		logger.Infoln("Plugin installation order: %v", installOrder)
+		if overrideMode {
+			installLevels = withReinstallTarget(installLevels, pName)
+		}
+		logger.Infoln("Plugin installation order: %v", installLevels)
 
-		pluginsList, err := plugins.CreatePluginsList(c.KubeConfig, ip, c.Name)
+		pluginsList, err := plugins.CreatePluginsList(c.KubeConfig, c.MasterIP, c.Name)
 		if err != nil {
 			logger.Errorln("Failed to create plugins list: %v", err)
 			return
 		}
 
+		var lock *plugins.Lockfile
+		if lockfilePath != "" {
+			lock, err = plugins.LoadLockfile(lockfilePath)
+			if err != nil {
+				logger.Errorln("Failed to load lockfile: %v", err)
+				return
+			}
+		}
+
 		pluginMap := make(map[string]plugins.Plugin)
 		for _, plugin := range pluginsList {
 			pluginMap[plugin.GetName()] = plugin
 		}
 
-		for _, pluginName := range installOrder {
-			plugin, exists := pluginMap[pluginName]
-			if !exists {
-				logger.Errorln("Plugin %s not found", pluginName)
+		// --namespace places the tls plugin's Issuer, and the whole plugin for others
+		scoped, isScoped := pluginMap[pName].(plugins.IssuerScopedPlugin)
+		switch {
+		case isScoped && (issuerScope != "" || pNamespace != ""):
+			if err := scoped.SetIssuerScope(issuerScope, pNamespace); err != nil {
+				logger.Errorln("Invalid issuer scope: %v", err)
 				return
 			}
-			status := plugin.Status()
-			if plugins.IsPluginInstalled(status) {
-				continue
+		case issuerScope != "":
+			logger.Errorln("Plugin %s does not support --issuer-scope", pName)
+			return
+		case pNamespace != "":
+			overridable, ok := pluginMap[pName].(plugins.NamespaceOverridable)
+			if !ok {
+				logger.Errorln("Plugin %s cannot be installed into another namespace", pName)
+				return
+			}
+			if err := overridable.SetNamespace(pNamespace); err != nil {
+				logger.Errorln("%v", err)
+				return
+			}
+		}
+
+		if len(dnsNames) > 0 || len(ipAddresses) > 0 {
+			sans, ok := pluginMap[pName].(plugins.SubjectAltNamesPlugin)
+			if !ok {
+				logger.Errorln("Plugin %s does not support --dns-names or --ip-addresses", pName)
+				return
 			}
+			if err := sans.AddSubjectAltNames(dnsNames, ipAddresses); err != nil {
+				logger.Errorln("Invalid certificate names: %v", err)
+				return
+			}
+		}
 
-			logger.Infoln("Installing plugin: %s", pluginName)
-			err := plugin.Install(c.KubeConfig, c.Name, true)
+		if rotateCA {
+			rotatable, ok := pluginMap[pName].(plugins.CARotatablePlugin)
+			if !ok {
+				logger.Errorln("Plugin %s does not support --rotate", pName)
+				return
+			}
+			if err := rotatable.RotateCA(); err != nil {
+				logger.Errorln("Failed to rotate the CA: %v", err)
+				return
+			}
+			logger.Successln("CA rotated successfully")
+			return
+		}
+
+		if sharedCert {
+			shared, ok := pluginMap[pName].(plugins.SharedCertificatePlugin)
+			if !ok {
+				logger.Errorln("Plugin %s does not support --shared-cert", pName)
+				return
+			}
+			shared.UseSharedCertificate()
+		}
+
+		if username, password := repoCredentials(); username != "" || password != "" {
+			target := pluginMap[pName]
+			creds, ok := target.(plugins.RepoCredentialsPlugin)
+			if !ok || target.GetOptions().ChartName == nil {
+				logger.Errorln("Plugin %s does not install a chart, repository credentials do not apply", pName)
+				return
+			}
+			creds.SetRepoCredentials(username, password)
+		}
+
+		if installerType != "" {
+			target := pluginMap[pName]
+			selectable, ok := target.(plugins.InstallerSelectablePlugin)
+			if !ok || target.GetOptions().ChartName == nil {
+				logger.Errorln("Plugin %s does not install a chart, --installer does not apply", pName)
+				return
+			}
+			if err := plugins.ValidateInstallerType(c.KubeConfig, installerType); err != nil {
+				logger.Errorln("%v", err)
+				return
+			}
+			selectable.SetInstallerType(installerType)
+		}
+
+		if valuesSHA256 != "" {
+			checksummed, ok := pluginMap[pName].(plugins.ValuesChecksumPlugin)
+			if !ok {
+				logger.Errorln("Plugin %s has no remote default values to verify", pName)
+				return
+			}
+			if err := checksummed.SetValuesChecksum(valuesSHA256); err != nil {
+				logger.Errorln("%v", err)
+				return
+			}
+		}
+
+		if offline {
+			for _, plugin := range pluginMap {
+				if o, ok := plugin.(plugins.OfflinePlugin); ok {
+					o.UseOffline()
+				}
+			}
+		}
+
+		var overrides map[string]interface{}
+		if overrideMode {
+			target, exists := pluginMap[pName]
+			if !exists {
+				logger.Errorln("Plugin %s not found", pName)
+				return
+			}
+			var stored map[string]interface{}
+			if values.empty() {
+				stored = loadStoredOverrides(c.KubeConfig, pName)
+			}
+			overrides, err = handlePluginOverride(target, values, stored)
 			if err != nil {
-				logger.Errorln("Error installing plugin %s: %v", pluginName, err)
+				logger.Errorln("Invalid override for plugin %s: %v", pName, err)
+				return
+			}
+			if noDefaults {
+				remote, ok := target.(plugins.RemoteDefaultsPlugin)
+				if !ok {
+					logger.Errorln("Plugin %s has no remote default values to skip", pName)
+					return
+				}
+				remote.SkipRemoteDefaults()
+			}
+		}
+
+		warnInsufficientResources(c.KubeConfig, hintedPluginsToInstall(installLevels, pluginMap))
+
+		li := &levelInstaller{cluster: c, pluginMap: pluginMap, lock: lock, wait: waitReady && !noWait}
+		if overrideMode && !values.empty() {
+			li.overrides = overrides
+		}
+		for _, level := range installLevels {
+			if err := li.installLevel(level); err != nil {
+				logger.Errorln("%v", err)
 				return
 			}
-			logger.Successln("Successfully installed %s", pluginName)
 		}
 
 		logger.Successln("All plugins installed successfully!")
 	},
 }
 
+// withReinstallTarget puts target in the last install level unless it is in one already.
+// The levels leave out installed plugins, but --override reinstalls an installed target.
+func withReinstallTarget(installLevels [][]string, target string) [][]string {
+	for _, level := range installLevels {
+		if slices.Contains(level, target) {
+			return installLevels
+		}
+	}
+	return append(installLevels, []string{target})
+}
+
+// hintedPluginsToInstall returns the plugins of installLevels with resource hints that are
+// about to be installed: those not installed yet, and the target when it is reinstalled
+func hintedPluginsToInstall(installLevels [][]string, pluginMap map[string]plugins.Plugin) []plugins.Plugin {
+	var toInstall []plugins.Plugin
+	for _, level := range installLevels {
+		for _, pluginName := range level {
+			plugin, exists := pluginMap[pluginName]
+			if _, hinted := plugin.(plugins.ResourceHinter); !exists || !hinted {
+				continue
+			}
+			reinstall := overrideMode && pluginName == pName
+			if reinstall || !plugins.IsPluginInstalled(plugin.Status()) {
+				toInstall = append(toInstall, plugin)
+			}
+		}
+	}
+	return toInstall
+}
+
+// warnInsufficientResources warns when the cluster can't allocate the resources the
+// plugins about to be installed need
+func warnInsufficientResources(kubeConfig string, toInstall []plugins.Plugin) {
+	if len(toInstall) == 0 {
+		return
+	}
+	k8sClient, err := k8s.NewK8sClient(kubeConfig)
+	if err != nil {
+		logger.Warnln("Failed to create k8s client, skipping the resource check: %v", err)
+		return
+	}
+	shortfalls, err := plugins.CheckClusterResources(k8sClient, toInstall)
+	if err != nil {
+		logger.Warnln("Failed to check cluster resources: %v", err)
+		return
+	}
+	for _, shortfall := range shortfalls {
+		logger.Warnln("The cluster may be too small for the plugins, %s", shortfall)
+	}
+}
+
+// levelInstaller installs the plugins of one dependency level concurrently
+type levelInstaller struct {
+	cluster   *types.Cluster
+	pluginMap map[string]plugins.Plugin
+	// wait makes every install block until the plugin's workloads are ready
+	wait bool
+	// overrides are the --set values recorded for the target plugin once it is reinstalled
+	overrides map[string]interface{}
+
+	lockMu sync.Mutex
+	lock   *plugins.Lockfile
+}
+
+// installLevel installs every plugin of level at the same time and returns the first
+// error once all of them are done, so no later level is started after a failure
+func (li *levelInstaller) installLevel(level []string) error {
+	for _, pluginName := range level {
+		if _, exists := li.pluginMap[pluginName]; !exists {
+			return fmt.Errorf("plugin %s not found", pluginName)
+		}
+	}
+
+	var g errgroup.Group
+	for _, pluginName := range level {
+		g.Go(func() error {
+			return li.install(pluginName)
+		})
+	}
+	return g.Wait()
+}
+
+func (li *levelInstaller) install(pluginName string) error {
+	plugin := li.pluginMap[pluginName]
+	reinstall := overrideMode && pluginName == pName
+	if plugins.IsPluginInstalled(plugin.Status()) && !reinstall {
+		return nil
+	}
+
+	lockable, isLockable := plugin.(plugins.LockablePlugin)
+	if li.lock != nil && isLockable {
+		li.lockMu.Lock()
+		entry, ok := li.lock.Plugins[pluginName]
+		li.lockMu.Unlock()
+		if ok {
+			lockable.PinLockEntry(&entry)
+		}
+	}
+
+	logger.Infoln("Installing plugin: %s", pluginName)
+	if err := plugin.Install(li.cluster.KubeConfig, li.cluster.Name, li.wait); err != nil {
+		return fmt.Errorf("error installing plugin %s: %w", pluginName, err)
+	}
+	logger.Successln("Successfully installed %s", pluginName)
+
+	if reinstall && li.overrides != nil {
+		recordOverrides(li.cluster.KubeConfig, pluginName, li.overrides)
+	}
+
+	if li.lock != nil && isLockable && lockable.LockEntry() != nil {
+		li.lockMu.Lock()
+		defer li.lockMu.Unlock()
+		li.lock.Plugins[pluginName] = *lockable.LockEntry()
+		if err := li.lock.Save(lockfilePath); err != nil {
+			logger.Warnln("Failed to update lockfile: %v", err)
+		}
+	}
+	return nil
+}
+
+// repoCredentials returns the chart repository credentials of the target plugin from
+// --repo-username/--repo-password, falling back to the environment
+func repoCredentials() (string, string) {
+	username, password := repoUsername, repoPassword
+	if username == "" {
+		username = os.Getenv(RepoUsernameEnv)
+	}
+	if password == "" {
+		password = os.Getenv(RepoPasswordEnv)
+	}
+	return username, password
+}
+
 func init() {
 	flags := addCmd.Flags()
 	flags.StringVarP(&pName, "name", "n", "", "Name of the plugin")
 	flags.StringVarP(&cName, "cluster", "c", "", "Name of the cluster")
+	flags.StringVar(&lockfilePath, "lockfile", "",
+		"Pin chart versions from this lockfile and record installs to it (e.g. "+plugins.LockfileName+")")
+	flags.BoolVar(&overrideMode, "override", false,
+		"Apply --set, --set-file and --values to the plugin, reinstalling it if it is already installed "+
+			"(without them the values of the previous override are reapplied)")
+	flags.StringArrayVar(&setValues, "set", nil, "Override a plugin value as key.path=value (repeatable, needs --override)")
+	flags.StringArrayVar(&valuesFiles, "values", nil,
+		"YAML file of override values, repeatable, --set-file and --set take precedence (needs --override)")
+	flags.StringArrayVar(&setFiles, "set-file", nil,
+		"Override a plugin value with the contents of a file as key.path=@file (repeatable, needs --override)")
+	flags.BoolVar(&noDefaults, "no-default-values", false,
+		"Skip the plugin's remote default values and use only --set and installed values (needs --override)")
+	flags.StringVar(&issuerScope, "issuer-scope", "",
+		"For the tls plugin: create a "+plugins.IssuerScopeCluster+" wide ClusterIssuer (default) or a "+
+			plugins.IssuerScopeNamespace+" Issuer")
+	flags.StringVar(&pNamespace, "namespace", "",
+		"Namespace to install the plugin into instead of its default one (argocd, dashboard), "+
+			"or for the tls plugin the namespace of --issuer-scope "+plugins.IssuerScopeNamespace)
+	flags.StringSliceVar(&dnsNames, "dns-names", nil,
+		"For the tls plugin: extra DNS names for the CA certificate (e.g. '*.apps.test,myapp.test')")
+	flags.StringSliceVar(&ipAddresses, "ip-addresses", nil, "For the tls plugin: extra IP addresses for the CA certificate")
+	flags.BoolVar(&rotateCA, "rotate", false,
+		"For the installed tls plugin: replace the CA and have cert-manager reissue the certificates it signed")
+	flags.BoolVar(&sharedCert, "shared-cert", false,
+		"For the ingress plugin: serve all ingresses of a namespace with one wildcard certificate for the cluster domain")
+	flags.BoolVar(&waitReady, "wait", true,
+		"Wait until each plugin's deployments, statefulsets and daemonsets are ready before installing the next level")
+	flags.BoolVar(&noWait, "no-wait", false, "Do not wait for installed plugins to become ready (same as --wait=false)")
+	addCmd.MarkFlagsMutuallyExclusive("wait", "no-wait")
+	flags.StringVar(&repoUsername, "repo-username", "",
+		"Username for the plugin's chart repository (defaults to $"+RepoUsernameEnv+")")
+	flags.StringVar(&repoPassword, "repo-password", "",
+		"Password for the plugin's chart repository (defaults to $"+RepoPasswordEnv+")")
+	flags.StringVar(&valuesSHA256, "values-sha256", "",
+		"Expected SHA256 of the plugin's remote default values file, the install fails on a mismatch (argocd)")
+	flags.BoolVar(&offline, "offline", false,
+		"Read remote default values files from ~/.playground/cache instead of the network, failing when not cached")
+	flags.StringVar(&installerType, "installer", "",
+		"Install the plugin with "+plugins.InstallerTypeHelm+" or "+plugins.InstallerTypeArgoCD+
+			" instead of the detected installer (argocd needs the argocd plugin)")
 	if err := addCmd.MarkFlagRequired("name"); err != nil {
 		logger.Errorln("Failed to mark name flag as required: %v", err)
 	}
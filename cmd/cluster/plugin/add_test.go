@@ -0,0 +1,168 @@
+package plugin
+
+import (
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mrgb7/playground/internal/plugins"
+	"github.com/mrgb7/playground/types"
+)
+
+type fakeLevelPlugin struct {
+	plugins.Plugin
+	status string
+	err    error
+	calls  *atomic.Int32
+	waited bool
+}
+
+func (f *fakeLevelPlugin) Status() string { return f.status }
+
+func (f *fakeLevelPlugin) Install(kubeConfig, clusterName string, ensure ...bool) error {
+	f.calls.Add(1)
+	f.waited = len(ensure) > 0 && ensure[0]
+	return f.err
+}
+
+func TestLevelInstallerInstallLevel(t *testing.T) {
+	calls := &atomic.Int32{}
+	li := &levelInstaller{
+		cluster: &types.Cluster{Name: "dev"},
+		pluginMap: map[string]plugins.Plugin{
+			"cert-manager":  &fakeLevelPlugin{calls: calls},
+			"load-balancer": &fakeLevelPlugin{calls: calls, err: errors.New("boom")},
+			"argocd":        &fakeLevelPlugin{calls: calls, status: "running"},
+		},
+	}
+
+	if err := li.installLevel([]string{"argocd", "cert-manager", "load-balancer"}); err == nil {
+		t.Error("expected the failed install to be reported")
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected the two missing plugins to be installed, got %d installs", calls.Load())
+	}
+
+	calls.Store(0)
+	if err := li.installLevel([]string{"cert-manager", "unknown"}); err == nil {
+		t.Error("expected an error for an unknown plugin")
+	}
+	if calls.Load() != 0 {
+		t.Errorf("expected no install to start for a level with an unknown plugin, got %d", calls.Load())
+	}
+}
+
+func TestWithReinstallTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		levels   [][]string
+		expected [][]string
+	}{
+		{
+			name:     "target already installed",
+			levels:   nil,
+			expected: [][]string{{"load-balancer"}},
+		},
+		{
+			name:     "installed target after missing dependencies",
+			levels:   [][]string{{"cert-manager"}},
+			expected: [][]string{{"cert-manager"}, {"load-balancer"}},
+		},
+		{
+			name:     "target not installed yet",
+			levels:   [][]string{{"cert-manager"}, {"load-balancer"}},
+			expected: [][]string{{"cert-manager"}, {"load-balancer"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withReinstallTarget(tt.levels, "load-balancer"); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestLevelInstallerReinstallsInstalledTarget(t *testing.T) {
+	oldOverride, oldName := overrideMode, pName
+	defer func() { overrideMode, pName = oldOverride, oldName }()
+	overrideMode, pName = true, "load-balancer"
+
+	calls := &atomic.Int32{}
+	li := &levelInstaller{
+		cluster: &types.Cluster{Name: "dev"},
+		pluginMap: map[string]plugins.Plugin{
+			"load-balancer": &fakeLevelPlugin{calls: calls, status: "running"},
+			"cert-manager":  &fakeLevelPlugin{calls: calls, status: "running"},
+		},
+	}
+
+	for _, level := range withReinstallTarget(nil, pName) {
+		if err := li.installLevel(level); err != nil {
+			t.Fatalf("installLevel: %v", err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected the installed target to be reinstalled once, got %d installs", calls.Load())
+	}
+}
+
+func TestLevelInstallerWait(t *testing.T) {
+	for _, wait := range []bool{true, false} {
+		plugin := &fakeLevelPlugin{calls: &atomic.Int32{}}
+		li := &levelInstaller{
+			cluster:   &types.Cluster{Name: "dev"},
+			pluginMap: map[string]plugins.Plugin{"cert-manager": plugin},
+			wait:      wait,
+		}
+		if err := li.installLevel([]string{"cert-manager"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if plugin.waited != wait {
+			t.Errorf("expected install to wait %v, got %v", wait, plugin.waited)
+		}
+	}
+}
+
+func TestRepoCredentials(t *testing.T) {
+	tests := []struct {
+		name             string
+		flagUsername     string
+		flagPassword     string
+		envUsername      string
+		envPassword      string
+		expectedUsername string
+		expectedPassword string
+	}{
+		{name: "none"},
+		{
+			name: "flags", flagUsername: "flag-user", flagPassword: "flag-pass",
+			expectedUsername: "flag-user", expectedPassword: "flag-pass",
+		},
+		{
+			name: "environment", envUsername: "env-user", envPassword: "env-pass",
+			expectedUsername: "env-user", expectedPassword: "env-pass",
+		},
+		{
+			name: "flags take precedence", flagUsername: "flag-user", envUsername: "env-user", envPassword: "env-pass",
+			expectedUsername: "flag-user", expectedPassword: "env-pass",
+		},
+	}
+
+	defer func(username, password string) { repoUsername, repoPassword = username, password }(repoUsername, repoPassword)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoUsername, repoPassword = tt.flagUsername, tt.flagPassword
+			t.Setenv(RepoUsernameEnv, tt.envUsername)
+			t.Setenv(RepoPasswordEnv, tt.envPassword)
+
+			username, password := repoCredentials()
+			if username != tt.expectedUsername || password != tt.expectedPassword {
+				t.Errorf("expected %q/%q, got %q/%q", tt.expectedUsername, tt.expectedPassword, username, password)
+			}
+		})
+	}
+}
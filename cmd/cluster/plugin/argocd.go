@@ -0,0 +1,174 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/mrgb7/playground/internal/installer"
+	"github.com/mrgb7/playground/internal/plugins"
+	"github.com/mrgb7/playground/pkg/logger"
+	"github.com/mrgb7/playground/types"
+	"github.com/spf13/cobra"
+)
+
+var copyPassword bool
+
+var argocdCmd = &cobra.Command{
+	Use:   "argocd",
+	Short: "Work with the ArgoCD installed by the argocd plugin",
+}
+
+var argocdPasswordCmd = &cobra.Command{
+	Use:   "password",
+	Short: "Print the ArgoCD admin password",
+	Long: `Print the ArgoCD admin password, or copy it to the clipboard with --copy. This is the password
+set with the admin.password override, read from the playground-argocd-admin secret, or else the one
+ArgoCD generated on install, read from the argocd-initial-admin-secret.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := types.ResolveCluster(cName)
+		if err != nil {
+			logger.Errorln("%v", err)
+			return
+		}
+
+		argo, err := installer.NewArgoInstaller(c.KubeConfig, c.Name)
+		if err != nil {
+			logger.Errorln("Failed to create ArgoCD installer: %v", err)
+			return
+		}
+		argo.ArgoNamespace = plugins.ArgoCDNamespace(c.KubeConfig)
+
+		password, err := argo.GetAdminPassword()
+		if errors.Is(err, installer.ErrAdminSecretNotFound) {
+			logger.Errorln("%v", err)
+			logger.Infoln("The initial secret is deleted once the admin password was changed, log in with the new " +
+				"password or reset it with 'argocd account update-password'")
+			return
+		}
+		if err != nil {
+			logger.Errorln("%v", err)
+			return
+		}
+
+		if !copyPassword {
+			fmt.Fprintln(cmd.OutOrStdout(), password)
+			return
+		}
+		if err := copyToClipboard(password); err != nil {
+			logger.Errorln("Failed to copy the password to the clipboard: %v", err)
+			return
+		}
+		logger.Successln("Copied the ArgoCD admin password to the clipboard")
+	},
+}
+
+// argoAppFlags are the flags of argocd app
+type argoAppFlags struct {
+	name, repo, path, revision, namespace, project string
+	recurse                                        bool
+	include, exclude                               string
+}
+
+var appFlags argoAppFlags
+
+var argocdAppCmd = &cobra.Command{
+	Use:   "app",
+	Short: "Deploy a directory of manifests from a git repository as an ArgoCD application",
+	Long: `Create an ArgoCD application syncing the manifests under --path of --repo into --namespace.
+With --project the application is created in that AppProject, which is created restricted to the
+repository and namespace when it does not exist. --recurse also reads the subdirectories of --path,
+e.g. for app-of-apps layouts.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := types.ResolveCluster(cName)
+		if err != nil {
+			logger.Errorln("%v", err)
+			return
+		}
+
+		argo, err := plugins.NewArgoInstaller(c.KubeConfig, c.Name)
+		if err != nil {
+			logger.Errorln("Failed to create ArgoCD installer: %v", err)
+			return
+		}
+		if err := argo.Install(appInstallOptions(appFlags, c.KubeConfig)); err != nil {
+			logger.Errorln("%v", err)
+			return
+		}
+		logger.Successln("Created ArgoCD application %s", appFlags.name)
+	},
+}
+
+// appInstallOptions returns the options of the application argocd app creates. The
+// directory settings are only sent when one of them is set.
+func appInstallOptions(flags argoAppFlags, kubeConfig string) *installer.InstallOptions {
+	opts := &installer.InstallOptions{
+		ApplicationName: flags.name,
+		RepoURL:         flags.repo,
+		Path:            flags.path,
+		Version:         flags.revision,
+		Namespace:       flags.namespace,
+		KubeConfig:      kubeConfig,
+		Project:         flags.project,
+		CreateNamespace: true,
+		Recurse:         flags.recurse,
+	}
+	if flags.include != "" || flags.exclude != "" {
+		opts.Directory = &installer.ArgoSourceDirectory{Include: flags.include, Exclude: flags.exclude}
+	}
+	return opts
+}
+
+// clipboardCommand returns the command writing its stdin to the clipboard of the given OS
+func clipboardCommand(goos string, wayland bool) (string, []string) {
+	switch {
+	case goos == "darwin":
+		return "pbcopy", nil
+	case goos == "windows":
+		return "clip", nil
+	case wayland:
+		return "wl-copy", nil
+	default:
+		return "xclip", []string{"-selection", "clipboard"}
+	}
+}
+
+func copyToClipboard(text string) error {
+	name, args := clipboardCommand(runtime.GOOS, os.Getenv("WAYLAND_DISPLAY") != "")
+	c := exec.Command(name, args...) //nolint:gosec
+	c.Stdin = strings.NewReader(text)
+	return c.Run()
+}
+
+func init() {
+	flags := argocdPasswordCmd.Flags()
+	flags.StringVarP(&cName, "cluster", "c", "", "Name of the cluster")
+	flags.BoolVar(&copyPassword, "copy", false, "Copy the password to the clipboard instead of printing it")
+	if err := argocdPasswordCmd.MarkFlagRequired("cluster"); err != nil {
+		logger.Errorln("Failed to mark cluster flag as required: %v", err)
+	}
+	argocdCmd.AddCommand(argocdPasswordCmd)
+
+	flags = argocdAppCmd.Flags()
+	flags.StringVarP(&cName, "cluster", "c", "", "Name of the cluster")
+	flags.StringVarP(&appFlags.name, "name", "n", "", "Name of the ArgoCD application")
+	flags.StringVar(&appFlags.repo, "repo", "", "URL of the git repository holding the manifests")
+	flags.StringVar(&appFlags.path, "path", ".", "Directory of the manifests in the repository")
+	flags.StringVar(&appFlags.revision, "revision", "HEAD", "Branch, tag or commit of the repository to sync")
+	flags.StringVar(&appFlags.namespace, "namespace", "default", "Namespace to deploy the manifests into")
+	flags.StringVar(&appFlags.project, "project", "",
+		"ArgoCD project of the application, created when missing (defaults to the default project)")
+	flags.BoolVar(&appFlags.recurse, "recurse", false, "Also read the manifests of the subdirectories of --path")
+	flags.StringVar(&appFlags.include, "include", "", "Glob of the manifest files to sync, e.g. '*.yaml'")
+	flags.StringVar(&appFlags.exclude, "exclude", "", "Glob of the manifest files to skip")
+	for _, name := range []string{"cluster", "name", "repo"} {
+		if err := argocdAppCmd.MarkFlagRequired(name); err != nil {
+			logger.Errorln("Failed to mark %s flag as required: %v", name, err)
+		}
+	}
+	argocdCmd.AddCommand(argocdAppCmd)
+	PluginCmd.AddCommand(argocdCmd)
+}
@@ -0,0 +1,79 @@
+package plugin
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mrgb7/playground/internal/installer"
+)
+
+func TestClipboardCommand(t *testing.T) {
+	tests := []struct {
+		goos         string
+		wayland      bool
+		expectedName string
+		expectedArgs []string
+	}{
+		{goos: "darwin", expectedName: "pbcopy"},
+		{goos: "windows", expectedName: "clip"},
+		{goos: "linux", wayland: true, expectedName: "wl-copy"},
+		{goos: "linux", expectedName: "xclip", expectedArgs: []string{"-selection", "clipboard"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			name, args := clipboardCommand(tt.goos, tt.wayland)
+			if name != tt.expectedName {
+				t.Errorf("expected command %q, got %q", tt.expectedName, name)
+			}
+			if !reflect.DeepEqual(args, tt.expectedArgs) {
+				t.Errorf("expected args %v, got %v", tt.expectedArgs, args)
+			}
+		})
+	}
+}
+
+func TestAppInstallOptions(t *testing.T) {
+	base := argoAppFlags{name: "guestbook", repo: "https://example.com/apps.git", path: "apps", revision: "main",
+		namespace: "guestbook", project: "team-a"}
+
+	tests := []struct {
+		name              string
+		modify            func(f *argoAppFlags)
+		expectedRecurse   bool
+		expectedDirectory *installer.ArgoSourceDirectory
+	}{
+		{name: "plain directory", modify: func(*argoAppFlags) {}},
+		{name: "recursive", modify: func(f *argoAppFlags) { f.recurse = true }, expectedRecurse: true},
+		{
+			name:              "include and exclude",
+			modify:            func(f *argoAppFlags) { f.include, f.exclude = "*.yaml", "secret-*" },
+			expectedDirectory: &installer.ArgoSourceDirectory{Include: "*.yaml", Exclude: "secret-*"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := base
+			tt.modify(&flags)
+			opts := appInstallOptions(flags, "kubeconfig")
+
+			if opts.ApplicationName != "guestbook" || opts.RepoURL != base.repo || opts.Path != "apps" ||
+				opts.Version != "main" || opts.Namespace != "guestbook" || opts.KubeConfig != "kubeconfig" {
+				t.Errorf("unexpected application options: %+v", opts)
+			}
+			if opts.Project != "team-a" {
+				t.Errorf("expected project team-a, got %q", opts.Project)
+			}
+			if opts.ChartName != nil || !opts.CreateNamespace {
+				t.Errorf("expected a directory source creating its namespace, got %+v", opts)
+			}
+			if opts.Recurse != tt.expectedRecurse {
+				t.Errorf("expected recurse %v, got %v", tt.expectedRecurse, opts.Recurse)
+			}
+			if !reflect.DeepEqual(opts.Directory, tt.expectedDirectory) {
+				t.Errorf("expected directory %+v, got %+v", tt.expectedDirectory, opts.Directory)
+			}
+		})
+	}
+}
@@ -1,82 +1,106 @@
 package plugin
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
 	"github.com/mrgb7/playground/internal/plugins"
 	"github.com/mrgb7/playground/pkg/logger"
 	"github.com/mrgb7/playground/types"
 	"github.com/spf13/cobra"
 )
 
+const (
+	depsFormatTree = "tree"
+	depsFormatDOT  = "dot"
+)
+
+var depsFormat string
+
 var depsCmd = &cobra.Command{
 	Use:   "deps",
 	Short: "Show plugin dependencies",
-	Long:  `Show dependency information for plugins including dependencies and dependents`,
+	Long: `Show the plugin dependency graph as an ASCII tree, or in Graphviz DOT format with --format dot.
+With --name only that plugin's transitive dependencies and dependents are shown.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		c := types.Cluster{
-			Name: cName,
+		if depsFormat != depsFormatTree && depsFormat != depsFormatDOT {
+			logger.Errorln("Invalid format %q, expected %s or %s", depsFormat, depsFormatTree, depsFormatDOT)
+			return
 		}
 
-		ip := c.GetMasterIP()
-		if err := c.SetKubeConfig(); err != nil {
-			logger.Errorln("Failed to set kubeconfig: %v", err)
+		c, err := types.ResolveCluster(cName)
+		if err != nil {
+			logger.Errorln("%v", err)
 			return
 		}
 
-		dependencyPlugins, err := plugins.CreateDependencyPluginsList(c.KubeConfig, ip, c.Name)
+		dependencyPlugins, err := plugins.CreateDependencyPluginsList(c.KubeConfig, c.MasterIP, c.Name)
 		if err != nil {
 			logger.Errorln("Failed to create dependency plugins list: %v", err)
 			return
 		}
 
-		validator := plugins.NewDependencyValidator(dependencyPlugins)
-
-		if pName != "" {
-			dependencies, dependents := validator.GetDependencyInfo(pName)
-
-			logger.Infoln("Plugin: %s", pName)
-			if len(dependencies) > 0 {
-				logger.Infoln("  Dependencies: %v", dependencies)
-			} else {
-				logger.Infoln("  Dependencies: none")
-			}
-
-			if len(dependents) > 0 {
-				logger.Infoln("  Dependents: %v", dependents)
-			} else {
-				logger.Infoln("  Dependents: none")
-			}
-		} else {
-			logger.Infoln("Plugin Dependency Information:")
-			logger.Infoln("=============================")
-
-			for _, plugin := range dependencyPlugins {
-				name := plugin.GetName()
-				dependencies, dependents := validator.GetDependencyInfo(name)
+		if err := writeDependencyGraph(cmd.OutOrStdout(), dependencyPlugins, pName, depsFormat); err != nil {
+			logger.Errorln("%v", err)
+		}
+	},
+}
 
-				logger.Infoln("")
-				logger.Infoln("Plugin: %s", name)
-				if len(dependencies) > 0 {
-					logger.Infoln("  Dependencies: %v", dependencies)
-				} else {
-					logger.Infoln("  Dependencies: none")
-				}
+// writeDependencyGraph renders the graph of the given plugins, or only the part around
+// pluginName when it is set, in the given format
+func writeDependencyGraph(out io.Writer, dependencyPlugins []plugins.DependencyPlugin, pluginName, format string) error {
+	graph := plugins.NewDependencyGraph()
+	for _, plugin := range dependencyPlugins {
+		graph.AddPlugin(plugin)
+	}
 
-				if len(dependents) > 0 {
-					logger.Infoln("  Dependents: %v", dependents)
-				} else {
-					logger.Infoln("  Dependents: none")
-				}
-			}
+	if pluginName != "" {
+		found := false
+		for _, plugin := range dependencyPlugins {
+			found = found || plugin.GetName() == pluginName
 		}
-	},
+		if !found {
+			return fmt.Errorf("plugin %s not found", pluginName)
+		}
+		graph = graph.Subgraph(pluginName)
+	}
+
+	rendered := graph.Tree()
+	if format == depsFormatDOT {
+		rendered = graph.DOT()
+	}
+	_, err := io.WriteString(out, rendered)
+	return err
 }
 
 func init() {
 	flags := depsCmd.Flags()
 	flags.StringVarP(&pName, "name", "n", "", "Name of the plugin (optional, shows all if not specified)")
 	flags.StringVarP(&cName, "cluster", "c", "", "Name of the cluster")
+	flags.StringVar(&depsFormat, "format", depsFormatTree, "Output format: "+depsFormatTree+" or "+depsFormatDOT)
 	if err := depsCmd.MarkFlagRequired("cluster"); err != nil {
 		logger.Errorln("Failed to mark cluster flag as required: %v", err)
 	}
 	PluginCmd.AddCommand(depsCmd)
 }
+
+// dependencyHint suggests how to resolve a dependency validation error, or returns an
+// empty string when there is nothing to suggest
+func dependencyHint(err error, clusterName string) string {
+	var missing *plugins.MissingDependencyError
+	var blocked *plugins.DependentBlockError
+	var cycle *plugins.CycleError
+	switch {
+	case errors.As(err, &missing):
+		return fmt.Sprintf("Install %s first with 'playground cluster plugin add --name <plugin> --cluster %s'",
+			strings.Join(missing.Missing, ", "), clusterName)
+	case errors.As(err, &blocked):
+		return fmt.Sprintf("Remove %s first, they need %s to keep working", strings.Join(blocked.Blockers, ", "),
+			blocked.Plugin)
+	case errors.As(err, &cycle) && len(cycle.Cycle) > 0:
+		return fmt.Sprintf("These plugins depend on each other: %s", strings.Join(cycle.Cycle, " -> "))
+	}
+	return ""
+}
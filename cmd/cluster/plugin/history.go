@@ -0,0 +1,60 @@
+package plugin
+
+import (
+	"time"
+
+	"github.com/mrgb7/playground/internal/plugins"
+	"github.com/mrgb7/playground/pkg/logger"
+	"github.com/mrgb7/playground/types"
+	"github.com/spf13/cobra"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the install history of a plugin",
+	Long:  `Show when a plugin was installed, upgraded, rolled back or uninstalled, and with which version`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := types.ResolveCluster(cName)
+		if err != nil {
+			logger.Errorln("%v", err)
+			return
+		}
+
+		history, err := plugins.NewPluginHistory(c.KubeConfig)
+		if err != nil {
+			logger.Errorln("Failed to create plugin history: %v", err)
+			return
+		}
+
+		events, err := history.GetEvents(pName)
+		if err != nil {
+			logger.Errorln("Failed to get history for plugin %s: %v", pName, err)
+			return
+		}
+
+		if len(events) == 0 {
+			logger.Infoln("No history recorded for plugin %s", pName)
+			return
+		}
+
+		logger.Infoln("History for plugin '%s':", pName)
+		logger.Infoln("  %-20s  %-10s  %-12s  %s", "TIME", "ACTION", "VERSION", "INSTALLER")
+		for _, event := range events {
+			logger.Infoln("  %-20s  %-10s  %-12s  %s", event.Time.Local().Format(time.DateTime),
+				event.Action, event.Version, event.Installer)
+		}
+	},
+}
+
+func init() {
+	flags := historyCmd.Flags()
+	flags.StringVarP(&pName, "name", "n", "", "Name of the plugin")
+	flags.StringVarP(&cName, "cluster", "c", "", "Name of the cluster")
+	if err := historyCmd.MarkFlagRequired("name"); err != nil {
+		logger.Errorln("Failed to mark name flag as required: %v", err)
+	}
+	if err := historyCmd.MarkFlagRequired("cluster"); err != nil {
+		logger.Errorln("Failed to mark cluster flag as required: %v", err)
+	}
+	PluginCmd.AddCommand(historyCmd)
+}
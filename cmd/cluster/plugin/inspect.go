@@ -0,0 +1,73 @@
+package plugin
+
+import (
+	"github.com/mrgb7/playground/internal/plugins"
+	"github.com/mrgb7/playground/pkg/logger"
+	"github.com/mrgb7/playground/types"
+	"github.com/spf13/cobra"
+)
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect",
+	Short: "List the Kubernetes resources a plugin manages",
+	Long: `List the namespaces, custom resources and ingresses a plugin created that exist in the cluster.
+After an uninstall the list is empty unless resources were left behind.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := types.ResolveCluster(cName)
+		if err != nil {
+			logger.Errorln("%v", err)
+			return
+		}
+
+		pluginsList, err := plugins.CreatePluginsList(c.KubeConfig, c.MasterIP, c.Name)
+		if err != nil {
+			logger.Errorln("Failed to create plugins list: %v", err)
+			return
+		}
+
+		var target plugins.Plugin
+		for _, p := range pluginsList {
+			if p.GetName() == pName {
+				target = p
+			}
+		}
+		if target == nil {
+			logger.Errorln("Plugin %s not found", pName)
+			return
+		}
+		inspector, ok := target.(plugins.ResourceInspector)
+		if !ok {
+			logger.Errorln("Plugin %s does not support inspecting its resources", pName)
+			return
+		}
+
+		refs, err := inspector.GetManagedResources(c.KubeConfig)
+		if err != nil {
+			logger.Errorln("Failed to list resources of plugin %s: %v", pName, err)
+			return
+		}
+		if len(refs) == 0 {
+			logger.Infoln("No resources of plugin %s found", pName)
+			return
+		}
+
+		logger.Infoln("Resources of plugin '%s':", pName)
+		logger.Infoln("  %-16s  %-20s  %s", "KIND", "NAMESPACE", "NAME")
+		for _, ref := range refs {
+			logger.Infoln("  %-16s  %-20s  %s", ref.Kind, ref.Namespace, ref.Name)
+		}
+	},
+}
+
+func init() {
+	flags := inspectCmd.Flags()
+	flags.StringVarP(&pName, "name", "n", "", "Name of the plugin")
+	flags.StringVarP(&cName, "cluster", "c", "", "Name of the cluster")
+	if err := inspectCmd.MarkFlagRequired("name"); err != nil {
+		logger.Errorln("Failed to mark name flag as required: %v", err)
+	}
+	if err := inspectCmd.MarkFlagRequired("cluster"); err != nil {
+		logger.Errorln("Failed to mark cluster flag as required: %v", err)
+	}
+	PluginCmd.AddCommand(inspectCmd)
+}
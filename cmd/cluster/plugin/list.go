@@ -14,22 +14,13 @@ var listCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		clusterName, _ := cmd.Flags().GetString("cluster-name")
 
-		c := types.Cluster{
-			Name: clusterName,
-		}
-
-		if !c.IsExists() {
-			logger.Errorln("Cluster '%s' does not exist. Please create it first.", clusterName)
-			return
-		}
-
-		ip := c.GetMasterIP()
-		if err := c.SetKubeConfig(); err != nil {
-			logger.Errorln("Failed to set kubeconfig: %v", err)
+		c, err := types.ResolveCluster(clusterName)
+		if err != nil {
+			logger.Errorln("%v", err)
 			return
 		}
 
-		pluginsList, err := plugins.CreatePluginsList(c.KubeConfig, ip, c.Name)
+		pluginsList, err := plugins.CreatePluginsList(c.KubeConfig, c.MasterIP, c.Name)
 		if err != nil {
 			logger.Errorln("Failed to create plugins list: %v", err)
 			return
@@ -0,0 +1,125 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mrgb7/playground/internal/k8s"
+	"github.com/mrgb7/playground/internal/plugins"
+	"github.com/mrgb7/playground/pkg/logger"
+	"github.com/mrgb7/playground/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logsTail     int64
+	logsSelector string
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Dump the events and pod logs of a plugin",
+	Long: `Dump the recent Kubernetes events and the pod logs in the namespace of a plugin,
+to troubleshoot an install that fails or hangs.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := types.ResolveCluster(cName)
+		if err != nil {
+			logger.Errorln("%v", err)
+			return
+		}
+
+		pluginsList, err := plugins.CreatePluginsList(c.KubeConfig, c.MasterIP, c.Name)
+		if err != nil {
+			logger.Errorln("Failed to create plugins list: %v", err)
+			return
+		}
+		var plugin plugins.Plugin
+		for _, p := range pluginsList {
+			if p.GetName() == pName {
+				plugin = p
+			}
+		}
+		if plugin == nil {
+			logger.Errorln("Plugin '%s' not found", pName)
+			return
+		}
+
+		namespace := ""
+		if opt := plugin.GetOptions(); opt.Namespace != nil {
+			namespace = *opt.Namespace
+		}
+		namespace = plugins.PluginNamespace(c.KubeConfig, plugin.GetName(), namespace)
+		if namespace == "" {
+			logger.Errorln("Plugin '%s' has no namespace to collect events and logs from", pName)
+			return
+		}
+
+		k8sClient, err := k8s.NewK8sClient(c.KubeConfig)
+		if err != nil {
+			logger.Errorln("Failed to create k8s client: %v", err)
+			return
+		}
+		if err := dumpPluginLogs(cmd.Context(), cmd.OutOrStdout(), k8sClient, namespace, logsSelector,
+			logsTail); err != nil {
+			logger.Errorln("%v", err)
+		}
+	},
+}
+
+// dumpPluginLogs writes the events in namespace followed by the logs of the pods matching
+// selector
+func dumpPluginLogs(ctx context.Context, out io.Writer, k8sClient *k8s.K8sClient, namespace, selector string,
+	tailLines int64) error {
+	events, err := k8sClient.GetNamespaceEvents(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Events in namespace %s:\n", namespace)
+	if len(events) == 0 {
+		fmt.Fprintln(out, "  none")
+	}
+	for _, event := range events {
+		fmt.Fprintf(out, "  %s\n", event)
+	}
+
+	podLogs, err := k8sClient.GetPodLogs(ctx, namespace, selector, tailLines)
+	if err != nil {
+		return err
+	}
+	if len(podLogs) == 0 {
+		fmt.Fprintf(out, "\nNo pods in namespace %s\n", namespace)
+	}
+	for _, podLog := range podLogs {
+		fmt.Fprintf(out, "\n==> pod/%s container %s <==\n", podLog.Pod, podLog.Container)
+		if podLog.Err != nil {
+			fmt.Fprintf(out, "failed to get logs: %v\n", podLog.Err)
+			continue
+		}
+		if podLog.Log == "" {
+			continue
+		}
+		fmt.Fprint(out, podLog.Log)
+		if !strings.HasSuffix(podLog.Log, "\n") {
+			fmt.Fprintln(out)
+		}
+	}
+	return nil
+}
+
+func init() {
+	flags := logsCmd.Flags()
+	flags.StringVarP(&pName, "name", "n", "", "Name of the plugin")
+	flags.StringVarP(&cName, "cluster", "c", "", "Name of the cluster")
+	flags.Int64Var(&logsTail, "tail", 50, "Number of recent log lines to show per container, 0 for all")
+	flags.StringVarP(&logsSelector, "selector", "l", "",
+		"Label selector of the pods to show logs of, e.g. app=server (defaults to every pod of the plugin)")
+	if err := logsCmd.MarkFlagRequired("name"); err != nil {
+		logger.Errorln("Failed to mark name flag as required: %v", err)
+	}
+	if err := logsCmd.MarkFlagRequired("cluster"); err != nil {
+		logger.Errorln("Failed to mark cluster flag as required: %v", err)
+	}
+	PluginCmd.AddCommand(logsCmd)
+}
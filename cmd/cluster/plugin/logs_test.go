@@ -0,0 +1,55 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mrgb7/playground/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDumpPluginLogs(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "backoff", Namespace: "argocd"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "argocd-server-5d9"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "BackOff",
+			Message:        "Back-off restarting failed container",
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "argocd-server-5d9", Namespace: "argocd"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "server"}}},
+		},
+	)
+
+	var out bytes.Buffer
+	err := dumpPluginLogs(context.Background(), &out, &k8s.K8sClient{Clientset: clientset}, "argocd", "", 10)
+	if err != nil {
+		t.Fatalf("dumpPluginLogs: %v", err)
+	}
+	for _, want := range []string{
+		"Events in namespace argocd:",
+		"Warning BackOff pod/argocd-server-5d9: Back-off restarting failed container",
+		"==> pod/argocd-server-5d9 container server <==",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestDumpPluginLogsEmptyNamespace(t *testing.T) {
+	var out bytes.Buffer
+	client := &k8s.K8sClient{Clientset: fake.NewSimpleClientset()}
+	if err := dumpPluginLogs(context.Background(), &out, client, "argocd", "", 10); err != nil {
+		t.Fatalf("dumpPluginLogs: %v", err)
+	}
+	if !strings.Contains(out.String(), "  none") || !strings.Contains(out.String(), "No pods in namespace argocd") {
+		t.Errorf("expected empty events and pods to be reported, got:\n%s", out.String())
+	}
+}
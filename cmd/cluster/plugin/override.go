@@ -0,0 +1,105 @@
+package plugin
+
+import (
+	"fmt"
+
+	"github.com/mrgb7/playground/internal/plugins"
+	"github.com/mrgb7/playground/pkg/logger"
+	"github.com/mrgb7/playground/types"
+	"github.com/spf13/cobra"
+)
+
+var overrideCmd = &cobra.Command{
+	Use:   "override",
+	Short: "Override the values of an installed plugin",
+	Long: `Apply --set, --set-file and --values to an installed plugin and reinstall it with them.
+Without any of them the values of the previous override are reapplied.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := types.ResolveCluster(cName)
+		if err != nil {
+			logger.Errorln("%v", err)
+			return
+		}
+
+		pluginsList, err := plugins.CreatePluginsList(c.KubeConfig, c.MasterIP, c.Name)
+		if err != nil {
+			logger.Errorln("Failed to create plugins list: %v", err)
+			return
+		}
+		var target plugins.Plugin
+		for _, plugin := range pluginsList {
+			if plugin.GetName() == pName {
+				target = plugin
+				break
+			}
+		}
+		if target == nil {
+			logger.Errorln("Plugin %s not found", pName)
+			return
+		}
+
+		values := valueFlags{files: valuesFiles, setFiles: setFiles, sets: setValues}
+		if err := overridePlugin(c, target, values, noDefaults, waitReady && !noWait); err != nil {
+			logger.Errorln("%v", err)
+			return
+		}
+		logger.Successln("Successfully overrode %s", pName)
+	},
+}
+
+// overridePlugin reinstalls the installed plugin with the override values, recording them
+// for later upgrades. Unlike add it never installs a plugin that isn't installed yet.
+func overridePlugin(c *types.Cluster, plugin plugins.Plugin, values valueFlags, skipDefaults, wait bool) error {
+	if !plugins.IsInstalled(plugin, plugin.Status()) {
+		return fmt.Errorf("plugin %s is not installed, add it with: playground cluster plugin add --name %s --cluster %s",
+			plugin.GetName(), plugin.GetName(), c.Name)
+	}
+
+	var stored map[string]interface{}
+	if values.empty() {
+		stored = loadStoredOverrides(c.KubeConfig, plugin.GetName())
+	}
+	overrides, err := handlePluginOverride(plugin, values, stored)
+	if err != nil {
+		return fmt.Errorf("invalid override for plugin %s: %w", plugin.GetName(), err)
+	}
+	if skipDefaults {
+		remote, ok := plugin.(plugins.RemoteDefaultsPlugin)
+		if !ok {
+			return fmt.Errorf("plugin %s has no remote default values to skip", plugin.GetName())
+		}
+		remote.SkipRemoteDefaults()
+	}
+
+	logger.Infoln("Reinstalling plugin: %s", plugin.GetName())
+	if err := plugin.Install(c.KubeConfig, c.Name, wait); err != nil {
+		return fmt.Errorf("error reinstalling plugin %s: %w", plugin.GetName(), err)
+	}
+	if !values.empty() {
+		recordOverrides(c.KubeConfig, plugin.GetName(), overrides)
+	}
+	return nil
+}
+
+func init() {
+	flags := overrideCmd.Flags()
+	flags.StringVarP(&pName, "name", "n", "", "Name of the plugin")
+	flags.StringVarP(&cName, "cluster", "c", "", "Name of the cluster")
+	flags.StringArrayVar(&setValues, "set", nil, "Override a plugin value as key.path=value (repeatable)")
+	flags.StringArrayVar(&valuesFiles, "values", nil,
+		"YAML file of override values, repeatable, --set-file and --set take precedence")
+	flags.StringArrayVar(&setFiles, "set-file", nil,
+		"Override a plugin value with the contents of a file as key.path=@file (repeatable)")
+	flags.BoolVar(&noDefaults, "no-default-values", false,
+		"Skip the plugin's remote default values and use only --set and installed values")
+	flags.BoolVar(&waitReady, "wait", true, "Wait until the plugin's workloads are ready again")
+	flags.BoolVar(&noWait, "no-wait", false, "Do not wait for the plugin to become ready (same as --wait=false)")
+	overrideCmd.MarkFlagsMutuallyExclusive("wait", "no-wait")
+	if err := overrideCmd.MarkFlagRequired("name"); err != nil {
+		logger.Errorln("Failed to mark name flag as required: %v", err)
+	}
+	if err := overrideCmd.MarkFlagRequired("cluster"); err != nil {
+		logger.Errorln("Failed to mark cluster flag as required: %v", err)
+	}
+	PluginCmd.AddCommand(overrideCmd)
+}
@@ -0,0 +1,80 @@
+package plugin
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mrgb7/playground/internal/plugins"
+	"github.com/mrgb7/playground/types"
+)
+
+type fakeOverridePlugin struct {
+	fakeLevelPlugin
+	values map[string]interface{}
+}
+
+func (f *fakeOverridePlugin) GetName() string { return "fake" }
+
+func (f *fakeOverridePlugin) SetOverrideValues(values map[string]interface{}) { f.values = values }
+
+func (f *fakeOverridePlugin) ValidateOverrideValues(values map[string]interface{}) error {
+	if _, ok := values["unknown"]; ok {
+		return errors.New("unknown override key")
+	}
+	return nil
+}
+
+func TestOverridePluginNotInstalled(t *testing.T) {
+	cluster := &types.Cluster{Name: "dev"}
+	for _, status := range []string{plugins.StatusNotInstalled, plugins.StatusUnknown, ""} {
+		plugin := &fakeOverridePlugin{fakeLevelPlugin: fakeLevelPlugin{status: status, calls: &atomic.Int32{}}}
+
+		err := overridePlugin(cluster, plugin, valueFlags{sets: []string{"server.replicas=2"}}, false, true)
+		if err == nil || !strings.Contains(err.Error(), "not installed") {
+			t.Errorf("status %q: expected a not installed error, got %v", status, err)
+		}
+		if plugin.calls.Load() != 0 || plugin.values != nil {
+			t.Errorf("status %q: expected the plugin to be left untouched", status)
+		}
+	}
+}
+
+func TestOverridePluginInvalidValues(t *testing.T) {
+	plugin := &fakeOverridePlugin{fakeLevelPlugin: fakeLevelPlugin{status: "running", calls: &atomic.Int32{}}}
+
+	err := overridePlugin(&types.Cluster{Name: "dev"}, plugin, valueFlags{sets: []string{"unknown=1"}}, false, true)
+	if err == nil {
+		t.Error("expected the invalid override to be rejected")
+	}
+	if plugin.calls.Load() != 0 {
+		t.Errorf("expected no reinstall for an invalid override, got %d", plugin.calls.Load())
+	}
+}
+
+// fakeTLSPlugin is the tls plugin with a canned status, so no cluster is needed
+type fakeTLSPlugin struct {
+	*plugins.TLS
+	status string
+}
+
+func (f *fakeTLSPlugin) Status() string { return f.status }
+
+func TestOverridePluginConfiguredStatus(t *testing.T) {
+	cluster := &types.Cluster{Name: "dev"}
+	invalid := valueFlags{sets: []string{"acme.email=ops"}}
+
+	// installed: the override gets past the installed check to the validation
+	installed := &fakeTLSPlugin{TLS: &plugins.TLS{}, status: plugins.TLSStatusReady}
+	err := overridePlugin(cluster, installed, invalid, false, true)
+	if err == nil || !strings.Contains(err.Error(), "invalid acme.email") {
+		t.Errorf("expected the installed tls plugin to reach validation, got %v", err)
+	}
+
+	missing := &fakeTLSPlugin{TLS: &plugins.TLS{}, status: "TLS CA secret not found"}
+	err = overridePlugin(cluster, missing, invalid, false, true)
+	if err == nil || !strings.Contains(err.Error(), "not installed") {
+		t.Errorf("expected a not installed error, got %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package plugin
 
 import (
+	"github.com/mrgb7/playground/internal/installer"
 	"github.com/spf13/cobra"
 )
 
@@ -11,4 +12,6 @@ var PluginCmd = &cobra.Command{
 }
 
 func init() {
+	PluginCmd.PersistentFlags().IntVar(&installer.ArgoLocalPort, "argo-local-port", installer.DefaultLocalPort,
+		"Local port to port-forward ArgoCD to when plugins are managed by ArgoCD (a free port is used if it is taken)")
 }
@@ -1,36 +1,51 @@
 package plugin
 
 import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/mrgb7/playground/internal/installer"
 	"github.com/mrgb7/playground/internal/plugins"
 	"github.com/mrgb7/playground/pkg/logger"
 	"github.com/mrgb7/playground/types"
 	"github.com/spf13/cobra"
 )
 
+var (
+	forceRemove bool
+	keepCRDs    bool
+)
+
 var removeCmd = &cobra.Command{
 	Use:   "remove",
 	Short: "remove plugin",
-	Long:  `Remove plugin from the cluster with automatic dependency resolution`,
+	Long: `Remove plugin from the cluster with automatic dependency resolution.
+With --force the finalizers of a plugin namespace still terminating after the
+normal wait are removed, e.g. when the controller handling them is already gone.
+CRDs that still have custom resources are only deleted after confirmation, and
+--keep-crds keeps all of them.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		c := types.Cluster{
-			Name: cName,
-		}
-
-		ip := c.GetMasterIP()
-		if err := c.SetKubeConfig(); err != nil {
-			logger.Errorln("Failed to set kubeconfig: %v", err)
+		c, err := types.ResolveCluster(cName)
+		if err != nil {
+			logger.Errorln("%v", err)
 			return
 		}
 
-		uninstallOrder, err := plugins.ValidateAndGetUninstallOrder(pName, c.KubeConfig, ip, c.Name)
+		uninstallOrder, err := plugins.ValidateAndGetUninstallOrder(pName, c.KubeConfig, c.MasterIP, c.Name)
 		if err != nil {
 			logger.Errorln("Dependency validation failed: %v", err)
+			if hint := dependencyHint(err, c.Name); hint != "" {
+				logger.Infoln("%s", hint)
+			}
 			return
 		}
 
 		logger.Infoln("Plugin uninstallation order: %v", uninstallOrder)
 
-		pluginsList, err := plugins.CreatePluginsList(c.KubeConfig, ip, c.Name)
+		pluginsList, err := plugins.CreatePluginsList(c.KubeConfig, c.MasterIP, c.Name)
 		if err != nil {
 			logger.Errorln("Failed to create plugins list: %v", err)
 			return
@@ -41,6 +56,8 @@ var removeCmd = &cobra.Command{
 			pluginMap[plugin.GetName()] = plugin
 		}
 
+		// shared by all plugins so buffered input isn't lost between prompts
+		confirm := confirmCRDDeletion(cmd.InOrStdin(), cmd.OutOrStdout())
 		for _, pluginName := range uninstallOrder {
 			plugin, exists := pluginMap[pluginName]
 			if !exists {
@@ -53,6 +70,13 @@ var removeCmd = &cobra.Command{
 				continue
 			}
 
+			if fp, ok := plugin.(plugins.ForceRemovablePlugin); ok && forceRemove {
+				fp.ForceNamespaceDeletion()
+			}
+			if cp, ok := plugin.(plugins.CRDRetentionPlugin); ok {
+				cp.SetCRDRetention(keepCRDs, confirm)
+			}
+
 			logger.Infoln("Uninstalling plugin: %s", pluginName)
 			err := plugin.Uninstall(c.KubeConfig, c.Name)
 			if err != nil {
@@ -66,10 +90,41 @@ var removeCmd = &cobra.Command{
 	},
 }
 
+// confirmCRDDeletion lists the CRDs still having custom resources and asks whether to
+// delete them. Only y or yes deletes them; an empty answer or no input keeps them.
+func confirmCRDDeletion(in io.Reader, out io.Writer) installer.ConfirmCRDDeletion {
+	scanner := bufio.NewScanner(in)
+	return func(inUse map[string]int) bool {
+		names := make([]string, 0, len(inUse))
+		for name := range inUse {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Fprintln(out, "The following CRDs still have custom resources:")
+		for _, name := range names {
+			fmt.Fprintf(out, "  - %s (%d)\n", name, inUse[name])
+		}
+		fmt.Fprint(out, "Delete these CRDs and all their custom resources? (y/N): ")
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return false
+		}
+		switch strings.ToLower(strings.TrimSpace(scanner.Text())) {
+		case "y", "yes":
+			return true
+		}
+		return false
+	}
+}
+
 func init() {
 	flags := removeCmd.Flags()
 	flags.StringVarP(&pName, "name", "n", "", "Name of the plugin")
 	flags.StringVarP(&cName, "cluster", "c", "", "Name of the cluster")
+	flags.BoolVar(&forceRemove, "force", false,
+		"Remove the finalizers of plugin namespaces stuck terminating after the normal wait")
+	flags.BoolVar(&keepCRDs, "keep-crds", false, "Keep the CRDs of removed plugins and their custom resources")
 	if err := removeCmd.MarkFlagRequired("name"); err != nil {
 		logger.Errorln("Failed to mark name flag as required: %v", err)
 	}
@@ -0,0 +1,32 @@
+package plugin
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestConfirmCRDDeletion(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"y\n", true},
+		{"YES\n", true},
+		{"n\n", false},
+		{"\n", false},
+		{"", false},
+	}
+
+	inUse := map[string]int{"issuers.cert-manager.io": 1, "certificates.cert-manager.io": 3}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if got := confirmCRDDeletion(strings.NewReader(tt.input), &out)(inUse); got != tt.expected {
+			t.Errorf("input %q: expected %v, got %v", tt.input, tt.expected, got)
+		}
+		listed := "  - certificates.cert-manager.io (3)\n  - issuers.cert-manager.io (1)\n"
+		if !strings.Contains(out.String(), listed) {
+			t.Errorf("expected the CRDs in use to be listed, got %q", out.String())
+		}
+	}
+}
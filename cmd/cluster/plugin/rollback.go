@@ -0,0 +1,100 @@
+package plugin
+
+import (
+	"github.com/mrgb7/playground/internal/installer"
+	"github.com/mrgb7/playground/internal/plugins"
+	"github.com/mrgb7/playground/pkg/logger"
+	"github.com/mrgb7/playground/types"
+	"github.com/spf13/cobra"
+)
+
+var rollbackRevision int
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Rollback a plugin",
+	Long:  `Rollback a Helm-installed plugin to a previous release revision`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := types.ResolveCluster(cName)
+		if err != nil {
+			logger.Errorln("%v", err)
+			return
+		}
+
+		pluginsList, err := plugins.CreatePluginsList(c.KubeConfig, c.MasterIP, c.Name)
+		if err != nil {
+			logger.Errorln("Failed to create plugins list: %v", err)
+			return
+		}
+
+		var target plugins.Plugin
+		for _, plugin := range pluginsList {
+			if plugin.GetName() == pName {
+				target = plugin
+				break
+			}
+		}
+		if target == nil {
+			logger.Errorln("Plugin %s not found", pName)
+			return
+		}
+
+		if opts := target.GetOptions(); opts.ChartName == nil || opts.Namespace == nil {
+			logger.Errorln("Plugin %s is not installed from a Helm chart, rollback is not applicable", pName)
+			return
+		}
+
+		tracker, err := plugins.NewInstallerTracker(c.KubeConfig)
+		if err != nil {
+			logger.Errorln("Failed to create installer tracker: %v", err)
+			return
+		}
+		installerType, err := tracker.GetPluginInstaller(pName)
+		if err != nil {
+			logger.Errorln("Failed to get recorded installer for plugin %s: %v", pName, err)
+			return
+		}
+		if installerType == plugins.InstallerTypeArgoCD {
+			logger.Errorln("Plugin %s is managed by ArgoCD, rollback is not applicable", pName)
+			return
+		}
+
+		helmInstaller, err := installer.NewHelmInstaller(c.KubeConfig)
+		if err != nil {
+			logger.Errorln("Failed to create helm installer: %v", err)
+			return
+		}
+
+		// roll back the release where it was installed, which may not be the default namespace
+		namespace, err := tracker.GetPluginNamespace(pName)
+		if err != nil || namespace == "" {
+			namespace = *target.GetOptions().Namespace
+		}
+		err = helmInstaller.Rollback(plugins.GetReleaseName(target), namespace, rollbackRevision)
+		if err != nil {
+			logger.Errorln("Error rolling back plugin %s: %v", pName, err)
+			return
+		}
+
+		plugins.RecordPluginEvent(c.KubeConfig, pName, plugins.HistoryEvent{
+			Action:    plugins.HistoryActionRollback,
+			Installer: plugins.InstallerTypeHelm,
+		})
+
+		logger.Successln("Successfully rolled back %s", pName)
+	},
+}
+
+func init() {
+	flags := rollbackCmd.Flags()
+	flags.StringVarP(&pName, "name", "n", "", "Name of the plugin")
+	flags.StringVarP(&cName, "cluster", "c", "", "Name of the cluster")
+	flags.IntVarP(&rollbackRevision, "revision", "r", 0, "Revision to roll back to (0 means the previous revision)")
+	if err := rollbackCmd.MarkFlagRequired("name"); err != nil {
+		logger.Errorln("Failed to mark name flag as required: %v", err)
+	}
+	if err := rollbackCmd.MarkFlagRequired("cluster"); err != nil {
+		logger.Errorln("Failed to mark cluster flag as required: %v", err)
+	}
+	PluginCmd.AddCommand(rollbackCmd)
+}
@@ -0,0 +1,166 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/mrgb7/playground/internal/k8s"
+	"github.com/mrgb7/playground/internal/plugins"
+	"github.com/mrgb7/playground/pkg/logger"
+	"github.com/mrgb7/playground/pkg/retry"
+	"github.com/mrgb7/playground/types"
+	"github.com/spf13/cobra"
+)
+
+// clearScreen moves the cursor home and clears the terminal
+const clearScreen = "\033[H\033[2J"
+
+var errPluginNotRunning = errors.New("plugin not running")
+
+var (
+	watchStatus   bool
+	watchInterval time.Duration
+	watchTimeout  time.Duration
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the status of a plugin",
+	Long: `Show the status of a plugin and the readiness of the deployments in its namespace.
+With --watch the status is redrawn every --interval until the plugin is ready or
+--timeout passes.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := types.ResolveCluster(cName)
+		if err != nil {
+			logger.Errorln("%v", err)
+			return
+		}
+
+		pluginsList, err := plugins.CreatePluginsList(c.KubeConfig, c.MasterIP, c.Name)
+		if err != nil {
+			logger.Errorln("Failed to create plugins list: %v", err)
+			return
+		}
+		var plugin plugins.Plugin
+		for _, p := range pluginsList {
+			if p.GetName() == pName {
+				plugin = p
+			}
+		}
+		if plugin == nil {
+			logger.Errorln("Plugin '%s' not found", pName)
+			return
+		}
+
+		k8sClient, err := k8s.NewK8sClient(c.KubeConfig)
+		if err != nil {
+			logger.Errorln("Failed to create k8s client: %v", err)
+			return
+		}
+		source := pluginStatusSource(cmd.Context(), plugin, k8sClient, c.KubeConfig)
+
+		out := cmd.OutOrStdout()
+		if !watchStatus {
+			status, details := source()
+			printPluginStatus(out, pName, status, details)
+			return
+		}
+		ready := func(status string) bool { return plugins.IsInstalled(plugin, status) }
+		if err := watchPluginStatus(cmd.Context(), out, isTerminal(out), pName, source, ready, watchInterval,
+			watchTimeout); err != nil {
+			logger.Errorln("%v", err)
+		}
+	},
+}
+
+// statusSource returns the current status of a plugin and details such as the readiness
+// of its deployments
+type statusSource func() (status string, details []string)
+
+// pluginStatusSource reports a plugin's Status together with the readiness of the
+// deployments in its namespace
+func pluginStatusSource(ctx context.Context, plugin plugins.Plugin, k8sClient *k8s.K8sClient,
+	kubeConfig string) statusSource {
+	namespace := ""
+	if opt := plugin.GetOptions(); opt.Namespace != nil {
+		namespace = *opt.Namespace
+	}
+	namespace = plugins.PluginNamespace(kubeConfig, plugin.GetName(), namespace)
+
+	return func() (string, []string) {
+		status := plugin.Status()
+		if namespace == "" {
+			return status, nil
+		}
+		details, err := k8sClient.DeploymentReadiness(ctx, namespace)
+		if err != nil {
+			details = []string{err.Error()}
+		}
+		return status, details
+	}
+}
+
+// watchPluginStatus redraws the status of a plugin every interval until ready accepts it
+// or timeout passes. The screen is only cleared between redraws on a terminal, so
+// redirected output keeps every iteration.
+func watchPluginStatus(ctx context.Context, out io.Writer, tty bool, name string, source statusSource,
+	ready func(status string) bool, interval, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	last := ""
+	err := retry.Do(ctx, retry.Options{Backoff: interval}, func() error {
+		status, details := source()
+		if tty {
+			fmt.Fprint(out, clearScreen)
+		}
+		fmt.Fprintf(out, "%s (every %v)\n", time.Now().Format(time.TimeOnly), interval)
+		printPluginStatus(out, name, status, details)
+		last = status
+		if !ready(status) {
+			return errPluginNotRunning
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("plugin %s is not running after %v, last status: %s: %w", name, timeout, last, err)
+	}
+	return nil
+}
+
+func printPluginStatus(out io.Writer, name, status string, details []string) {
+	fmt.Fprintf(out, "%s: %s\n", name, status)
+	for _, detail := range details {
+		fmt.Fprintf(out, "  %s\n", detail)
+	}
+}
+
+// isTerminal reports whether out is a terminal
+func isTerminal(out io.Writer) bool {
+	f, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func init() {
+	flags := statusCmd.Flags()
+	flags.StringVarP(&pName, "name", "n", "", "Name of the plugin")
+	flags.StringVarP(&cName, "cluster", "c", "", "Name of the cluster")
+	flags.BoolVarP(&watchStatus, "watch", "w", false, "Redraw the status until the plugin is ready")
+	flags.DurationVar(&watchInterval, "interval", 3*time.Second, "How often to refresh the status with --watch")
+	flags.DurationVar(&watchTimeout, "timeout", 5*time.Minute, "How long to wait for the plugin with --watch")
+	if err := statusCmd.MarkFlagRequired("name"); err != nil {
+		logger.Errorln("Failed to mark name flag as required: %v", err)
+	}
+	if err := statusCmd.MarkFlagRequired("cluster"); err != nil {
+		logger.Errorln("Failed to mark cluster flag as required: %v", err)
+	}
+	PluginCmd.AddCommand(statusCmd)
+}
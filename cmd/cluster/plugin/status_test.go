@@ -0,0 +1,92 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrgb7/playground/internal/plugins"
+)
+
+// transitioningSource reports the plugin as running from the runningAfter-th call on
+func transitioningSource(runningAfter int, calls *int) statusSource {
+	return func() (string, []string) {
+		*calls++
+		if *calls >= runningAfter {
+			return plugins.StatusRunning, []string{"deployment/server 1/1"}
+		}
+		return "pending", []string{"deployment/server 0/1"}
+	}
+}
+
+func TestWatchPluginStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		runningAfter int
+		tty          bool
+		expectError  bool
+	}{
+		{name: "running right away", runningAfter: 1},
+		{name: "running after polls", runningAfter: 3},
+		{name: "running after polls on a terminal", runningAfter: 3, tty: true},
+		{name: "never running", runningAfter: 1000, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			calls := 0
+			err := watchPluginStatus(context.Background(), &out, tt.tty, "argocd",
+				transitioningSource(tt.runningAfter, &calls), plugins.IsPluginInstalled, time.Millisecond,
+				50*time.Millisecond)
+			if tt.expectError {
+				if err == nil || !strings.Contains(err.Error(), "last status: pending") {
+					t.Errorf("expected a timeout error with the last status, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if calls != tt.runningAfter {
+				t.Errorf("expected %d polls, got %d", tt.runningAfter, calls)
+			}
+
+			expectedClears := 0
+			if tt.tty {
+				expectedClears = calls
+			}
+			if clears := strings.Count(out.String(), clearScreen); clears != expectedClears {
+				t.Errorf("expected %d screen clears, got %d", expectedClears, clears)
+			}
+			if got := strings.Count(out.String(), "argocd: "); got != calls {
+				t.Errorf("expected %d redraws, got %d", calls, got)
+			}
+		})
+	}
+}
+
+func TestWatchPluginStatusConfiguredPlugins(t *testing.T) {
+	tests := []struct {
+		plugin plugins.Plugin
+		status string
+	}{
+		{&plugins.TLS{}, plugins.TLSStatusReady},
+		{&plugins.TLS{}, plugins.TLSStatusACMEReady},
+		{&plugins.Ingress{}, plugins.IngressStatusConfigured},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			source := func() (string, []string) { return tt.status, nil }
+			ready := func(status string) bool { return plugins.IsInstalled(tt.plugin, status) }
+			err := watchPluginStatus(context.Background(), &bytes.Buffer{}, false, tt.plugin.GetName(), source, ready,
+				time.Millisecond, 50*time.Millisecond)
+			if err != nil {
+				t.Errorf("expected status %q to end the watch, got %v", tt.status, err)
+			}
+		})
+	}
+}
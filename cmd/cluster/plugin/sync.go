@@ -0,0 +1,75 @@
+package plugin
+
+import (
+	"fmt"
+
+	"github.com/mrgb7/playground/internal/plugins"
+	"github.com/mrgb7/playground/pkg/logger"
+	"github.com/mrgb7/playground/types"
+	"github.com/spf13/cobra"
+)
+
+var forceSync bool
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Show or trigger the ArgoCD sync of a plugin",
+	Long: `Show the sync and health status of an ArgoCD-managed plugin's application.
+With --force a sync to the application's target revision is started first.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := types.ResolveCluster(cName)
+		if err != nil {
+			logger.Errorln("%v", err)
+			return
+		}
+
+		tracker, err := plugins.NewInstallerTracker(c.KubeConfig)
+		if err != nil {
+			logger.Errorln("Failed to create installer tracker: %v", err)
+			return
+		}
+		installerType, err := tracker.GetPluginInstaller(pName)
+		if err != nil {
+			logger.Errorln("Failed to get recorded installer for plugin %s: %v", pName, err)
+			return
+		}
+		if installerType != plugins.InstallerTypeArgoCD {
+			logger.Errorln("Plugin %s is not managed by ArgoCD, sync is not applicable", pName)
+			return
+		}
+
+		argoInstaller, err := plugins.NewArgoInstaller(c.KubeConfig, c.Name)
+		if err != nil {
+			logger.Errorln("Failed to create ArgoCD installer: %v", err)
+			return
+		}
+
+		if forceSync {
+			if err := argoInstaller.ForceSync(pName); err != nil {
+				logger.Errorln("%v", err)
+				return
+			}
+		}
+
+		sync, health, err := argoInstaller.GetApplicationStatus(pName)
+		if err != nil {
+			logger.Errorln("Failed to get status of plugin %s: %v", pName, err)
+			return
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s: sync %s, health %s\n", pName, sync, health)
+	},
+}
+
+func init() {
+	flags := syncCmd.Flags()
+	flags.StringVarP(&pName, "name", "n", "", "Name of the plugin")
+	flags.StringVarP(&cName, "cluster", "c", "", "Name of the cluster")
+	flags.BoolVar(&forceSync, "force", false, "Start a sync of the plugin's application before showing its status")
+	if err := syncCmd.MarkFlagRequired("name"); err != nil {
+		logger.Errorln("Failed to mark name flag as required: %v", err)
+	}
+	if err := syncCmd.MarkFlagRequired("cluster"); err != nil {
+		logger.Errorln("Failed to mark cluster flag as required: %v", err)
+	}
+	PluginCmd.AddCommand(syncCmd)
+}
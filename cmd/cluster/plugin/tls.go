@@ -0,0 +1,120 @@
+package plugin
+
+import (
+	"time"
+
+	"github.com/mrgb7/playground/internal/plugins"
+	"github.com/mrgb7/playground/pkg/logger"
+	"github.com/mrgb7/playground/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	verifyHost    string
+	verifyAddress string
+)
+
+var tlsCmd = &cobra.Command{
+	Use:   "tls",
+	Short: "Inspect certificates issued by the tls plugin",
+}
+
+var tlsVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify that a service's certificate chains to the playground CA",
+	Long: `Connect to a host on port 443, retrieve the served certificate chain and verify it against the
+CA stored in the local-ca-secret. The host is resolved through /etc/hosts, falling back to the nginx
+LoadBalancer IP when it does not resolve.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := types.ResolveCluster(cName)
+		if err != nil {
+			logger.Errorln("%v", err)
+			return
+		}
+
+		t, err := plugins.NewTLS(c.KubeConfig, c.Name)
+		if err != nil {
+			logger.Errorln("Failed to create tls plugin: %v", err)
+			return
+		}
+		if pNamespace != "" {
+			if err := t.SetIssuerScope(plugins.IssuerScopeNamespace, pNamespace); err != nil {
+				logger.Errorln("%v", err)
+				return
+			}
+		}
+
+		report, err := t.VerifyHost(verifyHost, verifyAddress)
+		if err != nil {
+			logger.Errorln("Failed to verify %s: %v", verifyHost, err)
+			return
+		}
+
+		logger.Infoln("Host:    %s (%s)", report.Host, report.Address)
+		logger.Infoln("Subject: %s", report.Subject)
+		logger.Infoln("Issuer:  %s", report.Issuer)
+		logger.Infoln("Expires: %s", report.NotAfter.Local().Format(time.DateTime))
+		if !report.Valid {
+			logger.Errorln("Certificate chain is not valid: %v", report.Err)
+			return
+		}
+		logger.Successln("Certificate chain is valid and trusted by the playground CA")
+	},
+}
+
+var tlsDiagnoseCmd = &cobra.Command{
+	Use:   "diagnose",
+	Short: "Diagnose why the playground certificates are not trusted",
+	Long: `Check the CA secret and issuer of the tls plugin exist, then attempt a TLS handshake with
+argocd.<cluster domain> on port 443 trusting only the playground CA, and report whether it verified.
+The cluster domain is <cluster>.local unless the cluster was created with --domain.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := types.ResolveCluster(cName)
+		if err != nil {
+			logger.Errorln("%v", err)
+			return
+		}
+
+		t, err := plugins.NewTLS(c.KubeConfig, c.Name)
+		if err != nil {
+			logger.Errorln("Failed to create tls plugin: %v", err)
+			return
+		}
+		if pNamespace != "" {
+			if err := t.SetIssuerScope(plugins.IssuerScopeNamespace, pNamespace); err != nil {
+				logger.Errorln("%v", err)
+				return
+			}
+		}
+
+		if _, err := t.DiagnoseCertificateIssues(); err != nil {
+			logger.Errorln("Diagnosis failed: %v", err)
+		}
+	},
+}
+
+func init() {
+	diagnoseFlags := tlsDiagnoseCmd.Flags()
+	diagnoseFlags.StringVarP(&cName, "cluster", "c", "", "Name of the cluster")
+	diagnoseFlags.StringVar(&pNamespace, "namespace", "",
+		"Namespace of the CA secret when the tls plugin uses a namespaced Issuer")
+	if err := tlsDiagnoseCmd.MarkFlagRequired("cluster"); err != nil {
+		logger.Errorln("Failed to mark cluster flag as required: %v", err)
+	}
+	tlsCmd.AddCommand(tlsDiagnoseCmd)
+
+	flags := tlsVerifyCmd.Flags()
+	flags.StringVarP(&cName, "cluster", "c", "", "Name of the cluster")
+	flags.StringVar(&verifyHost, "host", "", "Host name to verify, e.g. argocd.<cluster>.local")
+	flags.StringVar(&verifyAddress, "address", "", "IP address to connect to instead of resolving the host")
+	flags.StringVar(&pNamespace, "namespace", "",
+		"Namespace of the CA secret when the tls plugin uses a namespaced Issuer")
+	if err := tlsVerifyCmd.MarkFlagRequired("cluster"); err != nil {
+		logger.Errorln("Failed to mark cluster flag as required: %v", err)
+	}
+	if err := tlsVerifyCmd.MarkFlagRequired("host"); err != nil {
+		logger.Errorln("Failed to mark host flag as required: %v", err)
+	}
+	tlsCmd.AddCommand(tlsVerifyCmd)
+	PluginCmd.AddCommand(tlsCmd)
+}
@@ -0,0 +1,207 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mrgb7/playground/internal/plugins"
+	"github.com/mrgb7/playground/pkg/logger"
+	"gopkg.in/yaml.v3"
+)
+
+// handlePluginOverride parses the override value flags, or takes the stored values when
+// none is given, coerces them to the plugin's schema and validates them when the plugin
+// can, and hands them to the plugin
+func handlePluginOverride(plugin plugins.Plugin, flags valueFlags,
+	stored map[string]interface{}) (map[string]interface{}, error) {
+	overridable, ok := plugin.(plugins.OverridablePlugin)
+	if !ok {
+		return nil, fmt.Errorf("plugin %s does not support overrides", plugin.GetName())
+	}
+
+	var schema map[string]plugins.OverrideType
+	if schemaPlugin, ok := plugin.(plugins.OverrideSchemaPlugin); ok {
+		schema = schemaPlugin.GetOverrideSchema()
+	}
+
+	values, err := flags.parse(schema)
+	if err != nil {
+		return nil, err
+	}
+	if flags.empty() && len(stored) > 0 {
+		values = stored
+	}
+	if err := plugins.CoerceOverrideValues(values, schema); err != nil {
+		return nil, err
+	}
+
+	if validator, ok := plugin.(plugins.OverrideValidator); ok {
+		if err := validator.ValidateOverrideValues(values); err != nil {
+			return nil, err
+		}
+	}
+
+	if values, err = plugins.NormalizeOverrideValues(plugin, values); err != nil {
+		return nil, err
+	}
+
+	overridable.SetOverrideValues(values)
+	return values, nil
+}
+
+// loadStoredOverrides returns the override values recorded by a previous --override install
+func loadStoredOverrides(kubeConfig, pluginName string) map[string]interface{} {
+	tracker, err := plugins.NewInstallerTracker(kubeConfig)
+	if err != nil {
+		logger.Warnln("Failed to create installer tracker: %v", err)
+		return nil
+	}
+	values, err := tracker.GetPluginValues(pluginName)
+	if err != nil {
+		logger.Warnln("Failed to load stored overrides for plugin %s: %v", pluginName, err)
+		return nil
+	}
+	if len(values) > 0 {
+		logger.Infoln("Reapplying stored overrides for plugin %s", pluginName)
+	}
+	return values
+}
+
+// recordOverrides stores the --set values a plugin was installed with for later upgrades
+func recordOverrides(kubeConfig, pluginName string, values map[string]interface{}) {
+	tracker, err := plugins.NewInstallerTracker(kubeConfig)
+	if err != nil {
+		logger.Warnln("Failed to create installer tracker: %v", err)
+		return
+	}
+	if err := tracker.RecordPluginValues(pluginName, values); err != nil {
+		logger.Warnln("Failed to record overrides for plugin %s: %v", pluginName, err)
+	}
+}
+
+// valueFlags are the flags supplying override values
+type valueFlags struct {
+	files    []string // --values
+	setFiles []string // --set-file
+	sets     []string // --set
+}
+
+func (f valueFlags) empty() bool {
+	return len(f.files) == 0 && len(f.setFiles) == 0 && len(f.sets) == 0
+}
+
+// parse merges the --values files in order, then the --set-file and finally the --set
+// values over them, so the most specific flag wins. --set values of string keys in schema
+// are kept verbatim.
+func (f valueFlags) parse(schema map[string]plugins.OverrideType) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	for _, path := range f.files {
+		fileValues, err := readValuesFile(path)
+		if err != nil {
+			return nil, err
+		}
+		values = plugins.MergeValues(values, fileValues)
+	}
+
+	fileValues, err := parseSetFileValues(f.setFiles)
+	if err != nil {
+		return nil, err
+	}
+	values = plugins.MergeValues(values, fileValues)
+
+	setValues, err := parseSetValues(f.sets, schema)
+	if err != nil {
+		return nil, err
+	}
+	return plugins.MergeValues(values, setValues), nil
+}
+
+// readValuesFile parses a YAML file of override values
+func readValuesFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read values file: %w", err)
+	}
+	values := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse values file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// parseSetValues turns key.path=value pairs into nested values, inferring the type of the
+// value unless schema declares the key a string
+func parseSetValues(sets []string, schema map[string]plugins.OverrideType) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	for _, set := range sets {
+		key, raw, ok := strings.Cut(set, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --set value %q, expected key=value", set)
+		}
+		var value interface{} = raw
+		if schema[key] != plugins.OverrideTypeString {
+			value = parseValue(raw)
+		}
+		nested, err := nestValue("--set", key, value)
+		if err != nil {
+			return nil, err
+		}
+		values = plugins.MergeValues(values, nested)
+	}
+	return values, nil
+}
+
+// parseSetFileValues turns key.path=@file pairs into nested values holding the file
+// contents as a string
+func parseSetFileValues(setFiles []string) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	for _, set := range setFiles {
+		key, path, ok := strings.Cut(set, "=")
+		path = strings.TrimPrefix(path, "@")
+		if !ok || key == "" || path == "" {
+			return nil, fmt.Errorf("invalid --set-file value %q, expected key=@file", set)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read --set-file %s: %w", key, err)
+		}
+		nested, err := nestValue("--set-file", key, string(data))
+		if err != nil {
+			return nil, err
+		}
+		values = plugins.MergeValues(values, nested)
+	}
+	return values, nil
+}
+
+// nestValue places value at the dotted key path
+func nestValue(flag, key string, value interface{}) (map[string]interface{}, error) {
+	parts := strings.Split(key, ".")
+	nested := value
+	for i := len(parts) - 1; i >= 0; i-- {
+		if parts[i] == "" {
+			return nil, fmt.Errorf("invalid %s key %q", flag, key)
+		}
+		nested = map[string]interface{}{parts[i]: nested}
+	}
+	return nested.(map[string]interface{}), nil
+}
+
+// parseValue infers bool, int and float values, falling back to a string
+func parseValue(raw string) interface{} {
+	switch raw {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if i, err := strconv.Atoi(raw); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(raw, 64); err == nil {
+		return f
+	}
+	return raw
+}
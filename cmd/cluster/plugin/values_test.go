@@ -0,0 +1,228 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mrgb7/playground/internal/plugins"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestParseValue(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected interface{}
+	}{
+		{"true", true},
+		{"false", false},
+		{"1", 1},
+		{"1.5", 1.5},
+		{"192.168.64.200-192.168.64.210", "192.168.64.200-192.168.64.210"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if got := parseValue(tt.raw); got != tt.expected {
+				t.Errorf("expected %v (%T), got %v (%T)", tt.expected, tt.expected, got, got)
+			}
+		})
+	}
+}
+
+func TestParseSetValues(t *testing.T) {
+	values, err := parseSetValues([]string{
+		"addressPool.range=192.168.64.200-192.168.64.210",
+		"server.replicas=2",
+		"server.insecure=true",
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"addressPool": map[string]interface{}{"range": "192.168.64.200-192.168.64.210"},
+		"server":      map[string]interface{}{"replicas": 2, "insecure": true},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+
+	for _, invalid := range []string{"novalue", "=value", "a..b=1"} {
+		if _, err := parseSetValues([]string{invalid}, nil); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestHandlePluginOverride(t *testing.T) {
+	lb := &plugins.LoadBalancer{}
+	sets := func(values ...string) valueFlags { return valueFlags{sets: values} }
+
+	if _, err := handlePluginOverride(lb, sets("addressPool.range=192.168.64.200-192.168.64.210"), nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := handlePluginOverride(lb, sets("addressPool.range=192.168.64.210-192.168.64.200"), nil); err == nil {
+		t.Error("expected validation error for reversed range")
+	}
+	if _, err := handlePluginOverride(lb, sets("unknown=1"), nil); err == nil {
+		t.Error("expected validation error for unknown key")
+	}
+	if _, err := handlePluginOverride(&plugins.Ingress{}, sets("a=b"), nil); err == nil {
+		t.Error("expected error for plugin without override support")
+	}
+
+	stored := map[string]interface{}{
+		"addressPool": map[string]interface{}{"range": "192.168.64.200-192.168.64.210"},
+	}
+	values, err := handlePluginOverride(lb, valueFlags{}, stored)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(values, stored) {
+		t.Errorf("expected stored values to be reapplied, got %v", values)
+	}
+
+	values, err = handlePluginOverride(lb, sets("addressPool.range=192.168.64.220-192.168.64.230"), stored)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	expected := map[string]interface{}{
+		"addressPool": map[string]interface{}{"range": "192.168.64.220-192.168.64.230"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected --set values to replace stored values, got %v", values)
+	}
+}
+
+func TestValueFlagsPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	env := filepath.Join(dir, "env.yaml")
+	motd := filepath.Join(dir, "motd.txt")
+	writeFile(t, base, "server:\n  replicas: 1\n  insecure: false\n  motd: base\nredis:\n  enabled: true\n")
+	writeFile(t, env, "server:\n  replicas: 2\n")
+	writeFile(t, motd, "hello\n")
+
+	tests := []struct {
+		name     string
+		flags    valueFlags
+		expected map[string]interface{}
+	}{
+		{
+			name:  "later files win",
+			flags: valueFlags{files: []string{base, env}},
+			expected: map[string]interface{}{
+				"server": map[string]interface{}{"replicas": 2, "insecure": false, "motd": "base"},
+				"redis":  map[string]interface{}{"enabled": true},
+			},
+		},
+		{
+			name: "set-file and set win over files",
+			flags: valueFlags{
+				files:    []string{base},
+				setFiles: []string{"server.motd=@" + motd},
+				sets:     []string{"server.insecure=true"},
+			},
+			expected: map[string]interface{}{
+				"server": map[string]interface{}{"replicas": 1, "insecure": true, "motd": "hello\n"},
+				"redis":  map[string]interface{}{"enabled": true},
+			},
+		},
+		{
+			name: "set wins over set-file",
+			flags: valueFlags{
+				setFiles: []string{"server.motd=@" + motd},
+				sets:     []string{"server.motd=inline"},
+			},
+			expected: map[string]interface{}{"server": map[string]interface{}{"motd": "inline"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := tt.flags.parse(nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(values, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, values)
+			}
+		})
+	}
+}
+
+func TestValueFlagsInvalid(t *testing.T) {
+	dir := t.TempDir()
+	broken := filepath.Join(dir, "broken.yaml")
+	writeFile(t, broken, "server: [unclosed\n")
+
+	for _, flags := range []valueFlags{
+		{files: []string{broken}},
+		{files: []string{filepath.Join(dir, "missing.yaml")}},
+		{setFiles: []string{"server.motd"}},
+		{setFiles: []string{"server.motd=@" + filepath.Join(dir, "missing.txt")}},
+	} {
+		if _, err := flags.parse(nil); err == nil {
+			t.Errorf("expected an error for %+v", flags)
+		}
+	}
+}
+
+func TestHandlePluginOverrideValidatesValuesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "values.yaml")
+	writeFile(t, path, "unknown: 1\n")
+
+	if _, err := handlePluginOverride(&plugins.LoadBalancer{}, valueFlags{files: []string{path}}, nil); err == nil {
+		t.Error("expected validation error for unknown key from the values file")
+	}
+}
+
+func TestHandlePluginOverrideCoercesPassword(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "values.yaml")
+	writeFile(t, path, "admin:\n  password: 12345\n")
+
+	tests := []struct {
+		name     string
+		flags    valueFlags
+		stored   map[string]interface{}
+		expected string
+	}{
+		{name: "numeric --set", flags: valueFlags{sets: []string{"admin.password=12345"}}, expected: "12345"},
+		{name: "leading zeros kept", flags: valueFlags{sets: []string{"admin.password=007"}}, expected: "007"},
+		{name: "values file", flags: valueFlags{files: []string{path}}, expected: "12345"},
+		{
+			name:     "stored values",
+			stored:   map[string]interface{}{"admin": map[string]interface{}{"password": float64(12345)}},
+			expected: "12345",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := handlePluginOverride(&plugins.Argocd{}, tt.flags, tt.stored)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// the coerced password is what gets hashed, so its string form must match
+			hash, _ := plugins.GetNestedValue(values, "configs.secret.argocdServerAdminPassword")
+			if s, _ := hash.(string); bcrypt.CompareHashAndPassword([]byte(s), []byte(tt.expected)) != nil {
+				t.Errorf("expected a hash of the password %q, got %v", tt.expected, hash)
+			}
+		})
+	}
+
+	sets := valueFlags{sets: []string{"admin.password.value=secret"}}
+	if _, err := handlePluginOverride(&plugins.Argocd{}, sets, nil); err == nil {
+		t.Error("expected a mapping for the string typed password to be rejected")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
@@ -0,0 +1,279 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrgb7/playground/internal/k8s"
+	"github.com/mrgb7/playground/internal/multipass"
+	"github.com/mrgb7/playground/internal/state"
+	"github.com/mrgb7/playground/pkg/logger"
+	"github.com/mrgb7/playground/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cScaleName        string
+	cScaleSize        int
+	cScaleMaxParallel int
+)
+
+// DrainGracePeriod is the termination grace period of pods evicted from workers before
+// they are deleted
+const DrainGracePeriod = 30 * time.Second
+
+var scaleCmd = &cobra.Command{
+	Use:   "scale",
+	Short: "Scale the worker nodes of a cluster",
+	Long:  `Add or remove worker nodes so the cluster has the given total number of nodes`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := scaleCluster(cmd.Context(), multipass.NewMultipassClient(), cScaleName, cScaleSize); err != nil {
+			logger.Errorln("Failed to scale cluster: %v", err)
+			return
+		}
+	},
+}
+
+func scaleCluster(ctx context.Context, client multipass.Client, clusterName string, size int) error {
+	if !client.IsMultipassInstalled() {
+		return fmt.Errorf("multipass is not installed or not in PATH")
+	}
+
+	if size < types.MinClusterSize {
+		return fmt.Errorf("cluster size must be at least %d (the master node)", types.MinClusterSize)
+	}
+	if size > types.MaxClusterSize {
+		return fmt.Errorf("cluster size cannot exceed %d nodes", types.MaxClusterSize)
+	}
+
+	if cScaleMaxParallel < 1 {
+		return fmt.Errorf("--max-parallel must be at least 1, got %d", cScaleMaxParallel)
+	}
+
+	if _, err := types.ResolveCluster(clusterName); err != nil {
+		return err
+	}
+
+	nodes, err := client.ListNodes(clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to list cluster nodes: %w", err)
+	}
+	workers := workerIndices(clusterName, nodes)
+
+	config := scaleConfig(clusterName)
+	current := len(workers) + 1
+	switch {
+	case size == current:
+		logger.Infoln("Cluster '%s' already has %d nodes", clusterName, size)
+		return nil
+	case size > current:
+		added := scaleUp(ctx, client, config, nextWorkerIndices(workers, size-current))
+		config.Size = current + added
+	default:
+		removed := scaleDown(ctx, client, config, selectWorkersToRemove(workers, current-size))
+		config.Size = current - removed
+	}
+
+	if err := state.Save(*config); err != nil {
+		logger.Warnln("Failed to save cluster state: %v", err)
+	}
+
+	if config.Size != size {
+		return fmt.Errorf("cluster '%s' has %d nodes, expected %d", clusterName, config.Size, size)
+	}
+	logger.Successln("Successfully scaled cluster '%s' to %d nodes", clusterName, size)
+	return nil
+}
+
+// scaleConfig returns the recorded config of a cluster, falling back to the create
+// defaults for clusters created before state was persisted
+func scaleConfig(clusterName string) *types.ClusterConfig {
+	if st, err := state.Load(clusterName); err == nil {
+		return &st.ClusterConfig
+	}
+	return &types.ClusterConfig{
+		Name:         clusterName,
+		MasterCPUs:   DefaultMasterCPUs,
+		MasterMemory: "2G",
+		MasterDisk:   "20G",
+		WorkerCPUs:   DefaultWorkerCPUs,
+		WorkerMemory: "2G",
+		WorkerDisk:   "20G",
+	}
+}
+
+// scaleUp creates and joins workers at the given indices and returns how many succeeded
+func scaleUp(ctx context.Context, client multipass.Client, config *types.ClusterConfig, indices []int) int {
+	masterNodeName := fmt.Sprintf("%s-master", config.Name)
+	accessToken, masterIP, err := getMasterCredentials(ctx, client, masterNodeName)
+	if err != nil {
+		logger.Errorln("Failed to get master credentials: %v", err)
+		return 0
+	}
+
+	cloudInit, err := resolveCloudInit(config.CloudInit)
+	if err != nil {
+		logger.Errorln("Failed to prepare cloud-init: %v", err)
+		return 0
+	}
+
+	nodeNames := make([]string, 0, len(indices))
+	for _, index := range indices {
+		nodeNames = append(nodeNames, types.WorkerNodeName(config.Name, index))
+	}
+	var mu sync.Mutex
+	created := make([]string, 0, len(indices))
+	forEachNode(ctx, nodeNames, cScaleMaxParallel, func(nodeName string) error {
+		err := client.CreateNode(nodeName, config.Image, config.WorkerCPUs, config.WorkerMemory,
+			config.WorkerDisk, cloudInit, config.MultipassArgs...)
+		if err != nil {
+			logger.Errorln("Failed to create worker node %s: %v", nodeName, err)
+			return err
+		}
+		mu.Lock()
+		created = append(created, nodeName)
+		mu.Unlock()
+		return nil
+	})
+
+	if len(config.Mounts) > 0 {
+		if err := mountHostPaths(client, config, created); err != nil {
+			logger.Warnln("Failed to mount host directories: %v", err)
+		}
+	}
+
+	workerErrors := joinWorkers(ctx, client, created, k3sWorkerInstallCmd(config.K3sVersion, masterIP, accessToken),
+		cScaleMaxParallel)
+	workerErrors = verifyWorkerJoins(ctx, client, masterNodeName, created, workerErrors)
+	for _, we := range workerErrors {
+		logger.Errorln("  - %s: %v", we.nodeName, we.err)
+	}
+	deleteFailedWorkers(client, workerErrors)
+
+	applyWorkerScheduling(ctx, client, config, masterNodeName, created, workerErrors)
+	return len(created) - len(workerErrors)
+}
+
+// deleteFailedWorkers deletes the workers that were created but failed to join, so
+// they don't linger as VMs outside the cluster
+func deleteFailedWorkers(client multipass.Client, workerErrors []workerError) {
+	for _, we := range workerErrors {
+		logger.Infoln("Deleting worker node %s that failed to join", we.nodeName)
+		if err := client.DeleteNode(we.nodeName); err != nil {
+			logger.Errorln("Failed to delete worker node %s: %v", we.nodeName, err)
+		}
+	}
+}
+
+// scaleDown drains and deletes workers at the given indices and returns how many were removed
+func scaleDown(ctx context.Context, client multipass.Client, config *types.ClusterConfig, indices []int) int {
+	masterNodeName := fmt.Sprintf("%s-master", config.Name)
+	kubeConfig, err := getKubeConfig(ctx, client, masterNodeName)
+	if err != nil {
+		logger.Errorln("Failed to get kubeconfig: %v", err)
+		return 0
+	}
+	k8sClient, err := k8s.NewK8sClient(kubeConfig)
+	if err != nil {
+		logger.Errorln("Failed to create k8s client: %v", err)
+		return 0
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	removed := 0
+	for _, index := range indices {
+		wg.Add(1)
+		go func(nodeName string) {
+			defer wg.Done()
+			logger.Infoln("Draining worker node %s", nodeName)
+			if err := k8sClient.DrainNode(nodeName, DrainGracePeriod); err != nil {
+				logger.Errorln("Failed to drain worker node %s: %v", nodeName, err)
+				return
+			}
+			if err := client.DeleteNode(nodeName); err != nil {
+				logger.Errorln("Failed to delete worker node %s: %v", nodeName, err)
+				return
+			}
+			// the cluster stays, so remove the node object of the deleted VM too
+			if err := k8sClient.DeleteNode(nodeName); err != nil {
+				logger.Warnln("%v", err)
+			}
+			mu.Lock()
+			removed++
+			mu.Unlock()
+		}(types.WorkerNodeName(config.Name, index))
+	}
+	wg.Wait()
+
+	if removed > 0 {
+		if err := client.PurgeNodes(); err != nil {
+			logger.Warnln("Failed to purge deleted instances: %v", err)
+		}
+	}
+	return removed
+}
+
+// workerIndices extracts the sorted worker indices from a cluster's node names
+func workerIndices(clusterName string, nodes []string) []int {
+	prefix := clusterName + "-worker-"
+	indices := make([]int, 0, len(nodes))
+	for _, node := range nodes {
+		if !strings.HasPrefix(node, prefix) {
+			continue
+		}
+		index, err := strconv.Atoi(strings.TrimPrefix(node, prefix))
+		if err != nil || index < 1 {
+			continue
+		}
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+	return indices
+}
+
+// nextWorkerIndices returns the lowest count indices not already in use
+func nextWorkerIndices(existing []int, count int) []int {
+	used := make(map[int]bool, len(existing))
+	for _, index := range existing {
+		used[index] = true
+	}
+
+	next := make([]int, 0, count)
+	for index := 1; len(next) < count; index++ {
+		if !used[index] {
+			next = append(next, index)
+		}
+	}
+	return next
+}
+
+// selectWorkersToRemove picks the count highest worker indices so the remaining
+// workers keep their original names
+func selectWorkersToRemove(existing []int, count int) []int {
+	sorted := append([]int(nil), existing...)
+	sort.Sort(sort.Reverse(sort.IntSlice(sorted)))
+	if count > len(sorted) {
+		count = len(sorted)
+	}
+	return sorted[:count]
+}
+
+func init() {
+	scaleCmd.Flags().StringVarP(&cScaleName, "name", "n", "", "Name of the cluster (required)")
+	scaleCmd.Flags().IntVarP(&cScaleSize, "size", "s", 0,
+		"Total number of nodes after scaling, including the master (required)")
+	scaleCmd.Flags().IntVar(&cScaleMaxParallel, "max-parallel", DefaultMaxParallel,
+		"Maximum number of workers created or joined at the same time")
+	if err := scaleCmd.MarkFlagRequired("name"); err != nil {
+		logger.Errorln("Failed to mark name flag as required: %v", err)
+	}
+	if err := scaleCmd.MarkFlagRequired("size"); err != nil {
+		logger.Errorln("Failed to mark size flag as required: %v", err)
+	}
+}
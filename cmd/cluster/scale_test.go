@@ -0,0 +1,122 @@
+package cluster
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mrgb7/playground/internal/multipass"
+	"github.com/mrgb7/playground/types"
+)
+
+func TestWorkerIndices(t *testing.T) {
+	nodes := []string{"dev-master", "dev-worker-3", "dev-worker-1", "dev-worker-x", "devx-worker-2"}
+
+	got := workerIndices("dev", nodes)
+	expected := []int{1, 3}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestNextWorkerIndices(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []int
+		count    int
+		expected []int
+	}{
+		{"no workers", nil, 2, []int{1, 2}},
+		{"contiguous workers", []int{1, 2}, 1, []int{3}},
+		{"fills gaps first", []int{1, 3}, 2, []int{2, 4}},
+		{"nothing to add", []int{1}, 0, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nextWorkerIndices(tt.existing, tt.count)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSelectWorkersToRemove(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []int
+		count    int
+		expected []int
+	}{
+		{"removes highest", []int{1, 2, 3}, 1, []int{3}},
+		{"removes all", []int{2, 1}, 2, []int{2, 1}},
+		{"count exceeds workers", []int{1}, 3, []int{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := selectWorkersToRemove(tt.existing, tt.count)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	existing := []int{1, 2, 3}
+	selectWorkersToRemove(existing, 1)
+	if !reflect.DeepEqual(existing, []int{1, 2, 3}) {
+		t.Errorf("expected input to be left unchanged, got %v", existing)
+	}
+}
+
+// fakeDeleteClient records the nodes deleted through it
+type fakeDeleteClient struct {
+	multipass.Client
+	deleted []string
+}
+
+func (f *fakeDeleteClient) DeleteNode(name string) error {
+	f.deleted = append(f.deleted, name)
+	return nil
+}
+
+func TestDeleteFailedWorkers(t *testing.T) {
+	client := &fakeDeleteClient{}
+	deleteFailedWorkers(client, []workerError{
+		{nodeName: "dev-worker-2", err: errors.New("join failed")},
+		{nodeName: "dev-worker-4", err: errors.New("not ready")},
+	})
+
+	expected := []string{"dev-worker-2", "dev-worker-4"}
+	if !reflect.DeepEqual(client.deleted, expected) {
+		t.Errorf("expected %v to be deleted, got %v", expected, client.deleted)
+	}
+}
+
+func TestResolveWorkerSchedulingScaledWorkers(t *testing.T) {
+	// scaling a 3 node cluster up adds workers past the recorded size
+	config := &types.ClusterConfig{
+		Name:         "dev",
+		Size:         3,
+		WorkerLabels: []string{"env=dev", "1:node-type=gpu", "4:disk=ssd"},
+	}
+
+	scheduling, err := resolveWorkerScheduling(config, []string{"dev-worker-3", "dev-worker-4"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]map[string]string{
+		"dev-worker-3": {"env": "dev"},
+		"dev-worker-4": {"env": "dev", "disk": "ssd"},
+	}
+	if len(scheduling) != len(expected) {
+		t.Fatalf("expected scheduling for %d workers, got %d", len(expected), len(scheduling))
+	}
+	for nodeName, labels := range expected {
+		if s := scheduling[nodeName]; s == nil || !reflect.DeepEqual(s.labels, labels) {
+			t.Errorf("expected labels %v for %s, got %+v", labels, nodeName, s)
+		}
+	}
+}
@@ -0,0 +1,354 @@
+package cluster
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mrgb7/playground/internal/installer"
+	"github.com/mrgb7/playground/internal/multipass"
+	"github.com/mrgb7/playground/internal/plugins"
+	"github.com/mrgb7/playground/internal/state"
+	"github.com/mrgb7/playground/internal/validator"
+	"github.com/mrgb7/playground/pkg/logger"
+	"github.com/mrgb7/playground/types"
+	"github.com/spf13/cobra"
+)
+
+const (
+	DefaultInitClusterName = "playground"
+	defaultNodeMemoryMB    = 2048
+	defaultNodeDiskMB      = 20480
+	minSuggestedMemoryMB   = 1024
+	minSuggestedDiskMB     = 10240
+)
+
+// initPlugins are the plugins offered by the init wizard, dependencies are installed with them
+var initPlugins = []string{
+	"nginx-ingress", "load-balancer", "cert-manager", plugins.TLSName, plugins.IngressName,
+	"argocd", plugins.DashboardName,
+}
+
+// InitCmd walks first time users through creating a cluster and installing plugins
+var InitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Interactively set up a first cluster",
+	Long: `Ask for the cluster name, size, node resources and plugins, create the cluster and
+install the plugins. Suggested resources fit the host, and the equivalent
+non-interactive commands are printed at the end.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !multipass.NewMultipassClient().IsMultipassInstalled() {
+			logger.Errorln("multipass is not installed or not in PATH")
+			return
+		}
+
+		var host *validator.HostResources
+		if h, err := validator.GetHostResources(); err == nil {
+			host = &h
+		} else {
+			logger.Warnln("Could not check host resources: %v", err)
+		}
+
+		p := newPrompter(cmd.InOrStdin(), cmd.OutOrStdout())
+		config, pluginNames, err := collectInitAnswers(p, host, func(name string) bool {
+			return types.NewCluster(name).IsExists()
+		})
+		if err != nil {
+			logger.Errorln("Setup aborted: %v", err)
+			return
+		}
+
+		commands := equivalentCommands(config, pluginNames)
+		create, err := p.confirm("Create the cluster now?", true)
+		if err != nil || !create {
+			printCommands(cmd.OutOrStdout(), "Run these commands to create the cluster later:", commands)
+			return
+		}
+
+		if err := createCluster(cmd.Context(), config); err != nil {
+			logger.Errorf("Failed to create cluster: %v", err)
+			return
+		}
+		if len(pluginNames) > 0 {
+			if err := installInitPlugins(config.Name, pluginNames); err != nil {
+				logger.Errorln("Failed to install plugins: %v", err)
+			}
+		}
+		printCommands(cmd.OutOrStdout(), "To recreate this setup without the wizard, run:", commands)
+	},
+}
+
+func installInitPlugins(clusterName string, pluginNames []string) error {
+	c, err := types.ResolveCluster(clusterName)
+	if err != nil {
+		return err
+	}
+	defs := make([]state.PluginDefinition, 0, len(pluginNames))
+	for _, name := range pluginNames {
+		defs = append(defs, state.PluginDefinition{Name: name})
+	}
+	return installDefinitionPlugins(c, defs)
+}
+
+// prompter asks questions on out and reads the answers line by line from in
+type prompter struct {
+	in  *bufio.Scanner
+	out io.Writer
+}
+
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	return &prompter{in: bufio.NewScanner(in), out: out}
+}
+
+// ask repeats question until the answer passes validate. An empty answer selects def.
+func (p *prompter) ask(question, def string, validate func(string) error) (string, error) {
+	for {
+		if def != "" {
+			fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", question)
+		}
+
+		if !p.in.Scan() {
+			if err := p.in.Err(); err != nil {
+				return "", err
+			}
+			return "", io.ErrUnexpectedEOF
+		}
+		answer := strings.TrimSpace(p.in.Text())
+		if answer == "" {
+			answer = def
+		}
+
+		if validate != nil {
+			if err := validate(answer); err != nil {
+				fmt.Fprintf(p.out, "  %v\n", err)
+				continue
+			}
+		}
+		return answer, nil
+	}
+}
+
+func (p *prompter) askInt(question string, def int, validate func(int) error) (int, error) {
+	answer, err := p.ask(question, strconv.Itoa(def), func(s string) error {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", s)
+		}
+		return validate(n)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(answer)
+}
+
+func (p *prompter) confirm(question string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	answer, err := p.ask(question+" ("+hint+")", "", func(s string) error {
+		switch strings.ToLower(s) {
+		case "", "y", "yes", "n", "no":
+			return nil
+		}
+		return fmt.Errorf("answer y or n")
+	})
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+		return true, nil
+	case "n", "no":
+		return false, nil
+	}
+	return def, nil
+}
+
+// nodeResources are the resources suggested for every node
+type nodeResources struct {
+	cpus   int
+	memory string
+	disk   string
+}
+
+// suggestResources scales the default node resources down so size nodes fit on host,
+// without going below what k3s comfortably runs on. A nil host keeps the defaults.
+func suggestResources(host *validator.HostResources, size int) nodeResources {
+	suggested := nodeResources{cpus: DefaultMasterCPUs, memory: formatSizeMB(defaultNodeMemoryMB),
+		disk: formatSizeMB(defaultNodeDiskMB)}
+	if host == nil || size < 1 {
+		return suggested
+	}
+
+	if host.CPUs > 0 && host.CPUs < suggested.cpus {
+		suggested.cpus = host.CPUs
+	}
+	if host.MemoryMB > 0 {
+		perNode := (host.MemoryMB - validator.HostMemoryReserveMB) / int64(size) / 512 * 512
+		suggested.memory = formatSizeMB(clampMB(perNode, minSuggestedMemoryMB, defaultNodeMemoryMB))
+	}
+	if host.DiskMB > 0 {
+		perNode := (host.DiskMB - validator.HostDiskReserveMB) / int64(size) / 1024 * 1024
+		suggested.disk = formatSizeMB(clampMB(perNode, minSuggestedDiskMB, defaultNodeDiskMB))
+	}
+	return suggested
+}
+
+func clampMB(mb, low, high int64) int64 {
+	return max(low, min(mb, high))
+}
+
+func formatSizeMB(mb int64) string {
+	if mb%1024 == 0 {
+		return fmt.Sprintf("%dG", mb/1024)
+	}
+	return fmt.Sprintf("%dM", mb)
+}
+
+// collectInitAnswers asks for the cluster settings and plugins, validating every answer
+// as it is given and asking for smaller resources until the cluster fits on host
+func collectInitAnswers(p *prompter, host *validator.HostResources,
+	exists func(string) bool) (*types.ClusterConfig, []string, error) {
+	name, err := p.ask("Cluster name", DefaultInitClusterName, func(s string) error {
+		if err := types.ValidateClusterName(s); err != nil {
+			return err
+		}
+		if exists(s) {
+			return fmt.Errorf("cluster '%s' already exists", s)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	size, err := p.askInt("Number of nodes", 1, types.ValidateClusterSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if host != nil {
+		fmt.Fprintf(p.out, "Host has %d CPUs, %dMB memory and %dMB disk available\n",
+			host.CPUs, host.MemoryMB, host.DiskMB)
+	}
+	suggested := suggestResources(host, size)
+
+	config := &types.ClusterConfig{Name: name, Size: size}
+	for {
+		if err := askNodeResources(p, "master", suggested, &config.MasterCPUs, &config.MasterMemory,
+			&config.MasterDisk); err != nil {
+			return nil, nil, err
+		}
+		config.WorkerCPUs, config.WorkerMemory, config.WorkerDisk = config.MasterCPUs, config.MasterMemory, config.MasterDisk
+		if size > 1 {
+			if err := askNodeResources(p, "worker", suggested, &config.WorkerCPUs, &config.WorkerMemory,
+				&config.WorkerDisk); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		if fitsHost(p.out, config, host) {
+			break
+		}
+		fmt.Fprintln(p.out, "Please enter smaller resources")
+	}
+
+	answer, err := p.ask(fmt.Sprintf("Plugins to install, comma separated (%s)", strings.Join(initPlugins, ", ")),
+		"", func(s string) error {
+			for _, name := range splitList(s) {
+				if !slices.Contains(initPlugins, name) {
+					return fmt.Errorf("unknown plugin %q", name)
+				}
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, nil, err
+	}
+	return config, splitList(answer), nil
+}
+
+func askNodeResources(p *prompter, nodeType string, suggested nodeResources, cpus *int, memory, disk *string) error {
+	var err error
+	*cpus, err = p.askInt(fmt.Sprintf("CPUs per %s node", nodeType), suggested.cpus, func(n int) error {
+		return types.ValidateCPUCount(n, nodeType)
+	})
+	if err != nil {
+		return err
+	}
+	*memory, err = p.ask(fmt.Sprintf("Memory per %s node", nodeType), suggested.memory, func(s string) error {
+		return types.ValidateMemoryFormat(s, nodeType)
+	})
+	if err != nil {
+		return err
+	}
+	*disk, err = p.ask(fmt.Sprintf("Disk per %s node", nodeType), suggested.disk, func(s string) error {
+		return types.ValidateDiskFormat(s, nodeType)
+	})
+	return err
+}
+
+// fitsHost reports whether the cluster fits on host, printing why it doesn't and any
+// recommendations. An unknown host always fits.
+func fitsHost(out io.Writer, config *types.ClusterConfig, host *validator.HostResources) bool {
+	if host == nil {
+		return true
+	}
+	req, err := validator.CalculateResourceRequirements(*config)
+	if err != nil {
+		fmt.Fprintf(out, "  %v\n", err)
+		return false
+	}
+
+	result := validator.ValidateResources(req, *host)
+	if report := result.String(); report != "" {
+		fmt.Fprintf(out, "  %s\n", strings.ReplaceAll(report, "\n", "\n  "))
+	}
+	return result.Valid
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" && !slices.Contains(items, item) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// equivalentCommands returns the commands creating the same cluster and plugins without the wizard
+func equivalentCommands(config *types.ClusterConfig, pluginNames []string) []string {
+	create := fmt.Sprintf("playground cluster create --name %s --size %d --master-cpus %d --master-memory %s "+
+		"--master-disk %s", config.Name, config.Size, config.MasterCPUs, config.MasterMemory, config.MasterDisk)
+	if config.Size > 1 {
+		create += fmt.Sprintf(" --worker-cpus %d --worker-memory %s --worker-disk %s",
+			config.WorkerCPUs, config.WorkerMemory, config.WorkerDisk)
+	}
+
+	commands := []string{create}
+	for _, name := range pluginNames {
+		commands = append(commands, fmt.Sprintf("playground cluster plugin add --name %s --cluster %s", name, config.Name))
+	}
+	return commands
+}
+
+func printCommands(out io.Writer, header string, commands []string) {
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, header)
+	for _, c := range commands {
+		fmt.Fprintf(out, "  %s\n", c)
+	}
+}
+
+func init() {
+	InitCmd.Flags().IntVar(&installer.ArgoLocalPort, "argo-local-port", installer.DefaultLocalPort,
+		"Local port to port-forward ArgoCD to when plugins are installed through it")
+}
@@ -0,0 +1,141 @@
+package cluster
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mrgb7/playground/internal/validator"
+	"github.com/mrgb7/playground/types"
+)
+
+func TestSuggestResources(t *testing.T) {
+	tests := []struct {
+		name     string
+		host     *validator.HostResources
+		size     int
+		expected nodeResources
+	}{
+		{"unknown host", nil, 3, nodeResources{cpus: 2, memory: "2G", disk: "20G"}},
+		{"large host", &validator.HostResources{CPUs: 16, MemoryMB: 32768, DiskMB: 512000}, 3,
+			nodeResources{cpus: 2, memory: "2G", disk: "20G"}},
+		{"small host", &validator.HostResources{CPUs: 1, MemoryMB: 6144, DiskMB: 50000}, 3,
+			nodeResources{cpus: 1, memory: "1536M", disk: "14G"}},
+		{"tiny host keeps minimum", &validator.HostResources{CPUs: 4, MemoryMB: 2048, DiskMB: 8192}, 2,
+			nodeResources{cpus: 2, memory: "1G", disk: "10G"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suggestResources(tt.host, tt.size); got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPrompterConfirm(t *testing.T) {
+	tests := []struct {
+		input    string
+		def      bool
+		expected bool
+	}{
+		{"\n", true, true},
+		{"\n", false, false},
+		{"y\n", false, true},
+		{"maybe\nno\n", true, false},
+	}
+
+	for _, tt := range tests {
+		p := newPrompter(strings.NewReader(tt.input), io.Discard)
+		got, err := p.confirm("Continue?", tt.def)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", tt.input, err)
+		}
+		if got != tt.expected {
+			t.Errorf("input %q: expected %t, got %t", tt.input, tt.expected, got)
+		}
+	}
+
+	_, err := newPrompter(strings.NewReader(""), io.Discard).confirm("Continue?", true)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected unexpected EOF without input, got %v", err)
+	}
+}
+
+func TestCollectInitAnswers(t *testing.T) {
+	host := &validator.HostResources{CPUs: 8, MemoryMB: 8192, DiskMB: 200000}
+	exists := func(name string) bool { return name == "taken" }
+
+	input := strings.Join([]string{
+		"Bad_Name",      // invalid name is asked again
+		"taken",         // existing cluster is asked again
+		"dev",           // name
+		"two",           // not a number
+		"2",             // size
+		"",              // master CPUs, suggested
+		"lots",          // invalid memory format
+		"6G",            // master memory
+		"",              // master disk, suggested
+		"",              // worker CPUs, suggested
+		"4G",            // worker memory, too much memory for the host together with the master
+		"",              // worker disk, suggested
+		"",              // master CPUs, asked again
+		"2G",            // master memory
+		"",              // master disk
+		"",              // worker CPUs
+		"2G",            // worker memory
+		"",              // worker disk
+		"argocd, vault", // unknown plugin
+		"cert-manager, argocd",
+	}, "\n") + "\n"
+
+	var out strings.Builder
+	config, pluginNames, err := collectInitAnswers(newPrompter(strings.NewReader(input), &out), host, exists)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\noutput:\n%s", err, out.String())
+	}
+
+	expected := &types.ClusterConfig{
+		Name: "dev", Size: 2,
+		MasterCPUs: 2, MasterMemory: "2G", MasterDisk: "20G",
+		WorkerCPUs: 2, WorkerMemory: "2G", WorkerDisk: "20G",
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("expected config %+v, got %+v", expected, config)
+	}
+	if !reflect.DeepEqual(pluginNames, []string{"cert-manager", "argocd"}) {
+		t.Errorf("unexpected plugins %v", pluginNames)
+	}
+
+	for _, msg := range []string{"already exists", "is not a number", "memory must be in format",
+		"Please enter smaller resources", `unknown plugin "vault"`} {
+		if !strings.Contains(out.String(), msg) {
+			t.Errorf("expected output to contain %q, got:\n%s", msg, out.String())
+		}
+	}
+}
+
+func TestEquivalentCommands(t *testing.T) {
+	config := &types.ClusterConfig{
+		Name: "dev", Size: 2,
+		MasterCPUs: 2, MasterMemory: "2G", MasterDisk: "20G",
+		WorkerCPUs: 1, WorkerMemory: "1G", WorkerDisk: "10G",
+	}
+
+	expected := []string{
+		"playground cluster create --name dev --size 2 --master-cpus 2 --master-memory 2G --master-disk 20G " +
+			"--worker-cpus 1 --worker-memory 1G --worker-disk 10G",
+		"playground cluster plugin add --name argocd --cluster dev",
+	}
+	if got := equivalentCommands(config, []string{"argocd"}); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	config.Size = 1
+	if got := equivalentCommands(config, nil); len(got) != 1 || strings.Contains(got[0], "--worker") {
+		t.Errorf("expected only the create command without worker flags, got %v", got)
+	}
+}
@@ -1,25 +1,49 @@
 package root
 
 import (
+	"context"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/mrgb7/playground/cmd/cluster"
+	"github.com/mrgb7/playground/internal/installer"
 	"github.com/mrgb7/playground/pkg/logger"
 	"github.com/spf13/cobra"
 )
 
+var (
+	logFormat string
+	logFile   string
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "playground",
 	Short: "A brief description of your application",
 	Long: `A longer description that spans multiple lines and likely contains
 examples and usage of using your application.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		f, err := logger.ParseFormat(logFormat)
+		if err != nil {
+			return err
+		}
+		logger.SetFormat(f)
+		if logFile != "" {
+			return logger.AddFileSink(logFile)
+		}
+		return nil
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		logger.Infoln("Hello from playground CLI!")
 	},
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// Ctrl-C cancels the command context so long running commands can clean up
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		logger.Errorln("Error: %v", err)
 		os.Exit(1)
 	}
@@ -27,5 +51,10 @@ func Execute() {
 
 func init() {
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	rootCmd.PersistentFlags().BoolVar(&installer.VerboseHelm, "verbose-helm", false,
+		"Show Helm's internal log output (also shown with LOG_LEVEL=debug)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Log output format: text or json")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Also append log output (without colors) to this file")
 	rootCmd.AddCommand(cluster.ClusterCmd)
+	rootCmd.AddCommand(cluster.InitCmd)
 }
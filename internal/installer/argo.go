@@ -5,14 +5,19 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/mrgb7/playground/internal/k8s"
+	"github.com/mrgb7/playground/internal/validator"
 	"github.com/mrgb7/playground/pkg/logger"
+	"github.com/mrgb7/playground/pkg/retry"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/portforward"
@@ -26,11 +31,15 @@ type ArgoInstaller struct {
 	ArgoServerPort int
 	LocalPort      int
 	ServerAddress  string
+	RetryAttempts  int           // attempts to establish the port forward and to authenticate
+	RetryBaseDelay time.Duration // delay after the first failed attempt, doubled after every other
 	k8sClient      *k8s.K8sClient
 	httpClient     *http.Client
 	authToken      string
 	stopChannel    chan struct{}
 	readyChannel   chan struct{}
+	sleep          func(ctx context.Context, d time.Duration) error // waits between retries, nil uses a timer
+	portForward    func() error                                     // sets up ServerAddress, nil port-forwards
 }
 
 type ArgoApplication struct {
@@ -57,12 +66,31 @@ type ArgoSource struct {
 	Path           string  `json:"path"`
 	TargetRevision string  `json:"targetRevision"`
 	Chart          *string `json:"chart,omitempty"` // Optional, used for Helm charts
-	Helm           struct {
+	// Directory configures plain manifest directories, e.g. recursion for app-of-apps layouts
+	Directory *ArgoSourceDirectory `json:"directory,omitempty"`
+	Helm      struct {
 		ReleaseName  string `json:"releaseName,omitempty"`
 		ValuesObject map[string]interface{}
 	} `json:"helm,omitempty"` // Optional, used for Helm charts
 }
 
+// ArgoSourceDirectory selects the manifests of a directory source
+type ArgoSourceDirectory struct {
+	Recurse bool   `json:"recurse,omitempty"`
+	Include string `json:"include,omitempty"` // glob of files to include, e.g. '*.yaml'
+	Exclude string `json:"exclude,omitempty"` // glob of files to skip
+}
+
+// ArgoApplicationStatus is the part of an Application's status playground reports
+type ArgoApplicationStatus struct {
+	Sync struct {
+		Status string `json:"status"`
+	} `json:"sync"`
+	Health struct {
+		Status string `json:"status"`
+	} `json:"health"`
+}
+
 type ArgoDestination struct {
 	Server    string `json:"server"`
 	Namespace string `json:"namespace"`
@@ -78,6 +106,28 @@ type ArgoSyncPolicyAutomated struct {
 	SelfHeal bool `json:"selfHeal,omitempty"`
 }
 
+type ArgoProject struct {
+	Metadata ArgoMetadata    `json:"metadata"`
+	Spec     ArgoProjectSpec `json:"spec"`
+}
+
+type ArgoProjectSpec struct {
+	Description              string            `json:"description,omitempty"`
+	SourceRepos              []string          `json:"sourceRepos"`
+	Destinations             []ArgoDestination `json:"destinations"`
+	ClusterResourceWhitelist []ArgoGroupKind   `json:"clusterResourceWhitelist,omitempty"`
+}
+
+type ArgoGroupKind struct {
+	Group string `json:"group"`
+	Kind  string `json:"kind"`
+}
+
+type ArgoProjectCreateRequest struct {
+	Project ArgoProject `json:"project"`
+	Upsert  bool        `json:"upsert"`
+}
+
 type ArgoSessionRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
@@ -91,8 +141,32 @@ const (
 	DefaultArgoNamespace  = "argocd"
 	DefaultArgoServerPort = 443
 	DefaultLocalPort      = 8080
+	DefaultArgoProject    = "default"
+	InClusterServer       = "https://kubernetes.default.svc"
+
+	DefaultArgoRetryAttempts  = 5
+	DefaultArgoRetryBaseDelay = 2 * time.Second
+
+	argoRetryMaxDelay       = 30 * time.Second
+	argoRetryJitter         = 0.2
+	portForwardReadyTimeout = 15 * time.Second
 )
 
+// ArgoInitialAdminSecret holds the admin password ArgoCD generates on install
+const ArgoInitialAdminSecret = "argocd-initial-admin-secret"
+
+// ArgoAdminPasswordSecret holds the admin password playground set through the argocd
+// plugin's admin.password override. ArgoCD only gets its hash, so without this secret
+// playground couldn't log in once the password was changed.
+const ArgoAdminPasswordSecret = "playground-argocd-admin"
+
+// ErrAdminSecretNotFound is returned when the initial admin secret no longer exists
+var ErrAdminSecretNotFound = errors.New(ArgoInitialAdminSecret + " not found")
+
+// ArgoLocalPort is the local port ArgoCD is port-forwarded to. When it is in use a free
+// port is picked instead.
+var ArgoLocalPort = DefaultLocalPort
+
 func NewArgoInstaller(kubeConfig, clusterName string) (*ArgoInstaller, error) {
 	k8sClient, err := k8s.NewK8sClient(kubeConfig)
 	if err != nil {
@@ -112,7 +186,9 @@ func NewArgoInstaller(kubeConfig, clusterName string) (*ArgoInstaller, error) {
 		ClusterName:    clusterName,
 		ArgoNamespace:  DefaultArgoNamespace,
 		ArgoServerPort: DefaultArgoServerPort,
-		LocalPort:      DefaultLocalPort,
+		LocalPort:      ArgoLocalPort,
+		RetryAttempts:  DefaultArgoRetryAttempts,
+		RetryBaseDelay: DefaultArgoRetryBaseDelay,
 		k8sClient:      k8sClient,
 		httpClient:     httpClient,
 	}, nil
@@ -124,12 +200,20 @@ func (a *ArgoInstaller) Install(options *InstallOptions) error {
 	}
 
 	logger.Infoln("Starting ArgoCD application installation...")
+	if options.RepoUsername != "" {
+		logger.Warnln("Repository credentials are not passed to ArgoCD, add %s as a repository in ArgoCD "+
+			"for it to pull private charts", options.RepoURL)
+	}
 
 	if err := a.connectToArgoCD(); err != nil {
 		return fmt.Errorf("failed to connect to ArgoCD: %w", err)
 	}
 	defer a.cleanup()
 
+	if err := a.ensureProject(options); err != nil {
+		return fmt.Errorf("failed to ensure ArgoCD project: %w", err)
+	}
+
 	if err := a.createApplication(options); err != nil {
 		return fmt.Errorf("failed to create ArgoCD application: %w", err)
 	}
@@ -160,14 +244,10 @@ func (a *ArgoInstaller) UnInstall(options *InstallOptions) error {
 		return nil
 	}
 
-	if err := k8sClient.DeleteNamespace(options.Namespace); err != nil {
+	if err := k8sClient.DeleteNamespace(options.Namespace, options.ForceNamespaceDeletion); err != nil {
 		logger.Warnf("Failed to cleanup namespace: %v", err)
 	}
-	if options.CRDsGroupVersion != "" {
-		if err := k8sClient.DeleteCRDsGroup(options.CRDsGroupVersion); err != nil {
-			logger.Warnf("Failed to delete CRDs: %v", err)
-		}
-	}
+	cleanupCRDs(k8sClient, options)
 
 	logger.Infoln("Successfully deleted ArgoCD application: %s", options.ApplicationName)
 	return nil
@@ -179,26 +259,54 @@ func (a *ArgoInstaller) connectToArgoCD() error {
 		return fmt.Errorf("failed to get admin password: %w", err)
 	}
 
-	if err := a.setupPortForward(); err != nil {
-		return fmt.Errorf("failed to setup port forward: %w", err)
+	portForward := a.portForward
+	if portForward == nil {
+		portForward = a.setupPortForward
+	}
+	if err := a.retryStep("port forward", portForward); err != nil {
+		return err
 	}
 
-	// Wait a bit longer for the port forward to be fully established
-	logger.Infoln("Waiting for port forward to stabilize...")
-	time.Sleep(5 * time.Second)
+	return a.retryStep("authentication", func() error {
+		return a.authenticate(password)
+	})
+}
 
-	// Retry authentication with backoff
-	var authErr error
-	for i := 0; i < 3; i++ {
-		authErr = a.authenticate(password)
-		if authErr == nil {
-			return nil
-		}
-		logger.Warnln("Authentication attempt %d failed: %v, retrying...", i+1, authErr)
-		time.Sleep(time.Duration(i+1) * 2 * time.Second)
+// retryOptions backs off exponentially with jitter from RetryBaseDelay
+func (a *ArgoInstaller) retryOptions() retry.Options {
+	opts := retry.Options{
+		Attempts:   a.RetryAttempts,
+		Backoff:    a.RetryBaseDelay,
+		Multiplier: 2,
+		MaxBackoff: argoRetryMaxDelay,
+		Jitter:     argoRetryJitter,
+		Sleep:      a.sleep,
 	}
+	if opts.Attempts <= 0 {
+		opts.Attempts = DefaultArgoRetryAttempts
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = DefaultArgoRetryBaseDelay
+	}
+	return opts
+}
 
-	return fmt.Errorf("failed to authenticate after 3 attempts: %w", authErr)
+// retryStep runs one step of connecting to ArgoCD until it succeeds or runs out of attempts
+func (a *ArgoInstaller) retryStep(name string, fn func() error) error {
+	opts := a.retryOptions()
+	attempt := 0
+	err := retry.Do(context.Background(), opts, func() error {
+		attempt++
+		stepErr := fn()
+		if stepErr != nil && attempt < opts.Attempts {
+			logger.Warnln("%s attempt %d failed: %v, retrying...", name, attempt, stepErr)
+		}
+		return stepErr
+	})
+	if err != nil {
+		return fmt.Errorf("%s failed after %d attempts: %w", name, attempt, err)
+	}
+	return nil
 }
 
 func (a *ArgoInstaller) authenticate(password string) error {
@@ -243,11 +351,30 @@ func (a *ArgoInstaller) authenticate(password string) error {
 	return nil
 }
 
-func (a *ArgoInstaller) createApplication(options *InstallOptions) error {
-	if options == nil {
-		return fmt.Errorf("install options cannot be nil")
+// syncOptions returns the ArgoCD sync options of an application installed with options
+func syncOptions(options *InstallOptions) []string {
+	if options.CreateNamespace {
+		return []string{"CreateNamespace=true"}
+	}
+	return nil
+}
+
+// directorySource returns the directory settings of an application installed with
+// options, nil when neither Recurse nor Directory is set
+func directorySource(options *InstallOptions) *ArgoSourceDirectory {
+	if !options.Recurse && options.Directory == nil {
+		return nil
+	}
+	directory := &ArgoSourceDirectory{}
+	if options.Directory != nil {
+		*directory = *options.Directory
 	}
+	directory.Recurse = directory.Recurse || options.Recurse
+	return directory
+}
 
+// buildApplication returns the Application created for options
+func (a *ArgoInstaller) buildApplication(options *InstallOptions) (ArgoApplication, error) {
 	app := ArgoApplication{
 		APIVersion: "argoproj.io/v1alpha1",
 		Kind:       "Application",
@@ -256,14 +383,15 @@ func (a *ArgoInstaller) createApplication(options *InstallOptions) error {
 			Namespace: a.ArgoNamespace,
 		},
 		Spec: ArgoApplicationSpec{
-			Project: "default",
+			Project: projectName(options),
 			Source: ArgoSource{
 				RepoURL:        options.RepoURL,
 				Path:           options.Path,
 				TargetRevision: options.Version,
+				Directory:      directorySource(options),
 			},
 			Destination: ArgoDestination{
-				Server:    "https://kubernetes.default.svc",
+				Server:    InClusterServer,
 				Namespace: options.Namespace,
 			},
 			SyncPolicy: &ArgoSyncPolicy{
@@ -271,11 +399,14 @@ func (a *ArgoInstaller) createApplication(options *InstallOptions) error {
 					Prune:    true,
 					SelfHeal: true,
 				},
-				SyncOptions: []string{"CreateNamespace=true"},
+				SyncOptions: syncOptions(options),
 			},
 		},
 	}
 	if options.ChartName != nil {
+		if app.Spec.Source.Directory != nil {
+			return ArgoApplication{}, fmt.Errorf("directory settings do not apply to chart %s", *options.ChartName)
+		}
 		app.Spec.Source.Chart = options.ChartName
 		app.Spec.Source.Helm.ReleaseName = options.ApplicationName
 		app.Spec.Source.Helm.ValuesObject = options.Values
@@ -288,6 +419,19 @@ func (a *ArgoInstaller) createApplication(options *InstallOptions) error {
 		app.Spec.Source.TargetRevision = "HEAD"
 	}
 
+	return app, nil
+}
+
+func (a *ArgoInstaller) createApplication(options *InstallOptions) error {
+	if options == nil {
+		return fmt.Errorf("install options cannot be nil")
+	}
+
+	app, err := a.buildApplication(options)
+	if err != nil {
+		return err
+	}
+
 	reqBody, err := json.Marshal(app)
 	if err != nil {
 		return fmt.Errorf("failed to marshal application: %w", err)
@@ -319,6 +463,235 @@ func (a *ArgoInstaller) createApplication(options *InstallOptions) error {
 	return nil
 }
 
+// CreateProject creates or updates an AppProject restricted to the given source
+// repositories and destinations. The installer connects to ArgoCD if needed.
+func (a *ArgoInstaller) CreateProject(name string, sourceRepos []string, destinations ...ArgoDestination) error {
+	if a.ServerAddress == "" {
+		if err := a.connectToArgoCD(); err != nil {
+			return fmt.Errorf("failed to connect to ArgoCD: %w", err)
+		}
+		defer a.cleanup()
+	}
+
+	return a.createProject(name, sourceRepos, destinations)
+}
+
+func (a *ArgoInstaller) createProject(name string, sourceRepos []string, destinations []ArgoDestination) error {
+	if name == "" {
+		return fmt.Errorf("project name cannot be empty")
+	}
+	if len(sourceRepos) == 0 {
+		return fmt.Errorf("project %s needs at least one source repository", name)
+	}
+	if len(destinations) == 0 {
+		return fmt.Errorf("project %s needs at least one destination", name)
+	}
+
+	project := ArgoProjectCreateRequest{
+		Project: ArgoProject{
+			Metadata: ArgoMetadata{
+				Name:      name,
+				Namespace: a.ArgoNamespace,
+			},
+			Spec: ArgoProjectSpec{
+				Description:  "Managed by playground",
+				SourceRepos:  sourceRepos,
+				Destinations: destinations,
+				// Charts such as cert-manager install cluster-scoped resources
+				ClusterResourceWhitelist: []ArgoGroupKind{{Group: "*", Kind: "*"}},
+			},
+		},
+		Upsert: true,
+	}
+
+	reqBody, err := json.Marshal(project)
+	if err != nil {
+		return fmt.Errorf("failed to marshal project: %w", err)
+	}
+
+	url := fmt.Sprintf("http://%s/api/v1/projects", a.ServerAddress)
+	req, err := http.NewRequestWithContext(context.Background(), "POST", url, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return fmt.Errorf("failed to create project request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+a.authToken)
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to create project: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Debugln("Failed to close response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to create project: HTTP %d - %s", resp.StatusCode, string(body))
+	}
+
+	logger.Infoln("Created ArgoCD project: %s", name)
+	return nil
+}
+
+func (a *ArgoInstaller) projectExists(name string) (bool, error) {
+	url := fmt.Sprintf("http://%s/api/v1/projects/%s", a.ServerAddress, name)
+	req, err := http.NewRequestWithContext(context.Background(), "GET", url, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create project request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+a.authToken)
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to get project: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Debugln("Failed to close response body: %v", err)
+		}
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound, http.StatusForbidden:
+		// ArgoCD answers 403 rather than 404 for missing projects
+		return false, nil
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return false, fmt.Errorf("failed to get project: HTTP %d - %s", resp.StatusCode, string(body))
+	}
+}
+
+// ensureProject creates the application's project, scoped to its repository and
+// namespace, when it does not exist yet
+func (a *ArgoInstaller) ensureProject(options *InstallOptions) error {
+	name := projectName(options)
+	if name == DefaultArgoProject {
+		return nil
+	}
+
+	exists, err := a.projectExists(name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	return a.createProject(name, []string{options.RepoURL}, []ArgoDestination{{
+		Server:    InClusterServer,
+		Namespace: options.Namespace,
+	}})
+}
+
+func projectName(options *InstallOptions) string {
+	if options.Project == "" {
+		return DefaultArgoProject
+	}
+	return options.Project
+}
+
+// GetApplicationStatus returns the sync status (e.g. Synced, OutOfSync) and the health
+// status (e.g. Healthy, Progressing) of an Application. The installer connects to
+// ArgoCD if needed.
+func (a *ArgoInstaller) GetApplicationStatus(name string) (sync string, health string, err error) {
+	if a.ServerAddress == "" {
+		if err := a.connectToArgoCD(); err != nil {
+			return "", "", fmt.Errorf("failed to connect to ArgoCD: %w", err)
+		}
+		defer a.cleanup()
+	}
+
+	status, err := a.applicationStatus(name)
+	if err != nil {
+		return "", "", err
+	}
+	return status.Sync.Status, status.Health.Status, nil
+}
+
+func (a *ArgoInstaller) applicationStatus(name string) (*ArgoApplicationStatus, error) {
+	url := fmt.Sprintf("http://%s/api/v1/applications/%s", a.ServerAddress, name)
+	req, err := http.NewRequestWithContext(context.Background(), "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create application request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+a.authToken)
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get application: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Debugln("Failed to close response body: %v", err)
+		}
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusForbidden:
+		// ArgoCD answers 403 rather than 404 for missing applications
+		return nil, fmt.Errorf("application %s not found", name)
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get application: HTTP %d - %s", resp.StatusCode, string(body))
+	}
+
+	var app struct {
+		Status ArgoApplicationStatus `json:"status"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&app); err != nil {
+		return nil, fmt.Errorf("failed to decode application: %w", err)
+	}
+	return &app.Status, nil
+}
+
+// ForceSync starts a sync of an Application to its target revision, pruning resources
+// that are no longer in its source. The installer connects to ArgoCD if needed.
+func (a *ArgoInstaller) ForceSync(name string) error {
+	if a.ServerAddress == "" {
+		if err := a.connectToArgoCD(); err != nil {
+			return fmt.Errorf("failed to connect to ArgoCD: %w", err)
+		}
+		defer a.cleanup()
+	}
+
+	reqBody, err := json.Marshal(map[string]interface{}{"name": name, "prune": true})
+	if err != nil {
+		return fmt.Errorf("failed to marshal sync request: %w", err)
+	}
+
+	url := fmt.Sprintf("http://%s/api/v1/applications/%s/sync", a.ServerAddress, name)
+	req, err := http.NewRequestWithContext(context.Background(), "POST", url, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return fmt.Errorf("failed to create sync request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+a.authToken)
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to sync application: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Debugln("Failed to close response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to sync application %s: HTTP %d - %s", name, resp.StatusCode, string(body))
+	}
+
+	logger.Infoln("Started sync of ArgoCD application: %s", name)
+	return nil
+}
+
 func (a *ArgoInstaller) deleteApplication(options *InstallOptions) error {
 	if options == nil {
 		return fmt.Errorf("install options cannot be nil")
@@ -382,7 +755,11 @@ func (a *ArgoInstaller) setupPortForward() error {
 	if err != nil {
 		return fmt.Errorf("failed to create SPDY transport: %w", err)
 	}
-	ports := []string{fmt.Sprintf("%d:8080", a.LocalPort)}
+	localPort, err := a.localForwardPort()
+	if err != nil {
+		return err
+	}
+	ports := []string{fmt.Sprintf("%d:8080", localPort)}
 
 	a.stopChannel = make(chan struct{}, 1)
 	a.readyChannel = make(chan struct{}, 1)
@@ -414,19 +791,63 @@ func (a *ArgoInstaller) setupPortForward() error {
 		logger.Infoln("Port forward established successfully")
 	case err := <-errChan:
 		close(a.stopChannel)
+		a.stopChannel = nil
 		return fmt.Errorf("port forwarding failed: %w", err)
-	case <-time.After(15 * time.Second):
+	case <-time.After(portForwardReadyTimeout):
 		close(a.stopChannel)
+		a.stopChannel = nil
 		return fmt.Errorf("timeout waiting for port forward to be ready")
 	}
 
-	a.ServerAddress = fmt.Sprintf("localhost:%d", a.LocalPort)
+	a.ServerAddress = fmt.Sprintf("localhost:%d", localPort)
 	return nil
 }
 
+// localForwardPort returns LocalPort, or a free port when LocalPort is unset or in use
+func (a *ArgoInstaller) localForwardPort() (int, error) {
+	if a.LocalPort > 0 && !validator.IsPortInUse(a.LocalPort) {
+		return a.LocalPort, nil
+	}
+
+	port, err := freeLocalPort()
+	if err != nil {
+		return 0, fmt.Errorf("failed to find a free local port for ArgoCD: %w", err)
+	}
+	if a.LocalPort > 0 {
+		logger.Warnln("Local port %d is in use, forwarding ArgoCD to port %d instead", a.LocalPort, port)
+	}
+	return port, nil
+}
+
+// freeLocalPort asks the OS for an unused port
+func freeLocalPort() (int, error) {
+	ln, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return 0, err
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	_ = ln.Close()
+	return port, nil
+}
+
+// GetAdminPassword returns the admin password: the one saved by SaveAdminPassword when
+// it was overridden, otherwise the initial one ArgoCD generated. It fails with
+// ErrAdminSecretNotFound once the initial secret was deleted, which ArgoCD recommends
+// after changing the password.
 func (a *ArgoInstaller) GetAdminPassword() (string, error) {
-	secret, err := a.k8sClient.Clientset.CoreV1().Secrets(a.ArgoNamespace).Get(
-		context.Background(), "argocd-initial-admin-secret", metav1.GetOptions{})
+	secrets := a.k8sClient.Clientset.CoreV1().Secrets(a.ArgoNamespace)
+	saved, err := secrets.Get(context.Background(), ArgoAdminPasswordSecret, metav1.GetOptions{})
+	switch {
+	case err == nil && len(saved.Data["password"]) > 0:
+		return string(saved.Data["password"]), nil
+	case err != nil && !apierrors.IsNotFound(err):
+		return "", fmt.Errorf("failed to get %s: %w", ArgoAdminPasswordSecret, err)
+	}
+
+	secret, err := secrets.Get(context.Background(), ArgoInitialAdminSecret, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return "", fmt.Errorf("%w in namespace %s", ErrAdminSecretNotFound, a.ArgoNamespace)
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to get ArgoCD admin secret: %w", err)
 	}
@@ -439,6 +860,32 @@ func (a *ArgoInstaller) GetAdminPassword() (string, error) {
 	return password, nil
 }
 
+// SaveAdminPassword stores the admin password set on ArgoCD in ArgoAdminPasswordSecret,
+// which GetAdminPassword prefers over the initial secret
+func (a *ArgoInstaller) SaveAdminPassword(password string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ArgoAdminPasswordSecret,
+			Namespace: a.ArgoNamespace,
+			Labels:    map[string]string{"app.kubernetes.io/managed-by": "playground"},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{"password": []byte(password)},
+	}
+	secrets := a.k8sClient.Clientset.CoreV1().Secrets(a.ArgoNamespace)
+	_, err := secrets.Create(ctx, secret, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save the ArgoCD admin password: %w", err)
+	}
+	return nil
+}
+
 func (a *ArgoInstaller) ValidateArgoConnection() error {
 	if a.ServerAddress == "" {
 		return fmt.Errorf("no active connection to ArgoCD")
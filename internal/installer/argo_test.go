@@ -1,9 +1,23 @@
 package installer
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/mrgb7/playground/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestNewArgoInstaller(t *testing.T) {
@@ -563,6 +577,153 @@ func TestArgoInstaller_HTTPClientConfiguration(t *testing.T) {
 	}
 }
 
+func newTestArgoServer(t *testing.T, existingProjects ...string) (*ArgoInstaller, *[]ArgoProjectCreateRequest, *[]ArgoApplication) {
+	t.Helper()
+	projects := []ArgoProjectCreateRequest{}
+	apps := []ArgoApplication{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/api/v1/projects/"):
+			name := strings.TrimPrefix(r.URL.Path, "/api/v1/projects/")
+			for _, p := range existingProjects {
+				if p == name {
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+			}
+			w.WriteHeader(http.StatusForbidden)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/projects":
+			var req ArgoProjectCreateRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			projects = append(projects, req)
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/applications":
+			var app ArgoApplication
+			if err := json.NewDecoder(r.Body).Decode(&app); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			apps = append(apps, app)
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	installer := &ArgoInstaller{
+		ArgoNamespace: DefaultArgoNamespace,
+		ServerAddress: strings.TrimPrefix(server.URL, "http://"),
+		httpClient:    server.Client(),
+	}
+	return installer, &projects, &apps
+}
+
+func TestArgoInstaller_CreateProject(t *testing.T) {
+	installer, projects, _ := newTestArgoServer(t)
+
+	err := installer.CreateProject("team-a", []string{"https://example.com/charts"},
+		ArgoDestination{Server: InClusterServer, Namespace: "team-a"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(*projects) != 1 {
+		t.Fatalf("expected 1 project request, got %d", len(*projects))
+	}
+	req := (*projects)[0]
+	if req.Project.Metadata.Name != "team-a" || !req.Upsert {
+		t.Errorf("unexpected project request: %+v", req)
+	}
+	if len(req.Project.Spec.Destinations) != 1 || req.Project.Spec.Destinations[0].Namespace != "team-a" {
+		t.Errorf("unexpected destinations: %+v", req.Project.Spec.Destinations)
+	}
+
+	if err := installer.CreateProject("team-b", nil, ArgoDestination{Server: InClusterServer}); err == nil {
+		t.Error("expected error for project without source repositories")
+	}
+	if err := installer.CreateProject("team-b", []string{"https://example.com/charts"}); err == nil {
+		t.Error("expected error for project without destinations")
+	}
+}
+
+func TestArgoInstaller_EnsureProjectBeforeApplication(t *testing.T) {
+	tests := []struct {
+		name             string
+		project          string
+		existing         []string
+		expectedProject  string
+		expectedCreation int
+	}{
+		{"default project", "", nil, DefaultArgoProject, 0},
+		{"missing project is created", "team-a", nil, "team-a", 1},
+		{"existing project is reused", "team-a", []string{"team-a"}, "team-a", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installer, projects, apps := newTestArgoServer(t, tt.existing...)
+			options := &InstallOptions{
+				ApplicationName: "app",
+				RepoURL:         "https://example.com/charts",
+				Namespace:       "app-ns",
+				Project:         tt.project,
+			}
+
+			if err := installer.ensureProject(options); err != nil {
+				t.Fatalf("unexpected error ensuring project: %v", err)
+			}
+			if err := installer.createApplication(options); err != nil {
+				t.Fatalf("unexpected error creating application: %v", err)
+			}
+
+			if len(*projects) != tt.expectedCreation {
+				t.Errorf("expected %d project creations, got %d", tt.expectedCreation, len(*projects))
+			}
+			if len(*apps) != 1 || (*apps)[0].Spec.Project != tt.expectedProject {
+				t.Errorf("expected application in project %s, got %+v", tt.expectedProject, *apps)
+			}
+		})
+	}
+}
+
+func TestArgoInstaller_CreateNamespaceSyncOption(t *testing.T) {
+	tests := []struct {
+		name            string
+		createNamespace bool
+		expected        []string
+	}{
+		{"create namespace", true, []string{"CreateNamespace=true"}},
+		{"existing namespace", false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installer, _, apps := newTestArgoServer(t)
+			options := &InstallOptions{
+				ApplicationName: "app",
+				RepoURL:         "https://example.com/charts",
+				Namespace:       "app-ns",
+				CreateNamespace: tt.createNamespace,
+			}
+
+			if err := installer.createApplication(options); err != nil {
+				t.Fatalf("unexpected error creating application: %v", err)
+			}
+			if len(*apps) != 1 {
+				t.Fatalf("expected 1 application, got %d", len(*apps))
+			}
+			if got := (*apps)[0].Spec.SyncPolicy.SyncOptions; !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected sync options %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
 func createValidKubeConfig() string {
 	return `
 apiVersion: v1
@@ -600,3 +761,382 @@ func validateInstallOptions(options *InstallOptions) bool {
 
 	return true
 }
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// sessionTransport fails the first failures session requests before handing out a token
+func sessionTransport(failures int) (http.RoundTripper, *int) {
+	calls := 0
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		status, body := http.StatusOK, `{"token":"session-token"}`
+		if calls <= failures {
+			status, body = http.StatusServiceUnavailable, "not ready"
+		}
+		return &http.Response{
+			StatusCode: status,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     make(http.Header),
+			Request:    req,
+		}, nil
+	}), &calls
+}
+
+func TestArgoInstaller_AuthenticationBackoff(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		expectErr bool
+		sleeps    int
+	}{
+		{"first attempt succeeds", 0, false, 0},
+		{"succeeds after retries", 2, false, 2},
+		{"runs out of attempts", 10, true, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, calls := sessionTransport(tt.failures)
+			var slept []time.Duration
+			installer := &ArgoInstaller{
+				ServerAddress:  "argocd.test",
+				RetryAttempts:  4,
+				RetryBaseDelay: 100 * time.Millisecond,
+				httpClient:     &http.Client{Transport: transport},
+				sleep: func(ctx context.Context, d time.Duration) error {
+					slept = append(slept, d)
+					return nil
+				},
+			}
+
+			err := installer.retryStep("authentication", func() error {
+				return installer.authenticate("password")
+			})
+			if tt.expectErr {
+				if err == nil || !strings.Contains(err.Error(), "after 4 attempts") {
+					t.Errorf("expected failure after 4 attempts, got %v", err)
+				}
+				if *calls != 4 {
+					t.Errorf("expected 4 session requests, got %d", *calls)
+				}
+			} else if err != nil || installer.authToken != "session-token" {
+				t.Errorf("expected authentication to succeed, got %v", err)
+			}
+
+			if len(slept) != tt.sleeps {
+				t.Fatalf("expected %d sleeps, got %v", tt.sleeps, slept)
+			}
+			base := installer.RetryBaseDelay
+			for i, d := range slept {
+				low := time.Duration(float64(base) * (1 - argoRetryJitter))
+				high := time.Duration(float64(base) * (1 + argoRetryJitter))
+				if d < low || d > high {
+					t.Errorf("sleep %d: expected %v to be within %v and %v", i, d, low, high)
+				}
+				base *= 2
+			}
+		})
+	}
+}
+
+func TestArgoInstaller_RetryOptionsDefaults(t *testing.T) {
+	opts := (&ArgoInstaller{}).retryOptions()
+	if opts.Attempts != DefaultArgoRetryAttempts || opts.Backoff != DefaultArgoRetryBaseDelay {
+		t.Errorf("expected default attempts and delay, got %d and %v", opts.Attempts, opts.Backoff)
+	}
+	if opts.Multiplier != 2 || opts.Jitter != argoRetryJitter {
+		t.Errorf("expected exponential backoff with jitter, got %+v", opts)
+	}
+}
+
+func TestArgoInstaller_LocalForwardPort(t *testing.T) {
+	occupied, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("failed to occupy a port: %v", err)
+	}
+	defer occupied.Close()
+	busyPort := occupied.Addr().(*net.TCPAddr).Port
+
+	freePort, err := freeLocalPort()
+	if err != nil {
+		t.Fatalf("failed to find a free port: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		localPort int
+		keep      bool
+	}{
+		{"free port is kept", freePort, true},
+		{"port in use falls back", busyPort, false},
+		{"unset port picks a free one", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port, err := (&ArgoInstaller{LocalPort: tt.localPort}).localForwardPort()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.keep && port != tt.localPort {
+				t.Errorf("expected port %d to be kept, got %d", tt.localPort, port)
+			}
+			if !tt.keep && (port == tt.localPort || port == busyPort || port <= 0) {
+				t.Errorf("expected a free fallback port, got %d", port)
+			}
+		})
+	}
+}
+
+func TestArgoInstaller_DirectorySource(t *testing.T) {
+	chart := "app"
+	tests := []struct {
+		name      string
+		options   InstallOptions
+		expected  *ArgoSourceDirectory
+		expectErr bool
+	}{
+		{"no directory settings", InstallOptions{Path: "apps"}, nil, false},
+		{"recurse", InstallOptions{Path: "apps", Recurse: true}, &ArgoSourceDirectory{Recurse: true}, false},
+		{"directory settings", InstallOptions{
+			Path: "apps", Recurse: true, Directory: &ArgoSourceDirectory{Include: "*.yaml"},
+		}, &ArgoSourceDirectory{Recurse: true, Include: "*.yaml"}, false},
+		{"chart ignores unset directory", InstallOptions{ChartName: &chart}, nil, false},
+		{"chart with recurse", InstallOptions{ChartName: &chart, Recurse: true}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.options.ApplicationName = "apps"
+			tt.options.RepoURL = "https://example.com/apps.git"
+			app, err := (&ArgoInstaller{ArgoNamespace: DefaultArgoNamespace}).buildApplication(&tt.options)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			body, err := json.Marshal(app)
+			if err != nil {
+				t.Fatalf("failed to marshal application: %v", err)
+			}
+			var raw struct {
+				Spec struct {
+					Source map[string]json.RawMessage `json:"source"`
+				} `json:"spec"`
+			}
+			if err := json.Unmarshal(body, &raw); err != nil {
+				t.Fatalf("failed to decode application: %v", err)
+			}
+
+			directory, ok := raw.Spec.Source["directory"]
+			if tt.expected == nil {
+				if ok {
+					t.Errorf("expected no directory block, got %s", directory)
+				}
+				return
+			}
+			var got ArgoSourceDirectory
+			if err := json.Unmarshal(directory, &got); err != nil {
+				t.Fatalf("expected a directory block, got %s", body)
+			}
+			if got != *tt.expected {
+				t.Errorf("expected directory %+v, got %+v", *tt.expected, got)
+			}
+		})
+	}
+}
+
+const cannedApplication = `{
+	"metadata": {"name": "cert-manager", "namespace": "argocd"},
+	"spec": {"project": "default"},
+	"status": {
+		"sync": {"status": "OutOfSync", "revision": "v1.16.1"},
+		"health": {"status": "Progressing"}
+	}
+}`
+
+func newApplicationServer(t *testing.T) (*ArgoInstaller, *[]string) {
+	t.Helper()
+	var syncs []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/applications/cert-manager":
+			_, _ = w.Write([]byte(cannedApplication))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/applications/cert-manager/sync":
+			var req map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			syncs = append(syncs, req["name"].(string))
+			_, _ = w.Write([]byte(cannedApplication))
+		case strings.HasPrefix(r.URL.Path, "/api/v1/applications/"):
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return &ArgoInstaller{
+		ArgoNamespace: DefaultArgoNamespace,
+		ServerAddress: strings.TrimPrefix(server.URL, "http://"),
+		httpClient:    server.Client(),
+		authToken:     "token",
+	}, &syncs
+}
+
+func TestArgoInstaller_GetApplicationStatus(t *testing.T) {
+	installer, _ := newApplicationServer(t)
+
+	sync, health, err := installer.GetApplicationStatus("cert-manager")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sync != "OutOfSync" || health != "Progressing" {
+		t.Errorf("expected OutOfSync and Progressing, got %s and %s", sync, health)
+	}
+
+	if _, _, err := installer.GetApplicationStatus("missing"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func TestArgoInstaller_ForceSync(t *testing.T) {
+	installer, syncs := newApplicationServer(t)
+
+	if err := installer.ForceSync("cert-manager"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(*syncs, []string{"cert-manager"}) {
+		t.Errorf("expected one sync of cert-manager, got %v", *syncs)
+	}
+
+	if err := installer.ForceSync("missing"); err == nil {
+		t.Error("expected syncing a missing application to fail")
+	}
+}
+
+func TestArgoInstaller_GetAdminPassword(t *testing.T) {
+	adminSecret := func(password string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: ArgoInitialAdminSecret, Namespace: "platform-argocd"},
+			Data:       map[string][]byte{"password": []byte(password)},
+		}
+	}
+	savedSecret := func(password string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: ArgoAdminPasswordSecret, Namespace: "platform-argocd"},
+			Data:       map[string][]byte{"password": []byte(password)},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		objects     []runtime.Object
+		expected    string
+		expectError error
+	}{
+		{name: "initial secret present", objects: []runtime.Object{adminSecret("s3cret")}, expected: "s3cret"},
+		{name: "initial secret deleted", expectError: ErrAdminSecretNotFound},
+		{name: "empty password", objects: []runtime.Object{adminSecret("")}},
+		{
+			name:     "overridden password preferred",
+			objects:  []runtime.Object{adminSecret("s3cret"), savedSecret("0verride")},
+			expected: "0verride",
+		},
+		{name: "overridden password only", objects: []runtime.Object{savedSecret("0verride")}, expected: "0verride"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			argo := &ArgoInstaller{
+				ArgoNamespace: "platform-argocd",
+				k8sClient:     &k8s.K8sClient{Clientset: fake.NewSimpleClientset(tt.objects...)},
+			}
+
+			password, err := argo.GetAdminPassword()
+			switch {
+			case tt.expectError != nil:
+				if !errors.Is(err, tt.expectError) {
+					t.Errorf("expected %v, got %v", tt.expectError, err)
+				}
+			case tt.expected == "":
+				if err == nil {
+					t.Errorf("expected an error, got password %q", password)
+				}
+			case err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case password != tt.expected:
+				t.Errorf("expected password %q, got %q", tt.expected, password)
+			}
+		})
+	}
+}
+
+func TestArgoInstaller_InstallAfterPasswordOverride(t *testing.T) {
+	var apps []ArgoApplication
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/session":
+			var req ArgoSessionRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Password != "0verride" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"token":"session-token"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/applications":
+			if r.Header.Get("Authorization") != "Bearer session-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			var app ArgoApplication
+			_ = json.NewDecoder(r.Body).Decode(&app)
+			apps = append(apps, app)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	// the override keeps ArgoCD from creating the initial admin secret
+	argo := &ArgoInstaller{
+		ArgoNamespace: DefaultArgoNamespace,
+		RetryAttempts: 1,
+		k8sClient:     &k8s.K8sClient{Clientset: fake.NewSimpleClientset()},
+		httpClient:    server.Client(),
+	}
+	argo.portForward = func() error {
+		argo.ServerAddress = strings.TrimPrefix(server.URL, "http://")
+		return nil
+	}
+	if err := argo.SaveAdminPassword("stale"); err != nil {
+		t.Fatalf("SaveAdminPassword: %v", err)
+	}
+	// saving again, e.g. on a later override, replaces the password
+	if err := argo.SaveAdminPassword("0verride"); err != nil {
+		t.Fatalf("SaveAdminPassword: %v", err)
+	}
+
+	chart := "nginx"
+	err := argo.Install(&InstallOptions{ApplicationName: "app", RepoURL: "https://example.com/charts",
+		ChartName: &chart, Version: "1.0.0", Namespace: "app"})
+	if err != nil {
+		t.Fatalf("expected the install to authenticate with the overridden password, got %v", err)
+	}
+	if len(apps) != 1 || apps[0].Metadata.Name != "app" {
+		t.Errorf("expected the application to be created, got %+v", apps)
+	}
+}
@@ -0,0 +1,46 @@
+package installer
+
+import (
+	"github.com/mrgb7/playground/internal/k8s"
+	"github.com/mrgb7/playground/pkg/logger"
+)
+
+// cleanupCRDs deletes the CRDs of options.CRDsGroupVersion after an uninstall unless they
+// are kept. CRDs that still have custom resources are only deleted once confirmed.
+func cleanupCRDs(k8sClient *k8s.K8sClient, options *InstallOptions) {
+	group := options.CRDsGroupVersion
+	if group == "" {
+		return
+	}
+	if options.KeepCRDs {
+		logger.Infoln("Keeping the CRDs of group %s", group)
+		return
+	}
+
+	counts, err := k8sClient.CountCustomResources(group)
+	if err != nil {
+		logger.Warnf("Failed to count custom resources, keeping the CRDs of group %s: %v", group, err)
+		return
+	}
+	if inUse := crdsInUse(counts); len(inUse) > 0 {
+		if options.ConfirmCRDDeletion == nil || !options.ConfirmCRDDeletion(inUse) {
+			logger.Infoln("Keeping the CRDs of group %s, they still have custom resources", group)
+			return
+		}
+	}
+
+	if err := k8sClient.DeleteCRDsGroup(group); err != nil {
+		logger.Warnf("Failed to delete CRDs: %v", err)
+	}
+}
+
+// crdsInUse returns the CRDs having custom resources
+func crdsInUse(counts map[string]int) map[string]int {
+	inUse := make(map[string]int)
+	for crd, count := range counts {
+		if count > 0 {
+			inUse[crd] = count
+		}
+	}
+	return inUse
+}
@@ -2,9 +2,12 @@ package installer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/mrgb7/playground/internal/k8s"
@@ -13,12 +16,28 @@ import (
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/downloader"
 	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/repo"
+	"helm.sh/helm/v3/pkg/storage/driver"
 )
 
 var settings = cli.New()
 
+// VerboseHelm surfaces Helm's internal log output without enabling debug logging
+var VerboseHelm bool
+
+// helmLog adapts Helm's printf-style logger to pkg/logger
+func helmLog(format string, v ...interface{}) {
+	if VerboseHelm {
+		logger.Print("[helm] "+format, v...)
+		return
+	}
+	logger.Debugf("[helm] "+format, v...)
+}
+
 func NewHelmInstaller(kubeConfig string) (*HelmInstaller, error) {
 	return &HelmInstaller{
 		KubeConfig: kubeConfig,
@@ -34,7 +53,7 @@ func (h *HelmInstaller) Install(options *InstallOptions) error {
 		return fmt.Errorf("install options cannot be nil")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), options.EffectiveTimeout())
 	defer cancel()
 	actionConfig, err := h.createHelmActionConfig(options.Namespace)
 	if err != nil {
@@ -47,8 +66,7 @@ func (h *HelmInstaller) Install(options *InstallOptions) error {
 
 	if err == nil {
 		// Release exists, upgrade it
-		upgrade := action.NewUpgrade(actionConfig)
-		upgrade.Namespace = options.Namespace
+		upgrade := newUpgradeAction(actionConfig, options)
 
 		chart, err := h.downloadAndLoadChart(options)
 		if err != nil {
@@ -66,10 +84,7 @@ func (h *HelmInstaller) Install(options *InstallOptions) error {
 		}
 	} else {
 		// Release doesn't exist, install it
-		install := action.NewInstall(actionConfig)
-		install.Namespace = options.Namespace
-		install.ReleaseName = options.ApplicationName
-		install.CreateNamespace = true
+		install := newInstallAction(actionConfig, options)
 
 		chart, err := h.downloadAndLoadChart(options)
 		if err != nil {
@@ -99,9 +114,7 @@ func (h *HelmInstaller) UnInstall(options *InstallOptions) error {
 		return fmt.Errorf("failed to create helm action config: %w", err)
 	}
 
-	uninstall := action.NewUninstall(actionConfig)
-	uninstall.Timeout = 5 * time.Minute
-	uninstall.Wait = true
+	uninstall := newUninstallAction(actionConfig, options)
 
 	_, err = uninstall.Run(options.ApplicationName)
 	if err != nil {
@@ -115,18 +128,141 @@ func (h *HelmInstaller) UnInstall(options *InstallOptions) error {
 		return nil
 	}
 
-	if err := k8sClient.DeleteNamespace(options.Namespace); err != nil {
+	if err := k8sClient.DeleteNamespace(options.Namespace, options.ForceNamespaceDeletion); err != nil {
 		logger.Errorf("Failed to cleanup namespace: %v", err)
 	}
-	if options.CRDsGroupVersion != "" {
-		if err := k8sClient.DeleteCRDsGroup(options.CRDsGroupVersion); err != nil {
-			logger.Warnf("Failed to delete CRDs: %v", err)
+	cleanupCRDs(k8sClient, options)
+
+	return nil
+}
+
+func newInstallAction(actionConfig *action.Configuration, options *InstallOptions) *action.Install {
+	install := action.NewInstall(actionConfig)
+	install.Namespace = options.Namespace
+	install.ReleaseName = options.ApplicationName
+	install.CreateNamespace = options.CreateNamespace
+	install.Wait = options.Wait
+	install.Timeout = options.EffectiveTimeout()
+	return install
+}
+
+func newUpgradeAction(actionConfig *action.Configuration, options *InstallOptions) *action.Upgrade {
+	upgrade := action.NewUpgrade(actionConfig)
+	upgrade.Namespace = options.Namespace
+	upgrade.Wait = options.Wait
+	upgrade.Timeout = options.EffectiveTimeout()
+	return upgrade
+}
+
+// newUninstallAction always waits so the namespace cleanup that follows sees the
+// release resources gone
+func newUninstallAction(actionConfig *action.Configuration, options *InstallOptions) *action.Uninstall {
+	uninstall := action.NewUninstall(actionConfig)
+	uninstall.Wait = true
+	uninstall.Timeout = options.EffectiveTimeout()
+	return uninstall
+}
+
+// GetCurrentValues returns the user-supplied values of an installed release. An empty
+// map is returned when the release does not exist.
+func (h *HelmInstaller) GetCurrentValues(releaseName, namespace string) (map[string]interface{}, error) {
+	actionConfig, err := h.createHelmActionConfig(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create helm action config: %w", err)
+	}
+
+	return getCurrentValues(actionConfig, releaseName)
+}
+
+func getCurrentValues(actionConfig *action.Configuration, releaseName string) (map[string]interface{}, error) {
+	histClient := action.NewHistory(actionConfig)
+	histClient.Max = 1
+	if _, err := histClient.Run(releaseName); err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			return map[string]interface{}{}, nil
 		}
+		return nil, fmt.Errorf("failed to get release history: %w", err)
+	}
+
+	getValues := action.NewGetValues(actionConfig)
+	getValues.AllValues = false
+	values, err := getValues.Run(releaseName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get values for release %s: %w", releaseName, err)
+	}
+
+	if values == nil {
+		return map[string]interface{}{}, nil
+	}
+	return values, nil
+}
+
+// Rollback rolls a release back to the given revision. A revision of 0 rolls back to
+// the revision preceding the current one.
+func (h *HelmInstaller) Rollback(releaseName, namespace string, revision int) error {
+	actionConfig, err := h.createHelmActionConfig(namespace)
+	if err != nil {
+		return fmt.Errorf("failed to create helm action config: %w", err)
+	}
+
+	history, err := action.NewHistory(actionConfig).Run(releaseName)
+	if err != nil {
+		return fmt.Errorf("failed to get history for release %s: %w", releaseName, err)
+	}
+
+	target, err := resolveRollbackRevision(history, revision)
+	if err != nil {
+		return err
+	}
+
+	rollback := action.NewRollback(actionConfig)
+	rollback.Version = target
+	rollback.Timeout = DefaultTimeout
+	rollback.Wait = true
+
+	logger.Infoln("Rolling back release %s to revision %d", releaseName, target)
+	if err := rollback.Run(releaseName); err != nil {
+		logger.Errorf("Error rolling back chart: %v", err)
+		return fmt.Errorf("failed to rollback release %s: %w", releaseName, err)
 	}
 
 	return nil
 }
 
+func resolveRollbackRevision(history []*release.Release, revision int) (int, error) {
+	if revision < 0 {
+		return 0, fmt.Errorf("revision must not be negative, got %d", revision)
+	}
+
+	if len(history) == 0 {
+		return 0, fmt.Errorf("release has no history")
+	}
+
+	current := 0
+	exists := false
+	for _, rel := range history {
+		if rel.Version > current {
+			current = rel.Version
+		}
+		if rel.Version == revision {
+			exists = true
+		}
+	}
+
+	if revision == 0 {
+		if current <= 1 {
+			return 0, fmt.Errorf("release has no previous revision to roll back to")
+		}
+		return current - 1, nil
+	}
+
+	if !exists {
+		return 0, fmt.Errorf("revision %d not found in release history", revision)
+	}
+
+	return revision, nil
+}
+
 func (h *HelmInstaller) createHelmActionConfig(namespace string) (*action.Configuration, error) {
 	tmpPath := filepath.Join(os.TempDir(), fmt.Sprintf("kubeconfig-%d", time.Now().UnixNano()))
 
@@ -134,15 +270,13 @@ func (h *HelmInstaller) createHelmActionConfig(namespace string) (*action.Config
 		return nil, fmt.Errorf("failed to write kubeconfig to temp file: %w", err)
 	}
 
-	settings.KubeConfig = tmpPath
+	// per-installer settings: installs of a dependency level run in parallel, each with
+	// its own kubeconfig file
+	envSettings := cli.New()
+	envSettings.KubeConfig = tmpPath
 	actionConfig := new(action.Configuration)
 
-	// Create a wrapper function to use our logger with Helm's Init method
-	logFunc := func(format string, v ...interface{}) {
-		logger.Debugf(format, v...)
-	}
-
-	if err := actionConfig.Init(settings.RESTClientGetter(), namespace, os.Getenv("HELM_DRIVER"), logFunc); err != nil {
+	if err := actionConfig.Init(envSettings.RESTClientGetter(), namespace, os.Getenv("HELM_DRIVER"), helmLog); err != nil {
 		return nil, fmt.Errorf("failed to initialize helm action config: %w", err)
 	}
 
@@ -150,19 +284,9 @@ func (h *HelmInstaller) createHelmActionConfig(namespace string) (*action.Config
 }
 
 func (h *HelmInstaller) downloadAndLoadChart(options *InstallOptions) (*chart.Chart, error) {
-	chartPathOptions := action.ChartPathOptions{
-		RepoURL:            options.RepoURL,
-		Version:            options.Version,
-		PassCredentialsAll: true,
-	}
-
-	if err := h.addHelmRepo(options); err != nil {
-		return nil, fmt.Errorf("failed to add helm repository: %w", err)
-	}
-
-	chartPath, err := chartPathOptions.LocateChart(*options.ChartName, settings)
+	chartPath, err := h.locateChart(options)
 	if err != nil {
-		return nil, fmt.Errorf("failed to locate chart %s: %w", *options.ChartName, err)
+		return nil, err
 	}
 
 	logger.Infof("Chart found at: %s", chartPath)
@@ -186,12 +310,10 @@ func (h *HelmInstaller) downloadAndLoadChart(options *InstallOptions) (*chart.Ch
 }
 
 func (h *HelmInstaller) addHelmRepo(options *InstallOptions) error {
-	entry := repo.Entry{
-		Name: options.RepoName,
-		URL:  options.RepoURL,
-	}
+	entry := newRepoEntry(options)
+	logger.Debugln("Adding helm repository %s", describeRepo(entry))
 
-	r, err := repo.NewChartRepository(&entry, getter.All(settings))
+	r, err := repo.NewChartRepository(entry, getter.All(settings))
 	if err != nil {
 		return fmt.Errorf("failed to create chart repository: %w", err)
 	}
@@ -208,3 +330,111 @@ func (h *HelmInstaller) addHelmRepo(options *InstallOptions) error {
 
 	return nil
 }
+
+// locateChart downloads the chart of options and returns its path. Charts in OCI
+// registries are pulled directly, other charts through their repository's index.
+func (h *HelmInstaller) locateChart(options *InstallOptions) (string, error) {
+	if ref, ok := ociChartRef(options); ok {
+		chartPath, err := pullOCIChart(ref, options)
+		if err != nil {
+			return "", fmt.Errorf("failed to pull chart %s: %w", ref, err)
+		}
+		return chartPath, nil
+	}
+
+	if err := h.addHelmRepo(options); err != nil {
+		return "", fmt.Errorf("failed to add helm repository: %w", err)
+	}
+
+	chartPathOptions := newChartPathOptions(options)
+	chartPath, err := chartPathOptions.LocateChart(*options.ChartName, settings)
+	if err != nil {
+		return "", fmt.Errorf("failed to locate chart %s: %w", *options.ChartName, err)
+	}
+	return chartPath, nil
+}
+
+// IsOCI reports whether ref points into an OCI registry (oci://...)
+func IsOCI(ref string) bool {
+	return registry.IsOCI(ref)
+}
+
+// ociChartRef returns the OCI reference of the chart of options. The chart name can be a
+// full reference, or be appended to an OCI repository URL.
+func ociChartRef(options *InstallOptions) (string, bool) {
+	if options.ChartName != nil && IsOCI(*options.ChartName) {
+		return *options.ChartName, true
+	}
+	if !IsOCI(options.RepoURL) {
+		return "", false
+	}
+	if options.ChartName == nil || *options.ChartName == "" {
+		return options.RepoURL, true
+	}
+	return strings.TrimSuffix(options.RepoURL, "/") + "/" + *options.ChartName, true
+}
+
+// pullOCIChart downloads an OCI chart into the repository cache, authenticating with the
+// repository credentials of options or the registry logins of the helm CLI
+func pullOCIChart(ref string, options *InstallOptions) (string, error) {
+	clientOptions := []registry.ClientOption{
+		registry.ClientOptCredentialsFile(settings.RegistryConfig),
+		registry.ClientOptWriter(io.Discard),
+	}
+	if options.RepoUsername != "" || options.RepoPassword != "" {
+		clientOptions = append(clientOptions, registry.ClientOptBasicAuth(options.RepoUsername, options.RepoPassword))
+	}
+	client, err := registry.NewClient(clientOptions...)
+	if err != nil {
+		return "", fmt.Errorf("failed to create registry client: %w", err)
+	}
+
+	if err := os.MkdirAll(settings.RepositoryCache, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create repository cache: %w", err)
+	}
+
+	dl := downloader.ChartDownloader{
+		Out:     io.Discard,
+		Verify:  downloader.VerifyNever,
+		Getters: getter.All(settings),
+		Options: []getter.Option{
+			getter.WithBasicAuth(options.RepoUsername, options.RepoPassword),
+			getter.WithRegistryClient(client),
+		},
+		RegistryClient:   client,
+		RepositoryConfig: settings.RepositoryConfig,
+		RepositoryCache:  settings.RepositoryCache,
+	}
+	logger.Debugln("Pulling chart %s version %s", ref, options.Version)
+	chartPath, _, err := dl.DownloadTo(ref, options.Version, settings.RepositoryCache)
+	if err != nil {
+		return "", err
+	}
+	return chartPath, nil
+}
+
+func newChartPathOptions(options *InstallOptions) action.ChartPathOptions {
+	return action.ChartPathOptions{
+		RepoURL:  options.RepoURL,
+		Version:  options.Version,
+		Username: options.RepoUsername,
+		Password: options.RepoPassword,
+	}
+}
+
+func newRepoEntry(options *InstallOptions) *repo.Entry {
+	return &repo.Entry{
+		Name:     options.RepoName,
+		URL:      options.RepoURL,
+		Username: options.RepoUsername,
+		Password: options.RepoPassword,
+	}
+}
+
+// describeRepo describes a repository for log output, leaving out its password
+func describeRepo(entry *repo.Entry) string {
+	if entry.Username == "" {
+		return fmt.Sprintf("%s (%s)", entry.Name, entry.URL)
+	}
+	return fmt.Sprintf("%s (%s) as user %s", entry.Name, entry.URL, entry.Username)
+}
@@ -0,0 +1,323 @@
+package installer
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/repo"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+func newFakeActionConfig(t *testing.T) *action.Configuration {
+	t.Helper()
+	return &action.Configuration{
+		Releases:     storage.Init(driver.NewMemory()),
+		KubeClient:   &kubefake.PrintingKubeClient{Out: io.Discard},
+		Capabilities: chartutil.DefaultCapabilities,
+		Log:          func(format string, v ...interface{}) {},
+	}
+}
+
+func TestGetCurrentValues_ReleaseNotFound(t *testing.T) {
+	cfg := newFakeActionConfig(t)
+
+	values, err := getCurrentValues(cfg, "missing-release")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if values == nil {
+		t.Fatal("expected empty map, got nil")
+	}
+
+	if len(values) != 0 {
+		t.Errorf("expected no values, got %v", values)
+	}
+}
+
+func TestGetCurrentValues_ReturnsUserSuppliedValues(t *testing.T) {
+	cfg := newFakeActionConfig(t)
+
+	rel := &release.Release{
+		Name:      "argocd",
+		Namespace: "argocd",
+		Version:   1,
+		Info:      &release.Info{Status: release.StatusDeployed},
+		Chart: &chart.Chart{
+			Metadata: &chart.Metadata{Name: "argo-cd", Version: "8.0.0"},
+			Values:   map[string]interface{}{"chartDefault": true},
+		},
+		Config: map[string]interface{}{"server": map[string]interface{}{"insecure": true}},
+	}
+	if err := cfg.Releases.Create(rel); err != nil {
+		t.Fatalf("failed to seed release: %v", err)
+	}
+
+	values, err := getCurrentValues(cfg, "argocd")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := values["chartDefault"]; ok {
+		t.Errorf("expected only user-supplied values, got computed value chartDefault")
+	}
+
+	server, ok := values["server"].(map[string]interface{})
+	if !ok || server["insecure"] != true {
+		t.Errorf("expected server.insecure=true, got %v", values)
+	}
+}
+
+func TestResolveRollbackRevision(t *testing.T) {
+	history := []*release.Release{
+		{Name: "argocd", Version: 1},
+		{Name: "argocd", Version: 2},
+		{Name: "argocd", Version: 3},
+	}
+
+	tests := []struct {
+		name        string
+		history     []*release.Release
+		revision    int
+		expected    int
+		expectError bool
+	}{
+		{"zero defaults to previous", history, 0, 2, false},
+		{"explicit revision", history, 1, 1, false},
+		{"unknown revision", history, 7, 0, true},
+		{"negative revision", history, -1, 0, true},
+		{"single revision has no previous", []*release.Release{{Name: "argocd", Version: 1}}, 0, 0, true},
+		{"empty history", nil, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveRollbackRevision(tt.history, tt.revision)
+
+			if tt.expectError && err == nil {
+				t.Errorf("expected error but got none")
+			}
+
+			if !tt.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if got != tt.expected {
+				t.Errorf("expected revision %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestHelmActionsUseInstallOptions(t *testing.T) {
+	actionConfig := newFakeActionConfig(t)
+
+	tests := []struct {
+		name            string
+		options         *InstallOptions
+		expectedTimeout time.Duration
+		expectedWait    bool
+	}{
+		{
+			name:            "defaults",
+			options:         &InstallOptions{ApplicationName: "app", Namespace: "ns"},
+			expectedTimeout: DefaultTimeout,
+			expectedWait:    false,
+		},
+		{
+			name:            "custom timeout and wait",
+			options:         &InstallOptions{ApplicationName: "app", Namespace: "ns", Timeout: 10 * time.Minute, Wait: true},
+			expectedTimeout: 10 * time.Minute,
+			expectedWait:    true,
+		},
+		{
+			name:            "create namespace",
+			options:         &InstallOptions{ApplicationName: "app", Namespace: "ns", CreateNamespace: true},
+			expectedTimeout: DefaultTimeout,
+			expectedWait:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			install := newInstallAction(actionConfig, tt.options)
+			if install.Timeout != tt.expectedTimeout || install.Wait != tt.expectedWait {
+				t.Errorf("install: expected timeout %v wait %v, got timeout %v wait %v",
+					tt.expectedTimeout, tt.expectedWait, install.Timeout, install.Wait)
+			}
+			if install.CreateNamespace != tt.options.CreateNamespace {
+				t.Errorf("install: expected CreateNamespace %v, got %v", tt.options.CreateNamespace, install.CreateNamespace)
+			}
+			if install.ReleaseName != "app" || install.Namespace != "ns" {
+				t.Errorf("install: expected release app in ns, got %s in %s", install.ReleaseName, install.Namespace)
+			}
+
+			upgrade := newUpgradeAction(actionConfig, tt.options)
+			if upgrade.Timeout != tt.expectedTimeout || upgrade.Wait != tt.expectedWait {
+				t.Errorf("upgrade: expected timeout %v wait %v, got timeout %v wait %v",
+					tt.expectedTimeout, tt.expectedWait, upgrade.Timeout, upgrade.Wait)
+			}
+
+			uninstall := newUninstallAction(actionConfig, tt.options)
+			if uninstall.Timeout != tt.expectedTimeout || !uninstall.Wait {
+				t.Errorf("uninstall: expected timeout %v and wait, got timeout %v wait %v",
+					tt.expectedTimeout, uninstall.Timeout, uninstall.Wait)
+			}
+		})
+	}
+}
+
+func TestRepoCredentials(t *testing.T) {
+	options := &InstallOptions{
+		RepoName:     "internal",
+		RepoURL:      "https://charts.example.com",
+		RepoUsername: "deployer",
+		RepoPassword: "s3cret",
+	}
+
+	entry := newRepoEntry(options)
+	if entry.Username != "deployer" || entry.Password != "s3cret" {
+		t.Errorf("expected the repository entry to carry the credentials, got %q/%q", entry.Username, entry.Password)
+	}
+
+	pathOptions := newChartPathOptions(options)
+	if pathOptions.Username != "deployer" || pathOptions.Password != "s3cret" {
+		t.Errorf("expected the chart path options to carry the credentials, got %q/%q",
+			pathOptions.Username, pathOptions.Password)
+	}
+	if pathOptions.PassCredentialsAll {
+		t.Error("expected the credentials not to be passed to the hosts the chart download redirects to")
+	}
+}
+
+func TestDescribeRepo(t *testing.T) {
+	tests := []struct {
+		name     string
+		entry    *repo.Entry
+		expected string
+	}{
+		{
+			name:     "public repository",
+			entry:    &repo.Entry{Name: "argo", URL: "https://argoproj.github.io/argo-helm"},
+			expected: "argo (https://argoproj.github.io/argo-helm)",
+		},
+		{
+			name:     "private repository",
+			entry:    &repo.Entry{Name: "internal", URL: "https://charts.example.com", Username: "deployer", Password: "s3cret"},
+			expected: "internal (https://charts.example.com) as user deployer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			description := describeRepo(tt.entry)
+			if description != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, description)
+			}
+			if tt.entry.Password != "" && strings.Contains(description, tt.entry.Password) {
+				t.Errorf("expected the password to be redacted, got %q", description)
+			}
+		})
+	}
+}
+
+func TestOCIChartRef(t *testing.T) {
+	chart := func(name string) *string { return &name }
+
+	tests := []struct {
+		name        string
+		options     *InstallOptions
+		expectedRef string
+		expectedOCI bool
+	}{
+		{
+			name:    "repository chart",
+			options: &InstallOptions{RepoURL: "https://argoproj.github.io/argo-helm", ChartName: chart("argo-cd")},
+		},
+		{
+			name:        "OCI repository URL",
+			options:     &InstallOptions{RepoURL: "oci://ghcr.io/example/charts/", ChartName: chart("app")},
+			expectedRef: "oci://ghcr.io/example/charts/app",
+			expectedOCI: true,
+		},
+		{
+			name:        "OCI chart reference",
+			options:     &InstallOptions{RepoURL: "https://charts.example.com", ChartName: chart("oci://ghcr.io/example/app")},
+			expectedRef: "oci://ghcr.io/example/app",
+			expectedOCI: true,
+		},
+		{
+			name:        "OCI repository URL without chart name",
+			options:     &InstallOptions{RepoURL: "oci://ghcr.io/example/app"},
+			expectedRef: "oci://ghcr.io/example/app",
+			expectedOCI: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, oci := ociChartRef(tt.options)
+			if ref != tt.expectedRef || oci != tt.expectedOCI {
+				t.Errorf("expected %q (OCI %v), got %q (OCI %v)", tt.expectedRef, tt.expectedOCI, ref, oci)
+			}
+		})
+	}
+}
+
+func TestLocateChartSkipsRepositoryIndexForOCI(t *testing.T) {
+	defer func(cache, config string) {
+		settings.RepositoryCache, settings.RegistryConfig = cache, config
+	}(settings.RepositoryCache, settings.RegistryConfig)
+	settings.RepositoryCache = t.TempDir()
+	settings.RegistryConfig = filepath.Join(t.TempDir(), "config.json")
+
+	chartName := "app"
+	h := &HelmInstaller{}
+
+	// nothing listens on port 1, so both lookups fail at their first request
+	_, err := h.locateChart(&InstallOptions{RepoName: "local", RepoURL: "http://127.0.0.1:1", ChartName: &chartName})
+	if err == nil || !strings.Contains(err.Error(), "failed to add helm repository") {
+		t.Errorf("expected the repository index to be downloaded, got %v", err)
+	}
+
+	_, err = h.locateChart(&InstallOptions{RepoName: "local", RepoURL: "oci://127.0.0.1:1/charts", ChartName: &chartName})
+	if err == nil || !strings.Contains(err.Error(), "failed to pull chart oci://127.0.0.1:1/charts/app") {
+		t.Errorf("expected the chart to be pulled from the registry, got %v", err)
+	}
+}
+
+func TestCreateHelmActionConfigPerInstaller(t *testing.T) {
+	kubeConfig := func(server string) string {
+		return "apiVersion: v1\nkind: Config\nclusters:\n- cluster:\n    server: " + server +
+			"\n  name: c\ncontexts:\n- context:\n    cluster: c\n    user: u\n  name: ctx\n" +
+			"current-context: ctx\nusers:\n- name: u\n  user:\n    token: t\n"
+	}
+	first := &HelmInstaller{KubeConfig: kubeConfig("https://first.example.com:6443")}
+	second := &HelmInstaller{KubeConfig: kubeConfig("https://second.example.com:6443")}
+
+	firstConfig, err := first.createHelmActionConfig("default")
+	if err != nil {
+		t.Fatalf("createHelmActionConfig: %v", err)
+	}
+	if _, err := second.createHelmActionConfig("default"); err != nil {
+		t.Fatalf("createHelmActionConfig: %v", err)
+	}
+
+	// creating the second config must not repoint the first one at the second kubeconfig
+	restConfig, err := firstConfig.RESTClientGetter.ToRESTConfig()
+	if err != nil {
+		t.Fatalf("ToRESTConfig: %v", err)
+	}
+	if restConfig.Host != "https://first.example.com:6443" {
+		t.Errorf("expected the first installer's server, got %s", restConfig.Host)
+	}
+}
@@ -1,5 +1,10 @@
 package installer
 
+import "time"
+
+// DefaultTimeout is used for Helm operations when InstallOptions.Timeout is unset
+const DefaultTimeout = 5 * time.Minute
+
 type Installer interface {
 	Install(options *InstallOptions) error
 	UnInstall(options *InstallOptions) error
@@ -15,5 +20,35 @@ type InstallOptions struct {
 	Values           map[string]interface{}
 	KubeConfig       string
 	RepoName         string
+	RepoUsername     string // basic auth for private chart repositories
+	RepoPassword     string // never logged, see describeRepo
 	CRDsGroupVersion string
+	Timeout          time.Duration
+	Wait             bool
+	Project          string // ArgoCD project for the application, "default" when empty
+	CreateNamespace  bool   // create Namespace when it does not exist
+	// Recurse makes ArgoCD read the manifests of Path and all its subdirectories, e.g. for
+	// app-of-apps layouts. Like Directory it only applies to ArgoCD directory sources.
+	Recurse   bool
+	Directory *ArgoSourceDirectory
+	// ForceNamespaceDeletion removes the finalizers of the namespace when it is stuck
+	// terminating after an uninstall
+	ForceNamespaceDeletion bool
+	// KeepCRDs leaves the CRDs of CRDsGroupVersion in place on uninstall
+	KeepCRDs bool
+	// ConfirmCRDDeletion is asked before deleting CRDs that still have custom resources.
+	// When nil such CRDs are kept.
+	ConfirmCRDDeletion ConfirmCRDDeletion
+}
+
+// ConfirmCRDDeletion reports whether CRDs still in use may be deleted, given the number
+// of custom resources per CRD name
+type ConfirmCRDDeletion func(inUse map[string]int) bool
+
+// EffectiveTimeout returns Timeout, or DefaultTimeout when it is unset
+func (o *InstallOptions) EffectiveTimeout() time.Duration {
+	if o.Timeout <= 0 {
+		return DefaultTimeout
+	}
+	return o.Timeout
 }
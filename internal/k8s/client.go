@@ -2,23 +2,67 @@ package k8s
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/mrgb7/playground/pkg/logger"
+	"github.com/mrgb7/playground/pkg/retry"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
+// errNotReady is returned from polling conditions that should be retried
+var errNotReady = stderrors.New("not ready")
+
+const (
+	// EnsureAppTimeout bounds how long EnsureApp waits for an app's workloads when the
+	// caller's context has no deadline
+	EnsureAppTimeout = 5 * time.Minute
+	// EnsureAppProgressInterval is how often EnsureApp logs the workloads it waits for
+	EnsureAppProgressInterval = 30 * time.Second
+)
+
+// ensureAppPollInterval is how often EnsureApp checks the app's workloads
+var ensureAppPollInterval = 5 * time.Second
+
+var (
+	// namespaceDeletionTimeout bounds how long DeleteNamespace waits for a namespace to go
+	namespaceDeletionTimeout = 5 * time.Minute
+	// namespaceFinalizeTimeout bounds the wait after a stuck namespace's finalizers are removed
+	namespaceFinalizeTimeout = time.Minute
+	namespacePollInterval    = 5 * time.Second
+)
+
+var (
+	// drainTimeout bounds how long DrainNode retries blocked evictions and waits for
+	// evicted pods to terminate
+	drainTimeout = 2 * time.Minute
+	// drainPollInterval is how often DrainNode retries evictions and checks the node's pods
+	drainPollInterval = 5 * time.Second
+)
+
+// nodeReadyPollInterval is how often WaitForNodeReady and WaitForNodesReady check nodes
+var nodeReadyPollInterval = 5 * time.Second
+
 type K8sClient struct {
-	Clientset              *kubernetes.Clientset
-	Dynamic                *dynamic.DynamicClient
+	Clientset              kubernetes.Interface
+	Dynamic                dynamic.Interface
 	apiextensionsclientset *apiextensionsclientset.Clientset
 	Config                 *rest.Config
 }
@@ -60,7 +104,10 @@ func (k *K8sClient) GetNameSpace(name string, ctx context.Context) (string, erro
 	return namespace.Name, nil
 }
 
-func (k *K8sClient) DeleteNamespace(namespace string) error {
+// DeleteNamespace deletes a namespace and waits until it is gone. With force, a namespace
+// still terminating when the wait times out, usually because the controller of one of its
+// finalizers is already gone, has its finalizers removed.
+func (k *K8sClient) DeleteNamespace(namespace string, force bool) error {
 	if namespace == "" {
 		return nil
 	}
@@ -75,18 +122,45 @@ func (k *K8sClient) DeleteNamespace(namespace string) error {
 		return fmt.Errorf("error checking namespace: %w", err)
 	}
 
-	if ns.Status.Phase == corev1.NamespaceTerminating {
-		return k.waitForNamespaceDeletion(namespace)
+	if ns.Status.Phase != corev1.NamespaceTerminating {
+		err = k.Clientset.CoreV1().
+			Namespaces().
+			Delete(context.Background(), namespace, v1.DeleteOptions{})
+		if err != nil {
+			return fmt.Errorf("error deleting namespace: %w", err)
+		}
 	}
 
-	err = k.Clientset.CoreV1().
-		Namespaces().
-		Delete(context.Background(), namespace, v1.DeleteOptions{})
+	err = k.waitForNamespaceDeletion(namespace, namespaceDeletionTimeout)
+	if !force || !stderrors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	logger.Warnln("Namespace %s is stuck terminating, removing its finalizers", namespace)
+	if err := k.finalizeNamespace(namespace); err != nil {
+		return err
+	}
+	return k.waitForNamespaceDeletion(namespace, namespaceFinalizeTimeout)
+}
+
+// finalizeNamespace clears the finalizers of a namespace through the finalize subresource
+func (k *K8sClient) finalizeNamespace(namespace string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ns, err := k.Clientset.CoreV1().Namespaces().Get(ctx, namespace, v1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("error deleting namespace: %w", err)
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("error checking namespace: %w", err)
 	}
 
-	return k.waitForNamespaceDeletion(namespace)
+	ns.Spec.Finalizers = nil
+	if _, err := k.Clientset.CoreV1().Namespaces().Finalize(ctx, ns, v1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to remove finalizers of namespace %s: %w", namespace, err)
+	}
+	return nil
 }
 
 func (k *K8sClient) GetCRDsByGroup(group string) ([]string, error) {
@@ -133,72 +207,626 @@ func (k *K8sClient) DeleteCRDsGroup(group string) error {
 	return nil
 }
 
-func (k *K8sClient) EnsureApp(namespace, appName string) <-chan error {
+var crdGVR = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1",
+	Resource: "customresourcedefinitions",
+}
+
+// CountCustomResources returns the number of custom resources of every CRD in an API
+// group, keyed by CRD name, so callers can tell which CRDs are still in use
+func (k *K8sClient) CountCustomResources(group string) (map[string]int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	crds, err := k.Dynamic.Resource(crdGVR).List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list CRDs for group %s: %w", group, err)
+	}
+
+	counts := make(map[string]int)
+	for _, crd := range crds.Items {
+		if g, _, _ := unstructured.NestedString(crd.Object, "spec", "group"); g != group {
+			continue
+		}
+		gvr, err := customResourceGVR(crd)
+		if err != nil {
+			return nil, err
+		}
+		resources, err := k.Dynamic.Resource(gvr).List(ctx, v1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", crd.GetName(), err)
+		}
+		counts[crd.GetName()] = len(resources.Items)
+	}
+	return counts, nil
+}
+
+// customResourceGVR returns the resource of a CRD's custom resources at its storage
+// version, or its first served one
+func customResourceGVR(crd unstructured.Unstructured) (schema.GroupVersionResource, error) {
+	group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+	plural, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "plural")
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+
+	version := ""
+	for _, v := range versions {
+		entry, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(entry, "name")
+		served, _, _ := unstructured.NestedBool(entry, "served")
+		storage, _, _ := unstructured.NestedBool(entry, "storage")
+		if storage {
+			version = name
+			break
+		}
+		if served && version == "" {
+			version = name
+		}
+	}
+	if plural == "" || version == "" {
+		return schema.GroupVersionResource{}, fmt.Errorf("CRD %s has no served version", crd.GetName())
+	}
+	return schema.GroupVersionResource{Group: group, Version: version, Resource: plural}, nil
+}
+
+// EnsureApp waits in the background until every deployment, statefulset and daemonset
+// of appName is ready, logging the workloads it is still waiting for every
+// EnsureAppProgressInterval. Without a deadline on ctx the wait is bounded by
+// EnsureAppTimeout. The returned channel receives exactly one value and is closed
+// afterwards; the wait, and its goroutine, stop as soon as ctx is cancelled.
+func (k *K8sClient) EnsureApp(ctx context.Context, namespace, appName string) <-chan error {
 	logger.Infof("Ensuring app %s in namespace %s", appName, namespace)
 	doneCh := make(chan error, 1)
 	go func() {
-		ticker := time.NewTicker(5 * time.Second)
-		defer ticker.Stop()
-		timer := time.NewTimer(5 * time.Minute)
-		for {
-			select {
-			case <-ticker.C:
-				deploys, err := k.Clientset.AppsV1().
-					Deployments(namespace).
-					List(context.Background(),
-						v1.ListOptions{LabelSelector: fmt.Sprintf("app.kubernetes.io/instance=%s", appName)})
-				if err != nil {
-					continue
-				}
-				if len(deploys.Items) == 0 {
-					continue
-				}
-				allReady := true
-				for _, deploy := range deploys.Items {
-					if deploy.Status.ReadyReplicas < deploy.Status.Replicas || deploy.Status.Replicas <= 0 {
-						logger.Debugf("Deployment %s in namespace %s is not ready yet", deploy.Name, namespace)
-						allReady = false
-						break
-					}
-				}
-				if !allReady {
-					logger.Debugf("App %s in namespace %s is not ready yet", appName, namespace)
-					continue
-				}
-				doneCh <- nil
-
-			case <-timer.C:
-				doneCh <- fmt.Errorf("timeout waiting for app %s in namespace %s to be ready", appName, namespace)
-				return
+		defer close(doneCh)
+		waitCtx := ctx
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			waitCtx, cancel = context.WithTimeout(ctx, EnsureAppTimeout)
+			defer cancel()
+		}
+
+		var notReady error
+		lastReport := time.Time{}
+		err := retry.Do(waitCtx, retry.Options{Backoff: ensureAppPollInterval}, func() error {
+			notReady = appWorkloadsReady(waitCtx, k.Clientset, namespace, appName)
+			if notReady != nil && time.Since(lastReport) >= EnsureAppProgressInterval {
+				logger.Infof("Waiting for app %s in namespace %s: %v", appName, namespace, notReady)
+				lastReport = time.Now()
 			}
+			return notReady
+		})
+
+		switch {
+		case err == nil:
+		case stderrors.Is(ctx.Err(), context.Canceled):
+			err = fmt.Errorf("stopped waiting for app %s in namespace %s (%v): %w", appName, namespace, notReady, ctx.Err())
+		default:
+			err = fmt.Errorf("timeout waiting for app %s in namespace %s to be ready: %w", appName, namespace, notReady)
+		}
+
+		// the buffer guarantees this never blocks, even if nobody reads the result
+		select {
+		case doneCh <- err:
+		default:
 		}
 	}()
 
 	return doneCh
 }
 
-func (c *K8sClient) waitForNamespaceDeletion(namespace string) error {
-	timeout := 5 * time.Minute
+// workloadsNotReadyError lists the workloads an app is waiting for. It matches errNotReady
+// so the readiness poll keeps retrying.
+type workloadsNotReadyError struct {
+	workloads []string
+}
+
+func (e *workloadsNotReadyError) Error() string {
+	if len(e.workloads) == 0 {
+		return "no workloads found"
+	}
+	return "not ready: " + strings.Join(e.workloads, ", ")
+}
+
+func (e *workloadsNotReadyError) Is(target error) bool {
+	return target == errNotReady
+}
+
+// appWorkloadsReady returns a workloadsNotReadyError naming every deployment, statefulset
+// and daemonset of appName that is not ready yet, with its ready and desired counts, or
+// when appName has no workloads at all
+func appWorkloadsReady(ctx context.Context, cs kubernetes.Interface, namespace, appName string) error {
+	opts := v1.ListOptions{LabelSelector: fmt.Sprintf("app.kubernetes.io/instance=%s", appName)}
+	apps := cs.AppsV1()
+
+	deploys, err := apps.Deployments(namespace).List(ctx, opts)
+	if err != nil {
+		return err
+	}
+	statefulSets, err := apps.StatefulSets(namespace).List(ctx, opts)
+	if err != nil {
+		return err
+	}
+	daemonSets, err := apps.DaemonSets(namespace).List(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	if len(deploys.Items)+len(statefulSets.Items)+len(daemonSets.Items) == 0 {
+		return &workloadsNotReadyError{}
+	}
+
+	var notReady []string
+	for _, deploy := range deploys.Items {
+		if deploy.Status.ReadyReplicas < deploy.Status.Replicas || deploy.Status.Replicas <= 0 {
+			notReady = append(notReady, fmt.Sprintf("deployment/%s %d/%d",
+				deploy.Name, deploy.Status.ReadyReplicas, deploy.Status.Replicas))
+		}
+	}
+	for _, sts := range statefulSets.Items {
+		desired := int32(1)
+		if sts.Spec.Replicas != nil {
+			desired = *sts.Spec.Replicas
+		}
+		if sts.Status.ReadyReplicas < desired {
+			notReady = append(notReady, fmt.Sprintf("statefulset/%s %d/%d", sts.Name, sts.Status.ReadyReplicas, desired))
+		}
+	}
+	for _, ds := range daemonSets.Items {
+		// a daemonset the controller has not seen yet reports nothing scheduled
+		if ds.Status.ObservedGeneration < ds.Generation || ds.Status.NumberReady < ds.Status.DesiredNumberScheduled {
+			notReady = append(notReady, fmt.Sprintf("daemonset/%s %d/%d",
+				ds.Name, ds.Status.NumberReady, ds.Status.DesiredNumberScheduled))
+		}
+	}
+
+	if len(notReady) > 0 {
+		return &workloadsNotReadyError{workloads: notReady}
+	}
+	return nil
+}
+
+// DeploymentReadiness returns the ready and desired replicas of every deployment in a
+// namespace, formatted like "deployment/server 1/2"
+func (k *K8sClient) DeploymentReadiness(ctx context.Context, namespace string) ([]string, error) {
+	deploys, err := k.Clientset.AppsV1().Deployments(namespace).List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments in namespace %s: %w", namespace, err)
+	}
+
+	readiness := make([]string, 0, len(deploys.Items))
+	for _, deploy := range deploys.Items {
+		desired := int32(1)
+		if deploy.Spec.Replicas != nil {
+			desired = *deploy.Spec.Replicas
+		}
+		readiness = append(readiness, fmt.Sprintf("deployment/%s %d/%d", deploy.Name, deploy.Status.ReadyReplicas, desired))
+	}
+	sort.Strings(readiness)
+	return readiness, nil
+}
+
+// GetNamespaceEvents returns the events in a namespace from oldest to newest, formatted
+// like "12:00:05 Warning BackOff pod/server-5d9: Back-off restarting failed container (x4)"
+func (k *K8sClient) GetNamespaceEvents(ctx context.Context, namespace string) ([]string, error) {
+	events, err := k.Clientset.CoreV1().Events(namespace).List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events in namespace %s: %w", namespace, err)
+	}
+
+	items := events.Items
+	sort.SliceStable(items, func(i, j int) bool {
+		return eventTime(items[i]).Before(eventTime(items[j]))
+	})
+	formatted := make([]string, 0, len(items))
+	for _, event := range items {
+		formatted = append(formatted, formatEvent(event))
+	}
+	return formatted, nil
+}
+
+// eventTime is when an event was last seen
+func eventTime(event corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
+
+func formatEvent(event corev1.Event) string {
+	formatted := fmt.Sprintf("%s %s %s %s/%s: %s", eventTime(event).Format(time.TimeOnly), event.Type,
+		event.Reason, strings.ToLower(event.InvolvedObject.Kind), event.InvolvedObject.Name,
+		strings.TrimSpace(event.Message))
+	if event.Count > 1 {
+		formatted += fmt.Sprintf(" (x%d)", event.Count)
+	}
+	return formatted
+}
+
+// PodLog is the tail of the log of one container of a pod
+type PodLog struct {
+	Pod       string
+	Container string
+	Log       string
+	// Err is set when the log couldn't be fetched, e.g. because the container hasn't started
+	Err error
+}
+
+// GetPodLogs returns the last tailLines lines logged by every container, init containers
+// first, of the pods in a namespace matching the label selector podSelector. An empty
+// selector matches every pod and tailLines <= 0 returns the whole logs.
+func (k *K8sClient) GetPodLogs(ctx context.Context, namespace, podSelector string, tailLines int64) ([]PodLog, error) {
+	pods, err := k.Clientset.CoreV1().Pods(namespace).List(ctx, v1.ListOptions{LabelSelector: podSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods in namespace %s: %w", namespace, err)
+	}
+
+	items := pods.Items
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	logs := make([]PodLog, 0, len(items))
+	for _, pod := range items {
+		for _, container := range slices.Concat(pod.Spec.InitContainers, pod.Spec.Containers) {
+			opts := &corev1.PodLogOptions{Container: container.Name}
+			if tailLines > 0 {
+				opts.TailLines = &tailLines
+			}
+			podLog := PodLog{Pod: pod.Name, Container: container.Name}
+			raw, err := k.Clientset.CoreV1().Pods(namespace).GetLogs(pod.Name, opts).DoRaw(ctx)
+			if err != nil {
+				podLog.Err = err
+			}
+			podLog.Log = string(raw)
+			logs = append(logs, podLog)
+		}
+	}
+	return logs, nil
+}
+
+// ApplyUnstructured creates obj, or updates it when it already exists. The update keeps
+// the server-managed metadata of the existing object as well as its labels and annotations,
+// those set on obj taking precedence. An empty namespace is used for cluster-scoped resources.
+func (k *K8sClient) ApplyUnstructured(ctx context.Context, gvr schema.GroupVersionResource, namespace string,
+	obj *unstructured.Unstructured) error {
+	var resource dynamic.ResourceInterface = k.Dynamic.Resource(gvr)
+	if namespace != "" {
+		resource = k.Dynamic.Resource(gvr).Namespace(namespace)
+	}
+
+	kind, name := obj.GetKind(), obj.GetName()
+	_, err := resource.Create(ctx, obj, v1.CreateOptions{})
+	switch {
+	case errors.IsAlreadyExists(err):
+		existing, err := resource.Get(ctx, name, v1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get existing %s %s: %w", kind, name, err)
+		}
+
+		obj.SetResourceVersion(existing.GetResourceVersion())
+		obj.SetUID(existing.GetUID())
+		obj.SetCreationTimestamp(existing.GetCreationTimestamp())
+		obj.SetGeneration(existing.GetGeneration())
+		obj.SetLabels(mergeStringMaps(existing.GetLabels(), obj.GetLabels()))
+		obj.SetAnnotations(mergeStringMaps(existing.GetAnnotations(), obj.GetAnnotations()))
+
+		if _, err := resource.Update(ctx, obj, v1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update existing %s %s: %w", kind, name, err)
+		}
+		logger.Infoln("Updated existing %s %s", kind, name)
+	case err != nil:
+		return fmt.Errorf("failed to create %s %s: %w", kind, name, err)
+	default:
+		logger.Successln("Created %s %s", kind, name)
+	}
+	return nil
+}
+
+// mergeStringMaps returns the entries of base overridden by those of overrides, nil when
+// both are empty
+func mergeStringMaps(base, overrides map[string]string) map[string]string {
+	if len(base) == 0 && len(overrides) == 0 {
+		return nil
+	}
+	merged := make(map[string]string, len(base)+len(overrides))
+	maps.Copy(merged, base)
+	maps.Copy(merged, overrides)
+	return merged
+}
+
+// AllocatableResources sums the allocatable CPU and memory of the schedulable nodes
+func (k *K8sClient) AllocatableResources(ctx context.Context) (corev1.ResourceList, error) {
+	nodes, err := k.Clientset.CoreV1().Nodes().List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	cpu, memory := resource.Quantity{}, resource.Quantity{}
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		cpu.Add(node.Status.Allocatable[corev1.ResourceCPU])
+		memory.Add(node.Status.Allocatable[corev1.ResourceMemory])
+	}
+	return corev1.ResourceList{corev1.ResourceCPU: cpu, corev1.ResourceMemory: memory}, nil
+}
+
+// WaitForNodeReady blocks until the named node has registered and reports Ready
+func (k *K8sClient) WaitForNodeReady(nodeName string, timeout time.Duration) error {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
+	err := retry.Do(ctx, retry.Options{Backoff: nodeReadyPollInterval}, func() error {
+		node, err := k.Clientset.CoreV1().Nodes().Get(ctx, nodeName, v1.GetOptions{})
+		if err != nil {
+			logger.Debugf("error getting node %s: %v", nodeName, err)
+			return err
+		}
+		if isNodeReady(node) {
+			return nil
+		}
+		return errNotReady
+	})
+	if err != nil {
+		return fmt.Errorf("timeout waiting for node %s to be ready after %v", nodeName, timeout)
+	}
+	return nil
+}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return fmt.Errorf("timeout waiting for namespace deletion after %v", timeout)
-		case <-ticker.C:
-			_, err := c.Clientset.CoreV1().
-				Namespaces().
-				Get(context.Background(), namespace, v1.GetOptions{})
-			if err != nil {
-				if errors.IsNotFound(err) {
-					return nil
-				}
-				return fmt.Errorf("error checking namespace status: %w", err)
+// WaitForNodesReady waits until every named node has registered and reports Ready. It
+// returns the nodes still missing or not Ready after timeout or once ctx is done, with the reason.
+func (k *K8sClient) WaitForNodesReady(ctx context.Context, nodeNames []string,
+	timeout time.Duration) map[string]error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	pending := make(map[string]error, len(nodeNames))
+	for _, nodeName := range nodeNames {
+		pending[nodeName] = fmt.Errorf("node %s did not register", nodeName)
+	}
+
+	err := retry.Do(ctx, retry.Options{Backoff: nodeReadyPollInterval}, func() error {
+		nodes, err := k.Clientset.CoreV1().Nodes().List(ctx, v1.ListOptions{})
+		if err != nil {
+			logger.Debugf("error listing nodes: %v", err)
+			return err
+		}
+		for i := range nodes.Items {
+			node := &nodes.Items[i]
+			if _, ok := pending[node.Name]; !ok {
+				continue
+			}
+			if isNodeReady(node) {
+				delete(pending, node.Name)
+				continue
+			}
+			pending[node.Name] = fmt.Errorf("node %s registered but is not Ready", node.Name)
+		}
+		if len(pending) > 0 {
+			return errNotReady
+		}
+		return nil
+	})
+	if err == nil {
+		return nil
+	}
+	for nodeName, reason := range pending {
+		pending[nodeName] = fmt.Errorf("%w after %v", reason, timeout)
+	}
+	return pending
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// LabelAndTaintNode merges the given labels and taints into the node. A taint replaces
+// any existing taint with the same key and effect.
+func (k *K8sClient) LabelAndTaintNode(nodeName string, labels map[string]string, taints []corev1.Taint) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	node, err := k.Clientset.CoreV1().Nodes().Get(ctx, nodeName, v1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get node %s: %w", nodeName, err)
+	}
+
+	if node.Labels == nil {
+		node.Labels = map[string]string{}
+	}
+	for key, value := range labels {
+		node.Labels[key] = value
+	}
+	node.Spec.Taints = mergeTaints(node.Spec.Taints, taints)
+
+	if _, err := k.Clientset.CoreV1().Nodes().Update(ctx, node, v1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update node %s: %w", nodeName, err)
+	}
+	return nil
+}
+
+// DrainNode cordons the node and evicts its pods, leaving DaemonSet and mirror pods
+// in place, then waits for them to terminate. Evictions a
+// PodDisruptionBudget blocks are retried until the drain times out. A positive
+// gracePeriod overrides the pods' termination grace period.
+func (k *K8sClient) DrainNode(nodeName string, gracePeriod time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+
+	node, err := k.Clientset.CoreV1().Nodes().Get(ctx, nodeName, v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get node %s: %w", nodeName, err)
+	}
+
+	if node.Spec.Unschedulable {
+		logger.Debugf("Node %s is already cordoned", nodeName)
+	} else {
+		node.Spec.Unschedulable = true
+		if _, err := k.Clientset.CoreV1().Nodes().Update(ctx, node, v1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to cordon node %s: %w", nodeName, err)
+		}
+	}
+
+	pods, err := k.podsToEvict(ctx, nodeName)
+	if err != nil {
+		return err
+	}
+	// evict concurrently so a pod waiting on its disruption budget doesn't hold up the rest
+	evictErrs := make([]error, len(pods))
+	var wg sync.WaitGroup
+	for i, pod := range pods {
+		wg.Add(1)
+		go func(i int, pod corev1.Pod) {
+			defer wg.Done()
+			evictErrs[i] = k.evictPod(ctx, pod, gracePeriod)
+			if evictErrs[i] == nil {
+				logger.Debugf("Evicted pod %s/%s from node %s", pod.Namespace, pod.Name, nodeName)
+			}
+		}(i, pod)
+	}
+	wg.Wait()
+	if err := stderrors.Join(evictErrs...); err != nil {
+		return fmt.Errorf("failed to drain node %s: %w", nodeName, err)
+	}
+
+	err = retry.Do(ctx, retry.Options{
+		Backoff:   drainPollInterval,
+		Retryable: func(err error) bool { return stderrors.Is(err, errNotReady) },
+	}, func() error {
+		pods, err := k.podsToEvict(ctx, nodeName)
+		if err != nil {
+			return err
+		}
+		if len(pods) > 0 {
+			return errNotReady
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("timeout waiting for pods to leave node %s: %w", nodeName, err)
+	}
+	return nil
+}
+
+// DeleteNode deletes the node object, e.g. once the VM of a drained node is gone
+func (k *K8sClient) DeleteNode(nodeName string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err := k.Clientset.CoreV1().Nodes().Delete(ctx, nodeName, v1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete node %s: %w", nodeName, err)
+	}
+	return nil
+}
+
+// podsToEvict lists the pods on a node a drain evicts
+func (k *K8sClient) podsToEvict(ctx context.Context, nodeName string) ([]corev1.Pod, error) {
+	pods, err := k.Clientset.CoreV1().Pods("").List(ctx, v1.ListOptions{
+		FieldSelector: "spec.nodeName=" + nodeName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods on node %s: %w", nodeName, err)
+	}
+
+	evict := make([]corev1.Pod, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		if !isDaemonSetOrMirrorPod(pod) {
+			evict = append(evict, pod)
+		}
+	}
+	return evict, nil
+}
+
+// evictPod evicts a pod through the eviction API, retrying while a PodDisruptionBudget
+// does not allow the disruption
+func (k *K8sClient) evictPod(ctx context.Context, pod corev1.Pod, gracePeriod time.Duration) error {
+	eviction := &policyv1.Eviction{ObjectMeta: v1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace}}
+	if gracePeriod > 0 {
+		seconds := int64(gracePeriod.Seconds())
+		eviction.DeleteOptions = &v1.DeleteOptions{GracePeriodSeconds: &seconds}
+	}
+
+	err := retry.Do(ctx, retry.Options{Backoff: drainPollInterval, Retryable: errors.IsTooManyRequests}, func() error {
+		err := k.Clientset.CoreV1().Pods(pod.Namespace).EvictV1(ctx, eviction)
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	})
+	if errors.IsTooManyRequests(err) {
+		return fmt.Errorf("eviction of pod %s/%s is blocked by a PodDisruptionBudget: %w", pod.Namespace, pod.Name, err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to evict pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+	return nil
+}
+
+func isDaemonSetOrMirrorPod(pod corev1.Pod) bool {
+	if _, ok := pod.Annotations[corev1.MirrorPodAnnotationKey]; ok {
+		return true
+	}
+	for _, ref := range pod.OwnerReferences {
+		if ref.Kind == "DaemonSet" {
+			return true
+		}
+	}
+	return false
+}
+
+func mergeTaints(existing, taints []corev1.Taint) []corev1.Taint {
+	merged := make([]corev1.Taint, 0, len(existing)+len(taints))
+	for _, e := range existing {
+		replaced := false
+		for _, t := range taints {
+			if e.Key == t.Key && e.Effect == t.Effect {
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, e)
+		}
+	}
+	return append(merged, taints...)
+}
+
+func (c *K8sClient) waitForNamespaceDeletion(namespace string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := retry.Do(ctx, retry.Options{
+		Backoff:   namespacePollInterval,
+		Retryable: func(err error) bool { return stderrors.Is(err, errNotReady) },
+	}, func() error {
+		_, err := c.Clientset.CoreV1().
+			Namespaces().
+			Get(ctx, namespace, v1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil
 			}
+			return fmt.Errorf("error checking namespace status: %w", err)
 		}
+		return errNotReady
+	})
+	if stderrors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timeout waiting for namespace deletion after %v: %w", timeout, context.DeadlineExceeded)
 	}
+	return err
 }
@@ -0,0 +1,758 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const testNamespace = "apps"
+
+func workloadMeta(name, instance string) v1.ObjectMeta {
+	return v1.ObjectMeta{
+		Name:      name,
+		Namespace: testNamespace,
+		Labels:    map[string]string{"app.kubernetes.io/instance": instance},
+	}
+}
+
+func deployment(ready, replicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: workloadMeta("server", "app"),
+		Status:     appsv1.DeploymentStatus{Replicas: replicas, ReadyReplicas: ready},
+	}
+}
+
+func statefulSet(ready, replicas int32) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: workloadMeta("db", "app"),
+		Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+		Status:     appsv1.StatefulSetStatus{ReadyReplicas: ready},
+	}
+}
+
+func daemonSet(ready, desired int32, observed bool) *appsv1.DaemonSet {
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: workloadMeta("agent", "app"),
+		Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: desired, NumberReady: ready},
+	}
+	ds.Generation = 1
+	if observed {
+		ds.Status.ObservedGeneration = 1
+	}
+	return ds
+}
+
+func TestAppWorkloadsReady(t *testing.T) {
+	tests := []struct {
+		name      string
+		workloads []runtime.Object
+		ready     bool
+	}{
+		{"no workloads", nil, false},
+		{"workloads of another app", []runtime.Object{&appsv1.StatefulSet{ObjectMeta: workloadMeta("db", "other")}}, false},
+		{"ready deployment", []runtime.Object{deployment(1, 1)}, true},
+		{"unready deployment", []runtime.Object{deployment(0, 1)}, false},
+		{"only a ready statefulset", []runtime.Object{statefulSet(3, 3)}, true},
+		{"unready statefulset", []runtime.Object{statefulSet(1, 3)}, false},
+		{"ready deployment with unready statefulset", []runtime.Object{deployment(1, 1), statefulSet(0, 1)}, false},
+		{"only a ready daemonset", []runtime.Object{daemonSet(2, 2, true)}, true},
+		{"unready daemonset", []runtime.Object{daemonSet(1, 2, true)}, false},
+		{"daemonset not observed yet", []runtime.Object{daemonSet(0, 0, false)}, false},
+		{"all workloads ready", []runtime.Object{deployment(2, 2), statefulSet(1, 1), daemonSet(3, 3, true)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := fake.NewSimpleClientset(tt.workloads...)
+
+			err := appWorkloadsReady(context.Background(), cs, testNamespace, "app")
+			if tt.ready && err != nil {
+				t.Errorf("expected app to be ready, got %v", err)
+			}
+			if !tt.ready && !errors.Is(err, errNotReady) {
+				t.Errorf("expected app not to be ready, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAppWorkloadsReadyReportsBlockingWorkloads(t *testing.T) {
+	cs := fake.NewSimpleClientset(deployment(1, 1), statefulSet(1, 3), daemonSet(0, 2, true))
+
+	err := appWorkloadsReady(context.Background(), cs, testNamespace, "app")
+	expected := "not ready: statefulset/db 1/3, daemonset/agent 0/2"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %q, got %v", expected, err)
+	}
+}
+
+func TestEnsureApp(t *testing.T) {
+	defer func(interval time.Duration) { ensureAppPollInterval = interval }(ensureAppPollInterval)
+	ensureAppPollInterval = 10 * time.Millisecond
+
+	tests := []struct {
+		name     string
+		workload runtime.Object
+		cancel   bool
+		timeout  time.Duration
+		wantErr  error
+	}{
+		{"ready app", deployment(1, 1), false, time.Second, nil},
+		{"cancelled", deployment(0, 1), true, time.Minute, context.Canceled},
+		{"deadline exceeded", deployment(0, 1), false, 50 * time.Millisecond, errNotReady},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := &K8sClient{Clientset: fake.NewSimpleClientset(tt.workload)}
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			doneCh := k.EnsureApp(ctx, testNamespace, "app")
+			if tt.cancel {
+				time.AfterFunc(50*time.Millisecond, cancel)
+			}
+
+			select {
+			case err := <-doneCh:
+				if tt.wantErr == nil {
+					if err != nil {
+						t.Errorf("unexpected error: %v", err)
+					}
+					return
+				}
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected %v, got %v", tt.wantErr, err)
+				}
+				if err == nil || !strings.Contains(err.Error(), "deployment/server 0/1") {
+					t.Errorf("expected the blocking deployment to be reported, got %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("EnsureApp did not return after its context was done")
+			}
+
+			if _, open := <-doneCh; open {
+				t.Error("expected the channel to be closed after the result")
+			}
+		})
+	}
+}
+
+func drainPod(name, app, ownerKind string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{
+			Name:            name,
+			Namespace:       testNamespace,
+			Labels:          map[string]string{"app": app},
+			OwnerReferences: []v1.OwnerReference{{Kind: ownerKind, Name: app}},
+		},
+		Spec: corev1.PodSpec{NodeName: "dev-worker-1"},
+	}
+}
+
+func pdb(app string, disruptionsAllowed int32) *policyv1.PodDisruptionBudget {
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: v1.ObjectMeta{Name: app, Namespace: testNamespace},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &v1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+		},
+		Status: policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: disruptionsAllowed},
+	}
+}
+
+// evictionReactor evicts pods like the API server, refusing evictions a
+// PodDisruptionBudget does not allow. It goes through the tracker since the clientset is
+// locked while reactors run.
+func evictionReactor(tracker k8stesting.ObjectTracker) k8stesting.ReactionFunc {
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1.Eviction)
+		podsGVR := corev1.SchemeGroupVersion.WithResource("pods")
+		obj, err := tracker.Get(podsGVR, eviction.Namespace, eviction.Name)
+		if err != nil {
+			return true, nil, err
+		}
+		pod := obj.(*corev1.Pod)
+
+		list, err := tracker.List(policyv1.SchemeGroupVersion.WithResource("poddisruptionbudgets"),
+			policyv1.SchemeGroupVersion.WithKind("PodDisruptionBudget"), eviction.Namespace)
+		if err != nil {
+			return true, nil, err
+		}
+		for _, pdb := range list.(*policyv1.PodDisruptionBudgetList).Items {
+			selector, err := v1.LabelSelectorAsSelector(pdb.Spec.Selector)
+			if err != nil {
+				return true, nil, err
+			}
+			if selector.Matches(labels.Set(pod.Labels)) && pdb.Status.DisruptionsAllowed < 1 {
+				return true, nil, apierrors.NewTooManyRequests("Cannot evict pod as it would violate the "+
+					"pod's disruption budget.", 0)
+			}
+		}
+		return true, nil, tracker.Delete(podsGVR, pod.Namespace, pod.Name)
+	}
+}
+
+func TestDrainNode(t *testing.T) {
+	defer func(timeout, interval time.Duration) {
+		drainTimeout, drainPollInterval = timeout, interval
+	}(drainTimeout, drainPollInterval)
+	drainTimeout, drainPollInterval = 200*time.Millisecond, 10*time.Millisecond
+
+	tests := []struct {
+		name          string
+		cordoned      bool
+		pdb           *policyv1.PodDisruptionBudget
+		wantErr       bool
+		remainingPods []string
+	}{
+		{"evicts pods", false, pdb("db", 1), false, []string{"agent"}},
+		{"already cordoned", true, nil, false, []string{"agent"}},
+		{"blocked by a disruption budget", false, pdb("db", 0), true, []string{"agent", "db"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &corev1.Node{
+				ObjectMeta: v1.ObjectMeta{Name: "dev-worker-1"},
+				Spec:       corev1.NodeSpec{Unschedulable: tt.cordoned},
+			}
+			objects := []runtime.Object{
+				node, drainPod("web", "web", "ReplicaSet"), drainPod("db", "db", "StatefulSet"),
+				drainPod("agent", "agent", "DaemonSet"),
+			}
+			if tt.pdb != nil {
+				objects = append(objects, tt.pdb)
+			}
+			cs := fake.NewSimpleClientset(objects...)
+			cs.PrependReactor("create", "pods", evictionReactor(cs.Tracker()))
+
+			err := (&K8sClient{Clientset: cs}).DrainNode("dev-worker-1", time.Second)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+
+			pods, err := cs.CoreV1().Pods(testNamespace).List(context.Background(), v1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var remaining []string
+			for _, pod := range pods.Items {
+				remaining = append(remaining, pod.Name)
+			}
+			if strings.Join(remaining, ",") != strings.Join(tt.remainingPods, ",") {
+				t.Errorf("expected pods %v to remain, got %v", tt.remainingPods, remaining)
+			}
+
+			for _, action := range cs.Actions() {
+				if tt.cordoned && action.GetVerb() == "update" && action.GetResource().Resource == "nodes" {
+					t.Error("expected an already cordoned node not to be updated")
+				}
+			}
+			drained, err := cs.CoreV1().Nodes().Get(context.Background(), "dev-worker-1", v1.GetOptions{})
+			if err != nil {
+				t.Fatalf("expected the node to be kept, got %v", err)
+			}
+			if !drained.Spec.Unschedulable {
+				t.Error("expected the node to be cordoned")
+			}
+		})
+	}
+}
+
+func TestDeleteNode(t *testing.T) {
+	cs := fake.NewSimpleClientset(&corev1.Node{ObjectMeta: v1.ObjectMeta{Name: "dev-worker-1"}})
+	client := &K8sClient{Clientset: cs}
+
+	if err := client.DeleteNode("dev-worker-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err := cs.CoreV1().Nodes().Get(context.Background(), "dev-worker-1", v1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected the node to be deleted, got %v", err)
+	}
+	if err := client.DeleteNode("dev-worker-1"); err != nil {
+		t.Errorf("expected no error for a missing node, got %v", err)
+	}
+}
+
+func TestDrainNodeMissingNode(t *testing.T) {
+	if err := (&K8sClient{Clientset: fake.NewSimpleClientset()}).DrainNode("dev-worker-1", 0); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// stuckNamespaceClientset returns a clientset whose namespaces stay terminating when
+// deleted until their finalizers are removed
+func stuckNamespaceClientset(stuck bool) *fake.Clientset {
+	ns := &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: testNamespace}}
+	cs := fake.NewSimpleClientset(ns)
+	namespacesGVR := corev1.SchemeGroupVersion.WithResource("namespaces")
+	cs.PrependReactor("delete", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if !stuck {
+			return false, nil, nil
+		}
+		terminating := ns.DeepCopy()
+		terminating.Spec.Finalizers = []corev1.FinalizerName{"example.com/finalizer"}
+		terminating.Status.Phase = corev1.NamespaceTerminating
+		return true, nil, cs.Tracker().Update(namespacesGVR, terminating, "")
+	})
+	cs.PrependReactor("create", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "finalize" {
+			return false, nil, nil
+		}
+		finalized := action.(k8stesting.CreateAction).GetObject().(*corev1.Namespace)
+		if len(finalized.Spec.Finalizers) > 0 {
+			return true, finalized, nil
+		}
+		return true, finalized, cs.Tracker().Delete(namespacesGVR, "", finalized.Name)
+	})
+	return cs
+}
+
+func TestDeleteNamespace(t *testing.T) {
+	defer func(deletion, finalize, interval time.Duration) {
+		namespaceDeletionTimeout, namespaceFinalizeTimeout, namespacePollInterval = deletion, finalize, interval
+	}(namespaceDeletionTimeout, namespaceFinalizeTimeout, namespacePollInterval)
+	namespaceDeletionTimeout, namespaceFinalizeTimeout = 50*time.Millisecond, 50*time.Millisecond
+	namespacePollInterval = 5 * time.Millisecond
+
+	tests := []struct {
+		name          string
+		stuck         bool
+		force         bool
+		wantErr       error
+		wantFinalized bool
+	}{
+		{name: "deleted normally"},
+		{name: "deleted normally with force", force: true},
+		{name: "stuck", stuck: true, wantErr: context.DeadlineExceeded},
+		{name: "stuck with force", stuck: true, force: true, wantFinalized: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := stuckNamespaceClientset(tt.stuck)
+			err := (&K8sClient{Clientset: cs}).DeleteNamespace(testNamespace, tt.force)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+
+			finalized := false
+			for _, action := range cs.Actions() {
+				if action.GetSubresource() == "finalize" {
+					finalized = true
+				}
+			}
+			if finalized != tt.wantFinalized {
+				t.Errorf("expected finalize %v, got %v", tt.wantFinalized, finalized)
+			}
+
+			_, err = cs.CoreV1().Namespaces().Get(context.Background(), testNamespace, v1.GetOptions{})
+			if gone := apierrors.IsNotFound(err); gone != (tt.wantErr == nil) {
+				t.Errorf("expected namespace gone %v, got %v", tt.wantErr == nil, gone)
+			}
+		})
+	}
+}
+
+func crdObject(group, plural, kind string, versions ...map[string]interface{}) *unstructured.Unstructured {
+	list := make([]interface{}, 0, len(versions))
+	for _, v := range versions {
+		list = append(list, v)
+	}
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"group":    group,
+			"names":    map[string]interface{}{"plural": plural, "kind": kind},
+			"versions": list,
+		},
+	}}
+	crd.SetAPIVersion("apiextensions.k8s.io/v1")
+	crd.SetKind("CustomResourceDefinition")
+	crd.SetName(plural + "." + group)
+	return crd
+}
+
+func customResource(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	cr := &unstructured.Unstructured{}
+	cr.SetAPIVersion(apiVersion)
+	cr.SetKind(kind)
+	cr.SetNamespace(namespace)
+	cr.SetName(name)
+	return cr
+}
+
+func TestCountCustomResources(t *testing.T) {
+	stored := map[string]interface{}{"name": "v1", "served": true, "storage": true}
+	objects := []runtime.Object{
+		crdObject("cert-manager.io", "certificates", "Certificate",
+			map[string]interface{}{"name": "v1beta1", "served": true, "storage": false}, stored),
+		crdObject("cert-manager.io", "issuers", "Issuer", stored),
+		crdObject("metallb.io", "ipaddresspools", "IPAddressPool", stored),
+		customResource("cert-manager.io/v1", "Certificate", "default", "web"),
+		customResource("cert-manager.io/v1", "Certificate", "apps", "api"),
+		customResource("metallb.io/v1", "IPAddressPool", "metallb-system", "pool"),
+	}
+	listKinds := map[schema.GroupVersionResource]string{
+		crdGVR: "CustomResourceDefinitionList",
+		{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}: "CertificateList",
+		{Group: "cert-manager.io", Version: "v1", Resource: "issuers"}:      "IssuerList",
+		{Group: "metallb.io", Version: "v1", Resource: "ipaddresspools"}:    "IPAddressPoolList",
+	}
+	k := &K8sClient{Dynamic: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(), listKinds, objects...)}
+
+	counts, err := k.CountCustomResources("cert-manager.io")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]int{"certificates.cert-manager.io": 2, "issuers.cert-manager.io": 0}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("expected %v, got %v", expected, counts)
+	}
+}
+
+func TestCustomResourceGVR(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []map[string]interface{}
+		expected string
+		wantErr  bool
+	}{
+		{
+			name: "storage version",
+			versions: []map[string]interface{}{
+				{"name": "v1alpha1", "served": true}, {"name": "v1", "served": true, "storage": true},
+			},
+			expected: "v1",
+		},
+		{
+			name:     "first served version",
+			versions: []map[string]interface{}{{"name": "v1alpha1"}, {"name": "v1beta1", "served": true}},
+			expected: "v1beta1",
+		},
+		{name: "no served version", versions: []map[string]interface{}{{"name": "v1"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gvr, err := customResourceGVR(*crdObject("example.com", "widgets", "Widget", tt.versions...))
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if gvr.Version != tt.expected || gvr.Resource != "widgets" || gvr.Group != "example.com" {
+				t.Errorf("unexpected resource %v", gvr)
+			}
+		})
+	}
+}
+
+func readyNode(name string, ready bool) *corev1.Node {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Node{
+		ObjectMeta: v1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}},
+		},
+	}
+}
+
+func TestWaitForNodesReady(t *testing.T) {
+	defer func(interval time.Duration) { nodeReadyPollInterval = interval }(nodeReadyPollInterval)
+	nodeReadyPollInterval = time.Millisecond
+
+	workers := []string{"dev-worker-1", "dev-worker-2"}
+
+	tests := []struct {
+		name string
+		// registrations lists the nodes that appear in the cluster on each poll
+		registrations [][]*corev1.Node
+		expected      map[string]string
+	}{
+		{
+			name: "registered gradually",
+			registrations: [][]*corev1.Node{
+				{},
+				{readyNode("dev-worker-1", false)},
+				{readyNode("dev-worker-1", true), readyNode("dev-worker-2", false)},
+				{readyNode("dev-worker-1", true), readyNode("dev-worker-2", true)},
+			},
+		},
+		{
+			name: "one worker never registers",
+			registrations: [][]*corev1.Node{
+				{readyNode("dev-master", true)},
+				{readyNode("dev-master", true), readyNode("dev-worker-1", true)},
+			},
+			expected: map[string]string{"dev-worker-2": "node dev-worker-2 did not register"},
+		},
+		{
+			name:          "one worker never ready",
+			registrations: [][]*corev1.Node{{readyNode("dev-worker-1", true), readyNode("dev-worker-2", false)}},
+			expected:      map[string]string{"dev-worker-2": "node dev-worker-2 registered but is not Ready"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := fake.NewSimpleClientset()
+			polls := 0
+			cs.PrependReactor("list", "nodes", func(k8stesting.Action) (bool, runtime.Object, error) {
+				// the last registration stays in place once the cluster stops changing
+				nodes := tt.registrations[min(polls, len(tt.registrations)-1)]
+				polls++
+				list := &corev1.NodeList{}
+				for _, node := range nodes {
+					list.Items = append(list.Items, *node)
+				}
+				return true, list, nil
+			})
+			k := &K8sClient{Clientset: cs}
+
+			unready := k.WaitForNodesReady(context.Background(), workers, 100*time.Millisecond)
+			if len(unready) != len(tt.expected) {
+				t.Fatalf("expected unready nodes %v, got %v", tt.expected, unready)
+			}
+			for nodeName, reason := range tt.expected {
+				if err := unready[nodeName]; err == nil || !strings.Contains(err.Error(), reason) {
+					t.Errorf("expected %s to be unready with %q, got %v", nodeName, reason, err)
+				}
+			}
+			if tt.expected == nil && polls != len(tt.registrations) {
+				t.Errorf("expected %d polls, got %d", len(tt.registrations), polls)
+			}
+		})
+	}
+}
+
+func TestGetNamespaceEvents(t *testing.T) {
+	at := func(second int) v1.Time {
+		return v1.NewTime(time.Date(2024, 5, 1, 12, 0, second, 0, time.UTC))
+	}
+	event := func(name, reason, message string, count int32, lastSeen v1.Time) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     v1.ObjectMeta{Name: name, Namespace: testNamespace},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "server-5d9"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         reason,
+			Message:        message,
+			Count:          count,
+			LastTimestamp:  lastSeen,
+		}
+	}
+	scheduled := &corev1.Event{
+		ObjectMeta:     v1.ObjectMeta{Name: "scheduled", Namespace: testNamespace},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "server-5d9"},
+		Type:           corev1.EventTypeNormal,
+		Reason:         "Scheduled",
+		Message:        "Successfully assigned apps/server-5d9 to dev-worker-1\n",
+		EventTime:      v1.NewMicroTime(at(1).Time),
+	}
+	other := event("other", "Failed", "not in this namespace", 1, at(0))
+	other.Namespace = "default"
+
+	clientset := fake.NewSimpleClientset(
+		event("backoff", "BackOff", "Back-off restarting failed container", 4, at(30)),
+		scheduled,
+		event("pulling", "Failed", "Failed to pull image", 1, at(5)),
+		other,
+	)
+	client := &K8sClient{Clientset: clientset}
+
+	events, err := client.GetNamespaceEvents(context.Background(), testNamespace)
+	if err != nil {
+		t.Fatalf("GetNamespaceEvents: %v", err)
+	}
+	expected := []string{
+		"12:00:01 Normal Scheduled pod/server-5d9: Successfully assigned apps/server-5d9 to dev-worker-1",
+		"12:00:05 Warning Failed pod/server-5d9: Failed to pull image",
+		"12:00:30 Warning BackOff pod/server-5d9: Back-off restarting failed container (x4)",
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected events %v, got %v", expected, events)
+	}
+}
+
+func TestGetPodLogs(t *testing.T) {
+	pod := func(name string, labels map[string]string, containers ...string) *corev1.Pod {
+		p := &corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: name, Namespace: testNamespace, Labels: labels}}
+		for _, container := range containers {
+			p.Spec.Containers = append(p.Spec.Containers, corev1.Container{Name: container})
+		}
+		return p
+	}
+	server := pod("server-5d9", map[string]string{"app": "server"}, "server", "sidecar")
+	server.Spec.InitContainers = []corev1.Container{{Name: "migrate"}}
+	clientset := fake.NewSimpleClientset(
+		server,
+		pod("repo-0", map[string]string{"app": "repo"}, "repo"),
+	)
+	client := &K8sClient{Clientset: clientset}
+
+	tests := []struct {
+		name     string
+		selector string
+		expected []string
+	}{
+		{
+			name:     "every pod",
+			expected: []string{"repo-0/repo", "server-5d9/migrate", "server-5d9/server", "server-5d9/sidecar"},
+		},
+		{
+			name:     "selected pods",
+			selector: "app=server",
+			expected: []string{"server-5d9/migrate", "server-5d9/server", "server-5d9/sidecar"},
+		},
+		{
+			name:     "no match",
+			selector: "app=missing",
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs, err := client.GetPodLogs(context.Background(), testNamespace, tt.selector, 20)
+			if err != nil {
+				t.Fatalf("GetPodLogs: %v", err)
+			}
+			got := make([]string, 0, len(logs))
+			for _, l := range logs {
+				if l.Err != nil || l.Log == "" {
+					t.Errorf("expected logs for %s/%s, got %q, %v", l.Pod, l.Container, l.Log, l.Err)
+				}
+				got = append(got, l.Pod+"/"+l.Container)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected containers %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestApplyUnstructured(t *testing.T) {
+	poolGVR := schema.GroupVersionResource{Group: "metallb.io", Version: "v1beta1", Resource: "ipaddresspools"}
+	pool := func(addresses string, annotations map[string]interface{}) *unstructured.Unstructured {
+		metadata := map[string]interface{}{"name": "pool", "namespace": testNamespace}
+		if annotations != nil {
+			metadata["annotations"] = annotations
+		}
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "metallb.io/v1beta1",
+			"kind":       "IPAddressPool",
+			"metadata":   metadata,
+			"spec":       map[string]interface{}{"addresses": []interface{}{addresses}},
+		}}
+	}
+	existing := pool("10.0.0.100-10.0.0.104", map[string]interface{}{"range": "old", "note": "kept"})
+	existing.SetUID("uid-1")
+	existing.SetResourceVersion("7")
+	existing.SetGeneration(3)
+	existing.SetLabels(map[string]string{"team": "platform"})
+
+	tests := []struct {
+		name            string
+		objects         []runtime.Object
+		createErr       error
+		wantErr         string
+		wantUID         string
+		wantLabels      map[string]string
+		wantAnnotations map[string]string
+	}{
+		{
+			name:            "create",
+			wantAnnotations: map[string]string{"range": "new"},
+		},
+		{
+			name:            "update when it already exists",
+			objects:         []runtime.Object{existing},
+			wantUID:         "uid-1",
+			wantLabels:      map[string]string{"team": "platform"},
+			wantAnnotations: map[string]string{"range": "new", "note": "kept"},
+		},
+		{
+			name:      "create failure",
+			createErr: apierrors.NewForbidden(poolGVR.GroupResource(), "pool", errors.New("denied")),
+			wantErr:   "failed to create IPAddressPool pool",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dynamic := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{poolGVR: "IPAddressPoolList"}, tt.objects...)
+			if tt.createErr != nil {
+				dynamic.PrependReactor("create", "ipaddresspools",
+					func(k8stesting.Action) (bool, runtime.Object, error) { return true, nil, tt.createErr })
+			}
+			client := &K8sClient{Dynamic: dynamic}
+
+			err := client.ApplyUnstructured(context.Background(), poolGVR, testNamespace,
+				pool("10.0.0.110-10.0.0.114", map[string]interface{}{"range": "new"}))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyUnstructured: %v", err)
+			}
+
+			got, err := dynamic.Resource(poolGVR).Namespace(testNamespace).Get(context.Background(), "pool",
+				v1.GetOptions{})
+			if err != nil {
+				t.Fatalf("expected the pool to exist: %v", err)
+			}
+			addresses, _, _ := unstructured.NestedStringSlice(got.Object, "spec", "addresses")
+			if !reflect.DeepEqual(addresses, []string{"10.0.0.110-10.0.0.114"}) {
+				t.Errorf("expected the spec to be applied, got addresses %v", addresses)
+			}
+			if string(got.GetUID()) != tt.wantUID {
+				t.Errorf("expected UID %q, got %q", tt.wantUID, got.GetUID())
+			}
+			if tt.wantUID != "" && (got.GetResourceVersion() != "7" || got.GetGeneration() != 3) {
+				t.Errorf("expected resourceVersion 7 and generation 3, got %s and %d",
+					got.GetResourceVersion(), got.GetGeneration())
+			}
+			if !reflect.DeepEqual(got.GetLabels(), tt.wantLabels) {
+				t.Errorf("expected labels %v, got %v", tt.wantLabels, got.GetLabels())
+			}
+			if !reflect.DeepEqual(got.GetAnnotations(), tt.wantAnnotations) {
+				t.Errorf("expected annotations %v, got %v", tt.wantAnnotations, got.GetAnnotations())
+			}
+		})
+	}
+}
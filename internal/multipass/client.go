@@ -5,8 +5,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -16,16 +19,20 @@ import (
 
 type Client interface {
 	IsMultipassInstalled() bool
-	CreateCluster(clusterName string, nodeCount int, masterCPUs int, masterMemory, masterDisk string,
-		workerCPUs int, workerMemory, workerDisk string, wg *sync.WaitGroup) error
 	DeleteCluster(clusterName string, wg *sync.WaitGroup) error
 	ListClusters() ([]string, error)
-	CreateNode(name string, cpus int, memory string, disk string) error
+	ListNodes(clusterName string) ([]string, error)
+	ListInstances() ([]MultiPassListItem, error)
+	CreateNode(name, image string, cpus int, memory, disk, cloudInit string, launchArgs ...string) error
 	DeleteNode(name string) error
 	PurgeNodes() error
 	GetNodeIP(name string) (string, error)
 	ExecuteShell(name string, command string) (string, error)
 	ExecuteShellWithTimeout(name string, command string, timeoutSeconds int, envs ...string) (string, error)
+	ExecuteShellContext(ctx context.Context, name string, command string, envs ...string) (string, error)
+	ExecuteShellStream(ctx context.Context, name string, command string, out io.Writer, envs ...string) error
+	Mount(name, source, target string) error
+	Unmount(name, target string) error
 }
 
 type MultiPassList struct {
@@ -72,67 +79,6 @@ func (m *MultipassClient) IsMultipassInstalled() bool {
 	return err == nil
 }
 
-func (m *MultipassClient) CreateCluster(
-	clusterName string, nodeCount int, masterCPUs int, masterMemory, masterDisk string,
-	workerCPUs int, workerMemory, workerDisk string, wg *sync.WaitGroup,
-) error {
-	masterName := fmt.Sprintf("%s-master", clusterName)
-	errChan := make(chan error, nodeCount)
-
-	wg.Add(1)
-	go func(name string) {
-		defer wg.Done()
-		err := m.CreateNode(name, masterCPUs, masterMemory, masterDisk)
-		if err != nil {
-			logger.Errorf("failed to create master node %s: %v\n", name, err)
-			errChan <- fmt.Errorf("failed to create master node %s: %w", name, err)
-			return
-		}
-		logger.Debugln("Master node %s created successfully", name)
-	}(masterName)
-
-	for i := 1; i < nodeCount; i++ {
-		wg.Add(1)
-		go func(workerIndex int) {
-			defer wg.Done()
-			nodeName := fmt.Sprintf("%s-worker-%d", clusterName, workerIndex)
-			err := m.CreateNode(nodeName, workerCPUs, workerMemory, workerDisk)
-			if err != nil {
-				logger.Errorln("failed to create worker node %s: %v", nodeName, err)
-				errChan <- fmt.Errorf("failed to create worker node %s: %w", nodeName, err)
-				return
-			}
-			logger.Debugln("Worker node %s created successfully", nodeName)
-		}(i)
-	}
-
-	go func() {
-		wg.Wait()
-		close(errChan)
-	}()
-
-	var creationErrors []error
-	for err := range errChan {
-		if err != nil {
-			creationErrors = append(creationErrors, err)
-		}
-	}
-
-	if len(creationErrors) > 0 {
-		logger.Errorln("Error during cluster creation for '%s', attempting cleanup.", clusterName)
-
-		var cleanupWG sync.WaitGroup
-		if cleanupErr := m.DeleteCluster(clusterName, &cleanupWG); cleanupErr != nil {
-			logger.Errorln("Failed to cleanup cluster %s during error recovery: %v", clusterName, cleanupErr)
-		}
-
-		return creationErrors[0]
-	}
-
-	logger.Debugln("Cluster %s created successfully with %d total instances.", clusterName, nodeCount)
-	return nil
-}
-
 func (m *MultipassClient) DeleteCluster(clusterName string, wg *sync.WaitGroup) error {
 	var list MultiPassList
 	cmd := exec.Command(m.BinaryPath, "list", "--format", "json") //nolint:gosec
@@ -140,7 +86,8 @@ func (m *MultipassClient) DeleteCluster(clusterName string, wg *sync.WaitGroup)
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to list instances: %s - %w", stderr.String(), err)
+		return fmt.Errorf("failed to list instances: %s - %w",
+			stderr.String(), classifyError(stderr.String(), err))
 	}
 	if err := json.Unmarshal(stdout.Bytes(), &list); err != nil {
 		return fmt.Errorf("failed to parse JSON output: %w", err)
@@ -190,14 +137,14 @@ func (m *MultipassClient) DeleteCluster(clusterName string, wg *sync.WaitGroup)
 	return nil
 }
 
-func (m *MultipassClient) CreateNode(name string, cpus int, memory string, disk string) error {
-	args := []string{
-		"launch",
-		"--name", name,
-		"--cpus", fmt.Sprintf("%d", cpus),
-		"--memory", memory,
-		"--disk", disk,
-	}
+// CreateNode launches an instance from image, or the latest Ubuntu LTS when it is empty,
+// configured by the cloud-init file at cloudInit unless it is empty. launchArgs are appended
+// verbatim to the multipass launch arguments and must not set the options managed here, see
+// ValidateLaunchArgs.
+func (m *MultipassClient) CreateNode(
+	name, image string, cpus int, memory, disk, cloudInit string, launchArgs ...string,
+) error {
+	args := launchCommandArgs(name, image, cpus, memory, disk, cloudInit, launchArgs)
 
 	logger.Debugln("Creating node: %s with %d CPUs, %s memory, %s disk", name, cpus, memory, disk)
 	cmd := exec.Command(m.BinaryPath, args...) //nolint:gosec
@@ -206,19 +153,72 @@ func (m *MultipassClient) CreateNode(name string, cpus int, memory string, disk
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to create node '%s': %s - %w", name, stderr.String(), err)
+		return fmt.Errorf("failed to create node '%s': %s - %w",
+			name, stderr.String(), classifyError(stderr.String(), err))
+	}
+
+	return nil
+}
+
+func launchCommandArgs(name, image string, cpus int, memory, disk, cloudInit string, launchArgs []string) []string {
+	args := []string{"launch"}
+	if image != "" {
+		args = append(args, image)
+	}
+	args = append(args,
+		"--name", name,
+		"--cpus", fmt.Sprintf("%d", cpus),
+		"--memory", memory,
+		"--disk", disk,
+	)
+	if cloudInit != "" {
+		args = append(args, "--cloud-init", cloudInit)
 	}
+	return append(args, launchArgs...)
+}
 
+// managedLaunchFlags are the multipass launch options playground sets itself
+var managedLaunchFlags = []string{"--name", "--cpus", "--memory", "--mem", "--disk", "--cloud-init"}
+
+// ValidateLaunchArgs rejects extra launch arguments that would override the name, CPUs,
+// memory, disk or cloud-init playground sets for each node
+func ValidateLaunchArgs(args []string) error {
+	for _, arg := range args {
+		if arg == "" {
+			return fmt.Errorf("multipass argument cannot be empty")
+		}
+		flag, _, _ := strings.Cut(arg, "=")
+		// short options may carry their value, e.g. -c4
+		short := len(arg) >= 2 && arg[0] == '-' && strings.ContainsRune("ncmd", rune(arg[1]))
+		if slices.Contains(managedLaunchFlags, flag) || short {
+			return fmt.Errorf("multipass argument %q conflicts with an option managed by playground, "+
+				"use the dedicated flags instead", arg)
+		}
+	}
 	return nil
 }
 
+// imagePattern matches multipass image names like 22.04, jammy or daily:24.04 as well as
+// file:// and https:// image URLs
+var imagePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:/+-]*$`)
+
+// ValidateImage checks image can be passed to multipass launch as the image to launch,
+// empty selecting the default
+func ValidateImage(image string) error {
+	if image == "" || imagePattern.MatchString(image) {
+		return nil
+	}
+	return fmt.Errorf("%q is not a multipass image name or URL, e.g. 22.04 or jammy", image)
+}
+
 func (m *MultipassClient) DeleteNode(name string) error {
 	cmd := exec.Command(m.BinaryPath, "delete", name) //nolint:gosec
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to delete node '%s': %s - %w", name, stderr.String(), err)
+		return fmt.Errorf("failed to delete node '%s': %s - %w",
+			name, stderr.String(), classifyError(stderr.String(), err))
 	}
 
 	logger.Debugln("Successfully deleted node '%s'", name)
@@ -233,7 +233,8 @@ func (m *MultipassClient) PurgeNodes() error {
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to purge deleted instances: %s - %w", stderr.String(), err)
+		return fmt.Errorf("failed to purge deleted instances: %s - %w",
+			stderr.String(), classifyError(stderr.String(), err))
 	}
 
 	logger.Successln("Successfully purged deleted nodes")
@@ -247,7 +248,8 @@ func (m *MultipassClient) GetNodeIP(name string) (string, error) {
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("failed to get IP address for node '%s': %s - %w", name, stderr.String(), err)
+		return "", fmt.Errorf("failed to get IP address for node '%s': %s - %w",
+			name, stderr.String(), classifyError(stderr.String(), err))
 	}
 
 	var data MultiPassInfo
@@ -257,7 +259,7 @@ func (m *MultipassClient) GetNodeIP(name string) (string, error) {
 
 	nodeInfo, exists := data.Info[name]
 	if !exists {
-		return "", fmt.Errorf("node '%s' not found in multipass info", name)
+		return "", fmt.Errorf("node '%s' not found in multipass info: %w", name, ErrInstanceNotFound)
 	}
 
 	if len(nodeInfo.IPv4) == 0 {
@@ -283,42 +285,130 @@ func (m *MultipassClient) ExecuteShellWithTimeout(name string, command string, t
 		defer cancel()
 	}
 
-	cmd := exec.CommandContext(ctx, m.BinaryPath, "exec", name, "--", "bash", "-c", command) //nolint:gosec
-	cmd.Env = append(os.Environ(), envs...)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	out, err := m.ExecuteShellContext(ctx, name, command, envs...)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return out, fmt.Errorf("command timed out after %d seconds", timeoutSeconds)
+	}
+	return out, err
+}
+
+// ExecuteShellContext runs command on the node and kills it once ctx is done. Only the last
+// MaxCapturedOutput bytes of stdout and stderr are kept.
+func (m *MultipassClient) ExecuteShellContext(ctx context.Context, name string, command string,
+	envs ...string,
+) (string, error) {
+	cmd := m.shellCommand(ctx, name, command, envs...)
+	stdout, stderr := newTailBuffer(MaxCapturedOutput), newTailBuffer(MaxCapturedOutput)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 	if err := cmd.Run(); err != nil {
 		logger.Errorln("Failed to execute command on node '%s': %v", name, err)
-		if ctx.Err() == context.DeadlineExceeded {
-			return stdout.String(), fmt.Errorf("command timed out after %d seconds", timeoutSeconds)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return stdout.String(), fmt.Errorf("command on node '%s' stopped: %w", name, ctxErr)
 		}
 
 		errMsg := fmt.Sprintf("Failed to execute shell command on node '%s': %s", name, stderr.String())
-		logger.Errorln(errMsg)
-		return "", fmt.Errorf("failed to execute shell command on node '%s': %s - %w", name, stderr.String(), err)
+		logger.Errorln("%s", errMsg)
+		return "", fmt.Errorf("failed to execute shell command on node '%s': %s - %w",
+			name, stderr.String(), classifyError(stderr.String(), err))
 	}
 
 	return stdout.String(), nil
 }
 
-func (m *MultipassClient) ListClusters() ([]string, error) {
+// ExecuteShellStream runs command on the node and writes its stdout and stderr to out as they are
+// produced, for cases where the full output must be seen live
+func (m *MultipassClient) ExecuteShellStream(ctx context.Context, name string, command string, out io.Writer,
+	envs ...string,
+) error {
+	cmd := m.shellCommand(ctx, name, command, envs...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("command on node '%s' stopped: %w", name, ctxErr)
+		}
+		return fmt.Errorf("failed to execute shell command on node '%s': %w", name, classifyError("", err))
+	}
+	return nil
+}
+
+func (m *MultipassClient) shellCommand(ctx context.Context, name string, command string, envs ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, m.BinaryPath, "exec", name, "--", "bash", "-c", command) //nolint:gosec
+	cmd.Env = append(os.Environ(), envs...)
+	return cmd
+}
+
+// Mount mounts the host directory source at target inside the instance. Mounting the
+// same target again is not an error.
+func (m *MultipassClient) Mount(name, source, target string) error {
+	cmd := exec.Command(m.BinaryPath, mountCommandArgs(name, source, target)...) //nolint:gosec
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if isAlreadyMounted(stderr.String()) {
+			logger.Debugln("'%s:%s' is already mounted", name, target)
+			return nil
+		}
+		return fmt.Errorf("failed to mount '%s' on node '%s': %s - %w",
+			source, name, stderr.String(), classifyError(stderr.String(), err))
+	}
+
+	logger.Debugln("Mounted '%s' at '%s:%s'", source, name, target)
+	return nil
+}
+
+// Unmount removes the mount at target inside the instance
+func (m *MultipassClient) Unmount(name, target string) error {
+	cmd := exec.Command(m.BinaryPath, unmountCommandArgs(name, target)...) //nolint:gosec
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to unmount '%s' on node '%s': %s - %w",
+			target, name, stderr.String(), classifyError(stderr.String(), err))
+	}
+
+	logger.Debugln("Unmounted '%s:%s'", name, target)
+	return nil
+}
+
+func mountCommandArgs(name, source, target string) []string {
+	return []string{"mount", source, name + ":" + target}
+}
+
+func unmountCommandArgs(name, target string) []string {
+	return []string{"umount", name + ":" + target}
+}
+
+// ListInstances returns every multipass instance, including those not created by playground
+func (m *MultipassClient) ListInstances() ([]MultiPassListItem, error) {
 	var list MultiPassList
 	cmd := exec.Command(m.BinaryPath, "list", "--format", "json") //nolint:gosec
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("failed to list instances: %s - %w", stderr.String(), err)
+		return nil, fmt.Errorf("failed to list instances: %s - %w",
+			stderr.String(), classifyError(stderr.String(), err))
 	}
 	if err := json.Unmarshal(stdout.Bytes(), &list); err != nil {
 		return nil, fmt.Errorf("failed to parse JSON output: %w", err)
 	}
+	return list.List, nil
+}
+
+func (m *MultipassClient) ListClusters() ([]string, error) {
+	instances, err := m.ListInstances()
+	if err != nil {
+		return nil, err
+	}
 
 	var clusters []string
 	seenClusters := make(map[string]bool) // To avoid duplicates
 
-	for _, instance := range list.List {
+	for _, instance := range instances {
 		if strings.HasSuffix(instance.Name, "-master") {
 			clusterName := strings.TrimSuffix(instance.Name, "-master")
 			if !seenClusters[clusterName] {
@@ -331,6 +421,22 @@ func (m *MultipassClient) ListClusters() ([]string, error) {
 	return clusters, nil
 }
 
+// ListNodes returns the names of the non-deleted instances belonging to a cluster
+func (m *MultipassClient) ListNodes(clusterName string) ([]string, error) {
+	instances, err := m.ListInstances()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]string, 0)
+	for _, instance := range instances {
+		if strings.HasPrefix(instance.Name, clusterName+"-") && instance.State != "Deleted" {
+			nodes = append(nodes, instance.Name)
+		}
+	}
+	return nodes, nil
+}
+
 func (m *MultipassClient) GetClusterInfo(clusterName string) (*MultiPassInfo, error) {
 	masterName := clusterName + "-master"
 	cmd := exec.Command(m.BinaryPath, "info", masterName, "--format", "json") //nolint:gosec
@@ -339,7 +445,8 @@ func (m *MultipassClient) GetClusterInfo(clusterName string) (*MultiPassInfo, er
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("failed to get info for cluster '%s': %s - %w", clusterName, stderr.String(), err)
+		return nil, fmt.Errorf("failed to get info for cluster '%s': %s - %w",
+			clusterName, stderr.String(), classifyError(stderr.String(), err))
 	}
 
 	var info MultiPassInfo
@@ -355,7 +462,7 @@ func (m *MultipassClient) GetClusterInfo(clusterName string) (*MultiPassInfo, er
 			return nil, fmt.Errorf("failed to purge deleted nodes: %w", err)
 		}
 
-		return nil, fmt.Errorf("cluster not found: %s", clusterName)
+		return nil, fmt.Errorf("cluster '%s' not found: %w", clusterName, ErrInstanceNotFound)
 
 	}
 
@@ -1,6 +1,13 @@
 package multipass
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -60,8 +67,207 @@ func TestMultipassClient_CreateNode_ValidatesInput(t *testing.T) {
 	client := NewMultipassClient()
 	client.BinaryPath = "nonexistent-binary" // Ensure it fails for the right reason
 
-	err := client.CreateNode("", 1, "1G", "5G")
+	err := client.CreateNode("", "", 1, "1G", "5G", "")
 	if err == nil {
 		t.Error("Expected CreateNode to fail with empty node name")
 	}
 }
+
+func TestMultipassClient_ExecuteShellContext_BoundsOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake multipass binary is a shell script")
+	}
+
+	// stands in for "multipass exec <name> -- bash -c <command>"
+	binary := filepath.Join(t.TempDir(), "multipass")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\nshift 3\nexec \"$@\"\n"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	client := &MultipassClient{BinaryPath: binary}
+
+	prev := MaxCapturedOutput
+	MaxCapturedOutput = 16
+	defer func() { MaxCapturedOutput = prev }()
+
+	out, err := client.ExecuteShellContext(context.Background(), "node", "head -c 1000 /dev/zero; printf done")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out, "[output truncated, 988 bytes omitted]\n") || !strings.HasSuffix(out, "done") {
+		t.Errorf("expected truncated tail, got %q", out)
+	}
+
+	var streamed strings.Builder
+	if err := client.ExecuteShellStream(context.Background(), "node", "head -c 1000 /dev/zero", &streamed); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if streamed.Len() != 1000 {
+		t.Errorf("expected streaming to keep all 1000 bytes, got %d", streamed.Len())
+	}
+}
+
+func TestMultipassClient_MountUnmount(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake multipass binary is a shell script")
+	}
+
+	// records its arguments, and fails like multipass does for unknown instances and
+	// targets that are already mounted
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	script := "#!/bin/sh\n" +
+		"echo \"$@\" >> " + argsFile + "\n" +
+		"case \"$*\" in *missing:*) echo 'instance \"missing\" does not exist' >&2; exit 2;; esac\n" +
+		"case \"$*\" in *node:/busy) echo 'mount failed: \"/busy\" is already mounted in node' >&2; exit 2;; esac\n"
+	binary := filepath.Join(dir, "multipass")
+	if err := os.WriteFile(binary, []byte(script), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	client := &MultipassClient{BinaryPath: binary}
+
+	if err := client.Mount("node", "/home/me/src", "/src"); err != nil {
+		t.Fatalf("unexpected mount error: %v", err)
+	}
+	if err := client.Unmount("node", "/src"); err != nil {
+		t.Fatalf("unexpected unmount error: %v", err)
+	}
+	if err := client.Mount("node", "/home/me/busy", "/busy"); err != nil {
+		t.Fatalf("expected mounting an already mounted target to succeed, got %v", err)
+	}
+
+	got, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "mount /home/me/src node:/src\numount node:/src\nmount /home/me/busy node:/busy\n"
+	if string(got) != expected {
+		t.Errorf("expected args %q, got %q", expected, string(got))
+	}
+
+	tests := []struct {
+		name string
+		run  func() error
+	}{
+		{"mount", func() error { return client.Mount("missing", "/home/me/src", "/src") }},
+		{"unmount", func() error { return client.Unmount("missing", "/src") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run()
+			if !errors.Is(err, ErrInstanceNotFound) {
+				t.Errorf("expected ErrInstanceNotFound, got %v", err)
+			}
+			if err != nil && !strings.Contains(err.Error(), "does not exist") {
+				t.Errorf("expected stderr in error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestMountCommandArgs(t *testing.T) {
+	if got, expected := mountCommandArgs("dev-master", "/home/me/src", "/src"),
+		[]string{"mount", "/home/me/src", "dev-master:/src"}; !slices.Equal(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if got, expected := unmountCommandArgs("dev-master", "/src"),
+		[]string{"umount", "dev-master:/src"}; !slices.Equal(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestLaunchCommandArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		image     string
+		cloudInit string
+		expected  []string
+	}{
+		{
+			name: "without cloud-init",
+			expected: []string{
+				"launch", "--name", "dev-master", "--cpus", "2", "--memory", "2G", "--disk", "20G",
+				"--bridged", "--mount=/src:/src",
+			},
+		},
+		{
+			name:      "with cloud-init",
+			cloudInit: "/home/me/init.yaml",
+			expected: []string{
+				"launch", "--name", "dev-master", "--cpus", "2", "--memory", "2G", "--disk", "20G",
+				"--cloud-init", "/home/me/init.yaml", "--bridged", "--mount=/src:/src",
+			},
+		},
+		{
+			name:  "with image",
+			image: "22.04",
+			expected: []string{
+				"launch", "22.04", "--name", "dev-master", "--cpus", "2", "--memory", "2G", "--disk", "20G",
+				"--bridged", "--mount=/src:/src",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := launchCommandArgs("dev-master", tt.image, 2, "2G", "20G", tt.cloudInit,
+				[]string{"--bridged", "--mount=/src:/src"})
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestValidateImage(t *testing.T) {
+	tests := []struct {
+		image       string
+		expectError bool
+	}{
+		{"", false},
+		{"22.04", false},
+		{"jammy", false},
+		{"daily:24.04", false},
+		{"file:///home/me/images/jammy.img", false},
+		{"https://cloud-images.ubuntu.com/jammy/current/jammy-server-cloudimg-amd64.img", false},
+		{"--name=other", true},
+		{"22.04 --bridged", true},
+		{" 22.04", true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateImage(tt.image)
+		if (err != nil) != tt.expectError {
+			t.Errorf("ValidateImage(%q) error = %v, expectError %v", tt.image, err, tt.expectError)
+		}
+	}
+}
+
+func TestValidateLaunchArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		expectError bool
+	}{
+		{"none", nil, false},
+		{"passthrough options", []string{"--bridged", "--timeout=600", "22.04"}, false},
+		{"cloud-init", []string{"--cloud-init=net.yaml"}, true},
+		{"name", []string{"--name=other"}, true},
+		{"cpus", []string{"--cpus"}, true},
+		{"memory alias", []string{"--mem=4G"}, true},
+		{"short disk with value", []string{"-d20G"}, true},
+		{"short name", []string{"-n"}, true},
+		{"empty", []string{""}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateLaunchArgs(tt.args)
+			if tt.expectError && err == nil {
+				t.Errorf("Expected error for %v", tt.args)
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error for %v: %v", tt.args, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package multipass
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"strings"
+)
+
+var (
+	// ErrInstanceNotFound is returned when multipass reports that an instance does not exist
+	ErrInstanceNotFound = errors.New("instance does not exist")
+	// ErrMultipassUnavailable is returned when the multipass binary or daemon cannot be reached
+	ErrMultipassUnavailable = errors.New("multipass is unavailable")
+)
+
+// stderr fragments multipass prints when its daemon is not reachable
+var unavailableMessages = []string{
+	"cannot connect to the multipass socket",
+	"multipassd",
+	"connection refused",
+}
+
+// classifyError wraps err with ErrInstanceNotFound or ErrMultipassUnavailable when the exit
+// error or the stderr of a multipass invocation identifies the cause, and returns err otherwise
+func classifyError(stderr string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, exec.ErrNotFound) || errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %w", ErrMultipassUnavailable, err)
+	}
+
+	msg := strings.ToLower(stderr)
+	if strings.Contains(msg, "does not exist") {
+		return fmt.Errorf("%w: %w", ErrInstanceNotFound, err)
+	}
+	for _, m := range unavailableMessages {
+		if strings.Contains(msg, m) {
+			return fmt.Errorf("%w: %w", ErrMultipassUnavailable, err)
+		}
+	}
+	return err
+}
+
+// isAlreadyMounted reports whether multipass refused a mount because the target is
+// already mounted
+func isAlreadyMounted(stderr string) bool {
+	return strings.Contains(strings.ToLower(stderr), "is already mounted")
+}
@@ -0,0 +1,50 @@
+package multipass
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	exitErr := errors.New("exit status 2")
+
+	tests := []struct {
+		name     string
+		stderr   string
+		err      error
+		expected error
+	}{
+		{"missing instance", `info failed: instance "dev-master" does not exist`, exitErr, ErrInstanceNotFound},
+		{"daemon not running", "cannot connect to the multipass socket", exitErr, ErrMultipassUnavailable},
+		{"binary missing", "", fmt.Errorf("run: %w", exec.ErrNotFound), ErrMultipassUnavailable},
+		{"other failure", "launch failed: not enough disk", exitErr, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifyError(tt.stderr, tt.err)
+			if !errors.Is(got, tt.err) {
+				t.Errorf("expected original error to be kept, got %v", got)
+			}
+			for _, sentinel := range []error{ErrInstanceNotFound, ErrMultipassUnavailable} {
+				if errors.Is(got, sentinel) != (sentinel == tt.expected) {
+					t.Errorf("expected %v to be classified as %v", got, tt.expected)
+				}
+			}
+		})
+	}
+
+	if classifyError("does not exist", nil) != nil {
+		t.Error("expected nil error to stay nil")
+	}
+}
+
+func TestMultipassClient_MissingBinaryIsUnavailable(t *testing.T) {
+	client := &MultipassClient{BinaryPath: "nonexistent-multipass-binary"}
+
+	if _, err := client.GetNodeIP("dev-master"); !errors.Is(err, ErrMultipassUnavailable) {
+		t.Errorf("expected ErrMultipassUnavailable, got %v", err)
+	}
+}
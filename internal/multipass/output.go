@@ -0,0 +1,65 @@
+package multipass
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MaxCapturedOutput is the number of bytes of stdout and stderr kept for non-streaming commands,
+// older output is dropped so a command printing huge amounts of data cannot exhaust memory
+var MaxCapturedOutput = 1 << 20
+
+// tailBuffer is an io.Writer keeping only the last limit bytes written to it
+type tailBuffer struct {
+	mu      sync.Mutex
+	limit   int
+	buf     []byte
+	dropped int64
+}
+
+func newTailBuffer(limit int) *tailBuffer {
+	return &tailBuffer{limit: limit}
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := len(p)
+	if b.limit <= 0 {
+		b.buf = append(b.buf, p...)
+		return n, nil
+	}
+
+	if len(p) >= b.limit {
+		b.dropped += int64(len(b.buf) + len(p) - b.limit)
+		b.buf = append(b.buf[:0], p[len(p)-b.limit:]...)
+		return n, nil
+	}
+
+	b.buf = append(b.buf, p...)
+	// compact only once the buffer holds twice the limit to keep writes amortized
+	if len(b.buf) > 2*b.limit {
+		excess := len(b.buf) - b.limit
+		b.dropped += int64(excess)
+		b.buf = append(b.buf[:0], b.buf[excess:]...)
+	}
+	return n, nil
+}
+
+// String returns the kept tail, prefixed with a note when earlier output was dropped
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	data := b.buf
+	dropped := b.dropped
+	if b.limit > 0 && len(data) > b.limit {
+		dropped += int64(len(data) - b.limit)
+		data = data[len(data)-b.limit:]
+	}
+	if dropped == 0 {
+		return string(data)
+	}
+	return fmt.Sprintf("[output truncated, %d bytes omitted]\n%s", dropped, data)
+}
@@ -0,0 +1,59 @@
+package multipass
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTailBuffer(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    int
+		writes   []string
+		expected string
+	}{
+		{"within limit", 10, []string{"abc", "def"}, "abcdef"},
+		{"keeps tail across writes", 4, []string{"abc", "def", "ghi"}, "[output truncated, 5 bytes omitted]\nfghi"},
+		{"single write over limit", 3, []string{"abcdef"}, "[output truncated, 3 bytes omitted]\ndef"},
+		{"no limit", 0, []string{"abc", "def"}, "abcdef"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTailBuffer(tt.limit)
+			for _, w := range tt.writes {
+				n, err := b.Write([]byte(w))
+				if err != nil || n != len(w) {
+					t.Fatalf("Write(%q) = %d, %v", w, n, err)
+				}
+			}
+			if got := b.String(); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTailBufferLargeOutput(t *testing.T) {
+	b := newTailBuffer(1024)
+	chunk := []byte(strings.Repeat("x", 100))
+	for i := 0; i < 10000; i++ {
+		if _, err := b.Write(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := b.Write([]byte("END")); err != nil {
+		t.Fatal(err)
+	}
+
+	if cap(b.buf) > 3*1024 {
+		t.Errorf("expected buffer to stay bounded, capacity is %d", cap(b.buf))
+	}
+	got := b.String()
+	if !strings.HasSuffix(got, "END") {
+		t.Errorf("expected the tail to be kept, got suffix %q", got[len(got)-10:])
+	}
+	if !strings.HasPrefix(got, "[output truncated, 998979 bytes omitted]\n") {
+		t.Errorf("expected truncation note, got prefix %q", got[:50])
+	}
+}
@@ -0,0 +1,47 @@
+package plugins
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/mrgb7/playground/pkg/logger"
+)
+
+// ClusterArch is the CPU architecture of the cluster nodes; multipass runs VMs of the
+// host's architecture
+var ClusterArch = runtime.GOARCH
+
+// ArchRestrictedPlugin is implemented by plugins whose images are only published for
+// some CPU architectures
+type ArchRestrictedPlugin interface {
+	SupportedArchs() []string
+}
+
+// warnUnsupportedArch warns when the plugin has no images for the cluster's architecture.
+// The install goes ahead as the images may have been mirrored or overridden.
+func warnUnsupportedArch(plugin Plugin) {
+	if warning := unsupportedArchWarning(plugin, ClusterArch); warning != "" {
+		logger.Warnln("%s", warning)
+	}
+}
+
+func unsupportedArchWarning(plugin Plugin, arch string) string {
+	restricted, ok := plugin.(ArchRestrictedPlugin)
+	if !ok {
+		return ""
+	}
+	archs := restricted.SupportedArchs()
+	if slices.Contains(archs, arch) {
+		return ""
+	}
+	return fmt.Sprintf("Plugin %s only publishes images for %s, its pods may fail to start on %s nodes",
+		plugin.GetName(), strings.Join(archs, ", "), arch)
+}
+
+// archValues deep merges the chart values byArch holds for arch into values
+func archValues(values map[string]interface{}, byArch map[string]map[string]interface{},
+	arch string) map[string]interface{} {
+	return MergeValues(values, copyValues(byArch[arch]))
+}
@@ -0,0 +1,80 @@
+package plugins
+
+import (
+	"reflect"
+	"testing"
+)
+
+// amd64OnlyPlugin is a plugin whose images are only published for amd64
+type amd64OnlyPlugin struct {
+	MockDependencyPlugin
+}
+
+func (p *amd64OnlyPlugin) SupportedArchs() []string { return []string{"amd64"} }
+
+func TestUnsupportedArchWarning(t *testing.T) {
+	restricted := &amd64OnlyPlugin{MockDependencyPlugin{name: "legacy"}}
+
+	tests := []struct {
+		name     string
+		plugin   Plugin
+		arch     string
+		expected string
+	}{
+		{name: "supported arch", plugin: restricted, arch: "amd64"},
+		{
+			name:     "unsupported arch",
+			plugin:   restricted,
+			arch:     "arm64",
+			expected: "Plugin legacy only publishes images for amd64, its pods may fail to start on arm64 nodes",
+		},
+		{name: "unrestricted plugin", plugin: &MockDependencyPlugin{name: "any"}, arch: "arm64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unsupportedArchWarning(tt.plugin, tt.arch); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNginxArchValues(t *testing.T) {
+	tests := []struct {
+		arch     string
+		expected map[string]interface{}
+	}{
+		{
+			arch:     "amd64",
+			expected: map[string]interface{}{"enabled": false},
+		},
+		{
+			arch: "arm64",
+			expected: map[string]interface{}{
+				"enabled": false,
+				"image":   map[string]interface{}{"image": "defaultbackend-arm64"},
+			},
+		},
+	}
+
+	nginx := NewNginx("")
+	for _, tt := range tests {
+		t.Run(tt.arch, func(t *testing.T) {
+			values := archValues(nginx.chartValues(), nginxArchValues, tt.arch)
+			if got := values["defaultBackend"]; !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected defaultBackend %v, got %v", tt.expected, got)
+			}
+			if _, ok := GetNestedValue(values, "controller.replicaCount"); !ok {
+				t.Error("expected the architecture independent values to be kept")
+			}
+		})
+	}
+
+	// merging must not modify the shared per-arch values
+	values := archValues(nginx.chartValues(), nginxArchValues, "arm64")
+	values["defaultBackend"].(map[string]interface{})["image"].(map[string]interface{})["image"] = "changed"
+	if got, _ := GetNestedValue(nginxArchValues["arm64"], "defaultBackend.image.image"); got != "defaultbackend-arm64" {
+		t.Errorf("expected nginxArchValues to be unchanged, got %v", got)
+	}
+}
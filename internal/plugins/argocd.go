@@ -3,24 +3,37 @@ package plugins
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"net/url"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/mrgb7/playground/internal/installer"
 	"github.com/mrgb7/playground/internal/k8s"
 	"github.com/mrgb7/playground/pkg/logger"
+	"golang.org/x/crypto/bcrypt"
 	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 type Argocd struct {
 	KubeConfig string
 	*BasePlugin
 	Tracker *InstallerTracker
+
+	mu                 sync.RWMutex
+	overrideValues     map[string]interface{}
+	skipRemoteDefaults bool
+	valuesSHA256       string                 // overrides ArgocdValuesExpectedSHA256
+	offline            bool                   // read the remote defaults from the cache only
+	verifiedValues     map[string]interface{} // remote defaults checked against the checksum by Install
+	adminPassword      string                 // plaintext of the admin.password override, saved by Install
 }
 
 var (
+	ArgocdName          = "argocd"
 	ArgocdRepoURL       = "https://argoproj.github.io/argo-helm"
 	ArgocdChartName     = "argo-cd"
 	ArgocdChartVersion  = "8.0.0"
@@ -29,11 +42,25 @@ var (
 	ArgoRepoName        = "argo"
 	ArgocdValuesFileURL = "https://raw.githubusercontent.com/mrgb7/core-infrastructure/" +
 		"refs/heads/main/argocd/argocd-values-local.yaml"
+	// ArgocdValuesExpectedSHA256 is the hex SHA256 the file at ArgocdValuesFileURL must
+	// have. Empty skips the check.
+	ArgocdValuesExpectedSHA256 = ""
 )
 
+// argocdResourceHints is the rough footprint of the default ArgoCD install: the server,
+// repo server, application controller, redis and dex
+var argocdResourceHints = ResourceRequirements{
+	CPU:    resource.MustParse("500m"),
+	Memory: resource.MustParse("1Gi"),
+}
+
+// ErrValuesChecksumMismatch is returned when remote default values don't have the expected checksum
+var ErrValuesChecksumMismatch = errors.New("values file checksum mismatch")
+
 const (
-	HTTPTimeoutSeconds = 30
-	MaxResponseSize    = 10 * 1024 * 1024
+	ArgocdInstallTimeout = 10 * time.Minute
+	HTTPTimeoutSeconds   = 30
+	MaxResponseSize      = 10 * 1024 * 1024
 )
 
 func NewArgocd(kubeConfig string) (*Argocd, error) {
@@ -49,8 +76,18 @@ func NewArgocd(kubeConfig string) (*Argocd, error) {
 	return argo, nil
 }
 
+// GetResourceHints returns the resources ArgoCD needs to run
+func (a *Argocd) GetResourceHints() ResourceRequirements {
+	return argocdResourceHints
+}
+
 func (a *Argocd) GetName() string {
-	return "argocd"
+	return ArgocdName
+}
+
+// SetNamespace makes the next install put ArgoCD into namespace
+func (a *Argocd) SetNamespace(namespace string) error {
+	return a.setNamespace(namespace)
 }
 
 func (a *Argocd) GetOptions() PluginOptions {
@@ -63,11 +100,91 @@ func (a *Argocd) GetOptions() PluginOptions {
 		releaseName:      &ArgocdReleaseName,
 		ChartValues:      a.getChartValues(),
 		CRDsGroupVersion: "argoproj.io",
+		Timeout:          ArgocdInstallTimeout,
 	}
 }
 
 func (a *Argocd) Install(kubeConfig, clusterName string, ensure ...bool) error {
-	return a.UnifiedInstall(kubeConfig, clusterName, ensure...)
+	if err := a.verifyRemoteDefaults(); err != nil {
+		return fmt.Errorf("cannot install plugin %s: %w", a.GetName(), err)
+	}
+	if err := a.UnifiedInstall(kubeConfig, clusterName, ensure...); err != nil {
+		return err
+	}
+	return a.saveAdminPassword(kubeConfig, clusterName)
+}
+
+// saveAdminPassword stores the overridden admin password for the ArgoCD installer to log
+// in with, since ArgoCD only got its hash and the initial secret is missing or stale
+func (a *Argocd) saveAdminPassword(kubeConfig, clusterName string) error {
+	a.mu.RLock()
+	password := a.adminPassword
+	a.mu.RUnlock()
+	if password == "" {
+		return nil
+	}
+
+	argo, err := NewArgoInstaller(kubeConfig, clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to create ArgoCD installer: %w", err)
+	}
+	if err := argo.SaveAdminPassword(password); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	a.adminPassword = ""
+	a.mu.Unlock()
+	return nil
+}
+
+// SetValuesChecksum makes installs fail unless the remote default values have the given
+// hex SHA256, taking precedence over ArgocdValuesExpectedSHA256
+func (a *Argocd) SetValuesChecksum(checksum string) error {
+	checksum = strings.ToLower(strings.TrimSpace(checksum))
+	if decoded, err := hex.DecodeString(checksum); err != nil || len(decoded) != sha256.Size {
+		return fmt.Errorf("invalid SHA256 checksum %q, expected %d hex characters", checksum, 2*sha256.Size)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.valuesSHA256 = checksum
+	return nil
+}
+
+func (a *Argocd) expectedValuesSHA256() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.valuesSHA256 != "" {
+		return a.valuesSHA256
+	}
+	return strings.ToLower(ArgocdValuesExpectedSHA256)
+}
+
+// UseOffline makes installs read the remote default values from the local cache only
+func (a *Argocd) UseOffline() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.offline = true
+}
+
+// verifyRemoteDefaults fetches the remote default values once when a checksum is expected
+// or when offline, so a mismatch or a missing cache entry fails the install instead of
+// falling back to the chart defaults
+func (a *Argocd) verifyRemoteDefaults() error {
+	a.mu.RLock()
+	skip, offline := a.skipRemoteDefaults, a.offline
+	a.mu.RUnlock()
+	if skip || (a.expectedValuesSHA256() == "" && !offline) {
+		return nil
+	}
+
+	values, err := a.getValuesContent()
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.verifiedValues = values
+	return nil
 }
 
 func (a *Argocd) Uninstall(kubeConfig, clusterName string, ensure ...bool) error {
@@ -87,52 +204,57 @@ func (a *Argocd) checkUsage() error {
 	return nil
 }
 
+// getValuesContent returns the remote default values, read from the local cache when
+// offline or when the values file can't be fetched
 func (a *Argocd) getValuesContent() (map[string]interface{}, error) {
-	if _, err := url.Parse(ArgocdValuesFileURL); err != nil {
-		return nil, fmt.Errorf("invalid values file URL: %w", err)
+	content, cached, err := a.valuesFileContent()
+	if err != nil {
+		return nil, err
 	}
 
-	httpClient := &http.Client{
-		Timeout: HTTPTimeoutSeconds * time.Second,
+	hash := fmt.Sprintf("%x", sha256.Sum256(content))
+	if expected := a.expectedValuesSHA256(); expected != "" && hash != expected {
+		return nil, fmt.Errorf("%w: %s has SHA256 %s, expected %s",
+			ErrValuesChecksumMismatch, ArgocdValuesFileURL, hash, expected)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), HTTPTimeoutSeconds*time.Second)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, "GET", ArgocdValuesFileURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal YAML content: %w", err)
 	}
 
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch values file: %w", err)
-	}
-	defer func() {
-		if err = resp.Body.Close(); err != nil {
-			logger.Debugln("Failed to close response body: %v", err)
+	if !cached && validateArgocdValues(values) == nil {
+		if err := cacheArgocdValues(content); err != nil {
+			logger.Debugln("Failed to cache ArgoCD values file: %v", err)
 		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch values file: HTTP %d %s", resp.StatusCode, resp.Status)
 	}
+	return values, nil
+}
 
-	limitedReader := io.LimitReader(resp.Body, MaxResponseSize)
-	content, err := io.ReadAll(limitedReader)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+// valuesFileContent returns the content of the values file and whether it came from the cache
+func (a *Argocd) valuesFileContent() ([]byte, bool, error) {
+	a.mu.RLock()
+	offline := a.offline
+	a.mu.RUnlock()
+	if offline {
+		content, path, err := cachedArgocdValues(a.expectedValuesSHA256())
+		if err != nil {
+			return nil, false, fmt.Errorf("offline and no cached ArgoCD values file: %w", err)
+		}
+		logger.Infoln("Using cached ArgoCD values file %s", path)
+		return content, true, nil
 	}
 
-	hash := sha256.Sum256(content)
-	logger.Debugf("ArgoCD values file SHA256: %x", hash)
-
-	var values map[string]interface{}
-	if err := yaml.Unmarshal(content, &values); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal YAML content: %w", err)
+	content, err := fetchRemoteContent(ArgocdValuesFileURL)
+	if err != nil {
+		cached, path, cacheErr := cachedArgocdValues(a.expectedValuesSHA256())
+		if cacheErr != nil {
+			return nil, false, err
+		}
+		logger.Warnln("Failed to fetch ArgoCD values file, using cached copy %s: %v", path, err)
+		return cached, true, nil
 	}
-
-	return values, nil
+	return content, false, nil
 }
 
 func (a *Argocd) Status() string {
@@ -143,7 +265,7 @@ func (a *Argocd) Status() string {
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	ns, err := c.GetNameSpace(ArgocdNamespace, ctx)
+	ns, err := c.GetNameSpace(ArgoCDNamespace(a.KubeConfig), ctx)
 	if ns == "" || err != nil {
 		logger.Debugf("failed to get argocd namespace: %v", err)
 		return StatusNotInstalled
@@ -152,12 +274,195 @@ func (a *Argocd) Status() string {
 }
 
 func (a *Argocd) getChartValues() map[string]interface{} {
+	a.mu.RLock()
+	skip, verified := a.skipRemoteDefaults, a.verifiedValues
+	a.mu.RUnlock()
+	if skip {
+		return a.applyOverrides(a.getInstalledValues())
+	}
+	if verified != nil {
+		return a.applyOverrides(copyValues(verified))
+	}
+
 	val, err := a.getValuesContent()
+	if err == nil {
+		err = validateArgocdValues(val)
+	}
 	if err != nil {
-		logger.Errorln("failed to get values content: %v", err)
+		logger.Warnln("Ignoring ArgoCD default values from %s, using the chart defaults: %v", ArgocdValuesFileURL, err)
+		return a.applyOverrides(nil)
+	}
+	return a.applyOverrides(val)
+}
+
+// argocdValuesKeys are top-level keys of the argo-cd chart values; the default
+// values file must use at least one of them and each must hold a mapping
+var argocdValuesKeys = []string{
+	"global", "configs", "controller", "server", "repoServer", "applicationSet",
+	"dex", "redis", "redis-ha", "notifications", "crds",
+}
+
+// validateArgocdValues rejects fetched content that doesn't look like argo-cd chart
+// values, e.g. a truncated download or an HTML error page
+func validateArgocdValues(values map[string]interface{}) error {
+	if len(values) == 0 {
+		return fmt.Errorf("values file is empty")
+	}
+	found := false
+	for _, key := range argocdValuesKeys {
+		v, ok := values[key]
+		if !ok {
+			continue
+		}
+		if _, isMap := v.(map[string]interface{}); !isMap {
+			return fmt.Errorf("values key %q should be a mapping, got %T", key, v)
+		}
+		found = true
+	}
+	if !found {
+		return fmt.Errorf("values file has none of the expected keys %v", argocdValuesKeys)
+	}
+	return nil
+}
+
+// SkipRemoteDefaults makes installs ignore ArgocdValuesFileURL and only use the
+// override values on top of the values of the installed release, if any
+func (a *Argocd) SkipRemoteDefaults() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.skipRemoteDefaults = true
+}
+
+func (a *Argocd) getInstalledValues() map[string]interface{} {
+	h, err := installer.NewHelmInstaller(a.KubeConfig)
+	if err != nil {
+		logger.Debugf("failed to create helm installer: %v", err)
+		return map[string]interface{}{}
+	}
+	values, err := h.GetCurrentValues(ArgocdReleaseName, ArgoCDNamespace(a.KubeConfig))
+	if err != nil {
+		logger.Debugf("failed to get installed argocd values: %v", err)
+		return map[string]interface{}{}
+	}
+	return values
+}
+
+// ArgocdAdminPasswordOverrideKey sets the password of the ArgoCD admin user
+const ArgocdAdminPasswordOverrideKey = "admin.password"
+
+// argocdMaxPasswordLength is the longest password bcrypt can hash
+const argocdMaxPasswordLength = 72
+
+// GetOverrideSchema keeps the admin password a string even when it looks like a number
+func (a *Argocd) GetOverrideSchema() map[string]OverrideType {
+	return map[string]OverrideType{ArgocdAdminPasswordOverrideKey: OverrideTypeString}
+}
+
+// ValidateOverrideValues checks the admin password can be hashed; other keys are passed to
+// the chart as they are
+func (a *Argocd) ValidateOverrideValues(values map[string]interface{}) error {
+	v, ok := GetNestedValue(values, ArgocdAdminPasswordOverrideKey)
+	if !ok {
 		return nil
 	}
-	return val
+	password, isString := v.(string)
+	if !isString || password == "" {
+		return fmt.Errorf("%s must be a non-empty string", ArgocdAdminPasswordOverrideKey)
+	}
+	if len(password) > argocdMaxPasswordLength {
+		return fmt.Errorf("%s must be at most %d bytes long", ArgocdAdminPasswordOverrideKey, argocdMaxPasswordLength)
+	}
+	return nil
+}
+
+// hashAdminPassword replaces the admin.password override in values with the bcrypt hash
+// and modification time the argo-cd chart reads from configs.secret, so the plaintext
+// never reaches the chart, and returns the plaintext. A password that already is a bcrypt
+// hash is used as is, and no plaintext is returned for it.
+func hashAdminPassword(values map[string]interface{}) (string, error) {
+	v, ok := GetNestedValue(values, ArgocdAdminPasswordOverrideKey)
+	if !ok {
+		return "", nil
+	}
+	if admin, isMap := values["admin"].(map[string]interface{}); isMap {
+		delete(admin, "password")
+		if len(admin) == 0 {
+			delete(values, "admin")
+		}
+	}
+
+	password, isString := v.(string)
+	if !isString || password == "" {
+		return "", fmt.Errorf("%s must be a non-empty string", ArgocdAdminPasswordOverrideKey)
+	}
+	hash := []byte(password)
+	plaintext := ""
+	if _, err := bcrypt.Cost(hash); err != nil {
+		hash, err = bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return "", fmt.Errorf("failed to hash %s: %w", ArgocdAdminPasswordOverrideKey, err)
+		}
+		plaintext = password
+	} else {
+		logger.Warnln("%s is a bcrypt hash, ArgoCD managed plugins can't log in to ArgoCD with it",
+			ArgocdAdminPasswordOverrideKey)
+	}
+	MergeValues(values, map[string]interface{}{
+		"configs": map[string]interface{}{
+			"secret": map[string]interface{}{
+				"argocdServerAdminPassword":      string(hash),
+				"argocdServerAdminPasswordMtime": time.Now().UTC().Format(time.RFC3339),
+			},
+		},
+	})
+	return plaintext, nil
+}
+
+// keepAdminPassword remembers the plaintext of an admin password override for Install
+func (a *Argocd) keepAdminPassword(password string) {
+	if password == "" {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.adminPassword = password
+}
+
+// NormalizeOverrideValues returns a copy of values with an admin.password override
+// replaced by its bcrypt hash, so only the hash is applied and recorded. The plaintext
+// is kept for Install to save.
+func (a *Argocd) NormalizeOverrideValues(values map[string]interface{}) (map[string]interface{}, error) {
+	normalized := copyValues(values)
+	password, err := hashAdminPassword(normalized)
+	if err != nil {
+		return nil, err
+	}
+	a.keepAdminPassword(password)
+	return normalized, nil
+}
+
+// SetOverrideValues stores a copy of values, so later changes by the caller don't leak in.
+// An admin password is hashed once here so every install renders the same values.
+func (a *Argocd) SetOverrideValues(values map[string]interface{}) {
+	overrides := copyValues(values)
+	password, err := hashAdminPassword(overrides)
+	if err != nil {
+		logger.Warnln("Ignoring the ArgoCD admin password override: %v", err)
+	}
+	a.keepAdminPassword(password)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.overrideValues = overrides
+}
+
+// applyOverrides merges the override values over the chart defaults
+func (a *Argocd) applyOverrides(values map[string]interface{}) map[string]interface{} {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if len(a.overrideValues) == 0 {
+		return values
+	}
+	return MergeValues(values, copyValues(a.overrideValues))
 }
 
 func (a *Argocd) GetDependencies() []string {
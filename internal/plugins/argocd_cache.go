@@ -0,0 +1,102 @@
+package plugins
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	cacheDirPermissions  = 0o700
+	cacheFilePermissions = 0o600
+	argocdValuesPrefix   = "argocd-values-"
+	argocdValuesSuffix   = ".yaml"
+)
+
+// ErrNoCachedValues is returned when the values cache has no usable copy of a values file
+var ErrNoCachedValues = errors.New("no cached values file")
+
+// valuesCacheDir returns the directory fetched values files are cached in
+func valuesCacheDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".playground", "cache"), nil
+}
+
+// cacheArgocdValues stores content as argocd-values-<sha256>.yaml in the cache
+func cacheArgocdValues(content []byte) error {
+	dir, err := valuesCacheDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, cacheDirPermissions); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	name := fmt.Sprintf("%s%x%s", argocdValuesPrefix, sha256.Sum256(content), argocdValuesSuffix)
+	path := filepath.Join(dir, name)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, cacheFilePermissions); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	// mark the copy as the most recent even when its content was cached before
+	now := time.Now()
+	return os.Chtimes(path, now, now)
+}
+
+// cachedArgocdValues returns the cached values file with the given checksum, or the most
+// recently cached one when checksum is empty, along with its path
+func cachedArgocdValues(checksum string) ([]byte, string, error) {
+	dir, err := valuesCacheDir()
+	if err != nil {
+		return nil, "", err
+	}
+
+	path := filepath.Join(dir, argocdValuesPrefix+checksum+argocdValuesSuffix)
+	if checksum == "" {
+		path, err = latestCachedArgocdValues(dir)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "", fmt.Errorf("%w in %s", ErrNoCachedValues, dir)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return content, path, nil
+}
+
+func latestCachedArgocdValues(dir string) (string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, argocdValuesPrefix+"*"+argocdValuesSuffix))
+	if err != nil {
+		return "", fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+
+	var latest string
+	var latestTime time.Time
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			continue
+		}
+		if latest == "" || info.ModTime().After(latestTime) {
+			latest, latestTime = match, info.ModTime()
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("%w in %s", ErrNoCachedValues, dir)
+	}
+	return latest, nil
+}
@@ -0,0 +1,367 @@
+package plugins
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestValidateArgocdValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  map[string]interface{}
+		wantErr bool
+	}{
+		{"valid", map[string]interface{}{"server": map[string]interface{}{"insecure": true}}, false},
+		{"empty", map[string]interface{}{}, true},
+		{"unknown keys only", map[string]interface{}{"html": "oops"}, true},
+		{"known key with wrong type", map[string]interface{}{"configs": "broken"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateArgocdValues(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateArgocdValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestArgocdChartValuesFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected map[string]interface{}
+	}{
+		{
+			name:     "valid values are used",
+			body:     "server:\n  insecure: true\n",
+			expected: map[string]interface{}{"server": map[string]interface{}{"insecure": true}},
+		},
+		{
+			name:     "unexpected content falls back to chart defaults",
+			body:     "title: Service Unavailable\n",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+			t.Setenv("HOME", t.TempDir())
+
+			prev := ArgocdValuesFileURL
+			ArgocdValuesFileURL = server.URL
+			defer func() { ArgocdValuesFileURL = prev }()
+
+			got := (&Argocd{}).getChartValues()
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestArgocdValuesChecksum(t *testing.T) {
+	const body = "server:\n  insecure: true\n"
+	sum := sha256.Sum256([]byte(body))
+	checksum := hex.EncodeToString(sum[:])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	t.Setenv("HOME", t.TempDir())
+
+	prev := ArgocdValuesFileURL
+	ArgocdValuesFileURL = server.URL
+	defer func() { ArgocdValuesFileURL = prev }()
+
+	tests := []struct {
+		name        string
+		packageSum  string
+		overrideSum string
+		wantErr     bool
+	}{
+		{name: "no checksum configured"},
+		{name: "package checksum matches", packageSum: checksum},
+		{name: "package checksum mismatch", packageSum: strings.Repeat("0", 64), wantErr: true},
+		{name: "flag checksum matches", overrideSum: strings.ToUpper(checksum)},
+		{name: "flag checksum takes precedence", packageSum: checksum, overrideSum: strings.Repeat("a", 64), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prevSum := ArgocdValuesExpectedSHA256
+			ArgocdValuesExpectedSHA256 = tt.packageSum
+			defer func() { ArgocdValuesExpectedSHA256 = prevSum }()
+
+			a := &Argocd{}
+			if tt.overrideSum != "" {
+				if err := a.SetValuesChecksum(tt.overrideSum); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			err := a.verifyRemoteDefaults()
+			if tt.wantErr {
+				if !errors.Is(err, ErrValuesChecksumMismatch) {
+					t.Fatalf("expected ErrValuesChecksumMismatch, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expected := map[string]interface{}{"server": map[string]interface{}{"insecure": true}}
+			if got := a.getChartValues(); !reflect.DeepEqual(got, expected) {
+				t.Errorf("expected %v, got %v", expected, got)
+			}
+		})
+	}
+}
+
+func TestArgocdSetValuesChecksumRejectsInvalid(t *testing.T) {
+	for _, checksum := range []string{"abc", strings.Repeat("z", 64), strings.Repeat("0", 63)} {
+		if err := (&Argocd{}).SetValuesChecksum(checksum); err == nil {
+			t.Errorf("expected an error for %q", checksum)
+		}
+	}
+}
+
+func TestArgocdValuesCache(t *testing.T) {
+	const cachedBody = "server:\n  insecure: true\n"
+	cachedValues := map[string]interface{}{"server": map[string]interface{}{"insecure": true}}
+
+	tests := []struct {
+		name     string
+		offline  bool
+		cached   bool
+		online   bool
+		expected map[string]interface{}
+		wantErr  bool
+	}{
+		{name: "fetch failure falls back to the cache", cached: true, expected: cachedValues},
+		{name: "fetch failure without cache", wantErr: true},
+		{name: "offline uses the cache", offline: true, cached: true, online: true, expected: cachedValues},
+		{name: "offline without cache", offline: true, online: true, wantErr: true},
+		{
+			name:     "fetched values win over the cache",
+			cached:   true,
+			online:   true,
+			expected: map[string]interface{}{"configs": map[string]interface{}{"cm": map[string]interface{}{}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			if tt.cached {
+				if err := cacheArgocdValues([]byte(cachedBody)); err != nil {
+					t.Fatalf("failed to populate cache: %v", err)
+				}
+			}
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !tt.online {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				_, _ = w.Write([]byte("configs:\n  cm: {}\n"))
+			}))
+			defer server.Close()
+
+			prev := ArgocdValuesFileURL
+			ArgocdValuesFileURL = server.URL
+			defer func() { ArgocdValuesFileURL = prev }()
+
+			a := &Argocd{}
+			if tt.offline {
+				a.UseOffline()
+			}
+			values, err := a.getValuesContent()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", values)
+				}
+				if tt.offline && !errors.Is(err, ErrNoCachedValues) {
+					t.Errorf("expected ErrNoCachedValues, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(values, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, values)
+			}
+		})
+	}
+}
+
+func TestArgocdValuesCachedAfterFetch(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	const body = "server:\n  insecure: true\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	prev := ArgocdValuesFileURL
+	ArgocdValuesFileURL = server.URL
+	defer func() { ArgocdValuesFileURL = prev }()
+
+	if _, err := (&Argocd{}).getValuesContent(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sum := sha256.Sum256([]byte(body))
+	content, _, err := cachedArgocdValues(hex.EncodeToString(sum[:]))
+	if err != nil {
+		t.Fatalf("expected the fetched file to be cached: %v", err)
+	}
+	if string(content) != body {
+		t.Errorf("expected cached content %q, got %q", body, content)
+	}
+}
+
+func TestArgocdAdminPasswordHashed(t *testing.T) {
+	const password = "s3cret-Admin-pass"
+	a := &Argocd{}
+	a.SetOverrideValues(map[string]interface{}{
+		"admin":  map[string]interface{}{"password": password},
+		"server": map[string]interface{}{"replicas": 2},
+	})
+
+	got := a.applyOverrides(map[string]interface{}{
+		"configs": map[string]interface{}{"cm": map[string]interface{}{"url": "https://argocd.local"}},
+	})
+
+	if _, ok := got["admin"]; ok {
+		t.Errorf("expected the admin override to be removed, got %v", got["admin"])
+	}
+	if strings.Contains(fmt.Sprint(got), password) {
+		t.Errorf("expected the plaintext password not to be in the values, got %v", got)
+	}
+	hash, _ := GetNestedValue(got, "configs.secret.argocdServerAdminPassword")
+	hashString, isString := hash.(string)
+	if !isString || bcrypt.CompareHashAndPassword([]byte(hashString), []byte(password)) != nil {
+		t.Errorf("expected a bcrypt hash of the password, got %v", hash)
+	}
+	mtime, _ := GetNestedValue(got, "configs.secret.argocdServerAdminPasswordMtime")
+	if s, _ := mtime.(string); s == "" {
+		t.Error("expected the password modification time to be set")
+	} else if _, err := time.Parse(time.RFC3339, s); err != nil {
+		t.Errorf("expected an RFC3339 modification time, got %q", s)
+	}
+	if url, _ := GetNestedValue(got, "configs.cm.url"); url != "https://argocd.local" {
+		t.Errorf("expected the other configs to be kept, got %v", url)
+	}
+	if replicas, _ := GetNestedValue(got, "server.replicas"); replicas != 2 {
+		t.Errorf("expected the other overrides to be applied, got %v", replicas)
+	}
+
+	// the password is hashed once, so every render of the values is the same
+	again := a.applyOverrides(nil)
+	for _, key := range []string{
+		"configs.secret.argocdServerAdminPassword", "configs.secret.argocdServerAdminPasswordMtime",
+	} {
+		first, _ := GetNestedValue(got, key)
+		second, _ := GetNestedValue(again, key)
+		if first != second {
+			t.Errorf("expected the same %s on every call, got %v and %v", key, first, second)
+		}
+	}
+}
+
+func TestArgocdNormalizeOverrideValues(t *testing.T) {
+	const password = "s3cret-Admin-pass"
+	values := map[string]interface{}{"admin": map[string]interface{}{"password": password}}
+
+	a := &Argocd{}
+	normalized, err := a.NormalizeOverrideValues(values)
+	if err != nil {
+		t.Fatalf("NormalizeOverrideValues: %v", err)
+	}
+	// the normalized values are applied next, so the plaintext must survive for Install
+	a.SetOverrideValues(normalized)
+	if a.adminPassword != password {
+		t.Errorf("expected the plaintext to be kept for the ArgoCD installer, got %q", a.adminPassword)
+	}
+	if strings.Contains(fmt.Sprint(normalized), password) {
+		t.Errorf("expected only the hash to be recorded, got %v", normalized)
+	}
+	hash, _ := GetNestedValue(normalized, "configs.secret.argocdServerAdminPassword")
+	if s, _ := hash.(string); bcrypt.CompareHashAndPassword([]byte(s), []byte(password)) != nil {
+		t.Errorf("expected a bcrypt hash of the password, got %v", hash)
+	}
+	if stored, _ := GetNestedValue(values, ArgocdAdminPasswordOverrideKey); stored != password {
+		t.Errorf("expected the caller's values to be left unchanged, got %v", stored)
+	}
+
+	if _, err := (&Argocd{}).NormalizeOverrideValues(map[string]interface{}{
+		"admin": map[string]interface{}{"password": strings.Repeat("a", 73)},
+	}); err == nil {
+		t.Error("expected a password bcrypt can't hash to be rejected")
+	}
+}
+
+func TestArgocdAdminPasswordAlreadyHashed(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("admin"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	a := &Argocd{}
+	a.SetOverrideValues(map[string]interface{}{"admin": map[string]interface{}{"password": string(hash)}})
+
+	got, _ := GetNestedValue(a.applyOverrides(nil), "configs.secret.argocdServerAdminPassword")
+	if got != string(hash) {
+		t.Errorf("expected the bcrypt hash to be used as is, got %v", got)
+	}
+	if a.adminPassword != "" {
+		t.Errorf("expected no plaintext to be kept for a hash, got %q", a.adminPassword)
+	}
+}
+
+func TestArgocdValidateOverrideValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  map[string]interface{}
+		wantErr bool
+	}{
+		{name: "no password", values: map[string]interface{}{"server": map[string]interface{}{"replicas": 2}}},
+		{name: "password", values: map[string]interface{}{"admin": map[string]interface{}{"password": "12345"}}},
+		{
+			name:    "empty password",
+			values:  map[string]interface{}{"admin": map[string]interface{}{"password": ""}},
+			wantErr: true,
+		},
+		{
+			name:    "password too long for bcrypt",
+			values:  map[string]interface{}{"admin": map[string]interface{}{"password": strings.Repeat("a", 73)}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := (&Argocd{}).ValidateOverrideValues(tt.values); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateOverrideValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,11 +1,14 @@
 package plugins
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	"github.com/mrgb7/playground/internal/installer"
 	"github.com/mrgb7/playground/internal/k8s"
 	"github.com/mrgb7/playground/pkg/logger"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
@@ -14,9 +17,70 @@ const (
 	StatusRunning      = "running"
 )
 
+// RepoCredentialsPlugin is implemented by plugins installing charts from a repository
+// that may need authentication
+type RepoCredentialsPlugin interface {
+	SetRepoCredentials(username, password string)
+}
+
+// NamespaceOverridable is implemented by plugins that can be installed into another
+// namespace than their default one
+type NamespaceOverridable interface {
+	SetNamespace(namespace string) error
+}
+
+// PreInstallChecker is implemented by plugins with prerequisites on the cluster, which
+// are checked before anything is installed
+type PreInstallChecker interface {
+	PreInstallCheck(kubeConfig string) error
+}
+
+// preInstallCheck runs the plugin's pre-install check if it has one
+func preInstallCheck(plugin Plugin, kubeConfig string) error {
+	checker, ok := plugin.(PreInstallChecker)
+	if !ok {
+		return nil
+	}
+	if err := checker.PreInstallCheck(kubeConfig); err != nil {
+		return fmt.Errorf("cannot install plugin %s, pre-install check failed: %w", plugin.GetName(), err)
+	}
+	return nil
+}
+
+// ForceRemovablePlugin can remove the finalizers of its namespace when the namespace is
+// stuck terminating after an uninstall
+type ForceRemovablePlugin interface {
+	ForceNamespaceDeletion()
+}
+
+// CRDRetentionPlugin is implemented by plugins whose uninstall deletes the CRDs of their
+// API group. keep leaves them in place; otherwise CRDs still having custom resources are
+// only deleted when confirm agrees.
+type CRDRetentionPlugin interface {
+	SetCRDRetention(keep bool, confirm installer.ConfirmCRDDeletion)
+}
+
+// InstallerSelectablePlugin is implemented by plugins that can be installed with an
+// explicitly chosen installer instead of the detected one
+type InstallerSelectablePlugin interface {
+	SetInstallerType(installerType string)
+}
+
 type BasePlugin struct {
 	KubeConfig string
 	plugin     Plugin
+	pinned     *LockEntry
+	installed  *LockEntry
+	version    string
+
+	repoUsername  string
+	repoPassword  string
+	namespace     string // overrides the plugin's namespace, see NamespaceOverridable
+	installerType string // forces helm or argocd, see InstallerSelectablePlugin
+
+	forceNamespaceDeletion bool
+	keepCRDs               bool
+	confirmCRDDeletion     installer.ConfirmCRDDeletion
 }
 
 func NewBasePlugin(kubeConfig string, plugin Plugin) *BasePlugin {
@@ -27,7 +91,14 @@ func NewBasePlugin(kubeConfig string, plugin Plugin) *BasePlugin {
 }
 
 func (b *BasePlugin) UnifiedInstall(kubeConfig, clusterName string, ensure ...bool) error {
-	inst, err := NewInstaller(b.plugin, kubeConfig, clusterName)
+	if err := checkKubeConfig(kubeConfig); err != nil {
+		return fmt.Errorf("cannot install plugin %s: %w", b.plugin.GetName(), err)
+	}
+	if err := preInstallCheck(b.plugin, kubeConfig); err != nil {
+		return err
+	}
+	warnUnsupportedArch(b.plugin)
+	inst, err := NewInstaller(b.plugin, kubeConfig, clusterName, b.installerType)
 	if err != nil {
 		return fmt.Errorf("failed to create installer: %w", err)
 	}
@@ -42,42 +113,163 @@ func (b *BasePlugin) UnifiedInstall(kubeConfig, clusterName string, ensure ...bo
 		installerType = "unknown"
 	}
 
-	opts := newInstallOptions(b.plugin, kubeConfig)
+	action := HistoryActionInstall
+	recordedNamespace := ""
+	tracker, trackerErr := NewInstallerTracker(kubeConfig)
+	if trackerErr != nil {
+		logger.Warnln("Failed to create installer tracker for %s: %v", b.plugin.GetName(), trackerErr)
+	} else {
+		if recorded, err := tracker.GetPluginInstaller(b.plugin.GetName()); err == nil && recorded != "" {
+			action = HistoryActionUpgrade
+		}
+		recordedNamespace = b.recordedNamespace(tracker)
+	}
+
+	opt := b.plugin.GetOptions()
+	opts := b.newInstallOptions(opt, kubeConfig, recordedNamespace)
+	if opt.ValuesURL != "" {
+		opts.Values = mergeRemoteValues(opts.Values, opt.ValuesURL)
+	}
+	if b.version != "" {
+		opts.Version = b.version
+		logger.Infoln("Using version %s for plugin %s", opts.Version, b.plugin.GetName())
+	}
+	if b.repoUsername != "" || b.repoPassword != "" {
+		opts.RepoUsername, opts.RepoPassword = b.repoUsername, b.repoPassword
+	}
+	if b.pinned != nil {
+		if err := applyLockEntry(opts, b.pinned); err != nil {
+			return err
+		}
+		logger.Infoln("Using locked version %s for plugin %s", opts.Version, b.plugin.GetName())
+	}
 
 	err = inst.Install(opts)
 	if err != nil {
 		return err
 	}
 
-	tracker, trackerErr := NewInstallerTracker(kubeConfig)
-	if trackerErr != nil {
-		logger.Warnln("Failed to create installer tracker after installing %s: %v", b.plugin.GetName(), trackerErr)
-	} else {
+	b.installed, err = newLockEntry(opts)
+	if err != nil {
+		logger.Warnln("Failed to compute lock entry for %s: %v", b.plugin.GetName(), err)
+	}
+
+	b.recordEvent(kubeConfig, action, opts, installerType)
+
+	if tracker != nil {
 		recordErr := tracker.RecordPluginInstaller(b.plugin.GetName(), installerType)
 		if recordErr != nil {
 			logger.Warnln("Failed to record installer type for %s: %v", b.plugin.GetName(), recordErr)
 		}
+		if opts.Namespace != recordedNamespace {
+			if err := tracker.RecordPluginNamespace(b.plugin.GetName(), opts.Namespace); err != nil {
+				logger.Warnln("Failed to record namespace for %s: %v", b.plugin.GetName(), err)
+			}
+		}
 	}
 	if len(ensure) > 0 && ensure[0] {
 		cl, err := k8s.NewK8sClient(kubeConfig)
 		if err != nil {
 			return fmt.Errorf("failed to create k8s client: %w", err)
 		}
-		opt := b.plugin.GetOptions()
-		if err := <-cl.EnsureApp(*opt.Namespace, b.plugin.GetName()); err != nil {
-			return fmt.Errorf("failed to ensure plugin %s in namespace %s: %w", b.plugin.GetName(), *opt.Namespace, err)
+		ctx, cancel := context.WithTimeout(context.Background(), opts.EffectiveTimeout())
+		defer cancel()
+		if err := <-cl.EnsureApp(ctx, opts.Namespace, b.plugin.GetName()); err != nil {
+			return fmt.Errorf("failed to ensure plugin %s in namespace %s: %w", b.plugin.GetName(), opts.Namespace, err)
 		}
 	}
 
 	return nil
 }
 
+// PinLockEntry makes the next install use the locked chart version
+func (b *BasePlugin) PinLockEntry(entry *LockEntry) {
+	b.pinned = entry
+}
+
+// PinVersion makes the next install use the given chart version
+func (b *BasePlugin) PinVersion(version string) {
+	b.version = version
+}
+
+// SetRepoCredentials makes the next install authenticate to the chart repository
+func (b *BasePlugin) SetRepoCredentials(username, password string) {
+	b.repoUsername, b.repoPassword = username, password
+}
+
+// SetInstallerType makes the next install use the given installer, helm or argocd, instead
+// of the recorded or detected one
+func (b *BasePlugin) SetInstallerType(installerType string) {
+	b.installerType = installerType
+}
+
+// ForceNamespaceDeletion makes the next uninstall remove the finalizers of the plugin's
+// namespace if it is still terminating after the normal wait
+func (b *BasePlugin) ForceNamespaceDeletion() {
+	b.forceNamespaceDeletion = true
+}
+
+// SetCRDRetention sets whether the next uninstall keeps the plugin's CRDs and how it
+// confirms deleting CRDs still in use
+func (b *BasePlugin) SetCRDRetention(keep bool, confirm installer.ConfirmCRDDeletion) {
+	b.keepCRDs, b.confirmCRDDeletion = keep, confirm
+}
+
+// setNamespace makes the next install use namespace instead of the plugin's default one.
+// Plugins implement NamespaceOverridable through it once every reference to their
+// namespace goes through PluginNamespace.
+func (b *BasePlugin) setNamespace(namespace string) error {
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return fmt.Errorf("invalid namespace %q: %s", namespace, strings.Join(errs, ", "))
+	}
+	b.namespace = namespace
+	return nil
+}
+
+// recordedNamespace returns the namespace the plugin was installed into, if recorded
+func (b *BasePlugin) recordedNamespace(tracker *InstallerTracker) string {
+	namespace, err := tracker.GetPluginNamespace(b.plugin.GetName())
+	if err != nil {
+		logger.Warnln("Failed to get recorded namespace for %s: %v", b.plugin.GetName(), err)
+	}
+	return namespace
+}
+
+// newInstallOptions builds the install options of the plugin. The namespace is the
+// overridden one, else the one the plugin was installed into, else the plugin's default.
+func (b *BasePlugin) newInstallOptions(opt PluginOptions, kubeConfig,
+	recordedNamespace string) *installer.InstallOptions {
+	opts := newInstallOptions(b.plugin.GetName(), opt, kubeConfig)
+	switch {
+	case b.namespace != "":
+		opts.Namespace = b.namespace
+	case recordedNamespace != "":
+		opts.Namespace = recordedNamespace
+	}
+	return opts
+}
+
+// LockEntry returns the chart version and values checksum of the last install
+func (b *BasePlugin) LockEntry() *LockEntry {
+	return b.installed
+}
+
 func (b *BasePlugin) UnifiedUninstall(kubeConfig, clusterName string, ensure ...bool) error {
-	inst, err := NewInstaller(b.plugin, kubeConfig, clusterName)
+	if err := checkKubeConfig(kubeConfig); err != nil {
+		return fmt.Errorf("cannot uninstall plugin %s: %w", b.plugin.GetName(), err)
+	}
+	inst, err := NewInstaller(b.plugin, kubeConfig, clusterName, "")
 	if err != nil {
 		return fmt.Errorf("failed to create installer: %w", err)
 	}
-	opts := newInstallOptions(b.plugin, kubeConfig)
+	tracker, trackerErr := NewInstallerTracker(kubeConfig)
+	recordedNamespace := ""
+	if trackerErr == nil {
+		recordedNamespace = b.recordedNamespace(tracker)
+	}
+	opts := b.newInstallOptions(b.plugin.GetOptions(), kubeConfig, recordedNamespace)
+	opts.ForceNamespaceDeletion = b.forceNamespaceDeletion
+	opts.KeepCRDs, opts.ConfirmCRDDeletion = b.keepCRDs, b.confirmCRDDeletion
 
 	// Uninstall the plugin
 	err = inst.UnInstall(opts)
@@ -85,7 +277,8 @@ func (b *BasePlugin) UnifiedUninstall(kubeConfig, clusterName string, ensure ...
 		return err
 	}
 
-	tracker, trackerErr := NewInstallerTracker(kubeConfig)
+	b.recordEvent(kubeConfig, HistoryActionUninstall, opts, "")
+
 	if trackerErr != nil {
 		logger.Warnln("Failed to create installer tracker after uninstalling %s: %v", b.plugin.GetName(), trackerErr)
 	} else {
@@ -98,8 +291,19 @@ func (b *BasePlugin) UnifiedUninstall(kubeConfig, clusterName string, ensure ...
 	return nil
 }
 
-func newInstallOptions(plugin Plugin, kubeConfig string) *installer.InstallOptions {
-	opt := plugin.GetOptions()
+func (b *BasePlugin) recordEvent(kubeConfig, action string, opts *installer.InstallOptions, installerType string) {
+	event := HistoryEvent{
+		Action:    action,
+		Version:   opts.Version,
+		Installer: installerType,
+	}
+	if checksum, err := valuesChecksum(opts.Values); err == nil {
+		event.ValuesChecksum = checksum
+	}
+	RecordPluginEvent(kubeConfig, b.plugin.GetName(), event)
+}
+
+func newInstallOptions(name string, opt PluginOptions, kubeConfig string) *installer.InstallOptions {
 	chartName := opt.ChartName
 	version := opt.Version
 	return &installer.InstallOptions{
@@ -107,10 +311,15 @@ func newInstallOptions(plugin Plugin, kubeConfig string) *installer.InstallOptio
 		Values:           opt.ChartValues,
 		ChartName:        chartName,
 		RepoURL:          *opt.Repository,
-		ApplicationName:  plugin.GetName(),
+		ApplicationName:  name,
 		Version:          *version,
 		KubeConfig:       kubeConfig,
 		RepoName:         *opt.RepoName,
+		RepoUsername:     opt.RepoUsername,
+		RepoPassword:     opt.RepoPassword,
 		CRDsGroupVersion: opt.CRDsGroupVersion,
+		Timeout:          opt.Timeout,
+		Wait:             opt.Wait,
+		CreateNamespace:  opt.CreateNamespace == nil || *opt.CreateNamespace,
 	}
 }
@@ -43,6 +43,7 @@ func (c *CertManager) GetOptions() PluginOptions {
 		Repository:       &CertManagerRepoURL,
 		ChartValues:      c.getDefaultValues(),
 		CRDsGroupVersion: "cert-manager.io",
+		Wait:             true, // the webhook must be serving before issuers can be created
 	}
 }
 
@@ -0,0 +1,251 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mrgb7/playground/internal/k8s"
+	"github.com/mrgb7/playground/pkg/logger"
+	"github.com/mrgb7/playground/pkg/retry"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	DashboardName           = "dashboard"
+	DashboardNamespace      = "kubernetes-dashboard"
+	DashboardChartVersion   = "7.10.0"
+	DashboardChartName      = "kubernetes-dashboard"
+	DashboardReleaseName    = "kubernetes-dashboard"
+	DashboardRepoName       = "kubernetes-dashboard"
+	DashboardRepoURL        = "https://kubernetes.github.io/dashboard/"
+	DashboardProxyService   = "kubernetes-dashboard-kong-proxy"
+	DashboardServiceAccount = "playground-dashboard"
+	DashboardClusterRole    = "view"
+	DashboardTokenTimeout   = 30 * time.Second
+)
+
+const (
+	DashboardProxyPort = 443
+)
+
+type Dashboard struct {
+	KubeConfig  string
+	ClusterName string
+	*BasePlugin
+}
+
+func NewDashboard(kubeConfig, clusterName string) *Dashboard {
+	dashboard := &Dashboard{
+		KubeConfig:  kubeConfig,
+		ClusterName: clusterName,
+	}
+	dashboard.BasePlugin = NewBasePlugin(kubeConfig, dashboard)
+	return dashboard
+}
+
+func (d *Dashboard) GetName() string {
+	return DashboardName
+}
+
+// SetNamespace makes the next install put the dashboard into namespace
+func (d *Dashboard) SetNamespace(namespace string) error {
+	return d.setNamespace(namespace)
+}
+
+// dashboardNamespace returns the namespace the dashboard was installed into
+func dashboardNamespace(kubeConfig string) string {
+	return PluginNamespace(kubeConfig, DashboardName, DashboardNamespace)
+}
+
+func (d *Dashboard) GetOptions() PluginOptions {
+	return PluginOptions{
+		Version:     &DashboardChartVersion,
+		Namespace:   &DashboardNamespace,
+		ChartName:   &DashboardChartName,
+		RepoName:    &DashboardRepoName,
+		Repository:  &DashboardRepoURL,
+		releaseName: &DashboardReleaseName,
+	}
+}
+
+func (d *Dashboard) Install(kubeConfig, clusterName string, ensure ...bool) error {
+	return d.UnifiedInstall(kubeConfig, clusterName, ensure...)
+}
+
+func (d *Dashboard) Uninstall(kubeConfig, clusterName string, ensure ...bool) error {
+	if err := d.UnifiedUninstall(kubeConfig, clusterName, ensure...); err != nil {
+		return err
+	}
+
+	// the ServiceAccount and its secret go with the namespace, the binding is cluster scoped
+	c, err := k8s.NewK8sClient(kubeConfig)
+	if err != nil {
+		logger.Warnln("Failed to create k8s client to delete the dashboard role binding: %v", err)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), DashboardTokenTimeout)
+	defer cancel()
+	if err := deleteServiceAccountBinding(ctx, c.Clientset, DashboardServiceAccount); err != nil {
+		logger.Warnln("%v", err)
+	}
+	return nil
+}
+
+func (d *Dashboard) Status() string {
+	if d.KubeConfig == "" {
+		logger.Errorf("kubeConfig is empty")
+		return StatusUnknown
+	}
+
+	c, err := k8s.NewK8sClient(d.KubeConfig)
+	if err != nil {
+		logger.Debugf("failed to create k8s client: %v", err)
+		return StatusUnknown
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ns, err := c.GetNameSpace(dashboardNamespace(d.KubeConfig), ctx)
+	if ns == "" || err != nil {
+		logger.Debugf("dashboard namespace not found or error occurred: %v", err)
+		return StatusNotInstalled
+	}
+	return StatusRunning
+}
+
+func (d *Dashboard) GetDependencies() []string {
+	return []string{"nginx-ingress"} // dashboard is served through the nginx ingress controller
+}
+
+// GetDashboardToken returns a bearer token for logging into the dashboard. The token
+// belongs to a ServiceAccount bound to the read-only "view" ClusterRole, which is
+// created along with its token secret on first use.
+func (d *Dashboard) GetDashboardToken() (string, error) {
+	c, err := k8s.NewK8sClient(d.KubeConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to create k8s client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DashboardTokenTimeout)
+	defer cancel()
+
+	return getServiceAccountToken(ctx, c.Clientset, dashboardNamespace(d.KubeConfig), DashboardServiceAccount,
+		DashboardClusterRole)
+}
+
+// getServiceAccountToken reads the token of a long-lived ServiceAccount token secret,
+// creating the ServiceAccount, its ClusterRoleBinding and the secret when missing
+func getServiceAccountToken(
+	ctx context.Context,
+	cs kubernetes.Interface,
+	namespace, serviceAccount, clusterRole string,
+) (string, error) {
+	secretName := fmt.Sprintf("%s-token", serviceAccount)
+
+	secret, err := cs.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		if token := string(secret.Data[v1.ServiceAccountTokenKey]); token != "" {
+			return token, nil
+		}
+	case apierrors.IsNotFound(err):
+		if err := ensureServiceAccount(ctx, cs, namespace, serviceAccount, clusterRole); err != nil {
+			return "", err
+		}
+		if err := createServiceAccountTokenSecret(ctx, cs, namespace, serviceAccount, secretName); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("failed to get token secret %s/%s: %w", namespace, secretName, err)
+	}
+
+	var token string
+	err = retry.Do(ctx, retry.Options{Backoff: time.Second}, func() error {
+		secret, err := cs.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		token = string(secret.Data[v1.ServiceAccountTokenKey])
+		if token == "" {
+			return fmt.Errorf("token secret %s/%s has not been populated yet", namespace, secretName)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read dashboard token: %w", err)
+	}
+	return token, nil
+}
+
+func ensureServiceAccount(ctx context.Context, cs kubernetes.Interface, namespace, name, clusterRole string) error {
+	sa := &v1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+	_, err := cs.CoreV1().ServiceAccounts(namespace).Create(ctx, sa, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create service account %s/%s: %w", namespace, name, err)
+	}
+
+	binding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     clusterRole,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      name,
+				Namespace: namespace,
+			},
+		},
+	}
+	_, err = cs.RbacV1().ClusterRoleBindings().Create(ctx, binding, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to bind service account %s/%s to %s: %w", namespace, name, clusterRole, err)
+	}
+	return nil
+}
+
+// deleteServiceAccountBinding deletes the ClusterRoleBinding ensureServiceAccount created
+func deleteServiceAccountBinding(ctx context.Context, cs kubernetes.Interface, name string) error {
+	err := cs.RbacV1().ClusterRoleBindings().Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete cluster role binding %s: %w", name, err)
+	}
+	return nil
+}
+
+func createServiceAccountTokenSecret(
+	ctx context.Context,
+	cs kubernetes.Interface,
+	namespace, serviceAccount, secretName string,
+) error {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				v1.ServiceAccountNameKey: serviceAccount,
+			},
+		},
+		Type: v1.SecretTypeServiceAccountToken,
+	}
+	_, err := cs.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create token secret %s/%s: %w", namespace, secretName, err)
+	}
+	return nil
+}
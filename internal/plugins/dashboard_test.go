@@ -0,0 +1,125 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDashboardOptions(t *testing.T) {
+	dashboard := NewDashboard("test-config", "test-cluster")
+	if dashboard.BasePlugin == nil {
+		t.Fatal("BasePlugin should not be nil")
+	}
+	if dashboard.GetName() != DashboardName {
+		t.Errorf("Expected name %s, got %s", DashboardName, dashboard.GetName())
+	}
+
+	options := dashboard.GetOptions()
+	tests := []struct {
+		name     string
+		got      *string
+		expected string
+	}{
+		{name: "version", got: options.Version, expected: DashboardChartVersion},
+		{name: "namespace", got: options.Namespace, expected: DashboardNamespace},
+		{name: "chart", got: options.ChartName, expected: DashboardChartName},
+		{name: "repo name", got: options.RepoName, expected: DashboardRepoName},
+		{name: "repository", got: options.Repository, expected: DashboardRepoURL},
+		{name: "release", got: options.releaseName, expected: DashboardReleaseName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got == nil || *tt.got != tt.expected {
+				t.Errorf("Expected %s %s, got %v", tt.name, tt.expected, tt.got)
+			}
+		})
+	}
+
+	deps := dashboard.GetDependencies()
+	if len(deps) != 1 || deps[0] != "nginx-ingress" {
+		t.Errorf("Expected dashboard to depend on nginx-ingress, got %v", deps)
+	}
+
+	var _ DependencyPlugin = dashboard
+}
+
+func TestGetServiceAccountToken(t *testing.T) {
+	const (
+		namespace = "kubernetes-dashboard"
+		sa        = "playground-dashboard"
+	)
+
+	t.Run("existing token secret", func(t *testing.T) {
+		cs := fake.NewSimpleClientset(&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: sa + "-token", Namespace: namespace},
+			Type:       v1.SecretTypeServiceAccountToken,
+			Data:       map[string][]byte{v1.ServiceAccountTokenKey: []byte("secret-token")},
+		})
+
+		token, err := getServiceAccountToken(context.Background(), cs, namespace, sa, "view")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if token != "secret-token" {
+			t.Errorf("Expected token 'secret-token', got '%s'", token)
+		}
+	})
+
+	t.Run("creates service account, binding and secret", func(t *testing.T) {
+		cs := fake.NewSimpleClientset()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// the fake clientset has no token controller, so the token is never populated
+		if _, err := getServiceAccountToken(ctx, cs, namespace, sa, "view"); err == nil {
+			t.Fatal("Expected error for an unpopulated token secret")
+		}
+
+		if _, err := cs.CoreV1().ServiceAccounts(namespace).Get(context.Background(), sa, metav1.GetOptions{}); err != nil {
+			t.Errorf("Expected service account to be created: %v", err)
+		}
+
+		binding, err := cs.RbacV1().ClusterRoleBindings().Get(context.Background(), sa, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Expected cluster role binding to be created: %v", err)
+		}
+		if binding.RoleRef.Name != "view" {
+			t.Errorf("Expected binding to the view role, got '%s'", binding.RoleRef.Name)
+		}
+
+		secret, err := cs.CoreV1().Secrets(namespace).Get(context.Background(), sa+"-token", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Expected token secret to be created: %v", err)
+		}
+		if secret.Type != v1.SecretTypeServiceAccountToken {
+			t.Errorf("Expected secret type %s, got %s", v1.SecretTypeServiceAccountToken, secret.Type)
+		}
+		if secret.Annotations[v1.ServiceAccountNameKey] != sa {
+			t.Errorf("Expected secret to reference service account %s, got %v", sa, secret.Annotations)
+		}
+	})
+}
+
+func TestDeleteServiceAccountBinding(t *testing.T) {
+	const sa = "playground-dashboard"
+	cs := fake.NewSimpleClientset()
+	if err := ensureServiceAccount(context.Background(), cs, "kubernetes-dashboard", sa, "view"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := deleteServiceAccountBinding(context.Background(), cs, sa); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := cs.RbacV1().ClusterRoleBindings().Get(context.Background(), sa, metav1.GetOptions{}); err == nil {
+		t.Error("Expected the cluster role binding to be deleted")
+	}
+
+	// deleting again is a no-op, e.g. when no token was ever requested
+	if err := deleteServiceAccountBinding(context.Background(), cs, sa); err != nil {
+		t.Errorf("Expected no error for a missing binding, got %v", err)
+	}
+}
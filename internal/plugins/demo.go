@@ -0,0 +1,265 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mrgb7/playground/internal/k8s"
+	"github.com/mrgb7/playground/pkg/logger"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+var (
+	DemoName      = "demo"
+	DemoVersion   = "1.0.0"
+	DemoNamespace = "demo"
+	DemoImage     = "nginxdemos/hello:plain-text"
+	DemoReplicas  = int32(1)
+)
+
+const (
+	DemoPort = 80
+)
+
+type Demo struct {
+	KubeConfig  string
+	k8sClient   *k8s.K8sClient
+	ClusterName string
+	*BasePlugin
+}
+
+func NewDemo(kubeConfig, clusterName string) (*Demo, error) {
+	c, err := k8s.NewK8sClient(kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create k8s client: %w", err)
+	}
+	demo := &Demo{
+		KubeConfig:  kubeConfig,
+		k8sClient:   c,
+		ClusterName: clusterName,
+	}
+	demo.BasePlugin = NewBasePlugin(kubeConfig, demo)
+	return demo, nil
+}
+
+func (d *Demo) GetName() string {
+	return DemoName
+}
+
+func (d *Demo) GetOptions() PluginOptions {
+	return PluginOptions{
+		Version:   &DemoVersion,
+		Namespace: &DemoNamespace,
+	}
+}
+
+func (d *Demo) Install(kubeConfig, clusterName string, ensure ...bool) error {
+	if err := checkKubeConfig(kubeConfig); err != nil {
+		return fmt.Errorf("cannot install plugin %s: %w", d.GetName(), err)
+	}
+
+	warnUnsupportedArch(d)
+	logger.Infoln("Deploying demo application for cluster: %s", clusterName)
+
+	if err := d.createNamespace(); err != nil {
+		return fmt.Errorf("failed to create demo namespace: %w", err)
+	}
+
+	if err := d.createDeployment(); err != nil {
+		return fmt.Errorf("failed to create demo deployment: %w", err)
+	}
+
+	if err := d.createService(); err != nil {
+		return fmt.Errorf("failed to create demo service: %w", err)
+	}
+
+	if len(ensure) > 0 && ensure[0] {
+		if err := <-d.k8sClient.EnsureApp(context.Background(), DemoNamespace, d.GetName()); err != nil {
+			return fmt.Errorf("failed to ensure demo app: %w", err)
+		}
+	}
+
+	ingress, err := NewIngress(kubeConfig, clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to get ingress plugin: %w", err)
+	}
+
+	url, err := ingress.AddServiceIngress(DemoNamespace, d.GetName(), DemoPort, d.GetName(),
+		d.GetOptions().IngressRoutes...)
+	if err != nil {
+		return fmt.Errorf("failed to expose demo app: %w", err)
+	}
+
+	logger.Successln("Demo application deployed successfully")
+	logger.Infoln("")
+	logger.Infoln("🚀 Demo app will be available at: %s", url)
+	logger.Infoln("💡 Make sure demo.%s resolves to the nginx LoadBalancer IP in /etc/hosts", ingress.domain())
+	return nil
+}
+
+func (d *Demo) Uninstall(kubeConfig, clusterName string, ensure ...bool) error {
+	logger.Infoln("Removing demo application")
+
+	ingress, err := NewIngress(kubeConfig, clusterName)
+	if err != nil {
+		logger.Warnln("Failed to get ingress plugin: %v", err)
+	} else if err := ingress.RemoveServiceIngress(DemoNamespace, d.GetName()); err != nil {
+		logger.Warnln("Failed to remove demo ingress: %v", err)
+	}
+
+	if err := d.k8sClient.DeleteNamespace(DemoNamespace, d.forceNamespaceDeletion); err != nil {
+		return fmt.Errorf("failed to delete demo namespace: %w", err)
+	}
+
+	logger.Successln("Demo application removed successfully")
+	return nil
+}
+
+func (d *Demo) Status() string {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	deploy, err := d.k8sClient.Clientset.AppsV1().Deployments(DemoNamespace).Get(ctx, d.GetName(), metav1.GetOptions{})
+	if err != nil {
+		logger.Debugf("demo deployment not found or error occurred: %v", err)
+		return StatusNotInstalled
+	}
+
+	if deploy.Status.ReadyReplicas < 1 {
+		return "Demo app is not ready yet"
+	}
+	return StatusRunning
+}
+
+func (d *Demo) GetDependencies() []string {
+	return []string{IngressName} // demo is exposed through the ingress plugin
+}
+
+func (d *Demo) labels() map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":       d.GetName(),
+		"app.kubernetes.io/instance":   d.GetName(),
+		"app.kubernetes.io/managed-by": "playground",
+	}
+}
+
+func (d *Demo) createNamespace() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ns := &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: DemoNamespace,
+		},
+	}
+	_, err := d.k8sClient.Clientset.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{})
+	if err != nil && !strings.Contains(err.Error(), "already exists") {
+		return err
+	}
+	return nil
+}
+
+func (d *Demo) createDeployment() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	deploy := d.buildDeployment()
+	deployments := d.k8sClient.Clientset.AppsV1().Deployments(DemoNamespace)
+
+	_, err := deployments.Create(ctx, deploy, metav1.CreateOptions{})
+	switch {
+	case err != nil && strings.Contains(err.Error(), "already exists"):
+		existing, getErr := deployments.Get(ctx, deploy.Name, metav1.GetOptions{})
+		if getErr != nil {
+			return fmt.Errorf("failed to get existing demo deployment: %w", getErr)
+		}
+		deploy.ResourceVersion = existing.ResourceVersion
+		if _, err = deployments.Update(ctx, deploy, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update existing demo deployment: %w", err)
+		}
+		logger.Infoln("Updated existing demo deployment")
+	case err != nil:
+		return err
+	default:
+		logger.Successln("Created demo deployment successfully")
+	}
+	return nil
+}
+
+func (d *Demo) createService() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	svc := d.buildService()
+	_, err := d.k8sClient.Clientset.CoreV1().Services(DemoNamespace).Create(ctx, svc, metav1.CreateOptions{})
+	switch {
+	case err != nil && strings.Contains(err.Error(), "already exists"):
+		logger.Debugln("Demo service already exists")
+	case err != nil:
+		return err
+	default:
+		logger.Successln("Created demo service successfully")
+	}
+	return nil
+}
+
+func (d *Demo) buildDeployment() *appsv1.Deployment {
+	replicas := DemoReplicas
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      d.GetName(),
+			Namespace: DemoNamespace,
+			Labels:    d.labels(),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: d.labels(),
+			},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: d.labels(),
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  d.GetName(),
+							Image: DemoImage,
+							Ports: []v1.ContainerPort{
+								{
+									Name:          "http",
+									ContainerPort: DemoPort,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *Demo) buildService() *v1.Service {
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      d.GetName(),
+			Namespace: DemoNamespace,
+			Labels:    d.labels(),
+		},
+		Spec: v1.ServiceSpec{
+			Selector: d.labels(),
+			Ports: []v1.ServicePort{
+				{
+					Name:       "http",
+					Port:       DemoPort,
+					TargetPort: intstr.FromString("http"),
+				},
+			},
+		},
+	}
+}
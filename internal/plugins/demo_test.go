@@ -0,0 +1,81 @@
+package plugins
+
+import (
+	"testing"
+)
+
+func TestDemoPluginInterface(t *testing.T) {
+	plugin, err := NewDemo("dummy-kubeconfig", "test-cluster")
+	if err != nil {
+		t.Logf("K8s client creation failed (expected in test): %v", err)
+		return
+	}
+
+	if plugin.GetName() != DemoName {
+		t.Errorf("Expected plugin name '%s', got '%s'", DemoName, plugin.GetName())
+	}
+
+	options := plugin.GetOptions()
+	if options.Namespace == nil || *options.Namespace != DemoNamespace {
+		t.Errorf("Expected namespace '%s', got '%v'", DemoNamespace, options.Namespace)
+	}
+
+	var _ DependencyPlugin = plugin
+}
+
+func TestDemoDependencies(t *testing.T) {
+	demo := &Demo{}
+	deps := demo.GetDependencies()
+	if len(deps) != 1 || deps[0] != IngressName {
+		t.Errorf("Expected demo to depend on '%s', got %v", IngressName, deps)
+	}
+}
+
+func TestDemoBuildDeployment(t *testing.T) {
+	demo := &Demo{}
+	deploy := demo.buildDeployment()
+
+	if deploy.Namespace != DemoNamespace {
+		t.Errorf("Expected namespace '%s', got '%s'", DemoNamespace, deploy.Namespace)
+	}
+
+	if deploy.Labels["app.kubernetes.io/instance"] != DemoName {
+		t.Errorf("Expected instance label '%s' for EnsureApp, got '%s'",
+			DemoName, deploy.Labels["app.kubernetes.io/instance"])
+	}
+
+	for key, value := range deploy.Spec.Selector.MatchLabels {
+		if deploy.Spec.Template.Labels[key] != value {
+			t.Errorf("Selector label %s=%s not present on pod template", key, value)
+		}
+	}
+
+	if len(deploy.Spec.Template.Spec.Containers) != 1 {
+		t.Fatalf("Expected 1 container, got %d", len(deploy.Spec.Template.Spec.Containers))
+	}
+
+	container := deploy.Spec.Template.Spec.Containers[0]
+	if container.Image != DemoImage {
+		t.Errorf("Expected image '%s', got '%s'", DemoImage, container.Image)
+	}
+	if len(container.Ports) != 1 || container.Ports[0].ContainerPort != DemoPort {
+		t.Errorf("Expected container port %d, got %v", DemoPort, container.Ports)
+	}
+}
+
+func TestDemoBuildService(t *testing.T) {
+	demo := &Demo{}
+	svc := demo.buildService()
+
+	if svc.Name != DemoName {
+		t.Errorf("Expected service name '%s', got '%s'", DemoName, svc.Name)
+	}
+
+	if len(svc.Spec.Ports) != 1 || svc.Spec.Ports[0].Port != DemoPort {
+		t.Errorf("Expected service port %d, got %v", DemoPort, svc.Spec.Ports)
+	}
+
+	if svc.Spec.Ports[0].TargetPort.StrVal != "http" {
+		t.Errorf("Expected target port 'http', got '%s'", svc.Spec.Ports[0].TargetPort.String())
+	}
+}
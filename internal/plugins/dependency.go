@@ -2,6 +2,8 @@ package plugins
 
 import (
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/mrgb7/playground/pkg/logger"
@@ -72,11 +74,9 @@ func (dg *DependencyGraph) GetInstallOrder(targetPlugins []string) ([]string, er
 		return []string{}, nil
 	}
 
-	required := make(map[string]bool)
-	for _, plugin := range targetPlugins {
-		if err := dg.collectDependencies(plugin, required); err != nil {
-			return nil, err
-		}
+	required, err := dg.collectRequired(targetPlugins)
+	if err != nil {
+		return nil, err
 	}
 
 	plugins := make([]string, 0, len(required))
@@ -87,6 +87,52 @@ func (dg *DependencyGraph) GetInstallOrder(targetPlugins []string) ([]string, er
 	return dg.topologicalSort(plugins)
 }
 
+// GetInstallLevels groups the target plugins and their dependencies by topological level.
+// Plugins of a level only depend on plugins of earlier levels, so a level can be installed
+// concurrently once the previous one is done. Plugins within a level are sorted by name.
+func (dg *DependencyGraph) GetInstallLevels(targetPlugins []string) ([][]string, error) {
+	if len(targetPlugins) == 0 {
+		return [][]string{}, nil
+	}
+
+	required, err := dg.collectRequired(targetPlugins)
+	if err != nil {
+		return nil, err
+	}
+
+	depths := make(map[string]int, len(required))
+	levels := make([][]string, 0)
+	for plugin := range required {
+		depth := dg.installDepth(plugin, depths)
+		for len(levels) <= depth {
+			levels = append(levels, []string{})
+		}
+		levels[depth] = append(levels[depth], plugin)
+	}
+
+	for _, level := range levels {
+		sort.Strings(level)
+	}
+	return levels, nil
+}
+
+// installDepth returns the length of the longest dependency chain below plugin. The
+// dependencies must already be checked for cycles.
+func (dg *DependencyGraph) installDepth(plugin string, depths map[string]int) int {
+	if depth, ok := depths[plugin]; ok {
+		return depth
+	}
+
+	depth := 0
+	if node := dg.nodes[plugin]; node != nil {
+		for _, dep := range node.Dependencies {
+			depth = max(depth, dg.installDepth(dep, depths)+1)
+		}
+	}
+	depths[plugin] = depth
+	return depth
+}
+
 func (dg *DependencyGraph) GetUninstallOrder(targetPlugins []string) ([]string, error) {
 	if len(targetPlugins) == 0 {
 		return []string{}, nil
@@ -135,8 +181,7 @@ func (dg *DependencyGraph) ValidateInstall(pluginName string, installedPlugins [
 	}
 
 	if len(missingDeps) > 0 {
-		return fmt.Errorf("plugin '%s' has unmet dependencies: %s",
-			pluginName, strings.Join(missingDeps, ", "))
+		return &MissingDependencyError{Plugin: pluginName, Missing: missingDeps}
 	}
 
 	return nil
@@ -161,8 +206,7 @@ func (dg *DependencyGraph) ValidateUninstall(pluginName string, installedPlugins
 	}
 
 	if len(blockers) > 0 {
-		return fmt.Errorf("cannot uninstall '%s': the following installed plugins depend on it: %s",
-			pluginName, strings.Join(blockers, ", "))
+		return &DependentBlockError{Plugin: pluginName, Blockers: blockers}
 	}
 
 	return nil
@@ -185,31 +229,82 @@ func (dg *DependencyGraph) GetDependents(pluginName string) []string {
 }
 
 func (dg *DependencyGraph) HasCycles() bool {
+	_, found := dg.FindCycle()
+	return found
+}
+
+// FindCycle returns the plugins along a dependency cycle, starting and ending with the
+// same plugin, e.g. A, B, C, A. A plugin depending on itself gives A, A.
+func (dg *DependencyGraph) FindCycle() ([]string, bool) {
+	names := make([]string, 0, len(dg.nodes))
+	for name := range dg.nodes {
+		names = append(names, name)
+	}
+	cycle := dg.findCycle(names)
+	return cycle, cycle != nil
+}
+
+// findCycle looks for a cycle among the dependencies reachable from start
+func (dg *DependencyGraph) findCycle(start []string) []string {
+	start = slices.Clone(start)
+	sort.Strings(start)
 	visited := make(map[string]bool)
-	recStack := make(map[string]bool)
+	for _, plugin := range start {
+		if visited[plugin] {
+			continue
+		}
+		if cycle := dg.findCycleDFS(plugin, visited, nil); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
 
-	for pluginName := range dg.nodes {
-		if !visited[pluginName] {
-			if dg.hasCyclesDFS(pluginName, visited, recStack) {
-				return true
+func (dg *DependencyGraph) findCycleDFS(plugin string, visited map[string]bool, path []string) []string {
+	if i := slices.Index(path, plugin); i >= 0 {
+		return append(slices.Clone(path[i:]), plugin)
+	}
+	if visited[plugin] {
+		return nil
+	}
+	visited[plugin] = true
+
+	path = append(path, plugin)
+	if node := dg.nodes[plugin]; node != nil {
+		for _, dep := range node.Dependencies {
+			if cycle := dg.findCycleDFS(dep, visited, path); cycle != nil {
+				return cycle
 			}
 		}
 	}
+	return nil
+}
 
-	return false
+// collectRequired returns the target plugins together with all their transitive dependencies
+func (dg *DependencyGraph) collectRequired(targetPlugins []string) (map[string]bool, error) {
+	required := make(map[string]bool)
+	for _, plugin := range targetPlugins {
+		if err := dg.collectDependencies(plugin, required); err != nil {
+			return nil, err
+		}
+	}
+	return required, nil
 }
 
 func (dg *DependencyGraph) collectDependencies(pluginName string, collected map[string]bool) error {
-	return dg.collectDependenciesWithStack(pluginName, collected, make(map[string]bool))
+	return dg.collectDependenciesWithPath(pluginName, collected, nil)
 }
 
-func (dg *DependencyGraph) collectDependenciesWithStack(pluginName string, collected, stack map[string]bool) error {
+// collectDependenciesWithPath collects the dependencies of pluginName, path holds the
+// plugins that depend on it in the current walk
+func (dg *DependencyGraph) collectDependenciesWithPath(pluginName string, collected map[string]bool,
+	path []string) error {
 	if collected[pluginName] {
 		return nil
 	}
 
-	if stack[pluginName] {
-		return fmt.Errorf("circular dependency detected involving plugin '%s'", pluginName)
+	if i := slices.Index(path, pluginName); i >= 0 {
+		return &CycleError{Cycle: append(slices.Clone(path[i:]), pluginName)}
 	}
 
 	node := dg.nodes[pluginName]
@@ -217,15 +312,13 @@ func (dg *DependencyGraph) collectDependenciesWithStack(pluginName string, colle
 		return fmt.Errorf("plugin '%s' not found", pluginName)
 	}
 
-	stack[pluginName] = true
-
+	path = append(path, pluginName)
 	for _, dep := range node.Dependencies {
-		if err := dg.collectDependenciesWithStack(dep, collected, stack); err != nil {
+		if err := dg.collectDependenciesWithPath(dep, collected, path); err != nil {
 			return err
 		}
 	}
 
-	stack[pluginName] = false
 	collected[pluginName] = true
 	return nil
 }
@@ -298,35 +391,16 @@ func (dg *DependencyGraph) topologicalSort(plugins []string) ([]string, error) {
 	}
 
 	if len(result) != len(plugins) {
-		return nil, fmt.Errorf("circular dependency detected")
+		return nil, &CycleError{Cycle: dg.findCycle(plugins)}
 	}
 
 	return result, nil
 }
 
-func (dg *DependencyGraph) hasCyclesDFS(plugin string, visited, recStack map[string]bool) bool {
-	visited[plugin] = true
-	recStack[plugin] = true
-
-	node := dg.nodes[plugin]
-	if node != nil {
-		for _, dep := range node.Dependencies {
-			if !visited[dep] {
-				if dg.hasCyclesDFS(dep, visited, recStack) {
-					return true
-				}
-			} else if recStack[dep] {
-				return true
-			}
-		}
-	}
-
-	recStack[plugin] = false
-	return false
-}
-
 type DependencyValidator struct {
 	graph *DependencyGraph
+	// cycle is a dependency cycle of the graph, found once when the validator is created
+	cycle []string
 }
 
 func NewDependencyValidator(plugins []DependencyPlugin) *DependencyValidator {
@@ -336,18 +410,33 @@ func NewDependencyValidator(plugins []DependencyPlugin) *DependencyValidator {
 		graph.AddPlugin(plugin)
 	}
 
-	if graph.HasCycles() {
-		logger.Error("Circular dependency detected in plugin graph")
+	cycle, found := graph.FindCycle()
+	if found {
+		logger.Errorln("Circular dependency detected in plugin graph: %s", strings.Join(cycle, " -> "))
 	}
 
 	return &DependencyValidator{
 		graph: graph,
+		cycle: cycle,
 	}
 }
 
+// checkCycle fails validation when the plugin graph has a dependency cycle, naming the
+// plugins along it
+func (dv *DependencyValidator) checkCycle() error {
+	if dv.cycle != nil {
+		return &CycleError{Cycle: dv.cycle}
+	}
+	return nil
+}
+
 func (dv *DependencyValidator) ValidateInstallation(targetPlugins []string, installedPlugins []string) ([]string, error) {
 	logger.Infoln("Validating plugin installation dependencies...")
 
+	if err := dv.checkCycle(); err != nil {
+		return nil, err
+	}
+
 	installOrder, err := dv.graph.GetInstallOrder(targetPlugins)
 	if err != nil {
 		return nil, fmt.Errorf("failed to determine install order: %w", err)
@@ -374,9 +463,58 @@ func (dv *DependencyValidator) ValidateInstallation(targetPlugins []string, inst
 	return needsInstallation, nil
 }
 
+// ValidateInstallationLevels is ValidateInstallation with the plugins to install grouped
+// by install level. Installed plugins are left out and so are levels left empty by that.
+func (dv *DependencyValidator) ValidateInstallationLevels(targetPlugins []string,
+	installedPlugins []string) ([][]string, error) {
+	logger.Infoln("Validating plugin installation dependencies...")
+
+	if err := dv.checkCycle(); err != nil {
+		return nil, err
+	}
+
+	levels, err := dv.graph.GetInstallLevels(targetPlugins)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine install levels: %w", err)
+	}
+
+	installedSet := make(map[string]bool)
+	for _, p := range installedPlugins {
+		installedSet[p] = true
+	}
+
+	needsInstallation := make([][]string, 0, len(levels))
+	for _, level := range levels {
+		pending := make([]string, 0, len(level))
+		for _, plugin := range level {
+			if installedSet[plugin] {
+				continue
+			}
+			if err := dv.graph.ValidateInstall(plugin, installedPlugins); err != nil {
+				return nil, err
+			}
+			pending = append(pending, plugin)
+		}
+		for _, plugin := range pending {
+			installedPlugins = append(installedPlugins, plugin)
+			installedSet[plugin] = true
+		}
+		if len(pending) > 0 {
+			needsInstallation = append(needsInstallation, pending)
+		}
+	}
+
+	logger.Successln("Dependency validation passed")
+	return needsInstallation, nil
+}
+
 func (dv *DependencyValidator) ValidateUninstallation(targetPlugins []string, installedPlugins []string) ([]string, error) {
 	logger.Infoln("Validating plugin uninstallation dependencies...")
 
+	if err := dv.checkCycle(); err != nil {
+		return nil, err
+	}
+
 	uninstallOrder, err := dv.graph.GetUninstallOrder(targetPlugins)
 	if err != nil {
 		return nil, fmt.Errorf("failed to determine uninstall order: %w", err)
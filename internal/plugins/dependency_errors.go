@@ -0,0 +1,41 @@
+package plugins
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MissingDependencyError is returned when a plugin is installed before its dependencies
+type MissingDependencyError struct {
+	Plugin  string
+	Missing []string
+}
+
+func (e *MissingDependencyError) Error() string {
+	return fmt.Sprintf("plugin '%s' has unmet dependencies: %s", e.Plugin, strings.Join(e.Missing, ", "))
+}
+
+// DependentBlockError is returned when a plugin is uninstalled while installed plugins
+// still depend on it
+type DependentBlockError struct {
+	Plugin   string
+	Blockers []string
+}
+
+func (e *DependentBlockError) Error() string {
+	return fmt.Sprintf("cannot uninstall '%s': the following installed plugins depend on it: %s",
+		e.Plugin, strings.Join(e.Blockers, ", "))
+}
+
+// CycleError is returned when plugins depend on each other in a cycle. Cycle lists the
+// plugins along the cycle, starting and ending with the same plugin.
+type CycleError struct {
+	Cycle []string
+}
+
+func (e *CycleError) Error() string {
+	if len(e.Cycle) == 0 {
+		return "circular dependency detected"
+	}
+	return fmt.Sprintf("circular dependency detected: %s", strings.Join(e.Cycle, " -> "))
+}
@@ -0,0 +1,145 @@
+package plugins
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Subgraph returns a graph holding only pluginName with its transitive dependencies and dependents
+func (dg *DependencyGraph) Subgraph(pluginName string) *DependencyGraph {
+	included := make(map[string]bool)
+	dg.walk(pluginName, included, func(n *GraphNode) []string { return n.Dependencies })
+	delete(included, pluginName)
+	dg.walk(pluginName, included, func(n *GraphNode) []string { return n.Dependents })
+
+	sub := NewDependencyGraph()
+	for name := range included {
+		node := dg.nodes[name]
+		sub.nodes[name] = &GraphNode{
+			Plugin:       node.Plugin,
+			Dependencies: filterNames(node.Dependencies, included),
+			Dependents:   filterNames(node.Dependents, included),
+		}
+	}
+	return sub
+}
+
+// walk marks every node reachable from name through next, tolerating cycles
+func (dg *DependencyGraph) walk(name string, seen map[string]bool, next func(*GraphNode) []string) {
+	node := dg.nodes[name]
+	if node == nil || seen[name] {
+		return
+	}
+	seen[name] = true
+	for _, n := range next(node) {
+		dg.walk(n, seen, next)
+	}
+}
+
+func filterNames(names []string, keep map[string]bool) []string {
+	filtered := make([]string, 0, len(names))
+	for _, name := range names {
+		if keep[name] {
+			filtered = append(filtered, name)
+		}
+	}
+	return filtered
+}
+
+func (dg *DependencyGraph) sortedNames() []string {
+	names := make([]string, 0, len(dg.nodes))
+	for name := range dg.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Tree renders the graph as an ASCII tree starting from the plugins nothing depends on,
+// each followed by its dependencies. Dependencies that are not registered plugins and
+// edges closing a cycle are marked.
+func (dg *DependencyGraph) Tree() string {
+	var b strings.Builder
+	if cycle, found := dg.FindCycle(); found {
+		fmt.Fprintf(&b, "WARNING: circular dependency detected: %s\n", strings.Join(cycle, " -> "))
+	}
+
+	printed := make(map[string]bool)
+	var roots []string
+	for _, name := range dg.sortedNames() {
+		if len(dg.nodes[name].Dependents) == 0 {
+			roots = append(roots, name)
+		}
+	}
+
+	for _, root := range roots {
+		dg.writeTree(&b, root, "", "", map[string]bool{}, printed)
+	}
+	// plugins only reachable through a cycle have no root, print them on their own
+	for _, name := range dg.sortedNames() {
+		if !printed[name] {
+			dg.writeTree(&b, name, "", "", map[string]bool{}, printed)
+		}
+	}
+	return b.String()
+}
+
+func (dg *DependencyGraph) writeTree(b *strings.Builder, name, branch, indent string, path, printed map[string]bool) {
+	node := dg.nodes[name]
+	label := name
+	switch {
+	case path[name]:
+		b.WriteString(branch + label + " (cycle)\n")
+		return
+	case node == nil || node.Plugin == nil:
+		label += " (not registered)"
+	}
+	b.WriteString(branch + label + "\n")
+	printed[name] = true
+	if node == nil {
+		return
+	}
+
+	path[name] = true
+	defer delete(path, name)
+
+	deps := append([]string(nil), node.Dependencies...)
+	sort.Strings(deps)
+	for i, dep := range deps {
+		if i == len(deps)-1 {
+			dg.writeTree(b, dep, indent+"└── ", indent+"    ", path, printed)
+		} else {
+			dg.writeTree(b, dep, indent+"├── ", indent+"│   ", path, printed)
+		}
+	}
+}
+
+// DOT renders the graph in Graphviz DOT format with an edge from each plugin to its dependencies
+func (dg *DependencyGraph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph plugins {\n")
+	b.WriteString("  rankdir=LR;\n")
+	if dg.HasCycles() {
+		b.WriteString("  label=\"circular dependency detected\";\n")
+		b.WriteString("  fontcolor=red;\n")
+	}
+
+	names := dg.sortedNames()
+	for _, name := range names {
+		if dg.nodes[name].Plugin == nil {
+			fmt.Fprintf(&b, "  %q [style=dashed];\n", name)
+		} else {
+			fmt.Fprintf(&b, "  %q;\n", name)
+		}
+	}
+	for _, name := range names {
+		deps := append([]string(nil), dg.nodes[name].Dependencies...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			fmt.Fprintf(&b, "  %q -> %q;\n", name, dep)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package plugins
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newRenderTestGraph() *DependencyGraph {
+	graph := NewDependencyGraph()
+	graph.AddPlugin(&MockDependencyPlugin{name: "cert-manager"})
+	graph.AddPlugin(&MockDependencyPlugin{name: "tls", dependencies: []string{"cert-manager"}})
+	graph.AddPlugin(&MockDependencyPlugin{name: "ingress", dependencies: []string{"tls", "nginx"}})
+	graph.AddPlugin(&MockDependencyPlugin{name: "demo", dependencies: []string{"ingress"}})
+	graph.AddPlugin(&MockDependencyPlugin{name: "argocd"})
+	return graph
+}
+
+func TestDependencyGraph_DOT(t *testing.T) {
+	expected := `digraph plugins {
+  rankdir=LR;
+  "argocd";
+  "cert-manager";
+  "demo";
+  "ingress";
+  "nginx" [style=dashed];
+  "tls";
+  "demo" -> "ingress";
+  "ingress" -> "nginx";
+  "ingress" -> "tls";
+  "tls" -> "cert-manager";
+}
+`
+	if got := newRenderTestGraph().DOT(); got != expected {
+		t.Errorf("Unexpected DOT output:\n%s\nexpected:\n%s", got, expected)
+	}
+
+	cyclic := NewDependencyGraph()
+	cyclic.AddPlugin(&MockDependencyPlugin{name: "A", dependencies: []string{"B"}})
+	cyclic.AddPlugin(&MockDependencyPlugin{name: "B", dependencies: []string{"A"}})
+	if !strings.Contains(cyclic.DOT(), `label="circular dependency detected"`) {
+		t.Errorf("Expected DOT output to flag the cycle, got:\n%s", cyclic.DOT())
+	}
+}
+
+func TestDependencyGraph_Tree(t *testing.T) {
+	expected := `argocd
+demo
+└── ingress
+    ├── nginx (not registered)
+    └── tls
+        └── cert-manager
+`
+	if got := newRenderTestGraph().Tree(); got != expected {
+		t.Errorf("Unexpected tree output:\n%s\nexpected:\n%s", got, expected)
+	}
+
+	cyclic := NewDependencyGraph()
+	cyclic.AddPlugin(&MockDependencyPlugin{name: "A", dependencies: []string{"B"}})
+	cyclic.AddPlugin(&MockDependencyPlugin{name: "B", dependencies: []string{"A"}})
+	expectedCycle := `WARNING: circular dependency detected: A -> B -> A
+A
+└── B
+    └── A (cycle)
+`
+	if got := cyclic.Tree(); got != expectedCycle {
+		t.Errorf("Unexpected tree output for cycle:\n%s\nexpected:\n%s", got, expectedCycle)
+	}
+}
+
+func TestDependencyGraph_Subgraph(t *testing.T) {
+	sub := newRenderTestGraph().Subgraph("tls")
+
+	names := sub.sortedNames()
+	if !reflect.DeepEqual(names, []string{"cert-manager", "demo", "ingress", "tls"}) {
+		t.Errorf("Expected tls with its dependencies and dependents, got %v", names)
+	}
+	if !reflect.DeepEqual(sub.GetDependencies("ingress"), []string{"tls"}) {
+		t.Errorf("Expected edges outside the subgraph to be dropped, got %v", sub.GetDependencies("ingress"))
+	}
+
+	expected := `demo
+└── ingress
+    └── tls
+        └── cert-manager
+`
+	if got := sub.Tree(); got != expected {
+		t.Errorf("Unexpected subgraph tree:\n%s\nexpected:\n%s", got, expected)
+	}
+
+	if len(newRenderTestGraph().Subgraph("unknown").nodes) != 0 {
+		t.Error("Expected an empty subgraph for an unknown plugin")
+	}
+}
@@ -1,6 +1,7 @@
 package plugins
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -371,3 +372,201 @@ func TestMultiplePluginInstallation(t *testing.T) {
 		t.Errorf("D should be last in install order, got index %d", dIndex)
 	}
 }
+
+func TestDependencyGraph_GetInstallLevels(t *testing.T) {
+	graph := NewDependencyGraph()
+	for _, plugin := range []DependencyPlugin{
+		&MockDependencyPlugin{name: "argocd", dependencies: []string{}},
+		&MockDependencyPlugin{name: "cert-manager", dependencies: []string{}},
+		&MockDependencyPlugin{name: "load-balancer", dependencies: []string{}},
+		&MockDependencyPlugin{name: "nginx-ingress", dependencies: []string{"load-balancer"}},
+		&MockDependencyPlugin{name: "tls", dependencies: []string{"cert-manager"}},
+		&MockDependencyPlugin{name: "ingress", dependencies: []string{"tls", "nginx-ingress", "load-balancer"}},
+	} {
+		graph.AddPlugin(plugin)
+	}
+
+	tests := []struct {
+		name     string
+		targets  []string
+		expected [][]string
+	}{
+		{"no targets", nil, [][]string{}},
+		{"independent plugin", []string{"cert-manager"}, [][]string{{"cert-manager"}}},
+		{"independent plugins share a level", []string{"cert-manager", "load-balancer", "argocd"},
+			[][]string{{"argocd", "cert-manager", "load-balancer"}}},
+		{"ingress with tls", []string{"ingress"}, [][]string{
+			{"cert-manager", "load-balancer"},
+			{"nginx-ingress", "tls"},
+			{"ingress"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			levels, err := graph.GetInstallLevels(tt.targets)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(levels, tt.expected) {
+				t.Errorf("expected levels %v, got %v", tt.expected, levels)
+			}
+		})
+	}
+
+	if _, err := graph.GetInstallLevels([]string{"unknown"}); err == nil {
+		t.Error("expected an error for an unknown plugin")
+	}
+}
+
+func TestDependencyValidator_ValidateInstallationLevels(t *testing.T) {
+	validator := NewDependencyValidator([]DependencyPlugin{
+		&MockDependencyPlugin{name: "cert-manager", dependencies: []string{}},
+		&MockDependencyPlugin{name: "load-balancer", dependencies: []string{}},
+		&MockDependencyPlugin{name: "nginx-ingress", dependencies: []string{"load-balancer"}},
+		&MockDependencyPlugin{name: "tls", dependencies: []string{"cert-manager"}},
+		&MockDependencyPlugin{name: "ingress", dependencies: []string{"tls", "nginx-ingress", "load-balancer"}},
+	})
+
+	levels, err := validator.ValidateInstallationLevels([]string{"ingress"}, []string{"load-balancer", "nginx-ingress"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := [][]string{{"cert-manager"}, {"tls"}, {"ingress"}}
+	if !reflect.DeepEqual(levels, expected) {
+		t.Errorf("expected levels %v, got %v", expected, levels)
+	}
+
+	levels, err = validator.ValidateInstallationLevels([]string{"tls"}, []string{"cert-manager", "tls"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(levels) != 0 {
+		t.Errorf("expected nothing to install, got %v", levels)
+	}
+}
+
+func TestDependencyErrors(t *testing.T) {
+	graph := NewDependencyGraph()
+	graph.AddPlugin(&MockDependencyPlugin{name: "load-balancer", dependencies: []string{}})
+	graph.AddPlugin(&MockDependencyPlugin{name: "nginx-ingress", dependencies: []string{"load-balancer"}})
+
+	err := graph.ValidateInstall("nginx-ingress", nil)
+	var missing *MissingDependencyError
+	if !errors.As(err, &missing) {
+		t.Fatalf("expected a MissingDependencyError, got %v", err)
+	}
+	if missing.Plugin != "nginx-ingress" || !reflect.DeepEqual(missing.Missing, []string{"load-balancer"}) {
+		t.Errorf("unexpected error fields: %+v", missing)
+	}
+	if err.Error() != "plugin 'nginx-ingress' has unmet dependencies: load-balancer" {
+		t.Errorf("unexpected message: %s", err)
+	}
+
+	err = graph.ValidateUninstall("load-balancer", []string{"load-balancer", "nginx-ingress"})
+	var blocked *DependentBlockError
+	if !errors.As(err, &blocked) {
+		t.Fatalf("expected a DependentBlockError, got %v", err)
+	}
+	if blocked.Plugin != "load-balancer" || !reflect.DeepEqual(blocked.Blockers, []string{"nginx-ingress"}) {
+		t.Errorf("unexpected error fields: %+v", blocked)
+	}
+	expected := "cannot uninstall 'load-balancer': the following installed plugins depend on it: nginx-ingress"
+	if err.Error() != expected {
+		t.Errorf("unexpected message: %s", err)
+	}
+
+	cyclic := NewDependencyValidator([]DependencyPlugin{
+		&MockDependencyPlugin{name: "A", dependencies: []string{"B"}},
+		&MockDependencyPlugin{name: "B", dependencies: []string{"A"}},
+	})
+	_, err = cyclic.ValidateInstallation([]string{"A"}, nil)
+	var cycle *CycleError
+	if !errors.As(err, &cycle) {
+		t.Fatalf("expected a CycleError, got %v", err)
+	}
+	if !reflect.DeepEqual(cycle.Cycle, []string{"A", "B", "A"}) {
+		t.Errorf("expected cycle A -> B -> A, got %v", cycle.Cycle)
+	}
+}
+
+func TestDependencyGraph_FindCycle(t *testing.T) {
+	tests := []struct {
+		name    string
+		plugins []DependencyPlugin
+		cycle   []string
+	}{
+		{
+			name: "no cycle",
+			plugins: []DependencyPlugin{
+				&MockDependencyPlugin{name: "A", dependencies: []string{"B"}},
+				&MockDependencyPlugin{name: "B", dependencies: []string{"C"}},
+				&MockDependencyPlugin{name: "C", dependencies: []string{}},
+			},
+		},
+		{
+			name: "multi-node cycle",
+			plugins: []DependencyPlugin{
+				&MockDependencyPlugin{name: "A", dependencies: []string{"B"}},
+				&MockDependencyPlugin{name: "B", dependencies: []string{"C"}},
+				&MockDependencyPlugin{name: "C", dependencies: []string{"A"}},
+				&MockDependencyPlugin{name: "D", dependencies: []string{"A"}},
+			},
+			cycle: []string{"A", "B", "C", "A"},
+		},
+		{
+			name: "self-loop",
+			plugins: []DependencyPlugin{
+				&MockDependencyPlugin{name: "A", dependencies: []string{}},
+				&MockDependencyPlugin{name: "B", dependencies: []string{"A", "B"}},
+			},
+			cycle: []string{"B", "B"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := NewDependencyGraph()
+			for _, p := range tt.plugins {
+				graph.AddPlugin(p)
+			}
+
+			cycle, found := graph.FindCycle()
+			if found != (tt.cycle != nil) {
+				t.Errorf("expected found to be %v, got %v", tt.cycle != nil, found)
+			}
+			if !reflect.DeepEqual(cycle, tt.cycle) {
+				t.Errorf("expected cycle %v, got %v", tt.cycle, cycle)
+			}
+			if graph.HasCycles() != found {
+				t.Errorf("expected HasCycles to agree with FindCycle")
+			}
+		})
+	}
+}
+
+func TestDependencyValidator_ReportsCyclePath(t *testing.T) {
+	validator := NewDependencyValidator([]DependencyPlugin{
+		&MockDependencyPlugin{name: "A", dependencies: []string{"B"}},
+		&MockDependencyPlugin{name: "B", dependencies: []string{"C"}},
+		&MockDependencyPlugin{name: "C", dependencies: []string{"A"}},
+		&MockDependencyPlugin{name: "D", dependencies: []string{}},
+	})
+
+	// The cycle is reported even when the plugins being validated are not part of it
+	_, installErr := validator.ValidateInstallation([]string{"D"}, nil)
+	_, levelsErr := validator.ValidateInstallationLevels([]string{"D"}, nil)
+	_, uninstallErr := validator.ValidateUninstallation([]string{"D"}, []string{"D"})
+
+	expected := "circular dependency detected: A -> B -> C -> A"
+	for _, err := range []error{installErr, levelsErr, uninstallErr} {
+		var cycle *CycleError
+		if !errors.As(err, &cycle) {
+			t.Errorf("expected a CycleError, got %v", err)
+			continue
+		}
+		if err.Error() != expected {
+			t.Errorf("expected %q, got %q", expected, err.Error())
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package plugins
+
+import (
+	"github.com/mrgb7/playground/internal/state"
+	"github.com/mrgb7/playground/pkg/logger"
+)
+
+// loadClusterDomain returns the domain recorded in the state of a cluster created with
+// --domain, or empty for the default one
+var loadClusterDomain = func(clusterName string) string {
+	if clusterName == "" {
+		return ""
+	}
+	st, err := state.Load(clusterName)
+	if err != nil {
+		logger.Debugln("Failed to load the state of cluster %s: %v", clusterName, err)
+		return ""
+	}
+	return st.Domain
+}
+
+// clusterDomain returns the domain the hostnames of a cluster are served under: domain
+// when set, <cluster>.local otherwise
+func clusterDomain(clusterName, domain string) string {
+	if domain != "" {
+		return domain
+	}
+	return clusterName + ".local"
+}
+
+func (i *Ingress) domain() string {
+	return clusterDomain(i.ClusterName, i.Domain)
+}
+
+func (t *TLS) domain() string {
+	return clusterDomain(t.ClusterName, t.Domain)
+}
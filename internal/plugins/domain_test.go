@@ -0,0 +1,86 @@
+package plugins
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/mrgb7/playground/internal/k8s"
+	"github.com/mrgb7/playground/internal/state"
+	"github.com/mrgb7/playground/types"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestClusterDomain(t *testing.T) {
+	if got := clusterDomain("dev", ""); got != "dev.local" {
+		t.Errorf("expected the default domain dev.local, got %s", got)
+	}
+	if got := clusterDomain("dev", "dev.example.com"); got != "dev.example.com" {
+		t.Errorf("expected the custom domain dev.example.com, got %s", got)
+	}
+}
+
+func TestLoadClusterDomain(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	if err := state.Save(types.ClusterConfig{Name: "dev", Domain: "dev.example.com"}); err != nil {
+		t.Fatalf("failed to save state: %v", err)
+	}
+	if got := loadClusterDomain("dev"); got != "dev.example.com" {
+		t.Errorf("expected the recorded domain dev.example.com, got %q", got)
+	}
+	if got := loadClusterDomain("other"); got != "" {
+		t.Errorf("expected no domain for a cluster without state, got %q", got)
+	}
+}
+
+func TestCustomDomainHostnames(t *testing.T) {
+	dynamic := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), issuerListKinds)
+	ingress := &Ingress{ClusterName: "dev", Domain: "dev.example.com", k8sClient: &k8s.K8sClient{Dynamic: dynamic}}
+
+	if endpoint := ingress.endpoint("argocd", "argocd", ArgocdNamespace); endpoint.URL != "http://argocd.dev.example.com" {
+		t.Errorf("expected the endpoint to use the custom domain, got %s", endpoint.URL)
+	}
+
+	var hosts []string
+	for _, rule := range ingress.serviceIngressRules("grafana", 3000, "grafana", []IngressRoute{{Subdomain: "metrics"}}) {
+		hosts = append(hosts, rule.Host)
+	}
+	if expected := []string{"grafana.dev.example.com", "metrics.dev.example.com"}; !reflect.DeepEqual(hosts, expected) {
+		t.Errorf("expected hosts %v, got %v", expected, hosts)
+	}
+}
+
+func TestCustomDomainCertificates(t *testing.T) {
+	tls := &TLS{ClusterName: "dev", Domain: "dev.example.com"}
+
+	certPEM, _, err := tls.generateCACertificate()
+	if err != nil {
+		t.Fatalf("generateCACertificate: %v", err)
+	}
+	block, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse the CA certificate: %v", err)
+	}
+	for _, name := range []string{"*.dev.example.com", "dev.example.com", "argocd.dev.example.com"} {
+		if !slices.Contains(cert.DNSNames, name) {
+			t.Errorf("expected SAN %s in %v", name, cert.DNSNames)
+		}
+	}
+	if slices.Contains(cert.DNSNames, "dev.local") {
+		t.Errorf("expected no SAN for the default domain, got %v", cert.DNSNames)
+	}
+
+	wildcard := tls.buildWildcardCertificate("argocd")
+	dnsNames, _, _ := unstructured.NestedStringSlice(wildcard.Object, "spec", "dnsNames")
+	if expected := []string{"*.dev.example.com", "dev.example.com"}; !reflect.DeepEqual(dnsNames, expected) {
+		t.Errorf("expected wildcard DNS names %v, got %v", expected, dnsNames)
+	}
+}
@@ -0,0 +1,60 @@
+package plugins
+
+import (
+	"fmt"
+
+	"github.com/mrgb7/playground/pkg/logger"
+)
+
+const (
+	ProtocolHTTP  = "http"
+	ProtocolHTTPS = "https"
+)
+
+// Endpoint is an address a plugin's service can be reached at
+type Endpoint struct {
+	Name     string
+	URL      string
+	Protocol string
+}
+
+// EndpointProvider is implemented by plugins exposing services through the ingress plugin
+type EndpointProvider interface {
+	GetEndpoints(clusterName string) []Endpoint
+}
+
+// endpoint returns the endpoint of a service the ingress plugin exposes at
+// <subdomain>.<domain>, served over https when TLS is available for namespace
+func (i *Ingress) endpoint(name, subdomain, namespace string) Endpoint {
+	protocol := ProtocolHTTP
+	if i.findTLSIssuer(namespace) != nil {
+		protocol = ProtocolHTTPS
+	}
+	return Endpoint{
+		Name:     name,
+		URL:      fmt.Sprintf("%s://%s.%s", protocol, subdomain, i.domain()),
+		Protocol: protocol,
+	}
+}
+
+// ingressEndpoints returns the endpoint of a plugin service exposed by the ingress plugin
+func ingressEndpoints(kubeConfig, clusterName, name, namespace string) []Endpoint {
+	ingress, err := NewIngress(kubeConfig, clusterName)
+	if err != nil {
+		logger.Debugln("Failed to get ingress plugin: %v", err)
+		return nil
+	}
+	return []Endpoint{ingress.endpoint(name, name, namespace)}
+}
+
+func (a *Argocd) GetEndpoints(clusterName string) []Endpoint {
+	return ingressEndpoints(a.KubeConfig, clusterName, a.GetName(), ArgoCDNamespace(a.KubeConfig))
+}
+
+func (d *Dashboard) GetEndpoints(clusterName string) []Endpoint {
+	return ingressEndpoints(d.KubeConfig, clusterName, DashboardName, dashboardNamespace(d.KubeConfig))
+}
+
+func (d *Demo) GetEndpoints(clusterName string) []Endpoint {
+	return ingressEndpoints(d.KubeConfig, clusterName, DemoName, DemoNamespace)
+}
@@ -0,0 +1,65 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/mrgb7/playground/internal/k8s"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func issuerObject(kind, namespace string) *unstructured.Unstructured {
+	issuer := &unstructured.Unstructured{}
+	issuer.SetAPIVersion("cert-manager.io/v1")
+	issuer.SetKind(kind)
+	issuer.SetName(TLSClusterIssuerName)
+	issuer.SetNamespace(namespace)
+	return issuer
+}
+
+func TestIngressEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		issuers  []runtime.Object
+		expected Endpoint
+	}{
+		{
+			name:     "without TLS",
+			expected: Endpoint{Name: "argocd", URL: "http://argocd.test.local", Protocol: ProtocolHTTP},
+		},
+		{
+			name:     "with cluster issuer",
+			issuers:  []runtime.Object{issuerObject("ClusterIssuer", "")},
+			expected: Endpoint{Name: "argocd", URL: "https://argocd.test.local", Protocol: ProtocolHTTPS},
+		},
+		{
+			name:     "with issuer in the plugin namespace",
+			issuers:  []runtime.Object{issuerObject("Issuer", ArgocdNamespace)},
+			expected: Endpoint{Name: "argocd", URL: "https://argocd.test.local", Protocol: ProtocolHTTPS},
+		},
+		{
+			name:     "with issuer in another namespace",
+			issuers:  []runtime.Object{issuerObject("Issuer", DemoNamespace)},
+			expected: Endpoint{Name: "argocd", URL: "http://argocd.test.local", Protocol: ProtocolHTTP},
+		},
+	}
+
+	listKinds := map[schema.GroupVersionResource]string{
+		issuerGVR:        "IssuerList",
+		clusterIssuerGVR: "ClusterIssuerList",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dynamic := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, tt.issuers...)
+			ingress := &Ingress{ClusterName: "test", k8sClient: &k8s.K8sClient{Dynamic: dynamic}}
+
+			endpoint := ingress.endpoint("argocd", "argocd", ArgocdNamespace)
+			if endpoint != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, endpoint)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mrgb7/playground/internal/k8s"
+	"github.com/mrgb7/playground/pkg/logger"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sretry "k8s.io/client-go/util/retry"
+)
+
+const (
+	PluginHistoryConfigMapName = "playground-plugin-history"
+	MaxHistoryEvents           = 20
+
+	HistoryActionInstall   = "install"
+	HistoryActionUpgrade   = "upgrade"
+	HistoryActionUninstall = "uninstall"
+	HistoryActionRollback  = "rollback"
+)
+
+// HistoryEvent is a single recorded change to a plugin
+type HistoryEvent struct {
+	Time           time.Time `json:"time"`
+	Action         string    `json:"action"`
+	Version        string    `json:"version,omitempty"`
+	Installer      string    `json:"installer,omitempty"`
+	ValuesChecksum string    `json:"valuesChecksum,omitempty"`
+}
+
+// PluginHistory stores a bounded list of events per plugin in a ConfigMap
+type PluginHistory struct {
+	k8sClient *k8s.K8sClient
+}
+
+func NewPluginHistory(kubeConfig string) (*PluginHistory, error) {
+	client, err := k8s.NewK8sClient(kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create k8s client: %w", err)
+	}
+
+	return &PluginHistory{
+		k8sClient: client,
+	}, nil
+}
+
+func (h *PluginHistory) RecordEvent(pluginName string, event HistoryEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	// plugins installed at the same time record their events concurrently
+	err := k8sretry.RetryOnConflict(k8sretry.DefaultRetry, func() error {
+		configMap, err := h.getOrCreateHistoryConfigMap(ctx)
+		if err != nil {
+			return err
+		}
+
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+
+		events, err := decodeHistory(configMap.Data[pluginName])
+		if err != nil {
+			logger.Warnln("Discarding unreadable history for plugin '%s': %v", pluginName, err)
+		}
+		events = appendHistoryEvent(events, event, MaxHistoryEvents)
+
+		data, err := json.Marshal(events)
+		if err != nil {
+			return fmt.Errorf("failed to marshal history: %w", err)
+		}
+		configMap.Data[pluginName] = string(data)
+
+		_, err = h.k8sClient.Clientset.CoreV1().ConfigMaps(InstallerTrackerNamespace).Update(
+			ctx, configMap, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to update history ConfigMap: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Debugln("Recorded %s event for plugin '%s'", event.Action, pluginName)
+	return nil
+}
+
+// GetEvents returns the recorded events of a plugin, oldest first
+func (h *PluginHistory) GetEvents(pluginName string) ([]HistoryEvent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	configMap, err := h.k8sClient.Clientset.CoreV1().ConfigMaps(InstallerTrackerNamespace).Get(
+		ctx, PluginHistoryConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get history ConfigMap: %w", err)
+	}
+
+	return decodeHistory(configMap.Data[pluginName])
+}
+
+func decodeHistory(data string) ([]HistoryEvent, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var events []HistoryEvent
+	if err := json.Unmarshal([]byte(data), &events); err != nil {
+		return nil, fmt.Errorf("failed to parse history: %w", err)
+	}
+	return events, nil
+}
+
+// appendHistoryEvent adds event and drops the oldest events beyond limit
+func appendHistoryEvent(events []HistoryEvent, event HistoryEvent, limit int) []HistoryEvent {
+	events = append(events, event)
+	if len(events) > limit {
+		events = events[len(events)-limit:]
+	}
+	return events
+}
+
+func (h *PluginHistory) getOrCreateHistoryConfigMap(ctx context.Context) (*v1.ConfigMap, error) {
+	configMap, err := h.k8sClient.Clientset.CoreV1().ConfigMaps(InstallerTrackerNamespace).Get(
+		ctx, PluginHistoryConfigMapName, metav1.GetOptions{})
+	if err == nil {
+		return configMap, nil
+	}
+
+	if !strings.Contains(err.Error(), "not found") {
+		return nil, fmt.Errorf("failed to get history ConfigMap: %w", err)
+	}
+
+	newConfigMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      PluginHistoryConfigMapName,
+			Namespace: InstallerTrackerNamespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/name":       "playground",
+				"app.kubernetes.io/component":  "plugin-history",
+				"app.kubernetes.io/managed-by": "playground",
+			},
+		},
+		Data: make(map[string]string),
+	}
+
+	createdConfigMap, err := h.k8sClient.Clientset.CoreV1().ConfigMaps(InstallerTrackerNamespace).Create(
+		ctx, newConfigMap, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create history ConfigMap: %w", err)
+	}
+
+	logger.Debugln("Created new plugin history ConfigMap")
+	return createdConfigMap, nil
+}
+
+// RecordPluginEvent records an event for a plugin, logging rather than failing on errors
+func RecordPluginEvent(kubeConfig, pluginName string, event HistoryEvent) {
+	history, err := NewPluginHistory(kubeConfig)
+	if err != nil {
+		logger.Warnln("Failed to create plugin history for %s: %v", pluginName, err)
+		return
+	}
+	if err := history.RecordEvent(pluginName, event); err != nil {
+		logger.Warnln("Failed to record %s event for %s: %v", event.Action, pluginName, err)
+	}
+}
@@ -0,0 +1,49 @@
+package plugins
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAppendHistoryEvent(t *testing.T) {
+	var events []HistoryEvent
+	for i := 0; i < 5; i++ {
+		events = appendHistoryEvent(events, HistoryEvent{
+			Time:    time.Unix(int64(i), 0),
+			Action:  HistoryActionInstall,
+			Version: string(rune('a' + i)),
+		}, 3)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("expected history bounded to 3 events, got %d", len(events))
+	}
+	if events[0].Version != "c" || events[2].Version != "e" {
+		t.Errorf("expected oldest events to be dropped, got %s..%s", events[0].Version, events[2].Version)
+	}
+}
+
+func TestDecodeHistory(t *testing.T) {
+	events, err := decodeHistory("")
+	if err != nil || len(events) != 0 {
+		t.Errorf("expected empty history, got %v, %v", events, err)
+	}
+
+	events, err = decodeHistory(`[{"time":"2025-01-01T00:00:00Z","action":"upgrade","version":"1.2.3"}]`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 1 || events[0].Action != HistoryActionUpgrade || events[0].Version != "1.2.3" {
+		t.Errorf("unexpected events: %+v", events)
+	}
+
+	if _, err := decodeHistory("not-json"); err == nil {
+		t.Error("expected error for malformed history")
+	}
+}
+
+func TestNewPluginHistory(t *testing.T) {
+	if _, err := NewPluginHistory("invalid-config"); err == nil {
+		t.Error("expected error for invalid kubeconfig")
+	}
+}
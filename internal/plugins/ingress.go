@@ -2,16 +2,20 @@ package plugins
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/mrgb7/playground/internal/k8s"
 	"github.com/mrgb7/playground/pkg/logger"
+	"github.com/mrgb7/playground/pkg/retry"
 	v1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 var (
@@ -26,11 +30,36 @@ const (
 	ArgoCDPort = 80
 )
 
+var errNoLoadBalancerIP = errors.New("load balancer IP not assigned")
+
+// IngressTLSIssuerOverrideKey names the TLS plugin issuer ingresses request certificates
+// from, when both the local CA and the ACME issuer exist
+const IngressTLSIssuerOverrideKey = "tls.issuer"
+
+// IngressStatusConfigured is the status of the installed ingress plugin
+const IngressStatusConfigured = "Ingress is configured"
+
+var (
+	// loadBalancerPollInterval is how often a service is checked for a load balancer address
+	loadBalancerPollInterval = 5 * time.Second
+	loadBalancerWaitTimeout  = 60 * time.Second
+)
+
 type Ingress struct {
 	KubeConfig  string
 	k8sClient   *k8s.K8sClient
 	ClusterName string
+	// Domain is the domain hostnames are served under, <cluster>.local when empty
+	Domain string
 	*BasePlugin
+
+	sharedCert bool   // reference one wildcard certificate per namespace instead of one per ingress
+	issuerName string // TLS plugin issuer to use, empty prefers the local CA issuer
+}
+
+// SharedCertificatePlugin can serve its ingresses with a shared wildcard certificate
+type SharedCertificatePlugin interface {
+	UseSharedCertificate()
 }
 
 func NewIngress(kubeConfig, clusterName string) (*Ingress, error) {
@@ -42,15 +71,47 @@ func NewIngress(kubeConfig, clusterName string) (*Ingress, error) {
 		KubeConfig:  kubeConfig,
 		k8sClient:   c,
 		ClusterName: clusterName,
+		Domain:      loadClusterDomain(clusterName),
 	}
 	ingress.BasePlugin = NewBasePlugin(kubeConfig, ingress)
 	return ingress, nil
 }
 
+// UseSharedCertificate makes the ingresses configured by the plugin use the wildcard
+// certificate of their namespace when TLS is enabled
+func (i *Ingress) UseSharedCertificate() {
+	i.sharedCert = true
+}
+
 func (i *Ingress) GetName() string {
 	return IngressName
 }
 
+func (i *Ingress) GetOverrideSchema() map[string]OverrideType {
+	return map[string]OverrideType{IngressTLSIssuerOverrideKey: OverrideTypeString}
+}
+
+func (i *Ingress) ValidateOverrideValues(values map[string]interface{}) error {
+	if err := validateOverrideKeys(values, IngressTLSIssuerOverrideKey); err != nil {
+		return err
+	}
+	if v, ok := GetNestedValue(values, IngressTLSIssuerOverrideKey); ok {
+		if issuer, isString := v.(string); !isString || (issuer != TLSClusterIssuerName && issuer != TLSACMEIssuerName) {
+			return fmt.Errorf("%s must be %s or %s, got %v",
+				IngressTLSIssuerOverrideKey, TLSClusterIssuerName, TLSACMEIssuerName, v)
+		}
+	}
+	return nil
+}
+
+func (i *Ingress) SetOverrideValues(values map[string]interface{}) {
+	if v, ok := GetNestedValue(values, IngressTLSIssuerOverrideKey); ok {
+		if issuer, isString := v.(string); isString {
+			i.issuerName = issuer
+		}
+	}
+}
+
 func (i *Ingress) GetOptions() PluginOptions {
 	return PluginOptions{
 		Version:   &IngressVersion,
@@ -59,9 +120,17 @@ func (i *Ingress) GetOptions() PluginOptions {
 }
 
 func (i *Ingress) Install(kubeConfig, clusterName string, ensure ...bool) error {
+	if err := checkKubeConfig(kubeConfig); err != nil {
+		return fmt.Errorf("cannot install plugin %s: %w", i.GetName(), err)
+	}
+	if err := preInstallCheck(i, kubeConfig); err != nil {
+		return err
+	}
+
 	logger.Infoln("Installing ingress plugin for cluster: %s", clusterName)
 
-	if err := i.ensureNginxLoadBalancer(); err != nil {
+	nginxService, err := i.ensureNginxLoadBalancer()
+	if err != nil {
 		return fmt.Errorf("failed to ensure nginx LoadBalancer: %w", err)
 	}
 
@@ -71,7 +140,13 @@ func (i *Ingress) Install(kubeConfig, clusterName string, ensure ...bool) error
 		return fmt.Errorf("failed to configure ArgoCD ingress: %w", err)
 	}
 
-	if err := i.printHostInstructions(); err != nil {
+	if err := i.configureServiceIngress(); err != nil {
+		return fmt.Errorf("failed to configure service ingresses: %w", err)
+	}
+
+	if nginxService.Spec.Type == v1.ServiceTypeNodePort {
+		i.printNodePortInstructions(nginxService)
+	} else if err := i.printHostInstructions(); err != nil {
 		return fmt.Errorf("failed to print host instructions: %w", err)
 	}
 
@@ -87,6 +162,16 @@ func (i *Ingress) Uninstall(kubeConfig, clusterName string, ensure ...bool) erro
 		logger.Warnln("Failed to remove ArgoCD ingress: %v", err)
 	}
 
+	dashboardNs := dashboardNamespace(i.KubeConfig)
+	if err := i.RemoveServiceIngress(dashboardNs, DashboardProxyService); err != nil {
+		logger.Warnln("Failed to remove dashboard ingress: %v", err)
+	}
+
+	tls := &TLS{k8sClient: i.k8sClient}
+	if err := tls.RemoveWildcardCertificates(); err != nil {
+		logger.Warnln("Failed to remove wildcard certificates: %v", err)
+	}
+
 	logger.Successln("Ingress plugin uninstalled successfully")
 	return nil
 }
@@ -102,41 +187,82 @@ func (i *Ingress) Status() string {
 		return "Ingress dependencies not satisfied"
 	}
 
-	return "Ingress is configured"
+	return IngressStatusConfigured
+}
+
+func (i *Ingress) IsInstalledStatus(status string) bool {
+	return status == IngressStatusConfigured
+}
+
+// PreInstallCheck verifies the nginx controller service the ingress plugin exposes
+// through the load balancer exists
+func (i *Ingress) PreInstallCheck(string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := i.k8sClient.Clientset.CoreV1().Services(NginxNamespace).Get(ctx, NginxControllerName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("nginx service %s/%s not found, install the nginx-ingress plugin first",
+			NginxNamespace, NginxControllerName)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get nginx service: %w", err)
+	}
+	return nil
 }
 
-func (i *Ingress) ensureNginxLoadBalancer() error {
+// ensureNginxLoadBalancer switches the nginx controller service to LoadBalancer, unless
+// the nginx-ingress plugin was installed with a NodePort service. It returns the service.
+func (i *Ingress) ensureNginxLoadBalancer() (*v1.Service, error) {
 	logger.Infoln("Ensuring nginx service is LoadBalancer type...")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	svc, err := i.k8sClient.Clientset.CoreV1().Services(NginxNamespace).Get(
-		ctx, "nginx-ingress-ingress-nginx-controller", metav1.GetOptions{})
+		ctx, NginxControllerName, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to get nginx service: %w", err)
+		return nil, fmt.Errorf("failed to get nginx service: %w", err)
 	}
 
-	if svc.Spec.Type == v1.ServiceTypeLoadBalancer {
+	switch svc.Spec.Type {
+	case v1.ServiceTypeLoadBalancer:
 		logger.Debugln("Nginx service is already LoadBalancer type")
-		return nil
+		return svc, nil
+	case v1.ServiceTypeNodePort:
+		logger.Infoln("Nginx service uses NodePort, keeping it")
+		return svc, nil
 	}
 
 	svc.Spec.Type = v1.ServiceTypeLoadBalancer
-	_, err = i.k8sClient.Clientset.
+	updated, err := i.k8sClient.Clientset.
 		CoreV1().
 		Services(NginxNamespace).
 		Update(ctx, svc, metav1.UpdateOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to update nginx service to LoadBalancer: %w", err)
+		return nil, fmt.Errorf("failed to update nginx service to LoadBalancer: %w", err)
 	}
 
 	logger.Successln("Updated nginx service to LoadBalancer type")
-	return nil
+	return updated, nil
+}
+
+// printNodePortInstructions explains how to reach the ingress through the node ports of
+// the nginx service
+func (i *Ingress) printNodePortInstructions(svc *v1.Service) {
+	logger.Infoln("")
+	logger.Infoln("🎯 Nginx is exposed through node ports, add any node IP to your /etc/hosts file:")
+	logger.Infoln("echo '<node-ip> %s' | sudo tee -a /etc/hosts", i.domain())
+	for _, port := range svc.Spec.Ports {
+		if port.NodePort != 0 {
+			logger.Infoln("🚀 %s is served on port %d, e.g. %s://%s:%d",
+				port.Name, port.NodePort, port.Name, i.domain(), port.NodePort)
+		}
+	}
 }
 
 func (i *Ingress) setupClusterDomain() {
-	logger.Infoln("Setting up cluster domain: %s.local", i.ClusterName)
+	logger.Infoln("Setting up cluster domain: %s", i.domain())
 }
 
 func (i *Ingress) configureArgoCDIngress() error {
@@ -154,26 +280,52 @@ func (i *Ingress) configureArgoCDIngress() error {
 
 	logger.Infoln("ArgoCD found, configuring ingress...")
 
-	isTLSAvailable := i.isTLSClusterIssuerAvailable()
-	if isTLSAvailable {
-		logger.Infoln("TLS cluster issuer found, enabling HTTPS for ArgoCD")
+	namespace := ArgoCDNamespace(i.KubeConfig)
+	issuer := i.findTLSIssuer(namespace)
+	if issuer != nil {
+		logger.Infoln("TLS issuer found, enabling HTTPS for ArgoCD")
+	}
+	if err := i.shareCertificate(namespace, issuer); err != nil {
+		return err
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	existingIngress, err := i.k8sClient.Clientset.NetworkingV1().Ingresses("argocd").Get(
+	existingIngress, err := i.k8sClient.Clientset.NetworkingV1().Ingresses(namespace).Get(
 		ctx, "argocd-server", metav1.GetOptions{})
 	if err != nil && !strings.Contains(err.Error(), "not found") {
 		return fmt.Errorf("failed to check existing ArgoCD ingress: %w", err)
 	}
 
-	hostname := fmt.Sprintf("argocd.%s.local", i.ClusterName)
+	hostname := fmt.Sprintf("argocd.%s", i.domain())
 
 	if err == nil {
-		return i.updateExistingArgoCDIngress(existingIngress, hostname, isTLSAvailable)
+		return i.updateExistingArgoCDIngress(existingIngress, hostname, issuer)
 	}
-	return i.createNewArgoCDIngress(hostname, isTLSAvailable)
+	return i.createNewArgoCDIngress(namespace, hostname, issuer)
+}
+
+// configureServiceIngress routes <service>.<domain> to the services of installed
+// plugins that are not exposed by the plugins themselves
+func (i *Ingress) configureServiceIngress() error {
+	dashboard := NewDashboard(i.KubeConfig, i.ClusterName)
+	if !strings.Contains(dashboard.Status(), StatusRunning) {
+		logger.Infoln("Dashboard not installed, skipping ingress configuration")
+		return nil
+	}
+
+	logger.Infoln("Dashboard found, configuring ingress...")
+
+	// the dashboard's kong proxy only serves HTTPS
+	url, err := i.addServiceIngress(dashboardNamespace(i.KubeConfig), DashboardProxyService, DashboardProxyPort,
+		DashboardName, map[string]string{"nginx.ingress.kubernetes.io/backend-protocol": "HTTPS"},
+		dashboard.GetOptions().IngressRoutes...)
+	if err != nil {
+		return fmt.Errorf("failed to expose dashboard: %w", err)
+	}
+	logger.Infoln("🚀 Dashboard will be available at: %s", url)
+	return nil
 }
 
 func (i *Ingress) removeArgoCDIngress() error {
@@ -183,7 +335,7 @@ func (i *Ingress) removeArgoCDIngress() error {
 	err := i.k8sClient.
 		Clientset.
 		NetworkingV1().
-		Ingresses("argocd").
+		Ingresses(ArgoCDNamespace(i.KubeConfig)).
 		Delete(ctx, "argocd-server", metav1.DeleteOptions{})
 	if err != nil && !strings.Contains(err.Error(), "not found") {
 		return fmt.Errorf("failed to delete ArgoCD ingress: %w", err)
@@ -192,44 +344,81 @@ func (i *Ingress) removeArgoCDIngress() error {
 	return nil
 }
 
-func (i *Ingress) printHostInstructions() error {
-	logger.Infoln("Getting nginx LoadBalancer IP...")
+// loadBalancerAddress returns the IP, or failing that the hostname, the load balancer
+// assigned to svc, or "" when none is assigned yet
+func loadBalancerAddress(svc *v1.Service) string {
+	if len(svc.Status.LoadBalancer.Ingress) == 0 {
+		return ""
+	}
+	if ip := svc.Status.LoadBalancer.Ingress[0].IP; ip != "" {
+		return ip
+	}
+	return svc.Status.LoadBalancer.Ingress[0].Hostname
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
+// waitForLoadBalancerIP polls a LoadBalancer service every loadBalancerPollInterval until
+// it is assigned an address, for as many attempts as fit before ctx's deadline
+func (i *Ingress) waitForLoadBalancerIP(ctx context.Context, service, namespace string) (string, error) {
+	attempts := 0 // without a deadline poll until ctx is cancelled
+	if deadline, ok := ctx.Deadline(); ok {
+		attempts = int(time.Until(deadline)/loadBalancerPollInterval) + 1
+	}
 
-	var nginxIP string
-	for retries := 0; retries < 12; retries++ {
-		svc, err := i.k8sClient.
-			Clientset.
-			CoreV1().
-			Services(NginxNamespace).Get(ctx, "nginx-ingress-ingress-nginx-controller", metav1.GetOptions{})
+	var address string
+	attempt := 0
+	err := retry.Do(ctx, retry.Options{
+		Attempts:  attempts,
+		Backoff:   loadBalancerPollInterval,
+		Retryable: func(err error) bool { return errors.Is(err, errNoLoadBalancerIP) },
+	}, func() error {
+		attempt++
+		svc, err := i.k8sClient.Clientset.CoreV1().Services(namespace).Get(ctx, service, metav1.GetOptions{})
 		if err != nil {
-			return fmt.Errorf("failed to get nginx service: %w", err)
+			return fmt.Errorf("failed to get service %s/%s: %w", namespace, service, err)
 		}
 
-		if len(svc.Status.LoadBalancer.Ingress) > 0 {
-			if svc.Status.LoadBalancer.Ingress[0].IP != "" {
-				nginxIP = svc.Status.LoadBalancer.Ingress[0].IP
-				break
-			}
+		if address = loadBalancerAddress(svc); address != "" {
+			return nil
 		}
 
-		logger.Infoln("Waiting for LoadBalancer IP assignment... (%d/12)", retries+1)
-		time.Sleep(5 * time.Second)
+		if attempts > 0 {
+			logger.Infoln("Waiting for LoadBalancer IP assignment... (%d/%d)", attempt, attempts)
+		} else {
+			logger.Infoln("Waiting for LoadBalancer IP assignment... (%d)", attempt)
+		}
+		return errNoLoadBalancerIP
+	})
+	if errors.Is(err, errNoLoadBalancerIP) {
+		return "", fmt.Errorf("timed out after %d attempts waiting for service %s/%s: %w",
+			attempt, namespace, service, errNoLoadBalancerIP)
+	}
+	if err != nil {
+		return "", err
 	}
+	return address, nil
+}
+
+func (i *Ingress) printHostInstructions() error {
+	logger.Infoln("Getting nginx LoadBalancer IP...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), loadBalancerWaitTimeout)
+	defer cancel()
 
-	if nginxIP == "" {
+	nginxIP, err := i.waitForLoadBalancerIP(ctx, NginxControllerName, NginxNamespace)
+	if errors.Is(err, errNoLoadBalancerIP) {
 		logger.Warnln("LoadBalancer IP not available yet. You can run this command later to get it:")
-		logger.Infoln("kubectl get svc -n %s nginx-ingress-ingress-nginx-controller "+
-			"-o jsonpath='{.status.loadBalancer.ingress[0].ip}'", NginxNamespace)
+		logger.Infoln("kubectl get svc -n %s %s "+
+			"-o jsonpath='{.status.loadBalancer.ingress[0].ip}'", NginxNamespace, NginxControllerName)
 		return nil
 	}
+	if err != nil {
+		return err
+	}
 
 	logger.Successln("LoadBalancer IP found: %s", nginxIP)
 	logger.Infoln("")
 	logger.Infoln("🎯 Add these entries to your /etc/hosts file:")
-	logger.Infoln("echo '%s %s.local' | sudo tee -a /etc/hosts", nginxIP, i.ClusterName)
+	logger.Infoln("echo '%s %s' | sudo tee -a /etc/hosts", nginxIP, i.domain())
 
 	argocd, err := NewArgocd(i.KubeConfig)
 	if err != nil {
@@ -237,49 +426,99 @@ func (i *Ingress) printHostInstructions() error {
 	}
 	argoCDStatus := argocd.Status()
 	if strings.Contains(argoCDStatus, StatusRunning) {
-		logger.Infoln("echo '%s argocd.%s.local' | sudo tee -a /etc/hosts", nginxIP, i.ClusterName)
+		logger.Infoln("echo '%s argocd.%s' | sudo tee -a /etc/hosts", nginxIP, i.domain())
 		logger.Infoln("")
 
-		isTLSAvailable := i.isTLSClusterIssuerAvailable()
-		if isTLSAvailable {
-			logger.Infoln("🚀 ArgoCD will be available at: https://argocd.%s.local", i.ClusterName)
+		if i.findTLSIssuer(ArgoCDNamespace(i.KubeConfig)) != nil {
+			logger.Infoln("🚀 ArgoCD will be available at: https://argocd.%s", i.domain())
 			logger.Infoln("🔒 TLS certificates will be automatically generated")
 		} else {
-			logger.Infoln("🚀 ArgoCD will be available at: http://argocd.%s.local", i.ClusterName)
+			logger.Infoln("🚀 ArgoCD will be available at: http://argocd.%s", i.domain())
 			logger.Infoln("💡 Install TLS plugin for HTTPS support:")
 			logger.Infoln("   playground cluster plugin add --name tls --cluster %s", i.ClusterName)
 		}
 	}
 
 	logger.Infoln("")
-	logger.Infoln("🌐 Cluster domain: %s.local", i.ClusterName)
+	logger.Infoln("🌐 Cluster domain: %s", i.domain())
 
 	return nil
 }
 
-func (i *Ingress) isTLSClusterIssuerAvailable() bool {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// tlsIssuer is the cert-manager issuer an ingress asks for certificates
+type tlsIssuer struct {
+	annotation   string // cert-manager.io/issuer or cert-manager.io/cluster-issuer
+	name         string
+	sharedSecret string // wildcard certificate secret used instead of a certificate per ingress
+}
+
+// secretName returns the secret the ingress certificate is stored in
+func (t *tlsIssuer) secretName(ingressSecret string) string {
+	if t.sharedSecret != "" {
+		return t.sharedSecret
+	}
+	return ingressSecret
+}
+
+// annotate asks cert-manager to issue a certificate for the ingress. The shared wildcard
+// certificate has its own Certificate resource, so ingresses using it are not annotated.
+func (t *tlsIssuer) annotate(annotations map[string]string) {
+	if t.sharedSecret == "" {
+		annotations[t.annotation] = t.name
+	}
+}
 
-	gvr := schema.GroupVersionResource{
-		Group:    "cert-manager.io",
-		Version:  "v1",
-		Resource: "clusterissuers",
+// shareCertificate issues the wildcard certificate in namespace and points issuer at it
+// when the shared certificate is enabled and TLS is available
+func (i *Ingress) shareCertificate(namespace string, issuer *tlsIssuer) error {
+	if !i.sharedCert || issuer == nil {
+		return nil
+	}
+	if issuer.name == TLSACMEIssuerName {
+		logger.Warnln("The ACME issuer can't issue wildcard certificates over HTTP-01, using one per ingress")
+		return nil
 	}
 
+	tls := &TLS{k8sClient: i.k8sClient, ClusterName: i.ClusterName, Domain: i.Domain}
+	if issuer.annotation == "cert-manager.io/issuer" {
+		tls.issuerNamespace = namespace
+	}
+	if err := tls.CreateWildcardCertificate(namespace); err != nil {
+		return fmt.Errorf("failed to create shared certificate: %w", err)
+	}
+	issuer.sharedSecret = TLSWildcardSecretName
+	return nil
+}
+
+// findTLSIssuer returns the TLS plugin's issuer for ingresses in namespace. A
+// namespaced Issuer takes precedence over the ClusterIssuer, and the local CA issuer over
+// the ACME one unless the tls.issuer override picks one; nil means no TLS.
+func (i *Ingress) findTLSIssuer(namespace string) *tlsIssuer {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
 	tls := &TLS{}
-	issuerName := tls.GetClusterIssuerName()
-	_, err := i.k8sClient.
-		Dynamic.
-		Resource(gvr).
-		Get(ctx, issuerName, metav1.GetOptions{})
-	return err == nil
+	issuerNames := []string{tls.GetClusterIssuerName(), TLSACMEIssuerName}
+	if i.issuerName != "" {
+		issuerNames = []string{i.issuerName}
+	}
+	for _, issuerName := range issuerNames {
+		if _, err := i.k8sClient.Dynamic.Resource(issuerGVR).Namespace(namespace).
+			Get(ctx, issuerName, metav1.GetOptions{}); err == nil {
+			return &tlsIssuer{annotation: "cert-manager.io/issuer", name: issuerName}
+		}
+		if _, err := i.k8sClient.Dynamic.Resource(clusterIssuerGVR).
+			Get(ctx, issuerName, metav1.GetOptions{}); err == nil {
+			return &tlsIssuer{annotation: "cert-manager.io/cluster-issuer", name: issuerName}
+		}
+	}
+	return nil
 }
 
 func (i *Ingress) updateExistingArgoCDIngress(
 	existingIngress *networkingv1.Ingress,
 	hostname string,
-	isTLSAvailable bool,
+	issuer *tlsIssuer,
 ) error {
 	logger.Infoln("Updating existing ArgoCD ingress with cluster domain and TLS...")
 
@@ -287,19 +526,20 @@ func (i *Ingress) updateExistingArgoCDIngress(
 		existingIngress.Spec.Rules[0].Host = hostname
 	}
 
-	if isTLSAvailable {
+	if issuer != nil {
 		if existingIngress.Annotations == nil {
 			existingIngress.Annotations = make(map[string]string)
 		}
-		tls := &TLS{}
-		existingIngress.Annotations["cert-manager.io/cluster-issuer"] = tls.GetClusterIssuerName()
+		delete(existingIngress.Annotations, "cert-manager.io/issuer")
+		delete(existingIngress.Annotations, "cert-manager.io/cluster-issuer")
+		issuer.annotate(existingIngress.Annotations)
 		existingIngress.Annotations["nginx.ingress.kubernetes.io/ssl-redirect"] = TrueValue
 		existingIngress.Annotations["nginx.ingress.kubernetes.io/force-ssl-redirect"] = TrueValue
 
 		existingIngress.Spec.TLS = []networkingv1.IngressTLS{
 			{
 				Hosts:      []string{hostname},
-				SecretName: "argocd-server-tls",
+				SecretName: issuer.secretName("argocd-server-tls"),
 			},
 		}
 	} else if existingIngress.Annotations != nil {
@@ -312,21 +552,21 @@ func (i *Ingress) updateExistingArgoCDIngress(
 
 	_, err := i.k8sClient.Clientset.
 		NetworkingV1().
-		Ingresses("argocd").
+		Ingresses(existingIngress.Namespace).
 		Update(ctx, existingIngress, metav1.UpdateOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to update existing ArgoCD ingress: %w", err)
 	}
 
-	if isTLSAvailable {
-		logger.Successln("Updated existing ArgoCD ingress with HTTPS: https://argocd.%s.local", i.ClusterName)
+	if issuer != nil {
+		logger.Successln("Updated existing ArgoCD ingress with HTTPS: https://argocd.%s", i.domain())
 	} else {
-		logger.Successln("Updated existing ArgoCD ingress with host: argocd.%s.local", i.ClusterName)
+		logger.Successln("Updated existing ArgoCD ingress with host: argocd.%s", i.domain())
 	}
 	return nil
 }
 
-func (i *Ingress) createNewArgoCDIngress(hostname string, isTLSAvailable bool) error {
+func (i *Ingress) createNewArgoCDIngress(namespace, hostname string, issuer *tlsIssuer) error {
 	logger.Infoln("Creating new ArgoCD ingress...")
 
 	annotations := map[string]string{
@@ -335,15 +575,14 @@ func (i *Ingress) createNewArgoCDIngress(hostname string, isTLSAvailable bool) e
 
 	var tlsConfig []networkingv1.IngressTLS
 
-	if isTLSAvailable {
-		tls := &TLS{}
-		annotations["cert-manager.io/cluster-issuer"] = tls.GetClusterIssuerName()
+	if issuer != nil {
+		issuer.annotate(annotations)
 		annotations["nginx.ingress.kubernetes.io/ssl-redirect"] = TrueValue
 		annotations["nginx.ingress.kubernetes.io/force-ssl-redirect"] = TrueValue
 		tlsConfig = []networkingv1.IngressTLS{
 			{
 				Hosts:      []string{hostname},
-				SecretName: "argocd-server-tls",
+				SecretName: issuer.secretName("argocd-server-tls"),
 			},
 		}
 	} else {
@@ -354,7 +593,7 @@ func (i *Ingress) createNewArgoCDIngress(hostname string, isTLSAvailable bool) e
 	ingress := &networkingv1.Ingress{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        "argocd-server",
-			Namespace:   "argocd",
+			Namespace:   namespace,
 			Annotations: annotations,
 		},
 		Spec: networkingv1.IngressSpec{
@@ -389,19 +628,254 @@ func (i *Ingress) createNewArgoCDIngress(hostname string, isTLSAvailable bool) e
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	_, err := i.k8sClient.Clientset.NetworkingV1().Ingresses("argocd").Create(ctx, ingress, metav1.CreateOptions{})
+	_, err := i.k8sClient.Clientset.NetworkingV1().Ingresses(namespace).Create(ctx, ingress, metav1.CreateOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to create ArgoCD ingress: %w", err)
 	}
 
-	if isTLSAvailable {
-		logger.Successln("Created ArgoCD ingress with HTTPS: https://argocd.%s.local", i.ClusterName)
+	if issuer != nil {
+		logger.Successln("Created ArgoCD ingress with HTTPS: https://argocd.%s", i.domain())
 	} else {
-		logger.Successln("Created ArgoCD ingress with host: argocd.%s.local", i.ClusterName)
+		logger.Successln("Created ArgoCD ingress with host: argocd.%s", i.domain())
 	}
 	return nil
 }
 
+// IngressRule routes requests for a host and path prefix to a service port
+type IngressRule struct {
+	Host    string
+	Path    string // "/" when empty
+	Service string
+	Port    int32
+}
+
+// IngressRoute is an additional host or path a plugin's service is exposed at, set
+// through PluginOptions.IngressRoutes
+type IngressRoute struct {
+	Subdomain string // served at <subdomain>.<domain>, the plugin's own subdomain when empty
+	Path      string // "/" when empty
+}
+
+// AddServiceIngress exposes a service at <subdomain>.<domain> through the nginx
+// ingress class, enabling HTTPS when the local cluster issuer is available. Routes
+// expose the service at additional hostnames or paths. It returns the URL the service
+// is reachable at.
+func (i *Ingress) AddServiceIngress(
+	namespace, serviceName string,
+	port int32,
+	subdomain string,
+	routes ...IngressRoute,
+) (string, error) {
+	return i.addServiceIngress(namespace, serviceName, port, subdomain, nil, routes...)
+}
+
+func (i *Ingress) addServiceIngress(
+	namespace, serviceName string,
+	port int32,
+	subdomain string,
+	annotations map[string]string,
+	routes ...IngressRoute,
+) (string, error) {
+	issuer := i.findTLSIssuer(namespace)
+	if err := i.shareCertificate(namespace, issuer); err != nil {
+		return "", err
+	}
+
+	rules := i.serviceIngressRules(serviceName, port, subdomain, routes)
+	ingress := buildIngress(namespace, serviceName, rules, issuer)
+	for key, value := range annotations {
+		ingress.Annotations[key] = value
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	existing, err := i.k8sClient.Clientset.NetworkingV1().Ingresses(namespace).Get(
+		ctx, serviceName, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		ingress.ResourceVersion = existing.ResourceVersion
+		_, err = i.k8sClient.Clientset.NetworkingV1().Ingresses(namespace).Update(ctx, ingress, metav1.UpdateOptions{})
+		if err != nil {
+			return "", fmt.Errorf("failed to update ingress for %s: %w", serviceName, err)
+		}
+		logger.Infoln("Updated existing ingress for %s", serviceName)
+	case strings.Contains(err.Error(), "not found"):
+		_, err = i.k8sClient.Clientset.NetworkingV1().Ingresses(namespace).Create(ctx, ingress, metav1.CreateOptions{})
+		if err != nil {
+			return "", fmt.Errorf("failed to create ingress for %s: %w", serviceName, err)
+		}
+		logger.Successln("Created ingress for %s", serviceName)
+	default:
+		return "", fmt.Errorf("failed to check existing ingress for %s: %w", serviceName, err)
+	}
+
+	return ingressURL(ingress), nil
+}
+
+// serviceIngress locates the ingress created for a service exposed by a plugin
+type serviceIngress struct {
+	namespace string
+	name      string
+}
+
+// exposedServices maps the names accepted by GetServiceURL to their ingresses, in the
+// plugins' default namespaces
+var exposedServices = map[string]serviceIngress{
+	"argocd":      {namespace: ArgocdNamespace, name: "argocd-server"},
+	DashboardName: {namespace: DashboardNamespace, name: DashboardProxyService},
+	DemoName:      {namespace: DemoNamespace, name: DemoName},
+}
+
+// ExposedServices returns the sorted names of the services GetServiceURL can resolve
+func ExposedServices() []string {
+	names := make([]string, 0, len(exposedServices))
+	for name := range exposedServices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetServiceURL returns the URL a plugin's service is exposed at, read from its ingress
+func (i *Ingress) GetServiceURL(service string) (string, error) {
+	target, ok := exposedServices[service]
+	if !ok {
+		return "", fmt.Errorf("unknown service %q, expected one of %s", service, strings.Join(ExposedServices(), ", "))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	namespace := PluginNamespace(i.KubeConfig, service, target.namespace)
+	ingress, err := i.k8sClient.Clientset.NetworkingV1().Ingresses(namespace).Get(
+		ctx, target.name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("no ingress found for %s, are the %s and ingress plugins installed: %w", service, service, err)
+	}
+
+	url := ingressURL(ingress)
+	if url == "" {
+		return "", fmt.Errorf("ingress for %s has no host", service)
+	}
+	return url, nil
+}
+
+// ingressURL returns the URL of the first host of an ingress, using https when the
+// ingress terminates TLS for that host
+func ingressURL(ingress *networkingv1.Ingress) string {
+	if len(ingress.Spec.Rules) == 0 || ingress.Spec.Rules[0].Host == "" {
+		return ""
+	}
+
+	host := ingress.Spec.Rules[0].Host
+	for _, t := range ingress.Spec.TLS {
+		if slices.Contains(t.Hosts, host) {
+			return fmt.Sprintf("https://%s", host)
+		}
+	}
+	return fmt.Sprintf("http://%s", host)
+}
+
+// RemoveServiceIngress deletes an ingress previously created by AddServiceIngress.
+func (i *Ingress) RemoveServiceIngress(namespace, serviceName string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err := i.k8sClient.Clientset.NetworkingV1().Ingresses(namespace).Delete(ctx, serviceName, metav1.DeleteOptions{})
+	if err != nil && !strings.Contains(err.Error(), "not found") {
+		return fmt.Errorf("failed to delete ingress for %s: %w", serviceName, err)
+	}
+	return nil
+}
+
+// serviceIngressRules returns the rules exposing a service at <subdomain>.<domain>
+// followed by the rules of its additional routes
+func (i *Ingress) serviceIngressRules(serviceName string, port int32, subdomain string,
+	routes []IngressRoute) []IngressRule {
+	host := func(subdomain string) string { return fmt.Sprintf("%s.%s", subdomain, i.domain()) }
+
+	rules := []IngressRule{{Host: host(subdomain), Service: serviceName, Port: port}}
+	for _, route := range routes {
+		routeHost := host(subdomain)
+		if route.Subdomain != "" {
+			routeHost = host(route.Subdomain)
+		}
+		rules = append(rules, IngressRule{Host: routeHost, Path: route.Path, Service: serviceName, Port: port})
+	}
+	return rules
+}
+
+// buildIngress returns an nginx ingress named name serving rules, grouping the paths of
+// rules sharing a host. When issuer is set, TLS covers every host of the ingress.
+func buildIngress(namespace, name string, rules []IngressRule, issuer *tlsIssuer) *networkingv1.Ingress {
+	pathType := networkingv1.PathTypePrefix
+	ingressClassName := "nginx"
+
+	var hosts []string
+	paths := map[string][]networkingv1.HTTPIngressPath{}
+	for _, rule := range rules {
+		path := rule.Path
+		if path == "" {
+			path = "/"
+		}
+		if _, ok := paths[rule.Host]; !ok {
+			hosts = append(hosts, rule.Host)
+		}
+		paths[rule.Host] = append(paths[rule.Host], networkingv1.HTTPIngressPath{
+			Path:     path,
+			PathType: &pathType,
+			Backend: networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{
+					Name: rule.Service,
+					Port: networkingv1.ServiceBackendPort{Number: rule.Port},
+				},
+			},
+		})
+	}
+
+	ingressRules := make([]networkingv1.IngressRule, 0, len(hosts))
+	for _, host := range hosts {
+		ingressRules = append(ingressRules, networkingv1.IngressRule{
+			Host: host,
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{Paths: paths[host]},
+			},
+		})
+	}
+
+	annotations := map[string]string{}
+	var tlsConfig []networkingv1.IngressTLS
+
+	if issuer != nil {
+		issuer.annotate(annotations)
+		annotations["nginx.ingress.kubernetes.io/ssl-redirect"] = TrueValue
+		annotations["nginx.ingress.kubernetes.io/force-ssl-redirect"] = TrueValue
+		tlsConfig = []networkingv1.IngressTLS{
+			{
+				Hosts:      hosts,
+				SecretName: issuer.secretName(fmt.Sprintf("%s-tls", name)),
+			},
+		}
+	} else {
+		annotations["nginx.ingress.kubernetes.io/ssl-redirect"] = FalseValue
+		annotations["nginx.ingress.kubernetes.io/force-ssl-redirect"] = FalseValue
+	}
+
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Annotations: annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: &ingressClassName,
+			TLS:              tlsConfig,
+			Rules:            ingressRules,
+		},
+	}
+}
+
 func (i *Ingress) GetDependencies() []string {
 	return []string{"tls", "nginx-ingress", "load-balancer"} // ingress depends on nginx-ingress and load-balancer
 }
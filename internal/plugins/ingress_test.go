@@ -1,7 +1,20 @@
 package plugins
 
 import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
 	"testing"
+	"time"
+
+	"github.com/mrgb7/playground/internal/k8s"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestIngressPluginInterface(t *testing.T) {
@@ -48,3 +61,308 @@ func TestIngressPluginConstants(t *testing.T) {
 		t.Errorf("Expected IngressNamespace to be 'ingress-system', got '%s'", IngressNamespace)
 	}
 }
+
+var demoRules = []IngressRule{{Host: "demo.test-cluster.local", Service: "demo", Port: 80}}
+
+func TestIngressBuildServiceIngress(t *testing.T) {
+	clusterIssuer := &tlsIssuer{annotation: "cert-manager.io/cluster-issuer", name: TLSClusterIssuerName}
+	withTLS := buildIngress("demo", "demo", demoRules, clusterIssuer)
+	if len(withTLS.Spec.TLS) != 1 || withTLS.Spec.TLS[0].SecretName != "demo-tls" {
+		t.Errorf("Expected TLS secret 'demo-tls', got %v", withTLS.Spec.TLS)
+	}
+	if withTLS.Annotations["cert-manager.io/cluster-issuer"] != TLSClusterIssuerName {
+		t.Errorf("Expected cluster issuer annotation '%s', got '%s'",
+			TLSClusterIssuerName, withTLS.Annotations["cert-manager.io/cluster-issuer"])
+	}
+
+	namespaced := buildIngress("demo", "demo", demoRules,
+		&tlsIssuer{annotation: "cert-manager.io/issuer", name: TLSClusterIssuerName})
+	if namespaced.Annotations["cert-manager.io/issuer"] != TLSClusterIssuerName {
+		t.Errorf("Expected issuer annotation '%s', got %v", TLSClusterIssuerName, namespaced.Annotations)
+	}
+	if _, ok := namespaced.Annotations["cert-manager.io/cluster-issuer"]; ok {
+		t.Errorf("Namespaced issuer should not set the cluster-issuer annotation")
+	}
+
+	sharedIssuer := &tlsIssuer{
+		annotation:   "cert-manager.io/cluster-issuer",
+		name:         TLSClusterIssuerName,
+		sharedSecret: TLSWildcardSecretName,
+	}
+	shared := buildIngress("demo", "demo", demoRules, sharedIssuer)
+	if len(shared.Spec.TLS) != 1 || shared.Spec.TLS[0].SecretName != TLSWildcardSecretName {
+		t.Errorf("Expected shared TLS secret '%s', got %v", TLSWildcardSecretName, shared.Spec.TLS)
+	}
+	if _, ok := shared.Annotations["cert-manager.io/cluster-issuer"]; ok {
+		t.Errorf("Ingress using the shared certificate should not request its own certificate")
+	}
+
+	withoutTLS := buildIngress("demo", "demo", demoRules, nil)
+	if len(withoutTLS.Spec.TLS) != 0 {
+		t.Errorf("Expected no TLS config, got %v", withoutTLS.Spec.TLS)
+	}
+	if withoutTLS.Annotations["nginx.ingress.kubernetes.io/ssl-redirect"] != FalseValue {
+		t.Errorf("Expected ssl-redirect to be disabled without TLS")
+	}
+
+	rule := withoutTLS.Spec.Rules[0]
+	if rule.Host != "demo.test-cluster.local" {
+		t.Errorf("Expected host 'demo.test-cluster.local', got '%s'", rule.Host)
+	}
+	backend := rule.HTTP.Paths[0].Backend.Service
+	if backend.Name != "demo" || backend.Port.Number != 80 {
+		t.Errorf("Unexpected backend: %v", backend)
+	}
+}
+
+func TestBuildMultiRuleIngress(t *testing.T) {
+	ingress := &Ingress{ClusterName: "test-cluster"}
+	issuer := &tlsIssuer{annotation: "cert-manager.io/cluster-issuer", name: TLSClusterIssuerName}
+
+	type path struct {
+		path    string
+		service string
+		port    int32
+	}
+	tests := []struct {
+		name     string
+		routes   []IngressRoute
+		hosts    []string
+		paths    map[string][]path
+		issuer   *tlsIssuer
+		tlsHosts []string
+		secret   string
+	}{
+		{
+			name:   "single host",
+			hosts:  []string{"grafana.test-cluster.local"},
+			paths:  map[string][]path{"grafana.test-cluster.local": {{"/", "grafana", 3000}}},
+			issuer: issuer, tlsHosts: []string{"grafana.test-cluster.local"}, secret: "grafana-tls",
+		},
+		{
+			name:   "extra path on the same host",
+			routes: []IngressRoute{{Path: "/grafana"}},
+			hosts:  []string{"grafana.test-cluster.local"},
+			paths: map[string][]path{
+				"grafana.test-cluster.local": {{"/", "grafana", 3000}, {"/grafana", "grafana", 3000}},
+			},
+		},
+		{
+			name:   "alternate hostnames",
+			routes: []IngressRoute{{Subdomain: "metrics"}, {Subdomain: "monitoring", Path: "/grafana"}},
+			hosts:  []string{"grafana.test-cluster.local", "metrics.test-cluster.local", "monitoring.test-cluster.local"},
+			paths: map[string][]path{
+				"grafana.test-cluster.local":    {{"/", "grafana", 3000}},
+				"metrics.test-cluster.local":    {{"/", "grafana", 3000}},
+				"monitoring.test-cluster.local": {{"/grafana", "grafana", 3000}},
+			},
+			issuer: issuer,
+			tlsHosts: []string{
+				"grafana.test-cluster.local", "metrics.test-cluster.local", "monitoring.test-cluster.local",
+			},
+			secret: "grafana-tls",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := ingress.serviceIngressRules("grafana", 3000, "grafana", tt.routes)
+			built := buildIngress("monitoring", "grafana", rules, tt.issuer)
+
+			var hosts []string
+			for _, rule := range built.Spec.Rules {
+				hosts = append(hosts, rule.Host)
+				var paths []path
+				for _, p := range rule.HTTP.Paths {
+					if *p.PathType != networkingv1.PathTypePrefix {
+						t.Errorf("Expected prefix path type for %s%s, got %s", rule.Host, p.Path, *p.PathType)
+					}
+					paths = append(paths, path{p.Path, p.Backend.Service.Name, p.Backend.Service.Port.Number})
+				}
+				if !reflect.DeepEqual(paths, tt.paths[rule.Host]) {
+					t.Errorf("Expected paths %v for %s, got %v", tt.paths[rule.Host], rule.Host, paths)
+				}
+			}
+			if !reflect.DeepEqual(hosts, tt.hosts) {
+				t.Errorf("Expected hosts %v, got %v", tt.hosts, hosts)
+			}
+
+			if tt.issuer == nil {
+				if len(built.Spec.TLS) != 0 {
+					t.Errorf("Expected no TLS config, got %v", built.Spec.TLS)
+				}
+				return
+			}
+			if len(built.Spec.TLS) != 1 || built.Spec.TLS[0].SecretName != tt.secret ||
+				!reflect.DeepEqual(built.Spec.TLS[0].Hosts, tt.tlsHosts) {
+				t.Errorf("Expected TLS for %v with secret '%s', got %v", tt.tlsHosts, tt.secret, built.Spec.TLS)
+			}
+		})
+	}
+}
+
+func TestIngressURL(t *testing.T) {
+	issuer := &tlsIssuer{annotation: "cert-manager.io/cluster-issuer", name: TLSClusterIssuerName}
+
+	withTLS := buildIngress("demo", "demo", demoRules, issuer)
+	if got := ingressURL(withTLS); got != "https://demo.test-cluster.local" {
+		t.Errorf("Expected https URL, got '%s'", got)
+	}
+
+	withoutTLS := buildIngress("demo", "demo", demoRules, nil)
+	if got := ingressURL(withoutTLS); got != "http://demo.test-cluster.local" {
+		t.Errorf("Expected http URL, got '%s'", got)
+	}
+
+	withoutTLS.Spec.Rules = nil
+	if got := ingressURL(withoutTLS); got != "" {
+		t.Errorf("Expected no URL for an ingress without rules, got '%s'", got)
+	}
+}
+
+func TestExposedServices(t *testing.T) {
+	services := ExposedServices()
+	if !slices.Equal(services, []string{"argocd", DashboardName, DemoName}) {
+		t.Errorf("Expected argocd, dashboard and demo to be exposed, got %v", services)
+	}
+
+	if _, err := (&Ingress{}).GetServiceURL("unknown"); err == nil {
+		t.Error("Expected error for an unknown service")
+	}
+}
+
+// lbServiceAfter returns a clientset whose service gets the given load balancer ingress
+// from the assignAfter-th get onwards, counting gets in polls
+func lbServiceAfter(assignAfter int, lb v1.LoadBalancerIngress, polls *int) *fake.Clientset {
+	cs := fake.NewSimpleClientset()
+	cs.PrependReactor("get", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		*polls++
+		svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: NginxControllerName, Namespace: NginxNamespace}}
+		if *polls >= assignAfter {
+			svc.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{lb}
+		}
+		return true, svc, nil
+	})
+	return cs
+}
+
+func TestWaitForLoadBalancerIP(t *testing.T) {
+	interval := loadBalancerPollInterval
+	loadBalancerPollInterval = time.Millisecond
+	defer func() { loadBalancerPollInterval = interval }()
+
+	tests := []struct {
+		name        string
+		assignAfter int
+		lb          v1.LoadBalancerIngress
+		expected    string
+		expectError bool
+	}{
+		{name: "assigned right away", assignAfter: 1, lb: v1.LoadBalancerIngress{IP: "10.0.0.10"}, expected: "10.0.0.10"},
+		{name: "assigned after polls", assignAfter: 3, lb: v1.LoadBalancerIngress{IP: "10.0.0.10"}, expected: "10.0.0.10"},
+		{
+			name:        "hostname",
+			assignAfter: 2,
+			lb:          v1.LoadBalancerIngress{Hostname: "lb.example.com"},
+			expected:    "lb.example.com",
+		},
+		{name: "never assigned", assignAfter: 1000, lb: v1.LoadBalancerIngress{IP: "10.0.0.10"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			polls := 0
+			cs := lbServiceAfter(tt.assignAfter, tt.lb, &polls)
+			ingress := &Ingress{k8sClient: &k8s.K8sClient{Clientset: cs}}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			address, err := ingress.waitForLoadBalancerIP(ctx, NginxControllerName, NginxNamespace)
+			if tt.expectError {
+				if !errors.Is(err, errNoLoadBalancerIP) {
+					t.Errorf("expected errNoLoadBalancerIP, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if address != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, address)
+			}
+			if polls != tt.assignAfter {
+				t.Errorf("expected %d polls, got %d", tt.assignAfter, polls)
+			}
+		})
+	}
+}
+
+func TestIngressPreInstallCheck(t *testing.T) {
+	nginxService := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: NginxControllerName, Namespace: NginxNamespace},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
+	}
+
+	tests := []struct {
+		name        string
+		objects     []runtime.Object
+		expectError bool
+	}{
+		{name: "nginx service present", objects: []runtime.Object{nginxService}},
+		{name: "nginx service missing", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ingress := &Ingress{k8sClient: &k8s.K8sClient{Clientset: fake.NewSimpleClientset(tt.objects...)}}
+			err := preInstallCheck(ingress, "kubeconfig")
+			if tt.expectError != (err != nil) {
+				t.Errorf("expected error %v, got %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+func TestEnsureNginxLoadBalancer(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceType v1.ServiceType
+		expected    v1.ServiceType
+		updated     bool
+	}{
+		{name: "already load balancer", serviceType: v1.ServiceTypeLoadBalancer, expected: v1.ServiceTypeLoadBalancer},
+		{name: "node port is kept", serviceType: v1.ServiceTypeNodePort, expected: v1.ServiceTypeNodePort},
+		{
+			name:        "cluster IP is switched",
+			serviceType: v1.ServiceTypeClusterIP,
+			expected:    v1.ServiceTypeLoadBalancer,
+			updated:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := fake.NewSimpleClientset(&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: NginxControllerName, Namespace: NginxNamespace},
+				Spec:       v1.ServiceSpec{Type: tt.serviceType},
+			})
+			ingress := &Ingress{k8sClient: &k8s.K8sClient{Clientset: cs}}
+
+			svc, err := ingress.ensureNginxLoadBalancer()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if svc.Spec.Type != tt.expected {
+				t.Errorf("expected service type %s, got %s", tt.expected, svc.Spec.Type)
+			}
+
+			updated := slices.ContainsFunc(cs.Actions(), func(a k8stesting.Action) bool {
+				return a.GetVerb() == "update"
+			})
+			if updated != tt.updated {
+				t.Errorf("expected update %v, got %v", tt.updated, updated)
+			}
+		})
+	}
+}
@@ -2,7 +2,9 @@ package plugins
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -10,6 +12,7 @@ import (
 	"github.com/mrgb7/playground/pkg/logger"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sretry "k8s.io/client-go/util/retry"
 )
 
 const (
@@ -17,6 +20,9 @@ const (
 	InstallerTrackerNamespace     = "kube-system"
 	InstallerTypeHelm             = "helm"
 	InstallerTypeArgoCD           = "argocd"
+
+	pluginValuesKeySuffix    = ".values"
+	pluginNamespaceKeySuffix = ".namespace"
 )
 
 type InstallerTracker struct {
@@ -40,20 +46,11 @@ func (t *InstallerTracker) RecordPluginInstaller(pluginName, installerType strin
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	configMap, err := t.getOrCreateTrackerConfigMap(ctx)
+	err := t.updateTrackerConfigMap(ctx, func(data map[string]string) {
+		data[pluginName] = installerType
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get or create tracker ConfigMap: %w", err)
-	}
-
-	if configMap.Data == nil {
-		configMap.Data = make(map[string]string)
-	}
-
-	configMap.Data[pluginName] = installerType
-
-	_, err = t.k8sClient.Clientset.CoreV1().ConfigMaps(InstallerTrackerNamespace).Update(ctx, configMap, metav1.UpdateOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to update tracker ConfigMap: %w", err)
+		return err
 	}
 
 	logger.Debugln("Recorded installer type '%s' for plugin '%s'", installerType, pluginName)
@@ -80,6 +77,9 @@ func (t *InstallerTracker) GetAllPluginByInstaller(installer string) ([]string,
 
 	var data []string
 	for plugin, installerType := range configMap.Data {
+		if strings.HasSuffix(plugin, pluginValuesKeySuffix) || strings.HasSuffix(plugin, pluginNamespaceKeySuffix) {
+			continue
+		}
 		if installerType == installer {
 			data = append(data, plugin)
 		}
@@ -116,7 +116,8 @@ func (t *InstallerTracker) RemovePluginInstaller(pluginName string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	configMap, err := t.k8sClient.Clientset.CoreV1().ConfigMaps(InstallerTrackerNamespace).Get(
+	// without the ConfigMap there is nothing to remove, so don't create it
+	_, err := t.k8sClient.Clientset.CoreV1().ConfigMaps(InstallerTrackerNamespace).Get(
 		ctx, InstallerTrackerConfigMapName, metav1.GetOptions{})
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
@@ -126,21 +127,168 @@ func (t *InstallerTracker) RemovePluginInstaller(pluginName string) error {
 		return fmt.Errorf("failed to get tracker ConfigMap: %w", err)
 	}
 
-	if configMap.Data == nil {
-		return nil
+	err = t.updateTrackerConfigMap(ctx, func(data map[string]string) {
+		delete(data, pluginName)
+		delete(data, pluginNamespaceKey(pluginName))
+		delete(data, pluginValuesKey(pluginName))
+	})
+	if err != nil {
+		return err
 	}
 
-	delete(configMap.Data, pluginName)
+	logger.Debugln("Removed installer tracking record for plugin '%s'", pluginName)
+	return nil
+}
 
-	_, err = t.k8sClient.Clientset.CoreV1().ConfigMaps(InstallerTrackerNamespace).Update(ctx, configMap, metav1.UpdateOptions{})
+// RecordPluginValues stores the override values a plugin was installed with so later
+// upgrades can reapply them
+func (t *InstallerTracker) RecordPluginValues(pluginName string, values map[string]interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	data, err := encodePluginValues(values)
 	if err != nil {
-		return fmt.Errorf("failed to update tracker ConfigMap: %w", err)
+		return err
 	}
 
-	logger.Debugln("Removed installer tracking record for plugin '%s'", pluginName)
+	err = t.updateTrackerConfigMap(ctx, func(configData map[string]string) {
+		configData[pluginValuesKey(pluginName)] = data
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Debugln("Recorded override values for plugin '%s'", pluginName)
+	return nil
+}
+
+// GetPluginValues returns the override values recorded for a plugin, or nil when none were recorded
+func (t *InstallerTracker) GetPluginValues(pluginName string) (map[string]interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	configMap, err := t.k8sClient.Clientset.CoreV1().ConfigMaps(InstallerTrackerNamespace).Get(
+		ctx, InstallerTrackerConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			logger.Debugln("Tracker ConfigMap not found, no values recorded for plugin '%s'", pluginName)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get tracker ConfigMap: %w", err)
+	}
+
+	return decodePluginValues(configMap.Data[pluginValuesKey(pluginName)])
+}
+
+// RecordPluginNamespace stores the namespace a plugin was installed into
+func (t *InstallerTracker) RecordPluginNamespace(pluginName, namespace string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err := t.updateTrackerConfigMap(ctx, func(data map[string]string) {
+		data[pluginNamespaceKey(pluginName)] = namespace
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Debugln("Recorded namespace '%s' for plugin '%s'", namespace, pluginName)
 	return nil
 }
 
+// GetPluginNamespace returns the namespace recorded for a plugin, or an empty string when
+// none was recorded
+func (t *InstallerTracker) GetPluginNamespace(pluginName string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	configMap, err := t.k8sClient.Clientset.CoreV1().ConfigMaps(InstallerTrackerNamespace).Get(
+		ctx, InstallerTrackerConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get tracker ConfigMap: %w", err)
+	}
+
+	return configMap.Data[pluginNamespaceKey(pluginName)], nil
+}
+
+func pluginNamespaceKey(pluginName string) string {
+	return pluginName + pluginNamespaceKeySuffix
+}
+
+func pluginValuesKey(pluginName string) string {
+	return pluginName + pluginValuesKeySuffix
+}
+
+func encodePluginValues(values map[string]interface{}) (string, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal plugin values: %w", err)
+	}
+	return string(data), nil
+}
+
+// decodePluginValues parses stored values, turning whole numbers back into ints
+// so they match what --set produced
+func decodePluginValues(data string) (map[string]interface{}, error) {
+	if data == "" {
+		return nil, nil
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &values); err != nil {
+		return nil, fmt.Errorf("failed to parse plugin values: %w", err)
+	}
+	return restoreInts(values).(map[string]interface{}), nil
+}
+
+func restoreInts(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			v[key] = restoreInts(nested)
+		}
+		return v
+	case []interface{}:
+		for i, nested := range v {
+			v[i] = restoreInts(nested)
+		}
+		return v
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int(v)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// updateTrackerConfigMap applies update to the tracker data, retrying on conflicts with
+// plugins that are installed at the same time
+func (t *InstallerTracker) updateTrackerConfigMap(ctx context.Context, update func(data map[string]string)) error {
+	return k8sretry.RetryOnConflict(k8sretry.DefaultRetry, func() error {
+		configMap, err := t.getOrCreateTrackerConfigMap(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get or create tracker ConfigMap: %w", err)
+		}
+
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		update(configMap.Data)
+
+		_, err = t.k8sClient.Clientset.CoreV1().ConfigMaps(InstallerTrackerNamespace).Update(
+			ctx, configMap, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to update tracker ConfigMap: %w", err)
+		}
+		return nil
+	})
+}
+
 func (t *InstallerTracker) getOrCreateTrackerConfigMap(ctx context.Context) (*v1.ConfigMap, error) {
 	configMap, err := t.k8sClient.Clientset.CoreV1().ConfigMaps(InstallerTrackerNamespace).Get(
 		ctx, InstallerTrackerConfigMapName, metav1.GetOptions{})
@@ -1,7 +1,13 @@
 package plugins
 
 import (
+	"context"
+	"reflect"
 	"testing"
+
+	"github.com/mrgb7/playground/internal/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestInstallerTrackerConstants(t *testing.T) {
@@ -91,3 +97,103 @@ func TestInstallerTrackerStructure(t *testing.T) {
 		t.Errorf("Expected kubeConfig to be 'test-config', got '%s'", tracker.kubeConfig)
 	}
 }
+
+func TestPluginValuesRoundTrip(t *testing.T) {
+	values := map[string]interface{}{
+		"server": map[string]interface{}{
+			"replicas": 2,
+			"insecure": true,
+			"resources": map[string]interface{}{
+				"limits": map[string]interface{}{"memory": "512Mi", "cpu": 0.5},
+			},
+		},
+		"ports":  []interface{}{80, 443},
+		"domain": "argocd.test.local",
+	}
+
+	data, err := encodePluginValues(values)
+	if err != nil {
+		t.Fatalf("Failed to encode values: %v", err)
+	}
+
+	configMap := map[string]string{pluginValuesKey("argocd"): data}
+	decoded, err := decodePluginValues(configMap[pluginValuesKey("argocd")])
+	if err != nil {
+		t.Fatalf("Failed to decode values: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, values) {
+		t.Errorf("Expected %v after round trip, got %v", values, decoded)
+	}
+
+	empty, err := decodePluginValues(configMap[pluginValuesKey("nginx")])
+	if err != nil || empty != nil {
+		t.Errorf("Expected no values for an unrecorded plugin, got %v, %v", empty, err)
+	}
+
+	if _, err := decodePluginValues("{not json"); err == nil {
+		t.Error("Expected error for unreadable values")
+	}
+}
+
+func TestPluginValuesKey(t *testing.T) {
+	if got := pluginValuesKey("argocd"); got != "argocd.values" {
+		t.Errorf("Expected key 'argocd.values', got '%s'", got)
+	}
+}
+
+func TestPluginNamespaceRecords(t *testing.T) {
+	tracker := &InstallerTracker{k8sClient: &k8s.K8sClient{Clientset: fake.NewSimpleClientset()}}
+
+	if namespace, err := tracker.GetPluginNamespace("argocd"); err != nil || namespace != "" {
+		t.Errorf("expected no namespace before the tracker exists, got %q, %v", namespace, err)
+	}
+
+	if err := tracker.RecordPluginInstaller("argocd", InstallerTypeHelm); err != nil {
+		t.Fatalf("failed to record installer: %v", err)
+	}
+	if err := tracker.RecordPluginNamespace("argocd", "platform"); err != nil {
+		t.Fatalf("failed to record namespace: %v", err)
+	}
+
+	if namespace, err := tracker.GetPluginNamespace("argocd"); err != nil || namespace != "platform" {
+		t.Errorf("expected namespace 'platform', got %q, %v", namespace, err)
+	}
+	if plugins, err := tracker.GetAllPluginByInstaller(InstallerTypeHelm); err != nil ||
+		!reflect.DeepEqual(plugins, []string{"argocd"}) {
+		t.Errorf("expected only the argocd plugin, got %v, %v", plugins, err)
+	}
+
+	if err := tracker.RemovePluginInstaller("argocd"); err != nil {
+		t.Fatalf("failed to remove installer: %v", err)
+	}
+	if namespace, err := tracker.GetPluginNamespace("argocd"); err != nil || namespace != "" {
+		t.Errorf("expected the namespace to be removed with the plugin, got %q, %v", namespace, err)
+	}
+}
+
+func TestRemovePluginInstallerValues(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	tracker := &InstallerTracker{k8sClient: &k8s.K8sClient{Clientset: clientset}}
+
+	if err := tracker.RemovePluginInstaller("argocd"); err != nil {
+		t.Fatalf("expected no error without the tracker, got %v", err)
+	}
+	_, err := clientset.CoreV1().ConfigMaps(InstallerTrackerNamespace).Get(
+		context.Background(), InstallerTrackerConfigMapName, metav1.GetOptions{})
+	if err == nil {
+		t.Error("expected removing from a missing tracker not to create it")
+	}
+
+	if err := tracker.RecordPluginInstaller("argocd", InstallerTypeHelm); err != nil {
+		t.Fatalf("failed to record installer: %v", err)
+	}
+	if err := tracker.RecordPluginValues("argocd", map[string]interface{}{"server": "x"}); err != nil {
+		t.Fatalf("failed to record values: %v", err)
+	}
+	if err := tracker.RemovePluginInstaller("argocd"); err != nil {
+		t.Fatalf("failed to remove installer: %v", err)
+	}
+	if values, err := tracker.GetPluginValues("argocd"); err != nil || len(values) != 0 {
+		t.Errorf("expected the values to be removed with the plugin, got %v, %v", values, err)
+	}
+}
@@ -3,11 +3,13 @@ package plugins
 import (
 	"context"
 	"fmt"
+	"net"
 	"strings"
 	"time"
 
 	"github.com/mrgb7/playground/internal/k8s"
 	"github.com/mrgb7/playground/pkg/logger"
+	"github.com/mrgb7/playground/pkg/retry"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -22,11 +24,32 @@ var (
 	repoName     = "metallb"
 )
 
+const (
+	lbRangeStart            = 100
+	lbRangeEnd              = 254
+	lbRangeSize             = 5
+	lbRangeAnnotationPrefix = "playground.mrgb7.io/ip-range."
+	LBRangeOverrideKey      = "addressPool.range"
+)
+
+var ipAddressPoolResource = schema.GroupVersionResource{
+	Group:    "metallb.io",
+	Version:  "v1beta1",
+	Resource: "ipaddresspools",
+}
+
+var l2AdvertisementResource = schema.GroupVersionResource{
+	Group:    "metallb.io",
+	Version:  "v1beta1",
+	Resource: "l2advertisements",
+}
+
 type LoadBalancer struct {
 	KubeConfig      string
 	k8sClient       *k8s.K8sClient
 	MasterClusterIP string
 	ClusterName     string
+	overrideRange   string
 	*BasePlugin
 }
 
@@ -92,29 +115,21 @@ func (l *LoadBalancer) ensure() error {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return fmt.Errorf("timeout waiting for ensure %v", timeout)
-		case <-ticker.C:
-			_, err := l.k8sClient.GetNameSpace(namespace, ctx)
-			if err != nil {
-				continue
-			}
-			_, err = l.k8sClient.Clientset.
-				AdmissionregistrationV1().
-				ValidatingWebhookConfigurations().
-				Get(ctx, "metallb-webhook-configuration", metav1.GetOptions{})
-			if err != nil {
-				continue
-			}
-			logger.Successln("LoadBalancer is ensured")
-			return nil
+	err := retry.Do(ctx, retry.Options{Backoff: 5 * time.Second}, func() error {
+		if _, err := l.k8sClient.GetNameSpace(namespace, ctx); err != nil {
+			return err
 		}
+		_, err := l.k8sClient.Clientset.
+			AdmissionregistrationV1().
+			ValidatingWebhookConfigurations().
+			Get(ctx, "metallb-webhook-configuration", metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("timeout waiting for ensure %v", timeout)
 	}
+	logger.Successln("LoadBalancer is ensured")
+	return nil
 }
 
 func (l *LoadBalancer) Uninstall(kubeConfig, clusterName string, ensure ...bool) error {
@@ -135,11 +150,9 @@ func (l *LoadBalancer) Status() string {
 }
 
 func (l *LoadBalancer) addl2IpPool() error {
-	ipRange := l.getIPRange()
-	ipPooRes := schema.GroupVersionResource{
-		Group:    "metallb.io",
-		Version:  "v1beta1",
-		Resource: "ipaddresspools",
+	ipRange := l.overrideRange
+	if ipRange == "" {
+		ipRange = l.allocateIPRange()
 	}
 
 	ipPool := &unstructured.Unstructured{
@@ -149,6 +162,9 @@ func (l *LoadBalancer) addl2IpPool() error {
 			"metadata": map[string]interface{}{
 				"name":      "k3s-pool-ip",
 				"namespace": "metallb-system",
+				"annotations": map[string]interface{}{
+					l.rangeAnnotationKey(): ipRange,
+				},
 			},
 			"spec": map[string]interface{}{
 				"addresses": []interface{}{ipRange},
@@ -161,57 +177,16 @@ func (l *LoadBalancer) addl2IpPool() error {
 		Kind:    "IPAddressPool",
 	})
 
-	_, err := l.k8sClient.Dynamic.Resource(ipPooRes).
-		Namespace(namespace).
-		Create(context.TODO(), ipPool, metav1.CreateOptions{})
-
-	switch {
-	case err != nil && strings.Contains(err.Error(), "already exists"):
-		// Get the existing IP address pool to preserve metadata
-		existing, getErr := l.k8sClient.Dynamic.Resource(ipPooRes).
-			Namespace(namespace).
-			Get(context.TODO(), "k3s-pool-ip", metav1.GetOptions{})
-		if getErr != nil {
-			return fmt.Errorf("failed to get existing IP address pool: %w", getErr)
-		}
-
-		// Preserve the existing metadata and update only the spec
-		ipPool.SetResourceVersion(existing.GetResourceVersion())
-		ipPool.SetUID(existing.GetUID())
-		ipPool.SetCreationTimestamp(existing.GetCreationTimestamp())
-		ipPool.SetGeneration(existing.GetGeneration())
-
-		// Copy any existing labels and annotations
-		if labels := existing.GetLabels(); labels != nil {
-			ipPool.SetLabels(labels)
-		}
-		if annotations := existing.GetAnnotations(); annotations != nil {
-			ipPool.SetAnnotations(annotations)
-		}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
 
-		_, err = l.k8sClient.Dynamic.Resource(ipPooRes).
-			Namespace(namespace).
-			Update(context.TODO(), ipPool, metav1.UpdateOptions{})
-		if err != nil {
-			return fmt.Errorf("failed to update existing IP address pool: %w", err)
-		}
-		logger.Infoln("Updated existing IP address pool")
-	case err != nil:
-		logger.Errorln("failed to create ip address pool: %v", err)
-		return fmt.Errorf("failed to create ip address pool: %w", err)
-	default:
-		logger.Successln("Created IP address pool successfully")
+	if err := l.k8sClient.ApplyUnstructured(ctx, ipAddressPoolResource, namespace, ipPool); err != nil {
+		return fmt.Errorf("failed to apply ip address pool: %w", err)
 	}
 	return nil
 }
 
 func (l *LoadBalancer) addl2Adv() error {
-	l2AdvRes := schema.GroupVersionResource{
-		Group:    "metallb.io",
-		Version:  "v1beta1",
-		Resource: "l2advertisements",
-	}
-
 	l2Adv := &unstructured.Unstructured{
 		Object: map[string]interface{}{
 			"apiVersion": "metallb.io/v1beta1",
@@ -231,46 +206,11 @@ func (l *LoadBalancer) addl2Adv() error {
 		Kind:    "L2Advertisement",
 	})
 
-	_, err := l.k8sClient.Dynamic.Resource(l2AdvRes).
-		Namespace(namespace).
-		Create(context.TODO(), l2Adv, metav1.CreateOptions{})
-
-	switch {
-	case err != nil && strings.Contains(err.Error(), "already exists"):
-		// Get the existing L2Advertisement to preserve metadata
-		existing, getErr := l.k8sClient.Dynamic.Resource(l2AdvRes).
-			Namespace(namespace).
-			Get(context.TODO(), "k3s-lb-pool", metav1.GetOptions{})
-		if getErr != nil {
-			return fmt.Errorf("failed to get existing L2Advertisement: %w", getErr)
-		}
-
-		// Preserve the existing metadata and update only the spec
-		l2Adv.SetResourceVersion(existing.GetResourceVersion())
-		l2Adv.SetUID(existing.GetUID())
-		l2Adv.SetCreationTimestamp(existing.GetCreationTimestamp())
-		l2Adv.SetGeneration(existing.GetGeneration())
-
-		// Copy any existing labels and annotations
-		if labels := existing.GetLabels(); labels != nil {
-			l2Adv.SetLabels(labels)
-		}
-		if annotations := existing.GetAnnotations(); annotations != nil {
-			l2Adv.SetAnnotations(annotations)
-		}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
 
-		_, err = l.k8sClient.Dynamic.Resource(l2AdvRes).
-			Namespace(namespace).
-			Update(context.TODO(), l2Adv, metav1.UpdateOptions{})
-		if err != nil {
-			return fmt.Errorf("failed to update existing L2Advertisement: %w", err)
-		}
-		logger.Infoln("Updated existing L2Advertisement")
-	case err != nil:
-		logger.Errorln("failed to create l2 advertisement: %v", err)
-		return fmt.Errorf("failed to create l2 advertisement: %w", err)
-	default:
-		logger.Successln("Created L2Advertisement successfully")
+	if err := l.k8sClient.ApplyUnstructured(ctx, l2AdvertisementResource, namespace, l2Adv); err != nil {
+		return fmt.Errorf("failed to apply l2 advertisement: %w", err)
 	}
 	return nil
 }
@@ -290,6 +230,113 @@ func (l *LoadBalancer) deleteValidationWebhookConfig() error {
 	return nil
 }
 
+// allocateIPRange picks the first free block of lbRangeSize addresses in the master's
+// /24 that no existing IPAddressPool uses, starting from the cluster's hashed block since
+// the pools of other clusters on the same network are not visible here. A range previously
+// recorded for this cluster is reused so re-installs are stable. Falls back to getIPRange
+// when pools cannot be listed.
+func (l *LoadBalancer) allocateIPRange() string {
+	pools, err := l.k8sClient.Dynamic.Resource(ipAddressPoolResource).
+		Namespace("").
+		List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		logger.Debugln("Failed to list IP address pools, using hashed range: %v", err)
+		return l.getIPRange()
+	}
+
+	prefix := subnetPrefix(l.MasterClusterIP)
+	occupied := make([][2]int, 0)
+	for _, pool := range pools.Items {
+		if recorded, ok := pool.GetAnnotations()[l.rangeAnnotationKey()]; ok && recorded != "" {
+			return recorded
+		}
+		addresses, _, _ := unstructured.NestedStringSlice(pool.Object, "spec", "addresses")
+		if pool.GetName() == "k3s-pool-ip" && pool.GetNamespace() == namespace && len(addresses) > 0 {
+			// Pool created before ranges were recorded in annotations
+			return addresses[0]
+		}
+		for _, address := range addresses {
+			if start, end, ok := parseLastOctetRange(address, prefix); ok {
+				occupied = append(occupied, [2]int{start, end})
+			}
+		}
+	}
+
+	start, ok := firstFreeBlock(occupied, l.getClusterOffset())
+	if !ok {
+		logger.Warnln("No free IP block found for cluster %s, using hashed range", l.ClusterName)
+		return l.getIPRange()
+	}
+	return fmt.Sprintf("%s.%d-%s.%d", prefix, start, prefix, start+lbRangeSize-1)
+}
+
+func (l *LoadBalancer) rangeAnnotationKey() string {
+	return lbRangeAnnotationPrefix + l.ClusterName
+}
+
+// firstFreeBlock returns the start of the first lbRangeSize block that does not overlap
+// any occupied [start, end] last-octet range, trying the blocks from the offset-th one
+// on and wrapping around
+func firstFreeBlock(occupied [][2]int, offset int) (int, bool) {
+	blocks := (lbRangeEnd - lbRangeStart + 1) / lbRangeSize
+	for i := 0; i < blocks; i++ {
+		start := lbRangeStart + (offset+i)%blocks*lbRangeSize
+		end := start + lbRangeSize - 1
+		free := true
+		for _, r := range occupied {
+			if start <= r[1] && r[0] <= end {
+				free = false
+				break
+			}
+		}
+		if free {
+			return start, true
+		}
+	}
+	return 0, false
+}
+
+// parseLastOctetRange parses a MetalLB "a.b.c.x-a.b.c.y" or "a.b.c.x/32" address entry
+// within the given /24 prefix into its last-octet bounds
+func parseLastOctetRange(address, prefix string) (int, int, bool) {
+	lastOctet := func(ip string) (int, bool) {
+		parsed := net.ParseIP(strings.TrimSpace(ip)).To4()
+		if parsed == nil || fmt.Sprintf("%d.%d.%d", parsed[0], parsed[1], parsed[2]) != prefix {
+			return 0, false
+		}
+		return int(parsed[3]), true
+	}
+
+	if from, to, isRange := strings.Cut(address, "-"); isRange {
+		start, ok1 := lastOctet(from)
+		end, ok2 := lastOctet(to)
+		return start, end, ok1 && ok2 && start <= end
+	}
+
+	ip, ipNet, err := net.ParseCIDR(address)
+	if err != nil {
+		return 0, 0, false
+	}
+	start, ok := lastOctet(ip.Mask(ipNet.Mask).String())
+	if !ok {
+		return 0, 0, false
+	}
+	ones, bits := ipNet.Mask.Size()
+	end := start + (1 << (bits - ones)) - 1
+	if end > 255 {
+		end = 255
+	}
+	return start, end, true
+}
+
+func subnetPrefix(ip string) string {
+	parts := strings.Split(ip, ".")
+	if len(parts) != 4 {
+		return ""
+	}
+	return strings.Join(parts[:3], ".")
+}
+
 func (l *LoadBalancer) getIPRange() string {
 	ipParts := strings.Split(l.MasterClusterIP, ".")
 	dhcp := ipParts[:3]
@@ -330,6 +377,52 @@ func (l *LoadBalancer) getClusterOffset() int {
 	return hash % 31
 }
 
+func (l *LoadBalancer) GetOverrideSchema() map[string]OverrideType {
+	return map[string]OverrideType{LBRangeOverrideKey: OverrideTypeString}
+}
+
+func (l *LoadBalancer) ValidateOverrideValues(values map[string]interface{}) error {
+	if err := validateOverrideKeys(values, LBRangeOverrideKey); err != nil {
+		return err
+	}
+	if v, ok := GetNestedValue(values, LBRangeOverrideKey); ok {
+		r, isString := v.(string)
+		if !isString {
+			return fmt.Errorf("%s must be a string like 192.168.64.200-192.168.64.210", LBRangeOverrideKey)
+		}
+		return validateIPRange(r)
+	}
+	return nil
+}
+
+func (l *LoadBalancer) SetOverrideValues(values map[string]interface{}) {
+	if v, ok := GetNestedValue(values, LBRangeOverrideKey); ok {
+		if r, isString := v.(string); isString {
+			l.overrideRange = r
+		}
+	}
+}
+
+// validateIPRange checks r is a "start-end" IPv4 range within a single /24
+func validateIPRange(r string) error {
+	from, to, ok := strings.Cut(r, "-")
+	if !ok {
+		return fmt.Errorf("IP range %q must be in format start-end", r)
+	}
+	start := net.ParseIP(strings.TrimSpace(from)).To4()
+	end := net.ParseIP(strings.TrimSpace(to)).To4()
+	if start == nil || end == nil {
+		return fmt.Errorf("IP range %q contains an invalid IPv4 address", r)
+	}
+	if !start.Mask(net.CIDRMask(24, 32)).Equal(end.Mask(net.CIDRMask(24, 32))) {
+		return fmt.Errorf("IP range %q must stay within a single /24 subnet", r)
+	}
+	if start[3] > end[3] {
+		return fmt.Errorf("IP range %q starts after it ends", r)
+	}
+	return nil
+}
+
 func (l *LoadBalancer) GetDependencies() []string {
 	return []string{}
 }
@@ -0,0 +1,121 @@
+package plugins
+
+import (
+	"testing"
+)
+
+func TestFirstFreeBlock(t *testing.T) {
+	tests := []struct {
+		name          string
+		occupied      [][2]int
+		offset        int
+		expectedStart int
+		expectedOK    bool
+	}{
+		{"no pools", nil, 0, 100, true},
+		{"first block taken", [][2]int{{100, 104}}, 0, 105, true},
+		{"partial overlap", [][2]int{{103, 106}}, 0, 110, true},
+		{"gap between pools", [][2]int{{100, 104}, {110, 114}}, 0, 105, true},
+		{"range below allocation area", [][2]int{{10, 50}}, 0, 100, true},
+		{"everything taken", [][2]int{{1, 254}}, 0, 0, false},
+		{"hashed block", nil, 7, 135, true},
+		{"hashed block taken", [][2]int{{135, 139}}, 7, 140, true},
+		{"wraps around", [][2]int{{250, 254}}, 30, 100, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, ok := firstFreeBlock(tt.occupied, tt.offset)
+			if ok != tt.expectedOK || start != tt.expectedStart {
+				t.Errorf("expected (%d, %v), got (%d, %v)", tt.expectedStart, tt.expectedOK, start, ok)
+			}
+		})
+	}
+}
+
+func TestParseLastOctetRange(t *testing.T) {
+	tests := []struct {
+		name          string
+		address       string
+		expectedStart int
+		expectedEnd   int
+		expectedOK    bool
+	}{
+		{"range", "192.168.64.100-192.168.64.104", 100, 104, true},
+		{"single ip cidr", "192.168.64.120/32", 120, 120, true},
+		{"small cidr", "192.168.64.128/30", 128, 131, true},
+		{"other subnet", "10.0.0.100-10.0.0.104", 0, 0, false},
+		{"reversed range", "192.168.64.110-192.168.64.100", 0, 0, false},
+		{"malformed", "not-an-ip", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, ok := parseLastOctetRange(tt.address, "192.168.64")
+			if ok != tt.expectedOK {
+				t.Fatalf("expected ok %v, got %v", tt.expectedOK, ok)
+			}
+			if ok && (start != tt.expectedStart || end != tt.expectedEnd) {
+				t.Errorf("expected %d-%d, got %d-%d", tt.expectedStart, tt.expectedEnd, start, end)
+			}
+		})
+	}
+}
+
+func TestSubnetPrefix(t *testing.T) {
+	if got := subnetPrefix("192.168.64.2"); got != "192.168.64" {
+		t.Errorf("expected 192.168.64, got %s", got)
+	}
+	if got := subnetPrefix(""); got != "" {
+		t.Errorf("expected empty prefix for empty IP, got %s", got)
+	}
+}
+
+func TestValidateIPRange(t *testing.T) {
+	tests := []struct {
+		name        string
+		ipRange     string
+		expectError bool
+	}{
+		{"valid range", "192.168.64.200-192.168.64.210", false},
+		{"single address", "192.168.64.200-192.168.64.200", false},
+		{"reversed range", "192.168.64.210-192.168.64.200", true},
+		{"cross subnet", "192.168.64.200-192.168.65.10", true},
+		{"malformed start", "192.168.64-192.168.64.210", true},
+		{"malformed end", "192.168.64.200-abc", true},
+		{"missing separator", "192.168.64.200", true},
+		{"ipv6", "fd00::1-fd00::5", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateIPRange(tt.ipRange)
+			if tt.expectError && err == nil {
+				t.Errorf("expected error for %q", tt.ipRange)
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("unexpected error for %q: %v", tt.ipRange, err)
+			}
+		})
+	}
+}
+
+func TestLoadBalancerOverrideValues(t *testing.T) {
+	lb := &LoadBalancer{}
+	values := map[string]interface{}{
+		"addressPool": map[string]interface{}{"range": "192.168.64.200-192.168.64.210"},
+	}
+
+	if err := lb.ValidateOverrideValues(values); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lb.SetOverrideValues(values)
+	if lb.overrideRange != "192.168.64.200-192.168.64.210" {
+		t.Errorf("expected override range to be set, got %q", lb.overrideRange)
+	}
+
+	invalid := map[string]interface{}{"addressPool": map[string]interface{}{"range": 5}}
+	if err := lb.ValidateOverrideValues(invalid); err == nil {
+		t.Error("expected error for non-string range")
+	}
+}
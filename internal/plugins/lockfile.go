@@ -0,0 +1,128 @@
+package plugins
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/mrgb7/playground/internal/installer"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	LockfileName        = "playground.lock"
+	LockfileAPIVersion  = 1
+	lockfilePermissions = 0o644
+)
+
+// Lockfile pins the exact chart versions and values used to install plugins so a
+// setup can be reproduced later
+type Lockfile struct {
+	APIVersion int                  `yaml:"apiVersion"`
+	Plugins    map[string]LockEntry `yaml:"plugins"`
+}
+
+type LockEntry struct {
+	Chart          string `yaml:"chart"`
+	Repository     string `yaml:"repository"`
+	Version        string `yaml:"version"`
+	ValuesChecksum string `yaml:"valuesChecksum"`
+}
+
+// LockablePlugin is implemented by plugins installed through UnifiedInstall
+type LockablePlugin interface {
+	PinLockEntry(entry *LockEntry)
+	LockEntry() *LockEntry
+}
+
+// VersionPinnablePlugin is implemented by plugins whose chart version can be chosen at install time
+type VersionPinnablePlugin interface {
+	PinVersion(version string)
+}
+
+// LoadLockfile reads a lockfile from path. A missing file yields an empty lockfile.
+func LoadLockfile(path string) (*Lockfile, error) {
+	lock := &Lockfile{
+		APIVersion: LockfileAPIVersion,
+		Plugins:    make(map[string]LockEntry),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("failed to parse lockfile %s: %w", path, err)
+	}
+	if lock.APIVersion != LockfileAPIVersion {
+		return nil, fmt.Errorf("unsupported lockfile apiVersion %d", lock.APIVersion)
+	}
+	if lock.Plugins == nil {
+		lock.Plugins = make(map[string]LockEntry)
+	}
+	return lock, nil
+}
+
+func (l *Lockfile) Save(path string) error {
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("failed to marshal lockfile: %w", err)
+	}
+	if err := os.WriteFile(path, data, lockfilePermissions); err != nil {
+		return fmt.Errorf("failed to write lockfile %s: %w", path, err)
+	}
+	return nil
+}
+
+func newLockEntry(opts *installer.InstallOptions) (*LockEntry, error) {
+	checksum, err := valuesChecksum(opts.Values)
+	if err != nil {
+		return nil, err
+	}
+	entry := &LockEntry{
+		Repository:     opts.RepoURL,
+		Version:        opts.Version,
+		ValuesChecksum: checksum,
+	}
+	if opts.ChartName != nil {
+		entry.Chart = *opts.ChartName
+	}
+	return entry, nil
+}
+
+// applyLockEntry pins opts to the locked chart version and fails if the values no
+// longer match what was locked
+func applyLockEntry(opts *installer.InstallOptions, entry *LockEntry) error {
+	if opts.ChartName != nil && entry.Chart != *opts.ChartName {
+		return fmt.Errorf("lockfile chart %s does not match plugin chart %s", entry.Chart, *opts.ChartName)
+	}
+
+	checksum, err := valuesChecksum(opts.Values)
+	if err != nil {
+		return err
+	}
+	if checksum != entry.ValuesChecksum {
+		return fmt.Errorf("values for %s do not match the lockfile checksum, "+
+			"remove its entry from the lockfile to re-lock", opts.ApplicationName)
+	}
+
+	opts.Version = entry.Version
+	return nil
+}
+
+// valuesChecksum hashes values in a stable form; yaml.v3 sorts map keys on marshal
+func valuesChecksum(values map[string]interface{}) (string, error) {
+	if len(values) == 0 {
+		return "", nil
+	}
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal values: %w", err)
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data)), nil
+}
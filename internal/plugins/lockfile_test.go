@@ -0,0 +1,113 @@
+package plugins
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/mrgb7/playground/internal/installer"
+)
+
+func TestLockfileSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LockfileName)
+
+	lock, err := LoadLockfile(path)
+	if err != nil {
+		t.Fatalf("expected missing lockfile to load empty, got error: %v", err)
+	}
+	if len(lock.Plugins) != 0 {
+		t.Errorf("expected empty lockfile, got %d entries", len(lock.Plugins))
+	}
+
+	lock.Plugins["argocd"] = LockEntry{
+		Chart:          "argo-cd",
+		Repository:     "https://argoproj.github.io/argo-helm",
+		Version:        "8.0.0",
+		ValuesChecksum: "sha256:abc",
+	}
+	if err := lock.Save(path); err != nil {
+		t.Fatalf("failed to save lockfile: %v", err)
+	}
+
+	loaded, err := LoadLockfile(path)
+	if err != nil {
+		t.Fatalf("failed to load lockfile: %v", err)
+	}
+	if loaded.Plugins["argocd"] != lock.Plugins["argocd"] {
+		t.Errorf("expected %+v, got %+v", lock.Plugins["argocd"], loaded.Plugins["argocd"])
+	}
+}
+
+func TestValuesChecksumIsStable(t *testing.T) {
+	a := map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": "d", "e": true}}
+	b := map[string]interface{}{"b": map[string]interface{}{"e": true, "c": "d"}, "a": 1}
+
+	sumA, err := valuesChecksum(a)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sumB, err := valuesChecksum(b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sumA != sumB {
+		t.Errorf("expected equal checksums, got %s and %s", sumA, sumB)
+	}
+
+	empty, _ := valuesChecksum(nil)
+	if empty != "" {
+		t.Errorf("expected empty checksum for nil values, got %s", empty)
+	}
+}
+
+func TestApplyLockEntry(t *testing.T) {
+	chart := "argo-cd"
+	values := map[string]interface{}{"server": map[string]interface{}{"insecure": true}}
+	checksum, _ := valuesChecksum(values)
+
+	tests := []struct {
+		name            string
+		entry           LockEntry
+		expectError     bool
+		expectedVersion string
+	}{
+		{
+			name:            "pins locked version",
+			entry:           LockEntry{Chart: chart, Version: "7.9.0", ValuesChecksum: checksum},
+			expectedVersion: "7.9.0",
+		},
+		{
+			name:        "values drifted",
+			entry:       LockEntry{Chart: chart, Version: "7.9.0", ValuesChecksum: "sha256:other"},
+			expectError: true,
+		},
+		{
+			name:        "chart mismatch",
+			entry:       LockEntry{Chart: "other", Version: "7.9.0", ValuesChecksum: checksum},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &installer.InstallOptions{
+				ApplicationName: "argocd",
+				ChartName:       &chart,
+				Version:         "8.0.0",
+				Values:          values,
+			}
+			err := applyLockEntry(opts, &tt.entry)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if opts.Version != tt.expectedVersion {
+				t.Errorf("expected version %s, got %s", tt.expectedVersion, opts.Version)
+			}
+		})
+	}
+}
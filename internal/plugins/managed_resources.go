@@ -0,0 +1,117 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ResourceRef identifies a Kubernetes resource, Namespace is empty for cluster scoped ones
+type ResourceRef struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// ResourceInspector lists the Kubernetes resources a plugin created that currently exist,
+// which is empty after a clean uninstall
+type ResourceInspector interface {
+	GetManagedResources(kubeConfig string) ([]ResourceRef, error)
+}
+
+// ignoreNotFound drops not found errors, resources that don't exist are not listed
+func ignoreNotFound(err error) error {
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (l *LoadBalancer) GetManagedResources(string) ([]ResourceRef, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var refs []ResourceRef
+	_, err := l.k8sClient.Clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err := ignoreNotFound(err); err != nil {
+		return nil, fmt.Errorf("failed to get namespace %s: %w", namespace, err)
+	}
+	if err == nil {
+		refs = append(refs, ResourceRef{Kind: "Namespace", Name: namespace})
+	}
+
+	for _, r := range []struct {
+		kind string
+		gvr  schema.GroupVersionResource
+	}{
+		{kind: "IPAddressPool", gvr: ipAddressPoolResource},
+		{kind: "L2Advertisement", gvr: l2AdvertisementResource},
+	} {
+		list, err := l.k8sClient.Dynamic.Resource(r.gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+		if err := ignoreNotFound(err); err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", r.gvr.Resource, err)
+		}
+		if err != nil {
+			continue
+		}
+		for _, item := range list.Items {
+			refs = append(refs, ResourceRef{Kind: r.kind, Namespace: item.GetNamespace(), Name: item.GetName()})
+		}
+	}
+	return refs, nil
+}
+
+func (t *TLS) GetManagedResources(string) ([]ResourceRef, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var refs []ResourceRef
+	_, err := t.k8sClient.Clientset.CoreV1().Secrets(t.secretNamespace()).Get(ctx, TLSSecretName, metav1.GetOptions{})
+	if err := ignoreNotFound(err); err != nil {
+		return nil, fmt.Errorf("failed to get CA secret: %w", err)
+	}
+	if err == nil {
+		refs = append(refs, ResourceRef{Kind: "Secret", Namespace: t.secretNamespace(), Name: TLSSecretName})
+	}
+
+	_, err = t.issuerResource().Get(ctx, TLSClusterIssuerName, metav1.GetOptions{})
+	if err := ignoreNotFound(err); err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", t.issuerKind(), err)
+	}
+	if err == nil {
+		refs = append(refs, ResourceRef{Kind: t.issuerKind(), Namespace: t.issuerNamespace, Name: TLSClusterIssuerName})
+	}
+
+	_, err = t.k8sClient.Dynamic.Resource(clusterIssuerGVR).Get(ctx, TLSACMEIssuerName, metav1.GetOptions{})
+	if err := ignoreNotFound(err); err != nil {
+		return nil, fmt.Errorf("failed to get ClusterIssuer %s: %w", TLSACMEIssuerName, err)
+	}
+	if err == nil {
+		refs = append(refs, ResourceRef{Kind: "ClusterIssuer", Name: TLSACMEIssuerName})
+	}
+	return refs, nil
+}
+
+// GetManagedResources lists the ingresses of the services the ingress plugin exposes
+func (i *Ingress) GetManagedResources(kubeConfig string) ([]ResourceRef, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var refs []ResourceRef
+	for _, service := range ExposedServices() {
+		target := exposedServices[service]
+		ns := PluginNamespace(kubeConfig, service, target.namespace)
+		_, err := i.k8sClient.Clientset.NetworkingV1().Ingresses(ns).Get(ctx, target.name, metav1.GetOptions{})
+		if err := ignoreNotFound(err); err != nil {
+			return nil, fmt.Errorf("failed to get ingress for %s: %w", service, err)
+		}
+		if err == nil {
+			refs = append(refs, ResourceRef{Kind: "Ingress", Namespace: ns, Name: target.name})
+		}
+	}
+	return refs, nil
+}
@@ -0,0 +1,184 @@
+package plugins
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mrgb7/playground/internal/k8s"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func metallbObject(kind, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("metallb.io/v1beta1")
+	obj.SetKind(kind)
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	return obj
+}
+
+func TestLoadBalancerManagedResources(t *testing.T) {
+	listKinds := map[schema.GroupVersionResource]string{
+		ipAddressPoolResource:   "IPAddressPoolList",
+		l2AdvertisementResource: "L2AdvertisementList",
+	}
+
+	tests := []struct {
+		name     string
+		objects  []runtime.Object
+		dynamic  []runtime.Object
+		expected []ResourceRef
+	}{
+		{name: "not installed"},
+		{
+			name:    "installed",
+			objects: []runtime.Object{&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}},
+			dynamic: []runtime.Object{
+				metallbObject("IPAddressPool", "k3s-pool-ip"),
+				metallbObject("L2Advertisement", "k3s-lb-pool"),
+			},
+			expected: []ResourceRef{
+				{Kind: "Namespace", Name: namespace},
+				{Kind: "IPAddressPool", Namespace: namespace, Name: "k3s-pool-ip"},
+				{Kind: "L2Advertisement", Namespace: namespace, Name: "k3s-lb-pool"},
+			},
+		},
+		{
+			name:    "pool left behind",
+			dynamic: []runtime.Object{metallbObject("IPAddressPool", "k3s-pool-ip")},
+			expected: []ResourceRef{
+				{Kind: "IPAddressPool", Namespace: namespace, Name: "k3s-pool-ip"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lb := &LoadBalancer{k8sClient: &k8s.K8sClient{
+				Clientset: fake.NewSimpleClientset(tt.objects...),
+				Dynamic:   dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, tt.dynamic...),
+			}}
+
+			refs, err := lb.GetManagedResources("")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(refs, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, refs)
+			}
+		})
+	}
+}
+
+func TestTLSManagedResources(t *testing.T) {
+	caSecret := func(ns string) *v1.Secret {
+		return &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: TLSSecretName, Namespace: ns}}
+	}
+	listKinds := map[schema.GroupVersionResource]string{
+		issuerGVR:        "IssuerList",
+		clusterIssuerGVR: "ClusterIssuerList",
+	}
+
+	tests := []struct {
+		name      string
+		namespace string
+		objects   []runtime.Object
+		issuers   []runtime.Object
+		expected  []ResourceRef
+	}{
+		{name: "not installed"},
+		{
+			name:    "cluster issuer",
+			objects: []runtime.Object{caSecret(CertManagerNamespace)},
+			issuers: []runtime.Object{issuerObject("ClusterIssuer", "")},
+			expected: []ResourceRef{
+				{Kind: "Secret", Namespace: CertManagerNamespace, Name: TLSSecretName},
+				{Kind: "ClusterIssuer", Name: TLSClusterIssuerName},
+			},
+		},
+		{
+			name:      "namespaced issuer",
+			namespace: "team-a",
+			objects:   []runtime.Object{caSecret("team-a")},
+			issuers:   []runtime.Object{issuerObject("Issuer", "team-a")},
+			expected: []ResourceRef{
+				{Kind: "Secret", Namespace: "team-a", Name: TLSSecretName},
+				{Kind: "Issuer", Namespace: "team-a", Name: TLSClusterIssuerName},
+			},
+		},
+		{
+			name:     "ACME issuer only",
+			issuers:  []runtime.Object{acmeIssuerObject()},
+			expected: []ResourceRef{{Kind: "ClusterIssuer", Name: TLSACMEIssuerName}},
+		},
+		{
+			name:     "secret left behind",
+			objects:  []runtime.Object{caSecret(CertManagerNamespace)},
+			expected: []ResourceRef{{Kind: "Secret", Namespace: CertManagerNamespace, Name: TLSSecretName}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &TLS{issuerNamespace: tt.namespace, k8sClient: &k8s.K8sClient{
+				Clientset: fake.NewSimpleClientset(tt.objects...),
+				Dynamic:   dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, tt.issuers...),
+			}}
+
+			refs, err := plugin.GetManagedResources("")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(refs, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, refs)
+			}
+		})
+	}
+}
+
+func TestIngressManagedResources(t *testing.T) {
+	ingressObject := func(ns, name string) *networkingv1.Ingress {
+		return &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns}}
+	}
+
+	tests := []struct {
+		name     string
+		objects  []runtime.Object
+		expected []ResourceRef
+	}{
+		{name: "no ingresses"},
+		{
+			name: "argocd and dashboard",
+			objects: []runtime.Object{
+				ingressObject(ArgocdNamespace, "argocd-server"),
+				ingressObject(DashboardNamespace, DashboardProxyService),
+				ingressObject("apps", "unrelated"),
+			},
+			expected: []ResourceRef{
+				{Kind: "Ingress", Namespace: ArgocdNamespace, Name: "argocd-server"},
+				{Kind: "Ingress", Namespace: DashboardNamespace, Name: DashboardProxyService},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ingress := &Ingress{k8sClient: &k8s.K8sClient{Clientset: fake.NewSimpleClientset(tt.objects...)}}
+
+			refs, err := ingress.GetManagedResources("")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(refs, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, refs)
+			}
+		})
+	}
+}
@@ -2,10 +2,12 @@ package plugins
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/mrgb7/playground/internal/k8s"
 	"github.com/mrgb7/playground/pkg/logger"
+	v1 "k8s.io/api/core/v1"
 )
 
 var (
@@ -15,11 +17,28 @@ var (
 	NginxChartName       = "ingress-nginx"
 	NginxRepoName        = "ingress-nginx"
 	NginxRepoURL         = "https://kubernetes.github.io/ingress-nginx"
+	NginxControllerName  = "nginx-ingress-ingress-nginx-controller"
 )
 
+// nginxArchValues are the chart values that depend on the node architecture. The default
+// backend image is published under a name per architecture, amd64 being the chart default.
+var nginxArchValues = map[string]map[string]interface{}{
+	"arm64": {
+		"defaultBackend": map[string]interface{}{
+			"image": map[string]interface{}{"image": "defaultbackend-arm64"},
+		},
+	},
+}
+
+// NginxServiceTypeOverrideKey selects the controller service type, for networks where the
+// load balancer can't assign an address
+const NginxServiceTypeOverrideKey = "controller.service.type"
+
 type Nginx struct {
 	KubeConfig string
 	*BasePlugin
+
+	serviceType string // overrides the LoadBalancer controller service type
 }
 
 func NewNginx(kubeConfig string) *Nginx {
@@ -79,11 +98,15 @@ func (n *Nginx) Status() string {
 }
 
 func (n *Nginx) GetChartValues() map[string]interface{} {
+	return archValues(n.chartValues(), nginxArchValues, ClusterArch)
+}
+
+func (n *Nginx) chartValues() map[string]interface{} {
 	return map[string]interface{}{
 		"controller": map[string]interface{}{
 			"replicaCount": DefaultNginxReplicas,
 			"service": map[string]interface{}{
-				"type": "LoadBalancer",
+				"type": n.controllerServiceType(),
 			},
 			"config": map[string]interface{}{
 				"enable-vts-status":          "true",
@@ -107,6 +130,42 @@ func (n *Nginx) GetChartValues() map[string]interface{} {
 	}
 }
 
+// controllerServiceType returns the type of the controller service, LoadBalancer unless
+// overridden
+func (n *Nginx) controllerServiceType() string {
+	if n.serviceType != "" {
+		return n.serviceType
+	}
+	return string(v1.ServiceTypeLoadBalancer)
+}
+
+func (n *Nginx) GetOverrideSchema() map[string]OverrideType {
+	return map[string]OverrideType{NginxServiceTypeOverrideKey: OverrideTypeString}
+}
+
+func (n *Nginx) ValidateOverrideValues(values map[string]interface{}) error {
+	if err := validateOverrideKeys(values, NginxServiceTypeOverrideKey); err != nil {
+		return err
+	}
+	if v, ok := GetNestedValue(values, NginxServiceTypeOverrideKey); ok {
+		serviceType, isString := v.(string)
+		if !isString || (serviceType != string(v1.ServiceTypeLoadBalancer) &&
+			serviceType != string(v1.ServiceTypeNodePort)) {
+			return fmt.Errorf("%s must be %s or %s, got %v",
+				NginxServiceTypeOverrideKey, v1.ServiceTypeLoadBalancer, v1.ServiceTypeNodePort, v)
+		}
+	}
+	return nil
+}
+
+func (n *Nginx) SetOverrideValues(values map[string]interface{}) {
+	if v, ok := GetNestedValue(values, NginxServiceTypeOverrideKey); ok {
+		if serviceType, isString := v.(string); isString {
+			n.serviceType = serviceType
+		}
+	}
+}
+
 func (n *Nginx) GetDependencies() []string {
 	return []string{"load-balancer"} // nginx-ingress depends on load-balancer
 }
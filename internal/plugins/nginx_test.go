@@ -175,3 +175,61 @@ func TestNginx_Status_Message_Format(t *testing.T) {
 		t.Errorf("expected status format 'nginx-ingress is running', got '%s'", expectedFormat)
 	}
 }
+
+func serviceTypeValues(serviceType string) map[string]interface{} {
+	service := map[string]interface{}{"type": serviceType}
+	return map[string]interface{}{"controller": map[string]interface{}{"service": service}}
+}
+
+func TestNginx_OverrideServiceType(t *testing.T) {
+	tests := []struct {
+		name        string
+		values      map[string]interface{}
+		expected    string
+		expectError bool
+	}{
+		{name: "no override", values: map[string]interface{}{}, expected: "LoadBalancer"},
+		{
+			name:     "node port",
+			values:   serviceTypeValues("NodePort"),
+			expected: "NodePort",
+		},
+		{
+			name:     "load balancer",
+			values:   serviceTypeValues("LoadBalancer"),
+			expected: "LoadBalancer",
+		},
+		{
+			name:        "unsupported type",
+			values:      serviceTypeValues("ClusterIP"),
+			expectError: true,
+		},
+		{
+			name:        "unknown key",
+			values:      map[string]interface{}{"controller": map[string]interface{}{"replicaCount": 3}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nginx := NewNginx("")
+			err := nginx.ValidateOverrideValues(tt.values)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error for %v", tt.values)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			nginx.SetOverrideValues(tt.values)
+			serviceType, _ := GetNestedValue(nginx.GetOptions().ChartValues, NginxServiceTypeOverrideKey)
+			if serviceType != tt.expected {
+				t.Errorf("expected service type %s, got %v", tt.expected, serviceType)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package plugins
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OverridablePlugin accepts user supplied values that take precedence over its defaults
+type OverridablePlugin interface {
+	SetOverrideValues(values map[string]interface{})
+}
+
+// OverrideValidator checks user supplied values before they are applied
+type OverrideValidator interface {
+	ValidateOverrideValues(values map[string]interface{}) error
+}
+
+// OverrideNormalizer rewrites override values before they are applied and recorded, e.g.
+// to keep secrets out of the installer tracker
+type OverrideNormalizer interface {
+	NormalizeOverrideValues(values map[string]interface{}) (map[string]interface{}, error)
+}
+
+// NormalizeOverrideValues returns values as the plugin applies and records them
+func NormalizeOverrideValues(plugin Plugin, values map[string]interface{}) (map[string]interface{}, error) {
+	normalizer, ok := plugin.(OverrideNormalizer)
+	if !ok || len(values) == 0 {
+		return values, nil
+	}
+	return normalizer.NormalizeOverrideValues(values)
+}
+
+// OverrideType is the type the value of an override key must have
+type OverrideType string
+
+const (
+	OverrideTypeString OverrideType = "string"
+	OverrideTypeBool   OverrideType = "bool"
+	OverrideTypeInt    OverrideType = "int"
+)
+
+// OverrideSchemaPlugin declares the types of its override keys, so values inferred as
+// another type, like a numeric-looking password, are coerced before validation
+type OverrideSchemaPlugin interface {
+	GetOverrideSchema() map[string]OverrideType
+}
+
+// RemoteDefaultsPlugin fetches its default values from a remote file, which users
+// who maintain their own configuration can skip
+type RemoteDefaultsPlugin interface {
+	SkipRemoteDefaults()
+}
+
+// OfflinePlugin can read its remote default values from a local cache instead of the network
+type OfflinePlugin interface {
+	UseOffline()
+}
+
+// ValuesChecksumPlugin verifies its remote default values against a SHA256 checksum
+type ValuesChecksumPlugin interface {
+	SetValuesChecksum(checksum string) error
+}
+
+// GetNestedValue looks up a dotted key such as "addressPool.range" in nested values
+func GetNestedValue(values map[string]interface{}, key string) (interface{}, bool) {
+	var current interface{} = values
+	for _, part := range strings.Split(key, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// FlattenKeys returns the sorted dotted keys of all leaf values
+func FlattenKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0)
+	var walk func(prefix string, m map[string]interface{})
+	walk = func(prefix string, m map[string]interface{}) {
+		for k, v := range m {
+			key := k
+			if prefix != "" {
+				key = prefix + "." + k
+			}
+			if nested, ok := v.(map[string]interface{}); ok {
+				walk(key, nested)
+				continue
+			}
+			keys = append(keys, key)
+		}
+	}
+	walk("", values)
+	sort.Strings(keys)
+	return keys
+}
+
+// CoerceOverrideValues converts the values of the keys in schema to their declared type
+// in place, rejecting values that can't be converted such as a mapping for a string key
+func CoerceOverrideValues(values map[string]interface{}, schema map[string]OverrideType) error {
+	keys := make([]string, 0, len(schema))
+	for key := range schema {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		v, ok := GetNestedValue(values, key)
+		if !ok {
+			continue
+		}
+		coerced, err := coerceOverrideValue(v, schema[key])
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		parts := strings.Split(key, ".")
+		parent := values
+		for _, part := range parts[:len(parts)-1] {
+			parent = parent[part].(map[string]interface{})
+		}
+		parent[parts[len(parts)-1]] = coerced
+	}
+	return nil
+}
+
+func coerceOverrideValue(v interface{}, t OverrideType) (interface{}, error) {
+	switch t {
+	case OverrideTypeString:
+		switch value := v.(type) {
+		case string:
+			return value, nil
+		case bool:
+			return strconv.FormatBool(value), nil
+		case int, int64, uint64:
+			return fmt.Sprint(value), nil
+		case float64:
+			return strconv.FormatFloat(value, 'f', -1, 64), nil
+		}
+	case OverrideTypeBool:
+		switch value := v.(type) {
+		case bool:
+			return value, nil
+		case string:
+			if value == "true" || value == "false" {
+				return value == "true", nil
+			}
+		}
+	case OverrideTypeInt:
+		switch value := v.(type) {
+		case int:
+			return value, nil
+		case int64:
+			return int(value), nil
+		case float64:
+			if value == math.Trunc(value) {
+				return int(value), nil
+			}
+		case string:
+			if i, err := strconv.Atoi(value); err == nil {
+				return i, nil
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown type %s", t)
+	}
+	return nil, fmt.Errorf("expected a %s, got %v (%T)", t, v, v)
+}
+
+// validateOverrideKeys rejects keys that are not in allowed
+func validateOverrideKeys(values map[string]interface{}, allowed ...string) error {
+	for _, key := range FlattenKeys(values) {
+		known := false
+		for _, a := range allowed {
+			if key == a {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown override key %q, supported keys: %s", key, strings.Join(allowed, ", "))
+		}
+	}
+	return nil
+}
+
+// MergeValues deep merges src into dst, with src winning on conflicts
+func MergeValues(dst, src map[string]interface{}) map[string]interface{} {
+	if dst == nil {
+		dst = make(map[string]interface{})
+	}
+	for k, v := range src {
+		if srcMap, ok := v.(map[string]interface{}); ok {
+			if dstMap, ok := dst[k].(map[string]interface{}); ok {
+				dst[k] = MergeValues(dstMap, srcMap)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+	return dst
+}
+
+// copyValues returns a deep copy of the nested maps in values
+func copyValues(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		if nested, ok := v.(map[string]interface{}); ok {
+			out[k] = copyValues(nested)
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
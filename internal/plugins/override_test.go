@@ -0,0 +1,147 @@
+package plugins
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestMergeValues(t *testing.T) {
+	dst := map[string]interface{}{
+		"a": map[string]interface{}{"b": 1, "c": 2},
+		"d": "keep",
+	}
+	src := map[string]interface{}{
+		"a": map[string]interface{}{"b": 10},
+		"e": true,
+	}
+
+	got := MergeValues(dst, src)
+	expected := map[string]interface{}{
+		"a": map[string]interface{}{"b": 10, "c": 2},
+		"d": "keep",
+		"e": true,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestCoerceOverrideValues(t *testing.T) {
+	schema := map[string]OverrideType{
+		"admin.password":  OverrideTypeString,
+		"acme.only":       OverrideTypeBool,
+		"server.replicas": OverrideTypeInt,
+	}
+	tests := []struct {
+		name     string
+		values   map[string]interface{}
+		expected map[string]interface{}
+		wantErr  bool
+	}{
+		{
+			name:     "numeric password to string",
+			values:   map[string]interface{}{"admin": map[string]interface{}{"password": 12345}},
+			expected: map[string]interface{}{"admin": map[string]interface{}{"password": "12345"}},
+		},
+		{
+			name:     "float to string",
+			values:   map[string]interface{}{"admin": map[string]interface{}{"password": 1.5}},
+			expected: map[string]interface{}{"admin": map[string]interface{}{"password": "1.5"}},
+		},
+		{
+			name: "bool and int from strings",
+			values: map[string]interface{}{
+				"acme":   map[string]interface{}{"only": "true"},
+				"server": map[string]interface{}{"replicas": float64(2)},
+			},
+			expected: map[string]interface{}{
+				"acme":   map[string]interface{}{"only": true},
+				"server": map[string]interface{}{"replicas": 2},
+			},
+		},
+		{
+			name:     "keys outside the schema untouched",
+			values:   map[string]interface{}{"server": map[string]interface{}{"insecure": "yes"}},
+			expected: map[string]interface{}{"server": map[string]interface{}{"insecure": "yes"}},
+		},
+		{
+			name:    "mapping for a string key",
+			values:  map[string]interface{}{"admin": map[string]interface{}{"password": map[string]interface{}{}}},
+			wantErr: true,
+		},
+		{
+			name:    "non boolean string",
+			values:  map[string]interface{}{"acme": map[string]interface{}{"only": "yes"}},
+			wantErr: true,
+		},
+		{
+			name:    "fractional int",
+			values:  map[string]interface{}{"server": map[string]interface{}{"replicas": 1.5}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CoerceOverrideValues(tt.values, schema)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CoerceOverrideValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(tt.values, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, tt.values)
+			}
+		})
+	}
+}
+
+func TestArgocdOverrideValues(t *testing.T) {
+	a := &Argocd{}
+	overrides := map[string]interface{}{
+		"server": map[string]interface{}{"replicas": 2},
+	}
+	a.SetOverrideValues(overrides)
+
+	// later changes by the caller must not affect the stored overrides
+	overrides["server"].(map[string]interface{})["replicas"] = 5
+
+	got := a.applyOverrides(map[string]interface{}{
+		"server": map[string]interface{}{"replicas": 1, "insecure": true},
+	})
+	expected := map[string]interface{}{
+		"server": map[string]interface{}{"replicas": 2, "insecure": true},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestArgocdOverrideValuesConcurrent(t *testing.T) {
+	a := &Argocd{}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			a.SetOverrideValues(map[string]interface{}{"server": map[string]interface{}{"replicas": i}})
+		}(i)
+		go func() {
+			defer wg.Done()
+			a.applyOverrides(map[string]interface{}{"server": map[string]interface{}{"replicas": 1}})
+		}()
+	}
+	wg.Wait()
+}
+
+func TestArgocdSkipRemoteDefaults(t *testing.T) {
+	a := &Argocd{}
+	a.SetOverrideValues(map[string]interface{}{"server": map[string]interface{}{"replicas": 2}})
+	a.SkipRemoteDefaults()
+
+	// no cluster is reachable, so only the overrides remain
+	got := a.getChartValues()
+	expected := map[string]interface{}{"server": map[string]interface{}{"replicas": 2}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
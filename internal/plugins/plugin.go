@@ -1,5 +1,7 @@
 package plugins
 
+import "time"
+
 type Plugin interface {
 	GetName() string
 	Install(kubeConfig, clusterName string, ensure ...bool) error
@@ -14,9 +16,16 @@ type PluginOptions struct {
 	ChartName        *string
 	RepoName         *string
 	Repository       *string
+	RepoUsername     string // basic auth for a private Repository
+	RepoPassword     string
 	releaseName      *string
 	ChartValues      map[string]interface{}
+	ValuesURL        string // remote default values ChartValues are merged over on install
 	CRDsGroupVersion string
+	Timeout          time.Duration // zero uses the installer default
+	Wait             bool
+	CreateNamespace  *bool          // nil creates the namespace, false installs into an existing one
+	IngressRoutes    []IngressRoute // extra hostnames and paths the plugin's service is exposed at
 }
 
 func CreatePluginsList(kubeConfig, masterClusterIP, clusterName string) ([]Plugin, error) {
@@ -39,12 +48,19 @@ func CreatePluginsList(kubeConfig, masterClusterIP, clusterName string) ([]Plugi
 		return nil, err
 	}
 
+	demo, err := NewDemo(kubeConfig, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
 	return []Plugin{
 		argocd,
 		NewCertManager(kubeConfig),
 		lb,
 		NewNginx(kubeConfig),
 		ingress,
+		NewDashboard(kubeConfig, clusterName),
 		tls,
+		demo,
 	}, nil
 }
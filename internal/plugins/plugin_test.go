@@ -1,6 +1,7 @@
 package plugins
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -62,6 +63,7 @@ func TestPluginNames(t *testing.T) {
 		"nginx-ingress",
 		IngressName,
 		TLSName,
+		DemoName,
 	}
 
 	plugins, err := CreatePluginsList("dummy-kubeconfig", "192.168.1.100", "test-cluster")
@@ -85,3 +87,87 @@ func TestPluginNames(t *testing.T) {
 		}
 	}
 }
+
+type existingNamespaceNginx struct {
+	*Nginx
+}
+
+func (n *existingNamespaceNginx) GetOptions() PluginOptions {
+	opts := n.Nginx.GetOptions()
+	createNamespace := false
+	opts.CreateNamespace = &createNamespace
+	return opts
+}
+
+func TestNewInstallOptionsCreateNamespace(t *testing.T) {
+	nginx := NewNginx("")
+	if opts := newInstallOptions(nginx.GetName(), nginx.GetOptions(), ""); !opts.CreateNamespace {
+		t.Error("expected the namespace to be created by default")
+	}
+
+	plugin := &existingNamespaceNginx{NewNginx("")}
+	if opts := newInstallOptions(plugin.GetName(), plugin.GetOptions(), ""); opts.CreateNamespace {
+		t.Error("expected CreateNamespace false to be passed to the installer")
+	}
+}
+
+func TestNewInstallOptionsNamespaceOverride(t *testing.T) {
+	tests := []struct {
+		name      string
+		override  string
+		recorded  string
+		namespace string
+	}{
+		{name: "default namespace", namespace: DashboardNamespace},
+		{name: "recorded namespace", recorded: "platform", namespace: "platform"},
+		{name: "override", override: "team-a", namespace: "team-a"},
+		{name: "override replaces recorded namespace", override: "team-a", recorded: "platform", namespace: "team-a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dashboard := NewDashboard("", "test")
+			if tt.override != "" {
+				if err := dashboard.SetNamespace(tt.override); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			opts := dashboard.newInstallOptions(dashboard.GetOptions(), "", tt.recorded)
+			if opts.Namespace != tt.namespace {
+				t.Errorf("expected namespace %q, got %q", tt.namespace, opts.Namespace)
+			}
+		})
+	}
+}
+
+func TestSetNamespaceRejectsInvalidNames(t *testing.T) {
+	var plugin NamespaceOverridable = NewDashboard("", "test")
+	for _, namespace := range []string{"Team_A", "-platform", ""} {
+		if err := plugin.SetNamespace(namespace); err == nil {
+			t.Errorf("expected namespace %q to be rejected", namespace)
+		}
+	}
+}
+
+type checkedPlugin struct {
+	*BasePlugin
+	checkErr error
+}
+
+func (c *checkedPlugin) GetName() string                         { return "checked" }
+func (c *checkedPlugin) Install(string, string, ...bool) error   { return nil }
+func (c *checkedPlugin) Uninstall(string, string, ...bool) error { return nil }
+func (c *checkedPlugin) Status() string                          { return StatusNotInstalled }
+func (c *checkedPlugin) GetOptions() PluginOptions               { return PluginOptions{} }
+func (c *checkedPlugin) PreInstallCheck(kubeConfig string) error { return c.checkErr }
+
+func TestUnifiedInstallAbortsOnFailedPreInstallCheck(t *testing.T) {
+	p := &checkedPlugin{checkErr: errors.New("no storage class")}
+	p.BasePlugin = NewBasePlugin("kubeconfig", p)
+
+	err := p.UnifiedInstall("kubeconfig", "test")
+	if !errors.Is(err, p.checkErr) {
+		t.Errorf("expected the pre-install check error, got %v", err)
+	}
+}
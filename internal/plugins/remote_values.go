@@ -0,0 +1,85 @@
+package plugins
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/mrgb7/playground/pkg/logger"
+	"gopkg.in/yaml.v3"
+)
+
+// fetchRemoteContent downloads a values file, refusing files larger than MaxResponseSize
+func fetchRemoteContent(rawURL string) ([]byte, error) {
+	if _, err := url.Parse(rawURL); err != nil {
+		return nil, fmt.Errorf("invalid values file URL: %w", err)
+	}
+
+	httpClient := &http.Client{
+		Timeout: HTTPTimeoutSeconds * time.Second,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), HTTPTimeoutSeconds*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch values file: %w", err)
+	}
+	defer func() {
+		if err = resp.Body.Close(); err != nil {
+			logger.Debugln("Failed to close response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch values file: HTTP %d %s", resp.StatusCode, resp.Status)
+	}
+
+	// read one byte past the limit to tell a file of exactly MaxResponseSize from a larger one
+	content, err := io.ReadAll(io.LimitReader(resp.Body, MaxResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if len(content) > MaxResponseSize {
+		return nil, fmt.Errorf("values file %s is larger than %d bytes", rawURL, MaxResponseSize)
+	}
+
+	logger.Debugf("Values file %s SHA256: %x", rawURL, sha256.Sum256(content))
+	return content, nil
+}
+
+// fetchRemoteValues downloads and parses a values file
+func fetchRemoteValues(rawURL string) (map[string]interface{}, error) {
+	content, err := fetchRemoteContent(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal YAML content: %w", err)
+	}
+	return values, nil
+}
+
+// mergeRemoteValues uses the values file at rawURL as defaults and merges a plugin's
+// ChartValues, overrides included, over them. The values are used alone when the file
+// can't be fetched
+func mergeRemoteValues(values map[string]interface{}, rawURL string) map[string]interface{} {
+	remote, err := fetchRemoteValues(rawURL)
+	if err != nil {
+		logger.Warnln("Ignoring values from %s, using the plugin values alone: %v", rawURL, err)
+		return values
+	}
+	return MergeValues(remote, copyValues(values))
+}
@@ -0,0 +1,96 @@
+package plugins
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergeRemoteValues(t *testing.T) {
+	values := map[string]interface{}{
+		"replicas": 2,
+		"image":    map[string]interface{}{"tag": "1.25"},
+	}
+
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected map[string]interface{}
+	}{
+		{
+			name:   "plugin values win over remote defaults",
+			status: http.StatusOK,
+			body:   "replicas: 1\nimage:\n  repository: nginx\n  tag: \"1.27\"\nresources:\n  limits:\n    cpu: 100m\n",
+			expected: map[string]interface{}{
+				"replicas":  2,
+				"image":     map[string]interface{}{"repository": "nginx", "tag": "1.25"},
+				"resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "100m"}},
+			},
+		},
+		{
+			name:     "fetch failure keeps the plugin values",
+			status:   http.StatusNotFound,
+			expected: values,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			got := mergeRemoteValues(copyValues(values), server.URL)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMergeRemoteValuesKeepsValuesUnchanged(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("image:\n  repository: nginx\n"))
+	}))
+	defer server.Close()
+
+	values := map[string]interface{}{"image": map[string]interface{}{"tag": "1.25"}}
+	merged := mergeRemoteValues(values, server.URL)
+	merged["image"].(map[string]interface{})["tag"] = "latest"
+	if image := values["image"].(map[string]interface{}); len(image) != 1 || image["tag"] != "1.25" {
+		t.Errorf("expected the plugin values to be left alone, got %v", image)
+	}
+}
+
+func TestFetchRemoteContentSizeLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		wantErr bool
+	}{
+		{name: "at the limit", size: MaxResponseSize},
+		{name: "over the limit", size: MaxResponseSize + 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(strings.Repeat("#", tt.size)))
+			}))
+			defer server.Close()
+
+			content, err := fetchRemoteContent(server.URL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fetchRemoteContent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && len(content) != tt.size {
+				t.Errorf("expected %d bytes, got %d", tt.size, len(content))
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mrgb7/playground/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ResourceRequirements are the CPU and memory a plugin's workloads need
+type ResourceRequirements struct {
+	CPU    resource.Quantity
+	Memory resource.Quantity
+}
+
+// ResourceHinter is implemented by plugins heavy enough that installing them on a small
+// cluster can fail, e.g. with pods killed for running out of memory
+type ResourceHinter interface {
+	GetResourceHints() ResourceRequirements
+}
+
+// SumResourceHints adds up the resource hints of the plugins providing them
+func SumResourceHints(pluginsList []Plugin) ResourceRequirements {
+	var total ResourceRequirements
+	for _, p := range pluginsList {
+		hinter, ok := p.(ResourceHinter)
+		if !ok {
+			continue
+		}
+		hints := hinter.GetResourceHints()
+		total.CPU.Add(hints.CPU)
+		total.Memory.Add(hints.Memory)
+	}
+	return total
+}
+
+// InsufficientResources describes every resource the cluster can allocate less of than required
+func InsufficientResources(required, allocatable ResourceRequirements) []string {
+	var shortfalls []string
+	if required.CPU.Cmp(allocatable.CPU) > 0 {
+		shortfalls = append(shortfalls, fmt.Sprintf("CPU: plugins need %s, cluster can allocate %s",
+			required.CPU.String(), allocatable.CPU.String()))
+	}
+	if required.Memory.Cmp(allocatable.Memory) > 0 {
+		shortfalls = append(shortfalls, fmt.Sprintf("memory: plugins need %s, cluster can allocate %s",
+			required.Memory.String(), allocatable.Memory.String()))
+	}
+	return shortfalls
+}
+
+// CheckClusterResources compares the resource hints of the plugins about to be installed
+// with the allocatable resources of the cluster's nodes
+func CheckClusterResources(k8sClient *k8s.K8sClient, pluginsList []Plugin) ([]string, error) {
+	required := SumResourceHints(pluginsList)
+	if required.CPU.IsZero() && required.Memory.IsZero() {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	allocatable, err := k8sClient.AllocatableResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return InsufficientResources(required, ResourceRequirements{
+		CPU:    allocatable[corev1.ResourceCPU],
+		Memory: allocatable[corev1.ResourceMemory],
+	}), nil
+}
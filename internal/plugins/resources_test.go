@@ -0,0 +1,104 @@
+package plugins
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mrgb7/playground/internal/k8s"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type hintedPlugin struct {
+	checkedPlugin
+	cpu, memory string
+}
+
+func (h *hintedPlugin) GetResourceHints() ResourceRequirements {
+	return ResourceRequirements{CPU: resource.MustParse(h.cpu), Memory: resource.MustParse(h.memory)}
+}
+
+func resourceNode(name, cpu, memory string, unschedulable bool) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1.NodeSpec{Unschedulable: unschedulable},
+		Status: v1.NodeStatus{Allocatable: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse(cpu),
+			v1.ResourceMemory: resource.MustParse(memory),
+		}},
+	}
+}
+
+func TestSumResourceHints(t *testing.T) {
+	pluginsList := []Plugin{
+		&hintedPlugin{cpu: "500m", memory: "1Gi"},
+		&checkedPlugin{},
+		&hintedPlugin{cpu: "1", memory: "512Mi"},
+	}
+
+	total := SumResourceHints(pluginsList)
+	if total.CPU.Cmp(resource.MustParse("1500m")) != 0 {
+		t.Errorf("expected 1500m CPU, got %s", total.CPU.String())
+	}
+	if total.Memory.Cmp(resource.MustParse("1536Mi")) != 0 {
+		t.Errorf("expected 1536Mi memory, got %s", total.Memory.String())
+	}
+}
+
+func TestCheckClusterResources(t *testing.T) {
+	heavy := []Plugin{&hintedPlugin{cpu: "2", memory: "4Gi"}}
+
+	tests := []struct {
+		name       string
+		plugins    []Plugin
+		nodes      []runtime.Object
+		shortfalls []string
+	}{
+		{
+			name:    "enough resources",
+			plugins: heavy,
+			nodes:   []runtime.Object{resourceNode("master", "2", "2Gi", false), resourceNode("worker", "2", "2Gi", false)},
+		},
+		{
+			name:       "not enough CPU and memory",
+			plugins:    heavy,
+			nodes:      []runtime.Object{resourceNode("master", "1", "2Gi", false)},
+			shortfalls: []string{"CPU", "memory"},
+		},
+		{
+			name:    "unschedulable nodes don't count",
+			plugins: heavy,
+			nodes: []runtime.Object{
+				resourceNode("master", "2", "4Gi", false), resourceNode("worker", "2", "4Gi", true),
+			},
+		},
+		{
+			name:       "cordoned capacity missing",
+			plugins:    heavy,
+			nodes:      []runtime.Object{resourceNode("master", "1", "4Gi", false), resourceNode("worker", "2", "4Gi", true)},
+			shortfalls: []string{"CPU"},
+		},
+		{name: "no hints", plugins: []Plugin{&checkedPlugin{}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sClient := &k8s.K8sClient{Clientset: fake.NewSimpleClientset(tt.nodes...)}
+			shortfalls, err := CheckClusterResources(k8sClient, tt.plugins)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(shortfalls) != len(tt.shortfalls) {
+				t.Fatalf("expected shortfalls %v, got %v", tt.shortfalls, shortfalls)
+			}
+			for i, prefix := range tt.shortfalls {
+				if !strings.HasPrefix(shortfalls[i], prefix+":") {
+					t.Errorf("expected a %s shortfall, got %q", prefix, shortfalls[i])
+				}
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"math/big"
 	"net"
 	"os"
+	"regexp"
 	"runtime"
 	"strings"
 	"time"
@@ -22,6 +23,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	clusterIssuerGVR = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "clusterissuers"}
+	issuerGVR        = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "issuers"}
 )
 
 var (
@@ -33,11 +40,42 @@ var (
 	RSAKeySize           = 4096
 )
 
+const (
+	IssuerScopeCluster   = "cluster"
+	IssuerScopeNamespace = "namespace"
+)
+
+// Statuses of an installed TLS plugin
+const (
+	TLSStatusReady     = "TLS is configured and ready"
+	TLSStatusACMEReady = "TLS is configured with the ACME issuer and ready"
+)
+
 type TLS struct {
 	KubeConfig  string
 	k8sClient   *k8s.K8sClient
 	ClusterName string
+	// Domain is the domain the certificates are issued for, <cluster>.local when empty
+	Domain string
 	*BasePlugin
+
+	issuerNamespace string // set for a namespaced Issuer, empty for the ClusterIssuer
+	extraDNSNames   []string
+	extraIPs        []net.IP
+
+	acmeEmail  string // set to create the ACME ClusterIssuer
+	acmeServer string
+	acmeOnly   bool // skip the local CA issuer
+}
+
+// SubjectAltNamesPlugin accepts extra DNS names and IP addresses for its certificate
+type SubjectAltNamesPlugin interface {
+	AddSubjectAltNames(dnsNames, ipAddresses []string) error
+}
+
+// IssuerScopedPlugin can create its issuer in a single namespace instead of cluster wide
+type IssuerScopedPlugin interface {
+	SetIssuerScope(scope, namespace string) error
 }
 
 func NewTLS(kubeConfig, clusterName string) (*TLS, error) {
@@ -49,8 +87,10 @@ func NewTLS(kubeConfig, clusterName string) (*TLS, error) {
 		KubeConfig:  kubeConfig,
 		k8sClient:   c,
 		ClusterName: clusterName,
+		Domain:      loadClusterDomain(clusterName),
 	}
 	tls.BasePlugin = NewBasePlugin(kubeConfig, tls)
+	tls.restoreIssuerScope()
 	return tls, nil
 }
 
@@ -70,8 +110,23 @@ func (t *TLS) GetOptions() PluginOptions {
 }
 
 func (t *TLS) Install(kubeConfig, clusterName string, ensure ...bool) error {
+	if err := checkKubeConfig(kubeConfig); err != nil {
+		return fmt.Errorf("cannot install plugin %s: %w", t.GetName(), err)
+	}
+
 	logger.Infoln("Installing TLS plugin for cluster: %s", clusterName)
 
+	if t.acmeEmail != "" {
+		if err := t.createACMEClusterIssuer(t.acmeEmail, t.acmeServer); err != nil {
+			return fmt.Errorf("failed to create ACME ClusterIssuer: %w", err)
+		}
+		logger.Infoln("Example ingress annotation: cert-manager.io/cluster-issuer: %s", TLSACMEIssuerName)
+	}
+	if t.acmeOnly {
+		logger.Successln("TLS plugin installed successfully with the ACME issuer only")
+		return nil
+	}
+
 	caCert, caKey, err := t.generateCACertificate()
 	if err != nil {
 		return fmt.Errorf("failed to generate CA certificate: %w", err)
@@ -86,9 +141,10 @@ func (t *TLS) Install(kubeConfig, clusterName string, ensure ...bool) error {
 		return fmt.Errorf("failed to store CA secret: %w", err)
 	}
 
-	if err := t.createClusterIssuer(); err != nil {
-		return fmt.Errorf("failed to create cluster issuer: %w", err)
+	if err := t.createIssuer(); err != nil {
+		return fmt.Errorf("failed to create %s: %w", t.issuerKind(), err)
 	}
+	t.recordIssuerScope()
 
 	if err := t.printTrustInstructions(caCert); err != nil {
 		return fmt.Errorf("failed to print trust instructions: %w", err)
@@ -104,21 +160,27 @@ func (t *TLS) Uninstall(kubeConfig, clusterName string, ensure ...bool) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	err := t.k8sClient.Clientset.CoreV1().Secrets(CertManagerNamespace).Delete(
+	err := t.k8sClient.Clientset.CoreV1().Secrets(t.secretNamespace()).Delete(
 		ctx, TLSSecretName, metav1.DeleteOptions{})
 	if err != nil && !strings.Contains(err.Error(), "not found") {
 		logger.Warnln("Failed to delete CA secret: %v", err)
 	}
 
-	gvr := schema.GroupVersionResource{
-		Group:    "cert-manager.io",
-		Version:  "v1",
-		Resource: "clusterissuers",
-	}
-	err = t.k8sClient.Dynamic.Resource(gvr).Delete(
-		ctx, TLSClusterIssuerName, metav1.DeleteOptions{})
+	err = t.issuerResource().Delete(ctx, TLSClusterIssuerName, metav1.DeleteOptions{})
 	if err != nil && !strings.Contains(err.Error(), "not found") {
-		logger.Warnln("Failed to delete cluster issuer: %v", err)
+		logger.Warnln("Failed to delete %s: %v", t.issuerKind(), err)
+	}
+
+	if err := t.deleteACMEClusterIssuer(); err != nil {
+		logger.Warnln("%v", err)
+	}
+
+	if err := t.RemoveWildcardCertificates(); err != nil {
+		logger.Warnln("Failed to remove wildcard certificates: %v", err)
+	}
+
+	if err := t.tracker().RemovePluginInstaller(TLSName); err != nil {
+		logger.Warnln("Failed to remove the recorded issuer scope: %v", err)
 	}
 
 	logger.Successln("TLS plugin uninstalled successfully")
@@ -129,28 +191,31 @@ func (t *TLS) Status() string {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	_, err := t.k8sClient.Clientset.CoreV1().Secrets(CertManagerNamespace).Get(
+	_, err := t.k8sClient.Clientset.CoreV1().Secrets(t.secretNamespace()).Get(
 		ctx, TLSSecretName, metav1.GetOptions{})
 	if err != nil {
+		// installed with the ACME issuer only
+		if _, acmeErr := t.k8sClient.Dynamic.Resource(clusterIssuerGVR).
+			Get(ctx, TLSACMEIssuerName, metav1.GetOptions{}); acmeErr == nil {
+			return TLSStatusACMEReady
+		}
 		return "TLS CA secret not found"
 	}
 
-	gvr := schema.GroupVersionResource{
-		Group:    "cert-manager.io",
-		Version:  "v1",
-		Resource: "clusterissuers",
-	}
-	_, err = t.k8sClient.Dynamic.Resource(gvr).Get(
-		ctx, TLSClusterIssuerName, metav1.GetOptions{})
+	_, err = t.issuerResource().Get(ctx, TLSClusterIssuerName, metav1.GetOptions{})
 	if err != nil {
-		return "TLS cluster issuer not found"
+		return "TLS " + t.issuerKind() + " not found"
 	}
 
-	return "TLS is configured and ready"
+	return TLSStatusReady
+}
+
+func (t *TLS) IsInstalledStatus(status string) bool {
+	return status == TLSStatusReady || status == TLSStatusACMEReady
 }
 
 func (t *TLS) generateCACertificate() ([]byte, []byte, error) {
-	logger.Infoln("Generating CA certificate for domain: *.%s.local", t.ClusterName)
+	logger.Infoln("Generating CA certificate for domain: *.%s", t.domain())
 
 	privateKey, err := rsa.GenerateKey(rand.Reader, RSAKeySize)
 	if err != nil {
@@ -182,19 +247,8 @@ func (t *TLS) generateCACertificate() ([]byte, []byte, error) {
 		IsCA:                  true,
 		MaxPathLen:            0,
 		MaxPathLenZero:        true,
-		// Enhanced DNS names for better compatibility
-		DNSNames: []string{
-			fmt.Sprintf("*.%s.local", t.ClusterName),
-			fmt.Sprintf("%s.local", t.ClusterName),
-			fmt.Sprintf("*.argocd.%s.local", t.ClusterName),
-			fmt.Sprintf("argocd.%s.local", t.ClusterName),
-			"localhost",
-			"*.localhost",
-		},
-		IPAddresses: []net.IP{
-			net.IPv4(127, 0, 0, 1),
-			net.IPv6loopback,
-		},
+		DNSNames:              t.dnsNames(),
+		IPAddresses:           t.ipAddresses(),
 	}
 
 	certDER, err := x509.CreateCertificate(rand.Reader, &template, &template, &privateKey.PublicKey, privateKey)
@@ -216,16 +270,70 @@ func (t *TLS) generateCACertificate() ([]byte, []byte, error) {
 	return certPEM, keyPEM, nil
 }
 
+// dnsNames returns the default names for the cluster domain followed by the extra names
+func (t *TLS) dnsNames() []string {
+	names := []string{
+		fmt.Sprintf("*.%s", t.domain()),
+		t.domain(),
+		fmt.Sprintf("*.argocd.%s", t.domain()),
+		fmt.Sprintf("argocd.%s", t.domain()),
+		"localhost",
+		"*.localhost",
+	}
+	return append(names, t.extraDNSNames...)
+}
+
+func (t *TLS) ipAddresses() []net.IP {
+	ips := []net.IP{
+		net.IPv4(127, 0, 0, 1),
+		net.IPv6loopback,
+	}
+	return append(ips, t.extraIPs...)
+}
+
+// AddSubjectAltNames adds DNS names (optionally with a leading wildcard) and IP
+// addresses to the CA certificate, so it covers hosts outside <domain>
+func (t *TLS) AddSubjectAltNames(dnsNames, ipAddresses []string) error {
+	for _, name := range dnsNames {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !isValidDNSName(name) {
+			return fmt.Errorf("invalid DNS name %q", name)
+		}
+		t.extraDNSNames = append(t.extraDNSNames, name)
+	}
+	for _, addr := range ipAddresses {
+		ip := net.ParseIP(strings.TrimSpace(addr))
+		if ip == nil {
+			return fmt.Errorf("invalid IP address %q", addr)
+		}
+		t.extraIPs = append(t.extraIPs, ip)
+	}
+	return nil
+}
+
+var dnsNamePattern = regexp.MustCompile(`^(\*\.)?[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+func isValidDNSName(name string) bool {
+	return len(name) <= 253 && dnsNamePattern.MatchString(name)
+}
+
 func (t *TLS) storeCASecret(caCert, caKey []byte) error {
 	logger.Infoln("Storing CA certificate in Kubernetes secret")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	namespace := t.secretNamespace()
+	if t.issuerNamespace != "" {
+		if err := t.ensureNamespace(ctx, namespace); err != nil {
+			return err
+		}
+	}
+
 	secret := &v1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      TLSSecretName,
-			Namespace: CertManagerNamespace,
+			Namespace: namespace,
 		},
 		Type: v1.SecretTypeOpaque,
 		Data: map[string][]byte{
@@ -234,11 +342,11 @@ func (t *TLS) storeCASecret(caCert, caKey []byte) error {
 		},
 	}
 
-	_, err := t.k8sClient.Clientset.CoreV1().Secrets(CertManagerNamespace).Create(ctx, secret, metav1.CreateOptions{})
+	_, err := t.k8sClient.Clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
 	switch {
 	case err != nil && strings.Contains(err.Error(), "already exists"):
 		// Get the existing secret to preserve metadata
-		existing, getErr := t.k8sClient.Clientset.CoreV1().Secrets(CertManagerNamespace).Get(ctx, TLSSecretName, metav1.GetOptions{})
+		existing, getErr := t.k8sClient.Clientset.CoreV1().Secrets(namespace).Get(ctx, TLSSecretName, metav1.GetOptions{})
 		if getErr != nil {
 			return fmt.Errorf("failed to get existing CA secret: %w", getErr)
 		}
@@ -257,7 +365,7 @@ func (t *TLS) storeCASecret(caCert, caKey []byte) error {
 			secret.Annotations = existing.Annotations
 		}
 
-		_, err = t.k8sClient.Clientset.CoreV1().Secrets(CertManagerNamespace).Update(ctx, secret, metav1.UpdateOptions{})
+		_, err = t.k8sClient.Clientset.CoreV1().Secrets(namespace).Update(ctx, secret, metav1.UpdateOptions{})
 		if err != nil {
 			return fmt.Errorf("failed to update existing CA secret: %w", err)
 		}
@@ -271,25 +379,100 @@ func (t *TLS) storeCASecret(caCert, caKey []byte) error {
 	return nil
 }
 
-func (t *TLS) createClusterIssuer() error {
-	logger.Infoln("Creating cluster issuer: %s", TLSClusterIssuerName)
+func (t *TLS) createIssuer() error {
+	logger.Infoln("Creating %s: %s", t.issuerKind(), TLSClusterIssuerName)
+	gvr := clusterIssuerGVR
+	if t.issuerNamespace != "" {
+		gvr = issuerGVR
+	}
+	return t.applyIssuer(gvr, t.buildIssuer())
+}
 
+// applyIssuer creates issuer, or updates it when it already exists
+func (t *TLS) applyIssuer(gvr schema.GroupVersionResource, issuer *unstructured.Unstructured) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	gvr := schema.GroupVersionResource{
-		Group:    "cert-manager.io",
-		Version:  "v1",
-		Resource: "clusterissuers",
+	return t.k8sClient.ApplyUnstructured(ctx, gvr, issuer.GetNamespace(), issuer)
+}
+
+// SetIssuerScope selects between the cluster wide ClusterIssuer and an Issuer (with
+// its own copy of the CA secret) in a single namespace
+func (t *TLS) SetIssuerScope(scope, namespace string) error {
+	switch scope {
+	case "", IssuerScopeCluster:
+		if namespace != "" {
+			return fmt.Errorf("--namespace is only used with issuer scope %q", IssuerScopeNamespace)
+		}
+		t.issuerNamespace = ""
+	case IssuerScopeNamespace:
+		if namespace == "" {
+			return fmt.Errorf("issuer scope %q needs a namespace", IssuerScopeNamespace)
+		}
+		t.issuerNamespace = namespace
+	default:
+		return fmt.Errorf("unknown issuer scope %q, expected %s or %s", scope, IssuerScopeCluster, IssuerScopeNamespace)
+	}
+	return nil
+}
+
+func (t *TLS) tracker() *InstallerTracker {
+	return &InstallerTracker{kubeConfig: t.KubeConfig, k8sClient: t.k8sClient}
+}
+
+// recordIssuerScope stores the namespace of a namespaced Issuer, empty for the
+// ClusterIssuer, so later commands find the issuer and the CA secret
+func (t *TLS) recordIssuerScope() {
+	if err := t.tracker().RecordPluginNamespace(TLSName, t.issuerNamespace); err != nil {
+		logger.Warnln("Failed to record the issuer scope: %v", err)
+	}
+}
+
+// restoreIssuerScope selects the issuer the plugin was installed with
+func (t *TLS) restoreIssuerScope() {
+	namespace, err := t.tracker().GetPluginNamespace(TLSName)
+	if err != nil {
+		logger.Debugln("Failed to get the recorded issuer scope: %v", err)
+		return
+	}
+	t.issuerNamespace = namespace
+}
+
+func (t *TLS) issuerKind() string {
+	if t.issuerNamespace != "" {
+		return "Issuer"
+	}
+	return "ClusterIssuer"
+}
+
+// secretNamespace is where the CA secret lives: a namespaced Issuer can only read
+// secrets from its own namespace
+func (t *TLS) secretNamespace() string {
+	if t.issuerNamespace != "" {
+		return t.issuerNamespace
+	}
+	return CertManagerNamespace
+}
+
+func (t *TLS) issuerResource() dynamic.ResourceInterface {
+	if t.issuerNamespace != "" {
+		return t.k8sClient.Dynamic.Resource(issuerGVR).Namespace(t.issuerNamespace)
 	}
+	return t.k8sClient.Dynamic.Resource(clusterIssuerGVR)
+}
 
-	clusterIssuer := &unstructured.Unstructured{
+func (t *TLS) buildIssuer() *unstructured.Unstructured {
+	metadata := map[string]interface{}{
+		"name": TLSClusterIssuerName,
+	}
+	if t.issuerNamespace != "" {
+		metadata["namespace"] = t.issuerNamespace
+	}
+	return &unstructured.Unstructured{
 		Object: map[string]interface{}{
 			"apiVersion": "cert-manager.io/v1",
-			"kind":       "ClusterIssuer",
-			"metadata": map[string]interface{}{
-				"name": TLSClusterIssuerName,
-			},
+			"kind":       t.issuerKind(),
+			"metadata":   metadata,
 			"spec": map[string]interface{}{
 				"ca": map[string]interface{}{
 					"secretName": TLSSecretName,
@@ -297,41 +480,14 @@ func (t *TLS) createClusterIssuer() error {
 			},
 		},
 	}
+}
 
-	_, err := t.k8sClient.Dynamic.Resource(gvr).Create(ctx, clusterIssuer, metav1.CreateOptions{})
-	switch {
-	case err != nil && strings.Contains(err.Error(), "already exists"):
-		// Get the existing cluster issuer to preserve metadata
-		existing, getErr := t.k8sClient.Dynamic.Resource(gvr).Get(ctx, TLSClusterIssuerName, metav1.GetOptions{})
-		if getErr != nil {
-			return fmt.Errorf("failed to get existing cluster issuer: %w", getErr)
-		}
-
-		// Preserve the existing metadata and update only the spec
-		clusterIssuer.SetResourceVersion(existing.GetResourceVersion())
-		clusterIssuer.SetUID(existing.GetUID())
-		clusterIssuer.SetCreationTimestamp(existing.GetCreationTimestamp())
-		clusterIssuer.SetGeneration(existing.GetGeneration())
-
-		// Copy any existing labels and annotations
-		if labels := existing.GetLabels(); labels != nil {
-			clusterIssuer.SetLabels(labels)
-		}
-		if annotations := existing.GetAnnotations(); annotations != nil {
-			clusterIssuer.SetAnnotations(annotations)
-		}
-
-		_, err = t.k8sClient.Dynamic.Resource(gvr).Update(ctx, clusterIssuer, metav1.UpdateOptions{})
-		if err != nil {
-			return fmt.Errorf("failed to update existing cluster issuer: %w", err)
-		}
-		logger.Infoln("Updated existing cluster issuer")
-	case err != nil:
-		return fmt.Errorf("failed to create cluster issuer: %w", err)
-	default:
-		logger.Successln("Created cluster issuer successfully")
+func (t *TLS) ensureNamespace(ctx context.Context, namespace string) error {
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+	_, err := t.k8sClient.Clientset.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{})
+	if err != nil && !strings.Contains(err.Error(), "already exists") {
+		return fmt.Errorf("failed to create namespace %s: %w", namespace, err)
 	}
-
 	return nil
 }
 
@@ -398,18 +554,18 @@ func (t *TLS) printMacOSInstructions(tempFile *os.File) {
 	logger.Infoln("")
 	logger.Infoln("8. Verify Domain Access:")
 	logger.Infoln("   # Test certificate validation")
-	logger.Infoln("   openssl s_client -connect %s.local:443 -servername %s.local", t.ClusterName, t.ClusterName)
+	logger.Infoln("   openssl s_client -connect %s:443 -servername %s", t.domain(), t.domain())
 	logger.Infoln("   # Should show 'Verify return code: 0 (ok)'")
 	logger.Infoln("")
 	logger.Infoln("9. Check /etc/hosts file:")
 	logger.Infoln("   # Ensure domain points to correct IP")
-	logger.Infoln("   grep '%s.local' /etc/hosts", t.ClusterName)
-	logger.Infoln("   # Should show: 127.0.0.1 *.%s.local", t.ClusterName)
+	logger.Infoln("   grep '%s' /etc/hosts", t.domain())
+	logger.Infoln("   # Should show: 127.0.0.1 *.%s", t.domain())
 	logger.Infoln("")
 	logger.Infoln("⚠️  Important Notes:")
 	logger.Infoln("- After trusting the certificate, restart Chrome completely")
 	logger.Infoln("- Clear Chrome's cache (chrome://settings/clearBrowserData)")
-	logger.Infoln("- Make sure you're accessing sites with the exact domain: *.%s.local", t.ClusterName)
+	logger.Infoln("- Make sure you're accessing sites with the exact domain: *.%s", t.domain())
 	logger.Infoln("- For localhost testing, use: https://localhost or https://127.0.0.1")
 	logger.Infoln("- macOS requires both System keychain AND proper trust settings")
 	logger.Infoln("- Some browsers have their own certificate stores")
@@ -478,12 +634,17 @@ func (t *TLS) printTrustInstructions(caCert []byte) error {
 
 	logger.Infoln("")
 	logger.Infoln("🎯 Certificate Details:")
-	logger.Infoln("Domain: *.%s.local", t.ClusterName)
+	logger.Infoln("Domain: *.%s", t.domain())
 	logger.Infoln("Validity: %d years", CertValidityYears)
-	logger.Infoln("Cluster Issuer: %s", TLSClusterIssuerName)
+	logger.Infoln("%s: %s", t.issuerKind(), TLSClusterIssuerName)
 	logger.Infoln("")
-	logger.Infoln("🚀 You can now use TLS certificates in your cluster!")
-	logger.Infoln("Example ingress annotation: cert-manager.io/cluster-issuer: %s", TLSClusterIssuerName)
+	if t.issuerNamespace != "" {
+		logger.Infoln("🚀 You can now use TLS certificates in namespace %s!", t.issuerNamespace)
+		logger.Infoln("Example ingress annotation: cert-manager.io/issuer: %s", TLSClusterIssuerName)
+	} else {
+		logger.Infoln("🚀 You can now use TLS certificates in your cluster!")
+		logger.Infoln("Example ingress annotation: cert-manager.io/cluster-issuer: %s", TLSClusterIssuerName)
+	}
 
 	logger.Infoln("")
 	logger.Infoln("🔧 Troubleshooting Chrome Issues:")
@@ -491,7 +652,7 @@ func (t *TLS) printTrustInstructions(caCert []byte) error {
 	logger.Infoln("1. Ensure you've restarted Chrome completely (quit all instances)")
 	logger.Infoln("2. Clear Chrome's SSL cache: chrome://settings/clearBrowserData")
 	logger.Infoln("3. Check certificate is in Chrome: chrome://settings/certificates")
-	logger.Infoln("4. Verify domain matches exactly: https://%s.local or https://subdomain.%s.local", t.ClusterName, t.ClusterName)
+	logger.Infoln("4. Verify domain matches exactly: https://%s or https://subdomain.%s", t.domain(), t.domain())
 	logger.Infoln("5. Try incognito mode to test without cache")
 	logger.Infoln("6. Check Chrome's certificate viewer: Developer Tools > Security tab")
 	logger.Infoln("7. For local development, ensure your app serves HTTPS on the correct domain")
@@ -499,7 +660,7 @@ func (t *TLS) printTrustInstructions(caCert []byte) error {
 	logger.Infoln("")
 	logger.Infoln("📋 Certificate content (base64):")
 	certBase64 := base64.StdEncoding.EncodeToString(caCert)
-	logger.Infoln(certBase64)
+	logger.Infoln("%s", certBase64)
 
 	return nil
 }
@@ -580,45 +741,42 @@ func (t *TLS) validateCACertificate(certPEM []byte) error {
 	return nil
 }
 
-func (t *TLS) DiagnoseCertificateIssues() error {
+// DiagnoseCertificateIssues checks the CA secret and issuer of the plugin exist, then
+// verifies a TLS handshake with argocd.<domain> against the cluster CA. It returns
+// the handshake result, which records why verification failed.
+func (t *TLS) DiagnoseCertificateIssues() (*HandshakeResult, error) {
 	logger.Infoln("🔍 Diagnosing Certificate Issues for cluster: %s", t.ClusterName)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	// Check if CA secret exists
-	_, err := t.k8sClient.Clientset.CoreV1().Secrets(CertManagerNamespace).Get(
-		ctx, TLSSecretName, metav1.GetOptions{})
+	caCert, err := t.getCACertificate(ctx)
 	if err != nil {
 		logger.Errorln("❌ CA secret not found: %v", err)
-		return fmt.Errorf("CA secret not found, run TLS plugin installation first")
+		return nil, fmt.Errorf("CA secret not found, run TLS plugin installation first")
 	}
 	logger.Successln("✅ CA secret exists in cluster")
 
-	// Check if cluster issuer exists
-	gvr := schema.GroupVersionResource{
-		Group:    "cert-manager.io",
-		Version:  "v1",
-		Resource: "clusterissuers",
-	}
-	_, err = t.k8sClient.Dynamic.Resource(gvr).Get(
-		ctx, TLSClusterIssuerName, metav1.GetOptions{})
+	// Check if the issuer exists
+	_, err = t.issuerResource().Get(ctx, TLSClusterIssuerName, metav1.GetOptions{})
 	if err != nil {
-		logger.Errorln("❌ Cluster issuer not found: %v", err)
-		return fmt.Errorf("cluster issuer not found")
+		logger.Errorln("❌ %s not found: %v", t.issuerKind(), err)
+		return nil, fmt.Errorf("%s not found", t.issuerKind())
 	}
-	logger.Successln("✅ Cluster issuer exists")
+	logger.Successln("✅ %s exists", t.issuerKind())
 
-	// Generate fresh certificate for diagnosis
-	caCert, _, err := t.generateCACertificate()
-	if err != nil {
-		return fmt.Errorf("failed to generate CA certificate for diagnosis: %w", err)
+	result := t.diagnoseHandshake(fmt.Sprintf("argocd.%s", t.domain()), caCert)
+	if result.Verified() {
+		logger.Successln("%s", result)
+	} else {
+		logger.Errorln("%s", result)
 	}
 
 	// Create diagnostic certificate file
 	tempFile, err := os.CreateTemp("", fmt.Sprintf("%s-ca-diagnostic-*.crt", t.ClusterName))
 	if err != nil {
-		return fmt.Errorf("failed to create diagnostic temp file: %w", err)
+		return nil, fmt.Errorf("failed to create diagnostic temp file: %w", err)
 	}
 	defer func() {
 		if err := tempFile.Close(); err != nil {
@@ -627,7 +785,7 @@ func (t *TLS) DiagnoseCertificateIssues() error {
 	}()
 
 	if _, err := tempFile.Write(caCert); err != nil {
-		return fmt.Errorf("failed to write diagnostic certificate: %w", err)
+		return nil, fmt.Errorf("failed to write diagnostic certificate: %w", err)
 	}
 
 	logger.Infoln("📋 Diagnostic Certificate File: %s", tempFile.Name())
@@ -636,7 +794,7 @@ func (t *TLS) DiagnoseCertificateIssues() error {
 		t.printMacOSDiagnostics(tempFile.Name())
 	}
 
-	return nil
+	return result, nil
 }
 
 func (t *TLS) printMacOSDiagnostics(certPath string) {
@@ -655,7 +813,8 @@ func (t *TLS) printMacOSDiagnostics(certPath string) {
 	logger.Infoln("   security trust-settings-show -d %s", certPath)
 	logger.Infoln("")
 	logger.Infoln("4. Test SSL connection (if service is running):")
-	logger.Infoln("   echo | openssl s_client -connect %s.local:443 -servername %s.local 2>/dev/null | openssl x509 -noout -subject -issuer", t.ClusterName, t.ClusterName)
+	logger.Infoln("   echo | openssl s_client -connect %s:443 -servername %s 2>/dev/null | "+
+		"openssl x509 -noout -subject -issuer", t.domain(), t.domain())
 	logger.Infoln("")
 	logger.Infoln("5. Check Chrome certificate store:")
 	logger.Infoln("   # Open Chrome -> Settings -> Privacy and Security -> Security -> Manage Certificates")
@@ -673,9 +832,9 @@ func (t *TLS) printMacOSDiagnostics(certPath string) {
 	logger.Infoln("")
 	logger.Infoln("   Issue: 'Domain name mismatch'")
 	logger.Infoln("   Solution: Ensure you're accessing the exact domains listed in the certificate")
-	logger.Infoln("   Certificate covers: *.%s.local, %s.local, localhost", t.ClusterName, t.ClusterName)
+	logger.Infoln("   Certificate covers: *.%s, %s, localhost", t.domain(), t.domain())
 	logger.Infoln("")
 	logger.Infoln("   Issue: 'No route to host'")
 	logger.Infoln("   Solution: Add domain to /etc/hosts")
-	logger.Infoln("   Fix: echo '127.0.0.1 %s.local' | sudo tee -a /etc/hosts", t.ClusterName)
+	logger.Infoln("   Fix: echo '127.0.0.1 %s' | sudo tee -a /etc/hosts", t.domain())
 }
@@ -0,0 +1,182 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mrgb7/playground/pkg/logger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var (
+	TLSACMEIssuerName     = "acme-issuer"
+	ACMEStagingServer     = "https://acme-staging-v02.api.letsencrypt.org/directory"
+	ACMEProductionServer  = "https://acme-v02.api.letsencrypt.org/directory"
+	acmeAccountSecretName = "acme-issuer-account-key"
+)
+
+// Override keys of the TLS plugin configuring the ACME ClusterIssuer
+const (
+	// TLSACMEEmailOverrideKey is the ACME account email; setting it creates the ACME issuer
+	TLSACMEEmailOverrideKey = "acme.email"
+	// TLSACMEServerOverrideKey is staging (the default), production or an ACME directory URL
+	TLSACMEServerOverrideKey = "acme.server"
+	// TLSACMEOnlyOverrideKey skips the local CA issuer so ACME is the only issuer
+	TLSACMEOnlyOverrideKey = "acme.only"
+)
+
+func (t *TLS) GetOverrideSchema() map[string]OverrideType {
+	return map[string]OverrideType{
+		TLSACMEEmailOverrideKey:  OverrideTypeString,
+		TLSACMEServerOverrideKey: OverrideTypeString,
+		TLSACMEOnlyOverrideKey:   OverrideTypeBool,
+	}
+}
+
+func (t *TLS) ValidateOverrideValues(values map[string]interface{}) error {
+	if err := validateOverrideKeys(values,
+		TLSACMEEmailOverrideKey, TLSACMEServerOverrideKey, TLSACMEOnlyOverrideKey); err != nil {
+		return err
+	}
+
+	email, hasEmail := GetNestedValue(values, TLSACMEEmailOverrideKey)
+	if hasEmail {
+		s, isString := email.(string)
+		if !isString {
+			return fmt.Errorf("%s must be a string, got %v", TLSACMEEmailOverrideKey, email)
+		}
+		if err := validateACMEEmail(s); err != nil {
+			return err
+		}
+	}
+	if server, ok := GetNestedValue(values, TLSACMEServerOverrideKey); ok {
+		s, isString := server.(string)
+		if !isString {
+			return fmt.Errorf("%s must be a string, got %v", TLSACMEServerOverrideKey, server)
+		}
+		if _, err := acmeServerURL(s); err != nil {
+			return err
+		}
+	}
+	if only, ok := GetNestedValue(values, TLSACMEOnlyOverrideKey); ok {
+		if _, isBool := only.(bool); !isBool {
+			return fmt.Errorf("%s must be true or false, got %v", TLSACMEOnlyOverrideKey, only)
+		}
+	}
+
+	if !hasEmail && len(FlattenKeys(values)) > 0 {
+		return fmt.Errorf("%s is required to use the ACME issuer", TLSACMEEmailOverrideKey)
+	}
+	return nil
+}
+
+func (t *TLS) SetOverrideValues(values map[string]interface{}) {
+	if v, ok := GetNestedValue(values, TLSACMEEmailOverrideKey); ok {
+		if email, isString := v.(string); isString {
+			t.acmeEmail = email
+		}
+	}
+	if v, ok := GetNestedValue(values, TLSACMEServerOverrideKey); ok {
+		if server, isString := v.(string); isString {
+			t.acmeServer = server
+		}
+	}
+	if v, ok := GetNestedValue(values, TLSACMEOnlyOverrideKey); ok {
+		if only, isBool := v.(bool); isBool {
+			t.acmeOnly = only
+		}
+	}
+}
+
+// validateACMEEmail checks email is a bare address, which ACME servers need to register
+// the account
+func validateACMEEmail(email string) error {
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return fmt.Errorf("invalid %s %q: expected an address like name@example.com", TLSACMEEmailOverrideKey, email)
+	}
+	domain := email[strings.LastIndex(email, "@")+1:]
+	if !strings.Contains(domain, ".") {
+		return fmt.Errorf("invalid %s %q: the domain must be fully qualified", TLSACMEEmailOverrideKey, email)
+	}
+	return nil
+}
+
+// acmeServerURL resolves the staging and production shorthands to the Let's Encrypt
+// directory URLs and checks any other server is an https URL
+func acmeServerURL(server string) (string, error) {
+	switch server {
+	case "", "staging":
+		return ACMEStagingServer, nil
+	case "production":
+		return ACMEProductionServer, nil
+	}
+	u, err := url.Parse(server)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return "", fmt.Errorf("invalid %s %q: expected staging, production or an https directory URL",
+			TLSACMEServerOverrideKey, server)
+	}
+	return server, nil
+}
+
+// createACMEClusterIssuer creates the ClusterIssuer requesting certificates from the ACME
+// server for email, solving HTTP-01 challenges through the nginx ingress controller
+func (t *TLS) createACMEClusterIssuer(email, server string) error {
+	if err := validateACMEEmail(email); err != nil {
+		return err
+	}
+	serverURL, err := acmeServerURL(server)
+	if err != nil {
+		return err
+	}
+
+	logger.Infoln("Creating ClusterIssuer: %s (%s)", TLSACMEIssuerName, serverURL)
+	return t.applyIssuer(clusterIssuerGVR, buildACMEClusterIssuer(email, serverURL))
+}
+
+func buildACMEClusterIssuer(email, serverURL string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "cert-manager.io/v1",
+			"kind":       "ClusterIssuer",
+			"metadata": map[string]interface{}{
+				"name": TLSACMEIssuerName,
+			},
+			"spec": map[string]interface{}{
+				"acme": map[string]interface{}{
+					"email":  email,
+					"server": serverURL,
+					"privateKeySecretRef": map[string]interface{}{
+						"name": acmeAccountSecretName,
+					},
+					"solvers": []interface{}{
+						map[string]interface{}{
+							"http01": map[string]interface{}{
+								"ingress": map[string]interface{}{
+									"ingressClassName": "nginx",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// deleteACMEClusterIssuer removes the ACME ClusterIssuer when it exists
+func (t *TLS) deleteACMEClusterIssuer() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err := t.k8sClient.Dynamic.Resource(clusterIssuerGVR).Delete(ctx, TLSACMEIssuerName, metav1.DeleteOptions{})
+	if err := ignoreNotFound(err); err != nil {
+		return fmt.Errorf("failed to delete ClusterIssuer %s: %w", TLSACMEIssuerName, err)
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package plugins
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mrgb7/playground/internal/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+var issuerListKinds = map[schema.GroupVersionResource]string{
+	issuerGVR:        "IssuerList",
+	clusterIssuerGVR: "ClusterIssuerList",
+	certificateGVR:   "CertificateList",
+}
+
+func acmeIssuerObject() *unstructured.Unstructured {
+	issuer := issuerObject("ClusterIssuer", "")
+	issuer.SetName(TLSACMEIssuerName)
+	return issuer
+}
+
+func TestBuildACMEClusterIssuer(t *testing.T) {
+	issuer := buildACMEClusterIssuer("ops@example.com", ACMEStagingServer)
+
+	if issuer.GetKind() != "ClusterIssuer" || issuer.GetName() != TLSACMEIssuerName || issuer.GetNamespace() != "" {
+		t.Errorf("expected ClusterIssuer %s, got %s %s/%s",
+			TLSACMEIssuerName, issuer.GetKind(), issuer.GetNamespace(), issuer.GetName())
+	}
+
+	acme, _, _ := unstructured.NestedMap(issuer.Object, "spec", "acme")
+	expected := map[string]interface{}{
+		"email":               "ops@example.com",
+		"server":              ACMEStagingServer,
+		"privateKeySecretRef": map[string]interface{}{"name": acmeAccountSecretName},
+		"solvers": []interface{}{
+			map[string]interface{}{
+				"http01": map[string]interface{}{
+					"ingress": map[string]interface{}{"ingressClassName": "nginx"},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(acme, expected) {
+		t.Errorf("expected acme spec %v, got %v", expected, acme)
+	}
+}
+
+func TestValidateACMEEmail(t *testing.T) {
+	tests := []struct {
+		email   string
+		wantErr bool
+	}{
+		{"ops@example.com", false},
+		{"first.last+demo@mail.example.org", false},
+		{"", true},
+		{"ops", true},
+		{"ops@localhost", true},
+		{"Ops <ops@example.com>", true},
+		{"ops@example.com ", true},
+	}
+
+	for _, tt := range tests {
+		err := validateACMEEmail(tt.email)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateACMEEmail(%q) error = %v, wantErr %v", tt.email, err, tt.wantErr)
+		}
+	}
+}
+
+func TestACMEServerURL(t *testing.T) {
+	tests := []struct {
+		server   string
+		expected string
+		wantErr  bool
+	}{
+		{"", ACMEStagingServer, false},
+		{"staging", ACMEStagingServer, false},
+		{"production", ACMEProductionServer, false},
+		{"https://acme.example.com/directory", "https://acme.example.com/directory", false},
+		{"http://acme.example.com/directory", "", true},
+		{"prod", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := acmeServerURL(tt.server)
+		if (err != nil) != tt.wantErr || got != tt.expected {
+			t.Errorf("acmeServerURL(%q) = %q, %v", tt.server, got, err)
+		}
+	}
+}
+
+func TestTLS_ValidateOverrideValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:   "email and server",
+			values: map[string]interface{}{"acme": map[string]interface{}{"email": "ops@example.com", "server": "staging"}},
+		},
+		{
+			name: "acme only",
+			values: map[string]interface{}{"acme": map[string]interface{}{
+				"email": "ops@example.com", "only": true,
+			}},
+		},
+		{
+			name:    "invalid email",
+			values:  map[string]interface{}{"acme": map[string]interface{}{"email": "ops"}},
+			wantErr: "invalid acme.email",
+		},
+		{
+			name:    "server without email",
+			values:  map[string]interface{}{"acme": map[string]interface{}{"server": "production"}},
+			wantErr: "acme.email is required",
+		},
+		{
+			name:    "unknown server",
+			values:  map[string]interface{}{"acme": map[string]interface{}{"email": "ops@example.com", "server": "prod"}},
+			wantErr: "invalid acme.server",
+		},
+		{
+			name:    "only is not a bool",
+			values:  map[string]interface{}{"acme": map[string]interface{}{"email": "ops@example.com", "only": "yes"}},
+			wantErr: "acme.only must be true or false",
+		},
+		{
+			name:    "unknown key",
+			values:  map[string]interface{}{"acme": map[string]interface{}{"mail": "ops@example.com"}},
+			wantErr: "unknown override key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&TLS{}).ValidateOverrideValues(tt.values)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCreateACMEClusterIssuer(t *testing.T) {
+	dynamic := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), issuerListKinds)
+	plugin := &TLS{k8sClient: &k8s.K8sClient{Dynamic: dynamic}}
+
+	if err := plugin.createACMEClusterIssuer("ops", "staging"); err == nil {
+		t.Fatal("expected an invalid email to be rejected")
+	}
+	if err := plugin.createACMEClusterIssuer("ops@example.com", "staging"); err != nil {
+		t.Fatalf("createACMEClusterIssuer: %v", err)
+	}
+	// switching to production updates the existing issuer
+	if err := plugin.createACMEClusterIssuer("ops@example.com", "production"); err != nil {
+		t.Fatalf("createACMEClusterIssuer: %v", err)
+	}
+
+	issuer, err := dynamic.Resource(clusterIssuerGVR).Get(t.Context(), TLSACMEIssuerName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected the ClusterIssuer to exist: %v", err)
+	}
+	if server, _, _ := unstructured.NestedString(issuer.Object, "spec", "acme", "server"); server != ACMEProductionServer {
+		t.Errorf("expected server %s, got %s", ACMEProductionServer, server)
+	}
+}
+
+func TestIngressFindTLSIssuer(t *testing.T) {
+	tests := []struct {
+		name       string
+		issuerName string
+		issuers    []runtime.Object
+		expected   *tlsIssuer
+	}{
+		{
+			name:     "local CA preferred",
+			issuers:  []runtime.Object{issuerObject("ClusterIssuer", ""), acmeIssuerObject()},
+			expected: &tlsIssuer{annotation: "cert-manager.io/cluster-issuer", name: TLSClusterIssuerName},
+		},
+		{
+			name:     "ACME issuer only",
+			issuers:  []runtime.Object{acmeIssuerObject()},
+			expected: &tlsIssuer{annotation: "cert-manager.io/cluster-issuer", name: TLSACMEIssuerName},
+		},
+		{
+			name:       "ACME issuer selected",
+			issuerName: TLSACMEIssuerName,
+			issuers:    []runtime.Object{issuerObject("ClusterIssuer", ""), acmeIssuerObject()},
+			expected:   &tlsIssuer{annotation: "cert-manager.io/cluster-issuer", name: TLSACMEIssuerName},
+		},
+		{
+			name:       "selected issuer missing",
+			issuerName: TLSACMEIssuerName,
+			issuers:    []runtime.Object{issuerObject("ClusterIssuer", "")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dynamic := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), issuerListKinds,
+				tt.issuers...)
+			ingress := &Ingress{issuerName: tt.issuerName, k8sClient: &k8s.K8sClient{Dynamic: dynamic}}
+
+			if got := ingress.findTLSIssuer("apps"); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestIngress_ValidateOverrideValues(t *testing.T) {
+	tests := []struct {
+		issuer  interface{}
+		wantErr bool
+	}{
+		{TLSClusterIssuerName, false},
+		{TLSACMEIssuerName, false},
+		{"letsencrypt", true},
+		{true, true},
+	}
+
+	for _, tt := range tests {
+		values := map[string]interface{}{"tls": map[string]interface{}{"issuer": tt.issuer}}
+		if err := (&Ingress{}).ValidateOverrideValues(values); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateOverrideValues(%v) error = %v, wantErr %v", tt.issuer, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mrgb7/playground/pkg/logger"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Annotations cert-manager sets on the secrets of the certificates it issues
+const (
+	certManagerIssuerNameAnnotation = "cert-manager.io/issuer-name"
+	certManagerIssuerKindAnnotation = "cert-manager.io/issuer-kind"
+)
+
+// CARotatablePlugin can replace its CA and have the certificates it signed reissued
+type CARotatablePlugin interface {
+	RotateCA() error
+}
+
+// RotateCA replaces the CA in the CA secret with a freshly generated one, then deletes
+// the secrets of the certificates signed by the old CA so cert-manager reissues them.
+// Clients trusting the old CA have to trust the new one.
+func (t *TLS) RotateCA() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := t.getCACertificate(ctx); err != nil {
+		return err
+	}
+
+	logger.Infoln("Rotating the CA of cluster: %s", t.ClusterName)
+
+	caCert, caKey, err := t.generateCACertificate()
+	if err != nil {
+		return fmt.Errorf("failed to generate CA certificate: %w", err)
+	}
+	if err := t.storeCASecret(caCert, caKey); err != nil {
+		return fmt.Errorf("failed to store CA secret: %w", err)
+	}
+
+	secrets, err := t.k8sClient.Clientset.CoreV1().Secrets(t.issuedSecretsNamespace()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list certificate secrets: %w", err)
+	}
+	for _, secret := range t.rotationTargets(secrets.Items) {
+		err := t.k8sClient.Clientset.CoreV1().Secrets(secret.Namespace).Delete(ctx, secret.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			logger.Warnln("Failed to delete certificate secret %s/%s: %v", secret.Namespace, secret.Name, err)
+			continue
+		}
+		logger.Infoln("Deleted certificate secret %s/%s, cert-manager will reissue it", secret.Namespace, secret.Name)
+	}
+
+	logger.Warnln("⚠️  The CA changed: browsers and clients trusting the old CA must trust the new one")
+	return t.printTrustInstructions(caCert)
+}
+
+// issuedSecretsNamespace is where certificates signed by the CA can live: anywhere for
+// the ClusterIssuer, the issuer's namespace for a namespaced Issuer
+func (t *TLS) issuedSecretsNamespace() string {
+	return t.issuerNamespace
+}
+
+// rotationTargets returns the TLS secrets cert-manager issued through the plugin's issuer,
+// such as the <service>-tls secrets of the ingresses
+func (t *TLS) rotationTargets(secrets []v1.Secret) []v1.Secret {
+	var targets []v1.Secret
+	for _, secret := range secrets {
+		if secret.Type != v1.SecretTypeTLS {
+			continue
+		}
+		if secret.Annotations[certManagerIssuerNameAnnotation] != TLSClusterIssuerName ||
+			secret.Annotations[certManagerIssuerKindAnnotation] != t.issuerKind() {
+			continue
+		}
+		targets = append(targets, secret)
+	}
+	return targets
+}
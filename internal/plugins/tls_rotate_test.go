@@ -0,0 +1,138 @@
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/mrgb7/playground/internal/k8s"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func issuedSecret(namespace, name, issuerKind string) *v1.Secret {
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				certManagerIssuerNameAnnotation: TLSClusterIssuerName,
+				certManagerIssuerKindAnnotation: issuerKind,
+			},
+		},
+		Type: v1.SecretTypeTLS,
+	}
+}
+
+func secretNames(secrets []v1.Secret) []string {
+	names := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		names = append(names, secret.Namespace+"/"+secret.Name)
+	}
+	return names
+}
+
+func TestTLSRotationTargets(t *testing.T) {
+	otherIssuer := issuedSecret("apps", "other-tls", "ClusterIssuer")
+	otherIssuer.Annotations[certManagerIssuerNameAnnotation] = "letsencrypt"
+	opaque := issuedSecret("apps", "opaque-tls", "ClusterIssuer")
+	opaque.Type = v1.SecretTypeOpaque
+
+	secrets := []v1.Secret{
+		*issuedSecret("argocd", "argocd-server-tls", "ClusterIssuer"),
+		*issuedSecret("demo", "demo-tls", "ClusterIssuer"),
+		*issuedSecret("team-a", "app-tls", "Issuer"),
+		*otherIssuer,
+		*opaque,
+		{ObjectMeta: metav1.ObjectMeta{Name: "plain-tls", Namespace: "apps"}, Type: v1.SecretTypeTLS},
+	}
+
+	tests := []struct {
+		name      string
+		scope     string
+		namespace string
+		expected  []string
+	}{
+		{name: "cluster issuer", expected: []string{"argocd/argocd-server-tls", "demo/demo-tls"}},
+		{name: "namespaced issuer", scope: IssuerScopeNamespace, namespace: "team-a", expected: []string{"team-a/app-tls"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &TLS{}
+			if err := plugin.SetIssuerScope(tt.scope, tt.namespace); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := secretNames(plugin.rotationTargets(secrets)); !slices.Equal(got, tt.expected) {
+				t.Errorf("expected targets %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTLSRotateCA(t *testing.T) {
+	prev := RSAKeySize
+	RSAKeySize = 2048
+	defer func() { RSAKeySize = prev }()
+
+	oldCA := []byte("old-ca")
+	caSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        TLSSecretName,
+			Namespace:   CertManagerNamespace,
+			Annotations: map[string]string{"owner": "playground"},
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: map[string][]byte{"tls.crt": oldCA, "tls.key": []byte("old-key")},
+	}
+	unrelated := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "apps"}, Type: v1.SecretTypeTLS}
+	objects := []runtime.Object{
+		caSecret,
+		issuedSecret("argocd", "argocd-server-tls", "ClusterIssuer"),
+		issuedSecret("demo", "demo-tls", "ClusterIssuer"),
+		unrelated,
+	}
+
+	cs := fake.NewSimpleClientset(objects...)
+	plugin := &TLS{ClusterName: "test", k8sClient: &k8s.K8sClient{Clientset: cs}}
+	if err := plugin.RotateCA(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := context.Background()
+	rotated, err := cs.CoreV1().Secrets(CertManagerNamespace).Get(ctx, TLSSecretName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get CA secret: %v", err)
+	}
+	if bytes.Equal(rotated.Data["tls.crt"], oldCA) || len(rotated.Data["tls.key"]) == 0 {
+		t.Errorf("expected a new CA in the secret, got %v", rotated.Data)
+	}
+	if err := plugin.validateCACertificate(rotated.Data["tls.crt"]); err != nil {
+		t.Errorf("expected a valid CA certificate: %v", err)
+	}
+	if rotated.Annotations["owner"] != "playground" {
+		t.Errorf("expected CA secret annotations to be kept, got %v", rotated.Annotations)
+	}
+
+	remaining, err := cs.CoreV1().Secrets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list secrets: %v", err)
+	}
+	expected := []string{CertManagerNamespace + "/" + TLSSecretName, "apps/unrelated"}
+	got := secretNames(remaining.Items)
+	slices.Sort(got)
+	slices.Sort(expected)
+	if !slices.Equal(got, expected) {
+		t.Errorf("expected remaining secrets %v, got %v", expected, got)
+	}
+}
+
+func TestTLSRotateCAWithoutCASecret(t *testing.T) {
+	plugin := &TLS{ClusterName: "test", k8sClient: &k8s.K8sClient{Clientset: fake.NewSimpleClientset()}}
+	if err := plugin.RotateCA(); err == nil {
+		t.Error("expected an error when the tls plugin is not installed")
+	}
+}
@@ -1,9 +1,20 @@
 package plugins
 
 import (
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
+	"net"
+	"slices"
 	"strings"
 	"testing"
+
+	"github.com/mrgb7/playground/internal/k8s"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestTLSPluginInterface(t *testing.T) {
@@ -93,3 +104,163 @@ func containsPEMBlock(content, blockType string) bool {
 	return strings.Contains(content, fmt.Sprintf("-----BEGIN %s-----", blockType)) &&
 		strings.Contains(content, fmt.Sprintf("-----END %s-----", blockType))
 }
+
+func TestTLSSetIssuerScope(t *testing.T) {
+	tests := []struct {
+		name         string
+		scope        string
+		namespace    string
+		wantErr      bool
+		wantKind     string
+		wantSecretNs string
+	}{
+		{"default is cluster", "", "", false, "ClusterIssuer", CertManagerNamespace},
+		{"cluster scope", IssuerScopeCluster, "", false, "ClusterIssuer", CertManagerNamespace},
+		{"namespace scope", IssuerScopeNamespace, "team-a", false, "Issuer", "team-a"},
+		{"namespace scope without namespace", IssuerScopeNamespace, "", true, "", ""},
+		{"cluster scope with namespace", IssuerScopeCluster, "team-a", true, "", ""},
+		{"unknown scope", "global", "", true, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &TLS{}
+			err := plugin.SetIssuerScope(tt.scope, tt.namespace)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetIssuerScope() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if plugin.issuerKind() != tt.wantKind {
+				t.Errorf("expected kind %s, got %s", tt.wantKind, plugin.issuerKind())
+			}
+			if plugin.secretNamespace() != tt.wantSecretNs {
+				t.Errorf("expected secret namespace %s, got %s", tt.wantSecretNs, plugin.secretNamespace())
+			}
+		})
+	}
+}
+
+func TestTLSBuildIssuer(t *testing.T) {
+	plugin := &TLS{}
+	if err := plugin.SetIssuerScope(IssuerScopeNamespace, "team-a"); err != nil {
+		t.Fatal(err)
+	}
+
+	issuer := plugin.buildIssuer()
+	if issuer.GetKind() != "Issuer" || issuer.GetNamespace() != "team-a" || issuer.GetName() != TLSClusterIssuerName {
+		t.Errorf("unexpected issuer %s %s/%s", issuer.GetKind(), issuer.GetNamespace(), issuer.GetName())
+	}
+
+	clusterIssuer := (&TLS{}).buildIssuer()
+	if clusterIssuer.GetKind() != "ClusterIssuer" || clusterIssuer.GetNamespace() != "" {
+		t.Errorf("unexpected cluster issuer %s %s", clusterIssuer.GetKind(), clusterIssuer.GetNamespace())
+	}
+}
+
+func TestTLSAddSubjectAltNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		dnsNames []string
+		ips      []string
+		wantErr  bool
+	}{
+		{"valid names and ips", []string{"*.apps.test", "MyApp.test"}, []string{"192.168.64.10", "::1"}, false},
+		{"invalid dns name", []string{"bad_name.test"}, nil, true},
+		{"wildcard not leftmost", []string{"a.*.test"}, nil, true},
+		{"invalid ip", nil, []string{"192.168.64.300"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &TLS{}
+			err := plugin.AddSubjectAltNames(tt.dnsNames, tt.ips)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AddSubjectAltNames() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTLSCertificateIncludesExtraNames(t *testing.T) {
+	prev := RSAKeySize
+	RSAKeySize = 2048
+	defer func() { RSAKeySize = prev }()
+
+	plugin := &TLS{ClusterName: "test-cluster"}
+	if err := plugin.AddSubjectAltNames([]string{"*.apps.test"}, []string{"192.168.64.10"}); err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM, _, err := plugin.generateCACertificate()
+	if err != nil {
+		t.Fatalf("generateCACertificate: %v", err)
+	}
+	block, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+
+	if !slices.Contains(cert.DNSNames, "*.apps.test") || !slices.Contains(cert.DNSNames, "*.test-cluster.local") {
+		t.Errorf("unexpected DNS names %v", cert.DNSNames)
+	}
+	found := false
+	for _, ip := range cert.IPAddresses {
+		if ip.Equal(net.ParseIP("192.168.64.10")) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected 192.168.64.10 in IP addresses %v", cert.IPAddresses)
+	}
+}
+
+func TestTLSIssuerScopeRecorded(t *testing.T) {
+	cs := fake.NewSimpleClientset()
+	plugin := &TLS{k8sClient: &k8s.K8sClient{Clientset: cs}}
+	if err := plugin.SetIssuerScope(IssuerScopeNamespace, "apps"); err != nil {
+		t.Fatalf("SetIssuerScope: %v", err)
+	}
+	plugin.recordIssuerScope()
+
+	restored := &TLS{k8sClient: plugin.k8sClient}
+	restored.restoreIssuerScope()
+	if restored.issuerKind() != "Issuer" || restored.secretNamespace() != "apps" {
+		t.Errorf("expected the namespaced Issuer in apps, got %s in %s", restored.issuerKind(), restored.secretNamespace())
+	}
+}
+
+func TestTLSUninstallNamespacedIssuer(t *testing.T) {
+	tracker := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: InstallerTrackerConfigMapName, Namespace: InstallerTrackerNamespace},
+		Data:       map[string]string{pluginNamespaceKey(TLSName): "apps"},
+	}
+	caSecret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: TLSSecretName, Namespace: "apps"}}
+	cs := fake.NewSimpleClientset(tracker, caSecret)
+	dynamic := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), issuerListKinds,
+		issuerObject("Issuer", "apps"))
+	plugin := &TLS{k8sClient: &k8s.K8sClient{Clientset: cs, Dynamic: dynamic}}
+	plugin.restoreIssuerScope()
+
+	if err := plugin.Uninstall("kubeconfig", "dev"); err != nil {
+		t.Fatalf("Uninstall: %v", err)
+	}
+
+	if _, err := cs.CoreV1().Secrets("apps").Get(t.Context(), TLSSecretName, metav1.GetOptions{}); err == nil {
+		t.Error("expected the CA secret in apps to be deleted")
+	}
+	if _, err := dynamic.Resource(issuerGVR).Namespace("apps").
+		Get(t.Context(), TLSClusterIssuerName, metav1.GetOptions{}); err == nil {
+		t.Error("expected the namespaced Issuer to be deleted")
+	}
+	cm, err := cs.CoreV1().ConfigMaps(InstallerTrackerNamespace).
+		Get(t.Context(), InstallerTrackerConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get the tracker: %v", err)
+	}
+	if _, ok := cm.Data[pluginNamespaceKey(TLSName)]; ok {
+		t.Error("expected the recorded issuer scope to be removed")
+	}
+}
@@ -0,0 +1,220 @@
+package plugins
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/mrgb7/playground/pkg/logger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	TLSVerifyPort    = 443
+	TLSVerifyTimeout = 10 * time.Second
+)
+
+// CertificateReport describes the certificate served for a host and whether it chains to the playground CA
+type CertificateReport struct {
+	Host     string
+	Address  string
+	Subject  string
+	Issuer   string
+	NotAfter time.Time
+	Valid    bool
+	Err      error
+}
+
+// VerifyHost connects to host and verifies the served certificate chain against the CA stored in the cluster.
+// When address is empty the host is resolved through the system resolver, so /etc/hosts entries are honored,
+// falling back to the nginx LoadBalancer IP when the name does not resolve.
+func (t *TLS) VerifyHost(host, address string) (*CertificateReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), TLSVerifyTimeout)
+	defer cancel()
+
+	caPEM, err := t.getCACertificate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if address == "" {
+		address, err = t.resolveHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	chain, err := fetchCertificateChain(ctx, address, host)
+	if err != nil {
+		return nil, err
+	}
+
+	report := verifyCertificateChain(caPEM, chain, host)
+	report.Address = address
+	return report, nil
+}
+
+func (t *TLS) getCACertificate(ctx context.Context) ([]byte, error) {
+	secret, err := t.k8sClient.Clientset.CoreV1().Secrets(t.secretNamespace()).Get(
+		ctx, TLSSecretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CA secret, is the TLS plugin installed: %w", err)
+	}
+
+	caPEM := secret.Data["tls.crt"]
+	if len(caPEM) == 0 {
+		return nil, fmt.Errorf("CA secret %s/%s has no tls.crt", t.secretNamespace(), TLSSecretName)
+	}
+	return caPEM, nil
+}
+
+func (t *TLS) resolveHost(ctx context.Context, host string) (string, error) {
+	if addrs, err := net.DefaultResolver.LookupHost(ctx, host); err == nil && len(addrs) > 0 {
+		return addrs[0], nil
+	}
+
+	svc, err := t.k8sClient.Clientset.CoreV1().Services(NginxNamespace).Get(ctx, NginxControllerName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s and to get nginx service: %w", host, err)
+	}
+	address := loadBalancerAddress(svc)
+	if address == "" {
+		return "", fmt.Errorf("failed to resolve %s: %w", host, errNoLoadBalancerIP)
+	}
+	return address, nil
+}
+
+// fetchCertificateChain returns the chain served at address for the given server name without verifying it,
+// verification is done against the playground CA afterwards so the report can describe invalid chains too
+func fetchCertificateChain(ctx context.Context, address, serverName string) ([]*x509.Certificate, error) {
+	dialer := &tls.Dialer{
+		Config: &tls.Config{
+			ServerName:         serverName,
+			InsecureSkipVerify: true, // #nosec G402 -- the chain is verified against the playground CA below
+		},
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(address, strconv.Itoa(TLSVerifyPort)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			logger.Debugln("Failed to close connection to %s: %v", address, err)
+		}
+	}()
+
+	chain := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("no certificate served by %s", address)
+	}
+	return chain, nil
+}
+
+func verifyCertificateChain(caPEM []byte, chain []*x509.Certificate, host string) *CertificateReport {
+	leaf := chain[0]
+	report := &CertificateReport{
+		Host:     host,
+		Subject:  leaf.Subject.String(),
+		Issuer:   leaf.Issuer.String(),
+		NotAfter: leaf.NotAfter,
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		report.Err = fmt.Errorf("failed to parse the playground CA certificate")
+		return report
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, report.Err = leaf.Verify(x509.VerifyOptions{
+		DNSName:       host,
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	report.Valid = report.Err == nil
+	return report
+}
+
+// HandshakeResult is the outcome of a TLS handshake verified against the playground CA
+type HandshakeResult struct {
+	Host    string
+	Address string // host:port the handshake was attempted with
+	Err     error  // nil when the served certificate is trusted for Host
+}
+
+// Verified reports whether the handshake succeeded and the certificate is trusted
+func (r *HandshakeResult) Verified() bool {
+	return r.Err == nil
+}
+
+func (r *HandshakeResult) String() string {
+	if r.Err == nil {
+		return fmt.Sprintf("✅ TLS handshake with %s (%s) verified against the playground CA", r.Host, r.Address)
+	}
+
+	var (
+		unknownAuthority x509.UnknownAuthorityError
+		hostname         x509.HostnameError
+		invalid          x509.CertificateInvalidError
+	)
+	reason := r.Err.Error()
+	switch {
+	case errors.As(r.Err, &unknownAuthority):
+		reason = "certificate is not signed by the playground CA"
+	case errors.As(r.Err, &hostname):
+		reason = fmt.Sprintf("certificate does not cover %s", r.Host)
+	case errors.As(r.Err, &invalid):
+		reason = fmt.Sprintf("certificate is invalid: %v", invalid)
+	}
+	return fmt.Sprintf("❌ TLS handshake with %s (%s) failed: %s", r.Host, r.Address, reason)
+}
+
+// diagnoseHandshake resolves host like VerifyHost and attempts a handshake with it
+func (t *TLS) diagnoseHandshake(host string, caPEM []byte) *HandshakeResult {
+	ctx, cancel := context.WithTimeout(context.Background(), TLSVerifyTimeout)
+	defer cancel()
+
+	address, err := t.resolveHost(ctx, host)
+	if err != nil {
+		return &HandshakeResult{Host: host, Err: err}
+	}
+	return tlsHandshake(ctx, caPEM, host, net.JoinHostPort(address, strconv.Itoa(TLSVerifyPort)))
+}
+
+// tlsHandshake dials address with host as server name, trusting only the CA in caPEM
+func tlsHandshake(ctx context.Context, caPEM []byte, host, address string) *HandshakeResult {
+	result := &HandshakeResult{Host: host, Address: address}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		result.Err = fmt.Errorf("failed to parse the playground CA certificate")
+		return result
+	}
+
+	dialer := &tls.Dialer{
+		Config: &tls.Config{
+			ServerName: host,
+			RootCAs:    roots,
+			MinVersion: tls.VersionTLS12,
+		},
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if err := conn.Close(); err != nil {
+		logger.Debugln("Failed to close connection to %s: %v", address, err)
+	}
+	return result
+}
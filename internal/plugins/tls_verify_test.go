@@ -0,0 +1,202 @@
+package plugins
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestCA(t *testing.T, clusterName string) ([]byte, *x509.Certificate, *rsa.PrivateKey) {
+	t.Helper()
+
+	certPEM, keyPEM, err := (&TLS{ClusterName: clusterName}).generateCACertificate()
+	if err != nil {
+		t.Fatalf("Failed to generate CA certificate: %v", err)
+	}
+
+	certBlock, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		t.Fatalf("Failed to parse CA certificate: %v", err)
+	}
+
+	keyBlock, _ := pem.Decode(keyPEM)
+	key, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		t.Fatalf("Failed to parse CA key: %v", err)
+	}
+	return certPEM, cert, key
+}
+
+func newTestLeaf(t *testing.T, host string, ca *x509.Certificate,
+	caKey *rsa.PrivateKey) (*x509.Certificate, *rsa.PrivateKey) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, RSAKeySize)
+	if err != nil {
+		t.Fatalf("Failed to generate leaf key: %v", err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: host},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{host},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("Failed to create leaf certificate: %v", err)
+	}
+
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse leaf certificate: %v", err)
+	}
+	return leaf, key
+}
+
+func TestVerifyCertificateChain(t *testing.T) {
+	prev := RSAKeySize
+	RSAKeySize = 2048
+	defer func() { RSAKeySize = prev }()
+
+	const host = "argocd.test.local"
+	caPEM, ca, caKey := newTestCA(t, "test")
+	otherPEM, _, _ := newTestCA(t, "other")
+	leaf, _ := newTestLeaf(t, host, ca, caKey)
+
+	tests := []struct {
+		name      string
+		caPEM     []byte
+		host      string
+		wantValid bool
+	}{
+		{name: "signed by playground CA", caPEM: caPEM, host: host, wantValid: true},
+		{name: "host not in certificate", caPEM: caPEM, host: "demo.test.local", wantValid: false},
+		{name: "signed by another CA", caPEM: otherPEM, host: host, wantValid: false},
+		{name: "invalid CA PEM", caPEM: []byte("not a certificate"), host: host, wantValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := verifyCertificateChain(tt.caPEM, []*x509.Certificate{leaf}, tt.host)
+			if report.Valid != tt.wantValid {
+				t.Errorf("Expected valid %t, got %t (err: %v)", tt.wantValid, report.Valid, report.Err)
+			}
+			if !tt.wantValid && report.Err == nil {
+				t.Error("Expected an error for an invalid chain")
+			}
+			if report.Subject != leaf.Subject.String() {
+				t.Errorf("Expected subject %q, got %q", leaf.Subject.String(), report.Subject)
+			}
+			if report.Issuer != ca.Subject.String() {
+				t.Errorf("Expected issuer %q, got %q", ca.Subject.String(), report.Issuer)
+			}
+			if !report.NotAfter.Equal(leaf.NotAfter) {
+				t.Errorf("Expected expiry %v, got %v", leaf.NotAfter, report.NotAfter)
+			}
+		})
+	}
+}
+
+func TestTLSHandshakeResult(t *testing.T) {
+	prev := RSAKeySize
+	RSAKeySize = 2048
+	defer func() { RSAKeySize = prev }()
+
+	const host = "argocd.test.local"
+	caPEM, ca, caKey := newTestCA(t, "test")
+	otherPEM, _, _ := newTestCA(t, "other")
+	leaf, leafKey := newTestLeaf(t, host, ca, caKey)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{leaf.Raw}, PrivateKey: leafKey}},
+		MinVersion:   tls.VersionTLS12,
+	}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0) // rejected handshakes are expected
+	server.StartTLS()
+	defer server.Close()
+	address := server.Listener.Addr().String()
+
+	closed := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	closedAddress := closed.Listener.Addr().String()
+	closed.Close()
+
+	tests := []struct {
+		name         string
+		caPEM        []byte
+		host         string
+		address      string
+		wantVerified bool
+		wantMessage  string
+	}{
+		{
+			name:         "trusted certificate",
+			caPEM:        caPEM,
+			host:         host,
+			address:      address,
+			wantVerified: true,
+			wantMessage:  "✅ TLS handshake with argocd.test.local (" + address + ") verified against the playground CA",
+		},
+		{
+			name:        "signed by another CA",
+			caPEM:       otherPEM,
+			host:        host,
+			address:     address,
+			wantMessage: "failed: certificate is not signed by the playground CA",
+		},
+		{
+			name:        "host not in certificate",
+			caPEM:       caPEM,
+			host:        "demo.test.local",
+			address:     address,
+			wantMessage: "failed: certificate does not cover demo.test.local",
+		},
+		{
+			name:        "invalid CA PEM",
+			caPEM:       []byte("not a certificate"),
+			host:        host,
+			address:     address,
+			wantMessage: "failed: failed to parse the playground CA certificate",
+		},
+		{
+			name:        "nothing listening",
+			caPEM:       caPEM,
+			host:        host,
+			address:     closedAddress,
+			wantMessage: "❌ TLS handshake with argocd.test.local (" + closedAddress + ") failed: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), TLSVerifyTimeout)
+			defer cancel()
+
+			result := tlsHandshake(ctx, tt.caPEM, tt.host, tt.address)
+			if result.Verified() != tt.wantVerified {
+				t.Errorf("Expected verified %t, got %t (err: %v)", tt.wantVerified, result.Verified(), result.Err)
+			}
+			if !strings.Contains(result.String(), tt.wantMessage) {
+				t.Errorf("Expected %q to contain %q", result.String(), tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mrgb7/playground/pkg/logger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var certificateGVR = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+
+var (
+	TLSWildcardCertificateName = "local-wildcard"
+	TLSWildcardSecretName      = "local-wildcard-tls"
+)
+
+// CreateWildcardCertificate issues one certificate for *.<domain> in namespace,
+// signed by the TLS plugin's issuer and stored in TLSWildcardSecretName. Ingresses can
+// only reference secrets of their own namespace, so every namespace with ingresses
+// sharing the certificate gets its own copy.
+func (t *TLS) CreateWildcardCertificate(namespace string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cert := t.buildWildcardCertificate(namespace)
+	resource := t.k8sClient.Dynamic.Resource(certificateGVR).Namespace(namespace)
+
+	_, err := resource.Create(ctx, cert, metav1.CreateOptions{})
+	switch {
+	case err != nil && strings.Contains(err.Error(), "already exists"):
+		existing, getErr := resource.Get(ctx, TLSWildcardCertificateName, metav1.GetOptions{})
+		if getErr != nil {
+			return fmt.Errorf("failed to get existing wildcard certificate: %w", getErr)
+		}
+		cert.SetResourceVersion(existing.GetResourceVersion())
+		if _, err := resource.Update(ctx, cert, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update wildcard certificate: %w", err)
+		}
+		logger.Debugln("Updated wildcard certificate in namespace %s", namespace)
+	case err != nil:
+		return fmt.Errorf("failed to create wildcard certificate in namespace %s: %w", namespace, err)
+	default:
+		logger.Successln("Created wildcard certificate for *.%s in namespace %s", t.domain(), namespace)
+	}
+	return nil
+}
+
+func (t *TLS) buildWildcardCertificate(namespace string) *unstructured.Unstructured {
+	wildcard := fmt.Sprintf("*.%s", t.domain())
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "cert-manager.io/v1",
+			"kind":       "Certificate",
+			"metadata": map[string]interface{}{
+				"name":      TLSWildcardCertificateName,
+				"namespace": namespace,
+			},
+			"spec": map[string]interface{}{
+				"secretName": TLSWildcardSecretName,
+				"commonName": wildcard,
+				"dnsNames": []interface{}{
+					wildcard,
+					t.domain(),
+				},
+				"issuerRef": map[string]interface{}{
+					"name":  TLSClusterIssuerName,
+					"kind":  t.issuerKind(),
+					"group": "cert-manager.io",
+				},
+			},
+		},
+	}
+}
+
+// RemoveWildcardCertificates deletes the wildcard certificate and its secret from every
+// namespace it was issued in; cert-manager leaves the secret behind on its own
+func (t *TLS) RemoveWildcardCertificates() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	certs, err := t.k8sClient.Dynamic.Resource(certificateGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list certificates: %w", err)
+	}
+
+	var errs []error
+	for _, cert := range certs.Items {
+		if cert.GetName() != TLSWildcardCertificateName {
+			continue
+		}
+		namespace := cert.GetNamespace()
+		err := t.k8sClient.Dynamic.Resource(certificateGVR).Namespace(namespace).
+			Delete(ctx, TLSWildcardCertificateName, metav1.DeleteOptions{})
+		if err != nil && !strings.Contains(err.Error(), "not found") {
+			errs = append(errs, fmt.Errorf("failed to delete wildcard certificate in namespace %s: %w", namespace, err))
+			continue
+		}
+		err = t.k8sClient.Clientset.CoreV1().Secrets(namespace).Delete(ctx, TLSWildcardSecretName, metav1.DeleteOptions{})
+		if err != nil && !strings.Contains(err.Error(), "not found") {
+			errs = append(errs, fmt.Errorf("failed to delete wildcard secret in namespace %s: %w", namespace, err))
+			continue
+		}
+		logger.Debugln("Removed wildcard certificate from namespace %s", namespace)
+	}
+	return errors.Join(errs...)
+}
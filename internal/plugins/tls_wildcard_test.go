@@ -0,0 +1,91 @@
+package plugins
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mrgb7/playground/internal/k8s"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestBuildWildcardCertificate(t *testing.T) {
+	tests := []struct {
+		name           string
+		issuerNs       string
+		expectedIssuer string
+	}{
+		{name: "cluster issuer", expectedIssuer: "ClusterIssuer"},
+		{name: "namespaced issuer", issuerNs: "argocd", expectedIssuer: "Issuer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tls := &TLS{ClusterName: "test", issuerNamespace: tt.issuerNs}
+			cert := tls.buildWildcardCertificate("argocd")
+
+			if cert.GetKind() != "Certificate" || cert.GetAPIVersion() != "cert-manager.io/v1" {
+				t.Errorf("Expected a cert-manager.io/v1 Certificate, got %s %s", cert.GetAPIVersion(), cert.GetKind())
+			}
+			if cert.GetName() != TLSWildcardCertificateName || cert.GetNamespace() != "argocd" {
+				t.Errorf("Unexpected metadata: %s/%s", cert.GetNamespace(), cert.GetName())
+			}
+
+			secret, _, _ := unstructured.NestedString(cert.Object, "spec", "secretName")
+			if secret != TLSWildcardSecretName {
+				t.Errorf("Expected secret '%s', got '%s'", TLSWildcardSecretName, secret)
+			}
+
+			dnsNames, _, _ := unstructured.NestedStringSlice(cert.Object, "spec", "dnsNames")
+			if !reflect.DeepEqual(dnsNames, []string{"*.test.local", "test.local"}) {
+				t.Errorf("Unexpected DNS names: %v", dnsNames)
+			}
+
+			issuerRef, _, _ := unstructured.NestedStringMap(cert.Object, "spec", "issuerRef")
+			expected := map[string]string{"name": TLSClusterIssuerName, "kind": tt.expectedIssuer, "group": "cert-manager.io"}
+			if !reflect.DeepEqual(issuerRef, expected) {
+				t.Errorf("Expected issuerRef %v, got %v", expected, issuerRef)
+			}
+		})
+	}
+}
+
+func TestRemoveWildcardCertificates(t *testing.T) {
+	tls := &TLS{ClusterName: "test"}
+	other := tls.buildWildcardCertificate("apps")
+	other.SetName("apps-cert")
+	secret := func(namespace, name string) *v1.Secret {
+		return &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	}
+
+	cs := fake.NewSimpleClientset(secret("argocd", TLSWildcardSecretName), secret("apps", TLSWildcardSecretName),
+		secret("apps", "apps-tls"))
+	dynamic := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), issuerListKinds,
+		tls.buildWildcardCertificate("argocd"), tls.buildWildcardCertificate("apps"), other)
+	tls.k8sClient = &k8s.K8sClient{Clientset: cs, Dynamic: dynamic}
+
+	if err := tls.RemoveWildcardCertificates(); err != nil {
+		t.Fatalf("RemoveWildcardCertificates: %v", err)
+	}
+
+	for _, namespace := range []string{"argocd", "apps"} {
+		if _, err := dynamic.Resource(certificateGVR).Namespace(namespace).
+			Get(t.Context(), TLSWildcardCertificateName, metav1.GetOptions{}); err == nil {
+			t.Errorf("expected the wildcard certificate in %s to be deleted", namespace)
+		}
+		if _, err := cs.CoreV1().Secrets(namespace).Get(t.Context(), TLSWildcardSecretName, metav1.GetOptions{}); err == nil {
+			t.Errorf("expected the wildcard secret in %s to be deleted", namespace)
+		}
+	}
+	if _, err := dynamic.Resource(certificateGVR).Namespace("apps").
+		Get(t.Context(), "apps-cert", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected other certificates to be kept: %v", err)
+	}
+	if _, err := cs.CoreV1().Secrets("apps").Get(t.Context(), "apps-tls", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected other secrets to be kept: %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package plugins
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -13,10 +14,43 @@ import (
 )
 
 const (
-	ArgocdInstallNamespace    = "argocd"
 	ArgocdServerLabelSelector = "app.kubernetes.io/name=argocd-server"
 )
 
+// ErrEmptyKubeConfig is returned when a plugin is installed without a kubeconfig,
+// which usually means fetching it from the master node failed earlier
+var ErrEmptyKubeConfig = errors.New("empty kubeconfig; is the cluster running and reachable?")
+
+func checkKubeConfig(kubeConfig string) error {
+	if strings.TrimSpace(kubeConfig) == "" {
+		return ErrEmptyKubeConfig
+	}
+	return nil
+}
+
+// PluginNamespace returns the namespace a plugin was installed into, or defaultNamespace
+// when none was recorded
+func PluginNamespace(kubeConfig, pluginName, defaultNamespace string) string {
+	tracker, err := NewInstallerTracker(kubeConfig)
+	if err != nil {
+		logger.Debugln("Failed to create installer tracker: %v", err)
+		return defaultNamespace
+	}
+	namespace, err := tracker.GetPluginNamespace(pluginName)
+	if err != nil {
+		logger.Debugln("Failed to get recorded namespace for plugin %s: %v", pluginName, err)
+	}
+	if namespace == "" {
+		return defaultNamespace
+	}
+	return namespace
+}
+
+// ArgoCDNamespace returns the namespace ArgoCD was installed into
+func ArgoCDNamespace(kubeConfig string) string {
+	return PluginNamespace(kubeConfig, ArgocdName, ArgocdNamespace)
+}
+
 func IsArgoCDRunning(kubeConfig string) bool {
 	client, err := k8s.NewK8sClient(kubeConfig)
 	if err != nil {
@@ -27,13 +61,14 @@ func IsArgoCDRunning(kubeConfig string) bool {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	namespace, err := client.GetNameSpace(ArgocdInstallNamespace, ctx)
+	argoNamespace := ArgoCDNamespace(kubeConfig)
+	namespace, err := client.GetNameSpace(argoNamespace, ctx)
 	if err != nil || namespace == "" {
 		logger.Debugln("ArgoCD namespace not found: %v", err)
 		return false
 	}
 
-	podList, err := client.Clientset.CoreV1().Pods(ArgocdInstallNamespace).List(ctx, metav1.ListOptions{
+	podList, err := client.Clientset.CoreV1().Pods(argoNamespace).List(ctx, metav1.ListOptions{
 		LabelSelector: ArgocdServerLabelSelector,
 	})
 	if err != nil {
@@ -67,7 +102,41 @@ func IsArgoCDRunning(kubeConfig string) bool {
 	return false
 }
 
-func NewInstaller(plugin Plugin, kubeConfig, clusterName string) (installer.Installer, error) {
+// ValidateInstallerType checks installerType is empty, helm or argocd, and that ArgoCD is
+// running when it is argocd
+func ValidateInstallerType(kubeConfig, installerType string) error {
+	switch installerType {
+	case "", InstallerTypeHelm:
+		return nil
+	case InstallerTypeArgoCD:
+		if !IsArgoCDRunning(kubeConfig) {
+			return fmt.Errorf("installer %s requested but ArgoCD is not installed, add the argocd plugin first",
+				InstallerTypeArgoCD)
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid installer %q: expected %s or %s", installerType, InstallerTypeHelm, InstallerTypeArgoCD)
+}
+
+// NewInstaller returns the installer for plugin. A non-empty installerType forces helm or
+// argocd; otherwise the installer recorded for the plugin is used, falling back to ArgoCD
+// when it is running and Helm otherwise.
+func NewInstaller(plugin Plugin, kubeConfig, clusterName, installerType string) (installer.Installer, error) {
+	if err := checkKubeConfig(kubeConfig); err != nil {
+		return nil, err
+	}
+
+	if installerType != "" {
+		if err := ValidateInstallerType(kubeConfig, installerType); err != nil {
+			return nil, err
+		}
+		logger.Infoln("Using installer type '%s' for plugin '%s'", installerType, plugin.GetName())
+		if installerType == InstallerTypeArgoCD {
+			return NewArgoInstaller(kubeConfig, clusterName)
+		}
+		return installer.NewHelmInstaller(kubeConfig)
+	}
+
 	tracker, err := NewInstallerTracker(kubeConfig)
 	if err != nil {
 		logger.Warnln("Failed to create installer tracker: %v", err)
@@ -81,7 +150,7 @@ func NewInstaller(plugin Plugin, kubeConfig, clusterName string) (installer.Inst
 			logger.Infoln("Using recorded installer type '%s' for plugin '%s'", recordedInstaller, plugin.GetName())
 			switch recordedInstaller {
 			case InstallerTypeArgoCD:
-				return installer.NewArgoInstaller(kubeConfig, clusterName)
+				return NewArgoInstaller(kubeConfig, clusterName)
 			case InstallerTypeHelm:
 				return installer.NewHelmInstaller(kubeConfig)
 			default:
@@ -91,7 +160,7 @@ func NewInstaller(plugin Plugin, kubeConfig, clusterName string) (installer.Inst
 	}
 
 	if IsArgoCDRunning(kubeConfig) {
-		argoInstaller, err := installer.NewArgoInstaller(kubeConfig, clusterName)
+		argoInstaller, err := NewArgoInstaller(kubeConfig, clusterName)
 		if err != nil {
 			logger.Errorln("Failed to create ArgoCD installer: %v", err)
 			return nil, err
@@ -102,6 +171,39 @@ func NewInstaller(plugin Plugin, kubeConfig, clusterName string) (installer.Inst
 	return installer.NewHelmInstaller(kubeConfig)
 }
 
+// NewArgoInstaller returns an installer for applications of the ArgoCD installed by the
+// argocd plugin, wherever its namespace is
+func NewArgoInstaller(kubeConfig, clusterName string) (*installer.ArgoInstaller, error) {
+	argoInstaller, err := installer.NewArgoInstaller(kubeConfig, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	argoInstaller.ArgoNamespace = ArgoCDNamespace(kubeConfig)
+	return argoInstaller, nil
+}
+
+// GetReleaseName returns the Helm release name a plugin is installed under. UnifiedInstall
+// names releases after the plugin rather than the release name of its options, so
+// rollback has to use the plugin name too.
+func GetReleaseName(plugin Plugin) string {
+	return plugin.GetName()
+}
+
+// InstalledStatusPlugin is implemented by plugins whose status doesn't contain
+// StatusRunning once they are installed
+type InstalledStatusPlugin interface {
+	IsInstalledStatus(status string) bool
+}
+
+// IsInstalled reports whether status, as returned by the plugin's Status, means the plugin
+// is installed
+func IsInstalled(plugin Plugin, status string) bool {
+	if p, ok := plugin.(InstalledStatusPlugin); ok {
+		return p.IsInstalledStatus(status)
+	}
+	return IsPluginInstalled(status)
+}
+
 // IsPluginInstalled checks if a plugin is installed based on its status
 func IsPluginInstalled(status string) bool {
 	statusLower := strings.ToLower(status)
@@ -174,6 +276,24 @@ func ValidateAndGetInstallOrder(targetPlugin string, kubeConfig, masterClusterIP
 	return installOrder, nil
 }
 
+// ValidateAndGetInstallLevels validates dependencies and returns the plugins to install
+// grouped into levels that can each be installed concurrently
+func ValidateAndGetInstallLevels(targetPlugin string, kubeConfig, masterClusterIP,
+	clusterName string) ([][]string, error) {
+	dependencyPlugins, err := CreateDependencyPluginsList(kubeConfig, masterClusterIP, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dependency plugins list: %w", err)
+	}
+
+	levels, err := NewDependencyValidator(dependencyPlugins).
+		ValidateInstallationLevels([]string{targetPlugin}, GetInstalledPlugins(kubeConfig))
+	if err != nil {
+		return nil, fmt.Errorf("dependency validation failed: %w", err)
+	}
+
+	return levels, nil
+}
+
 // ValidateAndGetUninstallOrder validates dependencies and returns the correct uninstall order
 func ValidateAndGetUninstallOrder(targetPlugin string, kubeConfig, masterClusterIP, clusterName string) ([]string, error) {
 	// Get all dependency plugins
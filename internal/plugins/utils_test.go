@@ -1,6 +1,8 @@
 package plugins
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/mrgb7/playground/internal/installer"
@@ -36,11 +38,12 @@ func TestIsArgoCDRunning(t *testing.T) {
 
 func TestNewInstaller(t *testing.T) {
 	tests := []struct {
-		name        string
-		kubeConfig  string
-		clusterName string
-		expectError bool
-		pluginName  string
+		name          string
+		kubeConfig    string
+		clusterName   string
+		installerType string
+		expectError   bool
+		pluginName    string
 	}{
 		{
 			name:        "invalid kubeconfig",
@@ -56,12 +59,36 @@ func TestNewInstaller(t *testing.T) {
 			expectError: false,
 			pluginName:  "test-plugin",
 		},
+		{
+			name:          "helm forced",
+			kubeConfig:    createValidKubeConfig(),
+			clusterName:   "test-cluster",
+			installerType: InstallerTypeHelm,
+			expectError:   false,
+			pluginName:    "test-plugin",
+		},
+		{
+			name:          "argocd forced without argocd",
+			kubeConfig:    "invalid-config",
+			clusterName:   "test-cluster",
+			installerType: InstallerTypeArgoCD,
+			expectError:   true,
+			pluginName:    "test-plugin",
+		},
+		{
+			name:          "unknown installer",
+			kubeConfig:    createValidKubeConfig(),
+			clusterName:   "test-cluster",
+			installerType: "kustomize",
+			expectError:   true,
+			pluginName:    "test-plugin",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mock := &MockPlugin{name: tt.pluginName}
-			inst, err := NewInstaller(mock, tt.kubeConfig, tt.clusterName)
+			inst, err := NewInstaller(mock, tt.kubeConfig, tt.clusterName, tt.installerType)
 
 			if tt.expectError && err == nil {
 				t.Errorf("Expected error but got none")
@@ -74,10 +101,44 @@ func TestNewInstaller(t *testing.T) {
 			if !tt.expectError && inst == nil {
 				t.Errorf("Expected installer but got nil")
 			}
+
+			if _, isHelm := inst.(*installer.HelmInstaller); tt.installerType == InstallerTypeHelm && !isHelm {
+				t.Errorf("Expected a Helm installer, got %T", inst)
+			}
 		})
 	}
 }
 
+func TestValidateInstallerType(t *testing.T) {
+	tests := []struct {
+		installerType string
+		wantErr       bool
+	}{
+		{"", false},
+		{InstallerTypeHelm, false},
+		{InstallerTypeArgoCD, true}, // ArgoCD isn't running behind an invalid kubeconfig
+		{"Helm", true},
+		{"flux", true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateInstallerType("invalid-config", tt.installerType)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateInstallerType(%q) error = %v, wantErr %v", tt.installerType, err, tt.wantErr)
+		}
+	}
+}
+
+func TestUnifiedInstallInstallerType(t *testing.T) {
+	base := NewBasePlugin("invalid-config", &MockPlugin{name: "test-plugin"})
+	base.SetInstallerType(InstallerTypeArgoCD)
+
+	err := base.UnifiedInstall("invalid-config", "test-cluster")
+	if err == nil || !strings.Contains(err.Error(), "ArgoCD is not installed") {
+		t.Errorf("expected the argocd installer to be rejected, got %v", err)
+	}
+}
+
 type MockPlugin struct {
 	name string
 }
@@ -173,3 +234,41 @@ users:
     token: test-token
 `
 }
+
+func TestEmptyKubeConfigGuard(t *testing.T) {
+	if _, err := NewInstaller(&MockPlugin{name: "test"}, "  ", "test-cluster", ""); !errors.Is(err, ErrEmptyKubeConfig) {
+		t.Errorf("NewInstaller: expected ErrEmptyKubeConfig, got %v", err)
+	}
+
+	nginx := NewNginx("")
+	if err := nginx.Install("", "test-cluster"); !errors.Is(err, ErrEmptyKubeConfig) {
+		t.Errorf("Install: expected ErrEmptyKubeConfig, got %v", err)
+	}
+	if err := nginx.Uninstall("", "test-cluster"); !errors.Is(err, ErrEmptyKubeConfig) {
+		t.Errorf("Uninstall: expected ErrEmptyKubeConfig, got %v", err)
+	}
+}
+
+func TestGetReleaseName(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   Plugin
+		expected string
+	}{
+		{name: "release name differs from the plugin", plugin: NewNginx("test-config"), expected: "nginx-ingress"},
+		{name: "dashboard", plugin: NewDashboard("test-config", "dev"), expected: DashboardName},
+		{name: "no release name", plugin: NewCertManager("test-config"), expected: "cert-manager"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetReleaseName(tt.plugin); got != tt.expected {
+				t.Errorf("expected release %s, got %s", tt.expected, got)
+			}
+			opts := newInstallOptions(tt.plugin.GetName(), tt.plugin.GetOptions(), "")
+			if opts.ApplicationName != tt.expected {
+				t.Errorf("expected the plugin to be installed as %s, got %s", tt.expected, opts.ApplicationName)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package state
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mrgb7/playground/types"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DefinitionAPIVersion = 1
+)
+
+// Definition is a portable description of a cluster and the plugins installed on it,
+// exported on one machine to recreate the same setup on another
+type Definition struct {
+	APIVersion int                 `yaml:"apiVersion"`
+	Cluster    types.ClusterConfig `yaml:"cluster"`
+	Plugins    []PluginDefinition  `yaml:"plugins,omitempty"`
+}
+
+// PluginDefinition is a plugin installed on an exported cluster, with the chart
+// version it runs and the override values it was installed with
+type PluginDefinition struct {
+	Name    string                 `yaml:"name"`
+	Version string                 `yaml:"version,omitempty"`
+	Values  map[string]interface{} `yaml:"values,omitempty"`
+}
+
+// LoadDefinition reads and validates a cluster definition from path
+func LoadDefinition(path string) (*Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster definition %s: %w", path, err)
+	}
+	def, err := ParseDefinition(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cluster definition %s: %w", path, err)
+	}
+	return def, nil
+}
+
+// ParseDefinition decodes and validates a YAML cluster definition
+func ParseDefinition(data []byte) (*Definition, error) {
+	def := &Definition{}
+	if err := yaml.Unmarshal(data, def); err != nil {
+		return nil, fmt.Errorf("failed to parse cluster definition: %w", err)
+	}
+	if def.APIVersion != DefinitionAPIVersion {
+		return nil, fmt.Errorf("unsupported cluster definition apiVersion %d", def.APIVersion)
+	}
+	if def.Cluster.Name == "" {
+		return nil, fmt.Errorf("cluster definition has no cluster name")
+	}
+
+	seen := make(map[string]bool, len(def.Plugins))
+	for _, p := range def.Plugins {
+		if p.Name == "" {
+			return nil, fmt.Errorf("cluster definition has a plugin without a name")
+		}
+		if seen[p.Name] {
+			return nil, fmt.Errorf("plugin %s is listed more than once", p.Name)
+		}
+		seen[p.Name] = true
+	}
+	return def, nil
+}
+
+// Marshal encodes the definition as YAML
+func (d *Definition) Marshal() ([]byte, error) {
+	data, err := yaml.Marshal(d)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal cluster definition: %w", err)
+	}
+	return data, nil
+}
+
+// Save writes the definition to path as YAML
+func (d *Definition) Save(path string) error {
+	data, err := d.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, filePermissions); err != nil {
+		return fmt.Errorf("failed to write cluster definition %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package state
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDefinitionRoundTrip(t *testing.T) {
+	cfg := testConfig("dev", 3)
+	cfg.K3sVersion = "v1.30.4+k3s1"
+	cfg.K3sArgs = []string{"--disable=metrics-server"}
+	cfg.WorkerLabels = []string{"tier=backend"}
+
+	def := &Definition{
+		APIVersion: DefinitionAPIVersion,
+		Cluster:    cfg,
+		Plugins: []PluginDefinition{
+			{Name: "cert-manager", Version: "v1.17.2"},
+			{Name: "load-balancer", Values: map[string]interface{}{
+				"addressPool": map[string]interface{}{"range": "192.168.64.200-192.168.64.210"},
+				"replicas":    2,
+			}},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "cluster.yaml")
+	if err := def.Save(path); err != nil {
+		t.Fatalf("failed to save definition: %v", err)
+	}
+
+	loaded, err := LoadDefinition(path)
+	if err != nil {
+		t.Fatalf("failed to load definition: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, def) {
+		t.Errorf("round trip mismatch:\nexpected %+v\ngot      %+v", def, loaded)
+	}
+}
+
+func TestParseDefinition(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			data: "apiVersion: 1\ncluster:\n  name: dev\n  size: 1\nplugins:\n  - name: argocd\n",
+		},
+		{
+			name:    "unsupported apiVersion",
+			data:    "apiVersion: 2\ncluster:\n  name: dev\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing cluster name",
+			data:    "apiVersion: 1\ncluster:\n  size: 1\n",
+			wantErr: true,
+		},
+		{
+			name:    "plugin without name",
+			data:    "apiVersion: 1\ncluster:\n  name: dev\nplugins:\n  - version: 1.0.0\n",
+			wantErr: true,
+		},
+		{
+			name:    "duplicate plugin",
+			data:    "apiVersion: 1\ncluster:\n  name: dev\nplugins:\n  - name: argocd\n  - name: argocd\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid yaml",
+			data:    "apiVersion: [",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDefinition([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %t, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mrgb7/playground/types"
+)
+
+const (
+	stateDirName     = ".playground"
+	clustersDirName  = "clusters"
+	stateExtension   = ".json"
+	lockExtension    = ".lock"
+	filePermissions  = 0o600
+	dirPermissions   = 0o700
+	lockRetryDelay   = 50 * time.Millisecond
+	lockTimeout      = 5 * time.Second
+	staleLockTimeout = 30 * time.Second
+)
+
+// ClusterState is the persisted record of how a cluster was created
+type ClusterState struct {
+	types.ClusterConfig
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Dir returns the directory cluster state files are stored in
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, stateDirName, clustersDirName), nil
+}
+
+// Save writes the state for cfg, keeping the original creation time if the cluster
+// already has a state file
+func Save(cfg types.ClusterConfig) error {
+	path, err := statePath(cfg.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), dirPermissions); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	return withLock(path, func() error {
+		now := time.Now().UTC()
+		st := &ClusterState{ClusterConfig: cfg, CreatedAt: now, UpdatedAt: now}
+		if existing, err := readState(path); err == nil {
+			st.CreatedAt = existing.CreatedAt
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		data, err := json.MarshalIndent(st, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal state for cluster %s: %w", cfg.Name, err)
+		}
+
+		// Write to a temp file and rename so readers never see a partial file
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, data, filePermissions); err != nil {
+			return fmt.Errorf("failed to write state for cluster %s: %w", cfg.Name, err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			return fmt.Errorf("failed to write state for cluster %s: %w", cfg.Name, err)
+		}
+		return nil
+	})
+}
+
+// Load reads the state of the named cluster. The returned error wraps os.ErrNotExist
+// when no state has been recorded.
+func Load(name string) (*ClusterState, error) {
+	path, err := statePath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var st *ClusterState
+	err = withLock(path, func() error {
+		st, err = readState(path)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// List returns the state of every recorded cluster sorted by name
+func List() ([]*ClusterState, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []*ClusterState{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state directory: %w", err)
+	}
+
+	states := make([]*ClusterState, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), stateExtension) {
+			continue
+		}
+		st, err := Load(strings.TrimSuffix(entry.Name(), stateExtension))
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, st)
+	}
+
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Name < states[j].Name
+	})
+	return states, nil
+}
+
+// Delete removes the state of the named cluster. Missing state is not an error.
+func Delete(name string) error {
+	path, err := statePath(name)
+	if err != nil {
+		return err
+	}
+
+	return withLock(path, func() error {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to delete state for cluster %s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+func statePath(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid cluster name %q", name)
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+stateExtension), nil
+}
+
+func readState(path string) (*ClusterState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file %s: %w", path, err)
+	}
+	st := &ClusterState{}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+	return st, nil
+}
+
+// withLock runs fn while holding an exclusive lock file next to path. Locks older
+// than staleLockTimeout are assumed to be left behind by a crashed process.
+func withLock(path string, fn func() error) error {
+	lockPath := path + lockExtension
+	deadline := time.Now().Add(lockTimeout)
+
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, filePermissions)
+		if err == nil {
+			_ = f.Close()
+			break
+		}
+		if errors.Is(err, os.ErrNotExist) {
+			// The state directory does not exist yet, so there is nothing to guard
+			return fn()
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("failed to acquire state lock: %w", err)
+		}
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > staleLockTimeout {
+			_ = os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout acquiring state lock %s", lockPath)
+		}
+		time.Sleep(lockRetryDelay)
+	}
+	defer func() { _ = os.Remove(lockPath) }()
+
+	return fn()
+}
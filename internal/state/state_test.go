@@ -0,0 +1,155 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/mrgb7/playground/types"
+)
+
+func setTempHome(t *testing.T) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+}
+
+func testConfig(name string, size int) types.ClusterConfig {
+	return types.ClusterConfig{
+		Name:         name,
+		Size:         size,
+		MasterCPUs:   2,
+		MasterMemory: "2G",
+		MasterDisk:   "20G",
+		WorkerCPUs:   2,
+		WorkerMemory: "2G",
+		WorkerDisk:   "20G",
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	setTempHome(t)
+
+	cfg := testConfig("dev", 3)
+	if err := Save(cfg); err != nil {
+		t.Fatalf("failed to save state: %v", err)
+	}
+
+	st, err := Load("dev")
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if st.Name != "dev" || st.Size != 3 || st.WorkerMemory != "2G" {
+		t.Errorf("expected saved config, got %+v", st.ClusterConfig)
+	}
+	if st.CreatedAt.IsZero() {
+		t.Error("expected CreatedAt to be set")
+	}
+
+	cfg.Size = 4
+	if err := Save(cfg); err != nil {
+		t.Fatalf("failed to update state: %v", err)
+	}
+	updated, err := Load("dev")
+	if err != nil {
+		t.Fatalf("failed to load updated state: %v", err)
+	}
+	if updated.Size != 4 {
+		t.Errorf("expected size 4, got %d", updated.Size)
+	}
+	if !updated.CreatedAt.Equal(st.CreatedAt) {
+		t.Errorf("expected CreatedAt to be preserved, got %v and %v", st.CreatedAt, updated.CreatedAt)
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	setTempHome(t)
+
+	_, err := Load("missing")
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected os.ErrNotExist, got %v", err)
+	}
+}
+
+func TestListAndDelete(t *testing.T) {
+	setTempHome(t)
+
+	states, err := List()
+	if err != nil {
+		t.Fatalf("failed to list empty state: %v", err)
+	}
+	if len(states) != 0 {
+		t.Errorf("expected no states, got %d", len(states))
+	}
+
+	for _, name := range []string{"beta", "alpha"} {
+		if err := Save(testConfig(name, 1)); err != nil {
+			t.Fatalf("failed to save state: %v", err)
+		}
+	}
+
+	states, err = List()
+	if err != nil {
+		t.Fatalf("failed to list state: %v", err)
+	}
+	if len(states) != 2 || states[0].Name != "alpha" || states[1].Name != "beta" {
+		t.Fatalf("expected [alpha beta], got %d states", len(states))
+	}
+
+	if err := Delete("alpha"); err != nil {
+		t.Fatalf("failed to delete state: %v", err)
+	}
+	if err := Delete("alpha"); err != nil {
+		t.Errorf("expected deleting missing state to succeed, got %v", err)
+	}
+
+	states, err = List()
+	if err != nil {
+		t.Fatalf("failed to list state: %v", err)
+	}
+	if len(states) != 1 || states[0].Name != "beta" {
+		t.Errorf("expected only beta to remain, got %d states", len(states))
+	}
+}
+
+func TestConcurrentSave(t *testing.T) {
+	setTempHome(t)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 1; i <= 20; i++ {
+		wg.Add(1)
+		go func(size int) {
+			defer wg.Done()
+			errs <- Save(testConfig("shared", size))
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("concurrent save failed: %v", err)
+		}
+	}
+
+	st, err := Load("shared")
+	if err != nil {
+		t.Fatalf("failed to load state after concurrent saves: %v", err)
+	}
+	if st.Size < 1 || st.Size > 20 {
+		t.Errorf("expected a size written by one of the savers, got %d", st.Size)
+	}
+}
+
+func TestInvalidName(t *testing.T) {
+	setTempHome(t)
+
+	for _, name := range []string{"", "../escape", `a\b`} {
+		if err := Save(testConfig(name, 1)); err == nil {
+			t.Errorf("expected error for name %q", name)
+		}
+	}
+}
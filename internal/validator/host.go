@@ -0,0 +1,41 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+)
+
+var errUnsupportedPlatform = errors.New("resource probing is not supported on " + runtime.GOOS)
+
+// GetHostResources reports the CPUs, available memory and free disk space of the
+// host. Disk space is measured where multipass keeps its VM images.
+func GetHostResources() (HostResources, error) {
+	memory, err := availableMemoryMB()
+	if err != nil {
+		return HostResources{}, fmt.Errorf("failed to get available memory: %w", err)
+	}
+
+	path, err := diskProbePath()
+	if err != nil {
+		return HostResources{}, fmt.Errorf("failed to find where multipass stores its data: %w", err)
+	}
+	disk, err := freeDiskMB(path)
+	if err != nil {
+		return HostResources{}, fmt.Errorf("failed to get free disk space: %w", err)
+	}
+
+	return HostResources{
+		CPUs:     runtime.NumCPU(),
+		MemoryMB: memory,
+		DiskMB:   disk,
+		Arch:     runtime.GOARCH,
+	}, nil
+}
+
+// homeDir is used as the disk probe path on platforms where multipass keeps its
+// images under a system directory on the same filesystem
+func homeDir() (string, error) {
+	return os.UserHomeDir()
+}
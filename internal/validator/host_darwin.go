@@ -0,0 +1,22 @@
+package validator
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// availableMemoryMB reports the physical memory from sysctl. macOS keeps most
+// memory in caches, so the total is a better estimate than the free pages.
+func availableMemoryMB() (int64, error) {
+	out, err := exec.Command("sysctl", "-n", "hw.memsize").Output()
+	if err != nil {
+		return 0, err
+	}
+	bytes, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid hw.memsize value %q: %w", out, err)
+	}
+	return bytes / (1024 * 1024), nil
+}
@@ -0,0 +1,15 @@
+//go:build !linux && !darwin && !windows
+
+package validator
+
+func availableMemoryMB() (int64, error) {
+	return 0, errUnsupportedPlatform
+}
+
+func freeDiskMB(string) (int64, error) {
+	return 0, errUnsupportedPlatform
+}
+
+func diskProbePath() (string, error) {
+	return homeDir()
+}
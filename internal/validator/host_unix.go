@@ -0,0 +1,17 @@
+//go:build linux || darwin
+
+package validator
+
+import "syscall"
+
+func freeDiskMB(path string) (int64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	return int64(uint64(st.Bavail) * uint64(st.Bsize) / (1024 * 1024)), nil //nolint:gosec
+}
+
+func diskProbePath() (string, error) {
+	return homeDir()
+}
@@ -0,0 +1,68 @@
+//go:build windows
+
+package validator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32                 = syscall.NewLazyDLL("kernel32.dll")
+	procGlobalMemoryStatusEx = kernel32.NewProc("GlobalMemoryStatusEx")
+	procGetDiskFreeSpaceExW  = kernel32.NewProc("GetDiskFreeSpaceExW")
+)
+
+// memoryStatusEx mirrors the Win32 MEMORYSTATUSEX struct
+type memoryStatusEx struct {
+	Length               uint32
+	MemoryLoad           uint32
+	TotalPhys            uint64
+	AvailPhys            uint64
+	TotalPageFile        uint64
+	AvailPageFile        uint64
+	TotalVirtual         uint64
+	AvailVirtual         uint64
+	AvailExtendedVirtual uint64
+}
+
+func availableMemoryMB() (int64, error) {
+	status := memoryStatusEx{}
+	status.Length = uint32(unsafe.Sizeof(status))
+	r, _, err := procGlobalMemoryStatusEx.Call(uintptr(unsafe.Pointer(&status)))
+	if r == 0 {
+		return 0, fmt.Errorf("GlobalMemoryStatusEx failed: %w", err)
+	}
+	return int64(status.AvailPhys / (1024 * 1024)), nil //nolint:gosec
+}
+
+func freeDiskMB(path string) (int64, error) {
+	p, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var freeToCaller, total, totalFree uint64
+	r, _, callErr := procGetDiskFreeSpaceExW.Call(
+		uintptr(unsafe.Pointer(p)),
+		uintptr(unsafe.Pointer(&freeToCaller)),
+		uintptr(unsafe.Pointer(&total)),
+		uintptr(unsafe.Pointer(&totalFree)),
+	)
+	if r == 0 {
+		return 0, fmt.Errorf("GetDiskFreeSpaceEx %s failed: %w", path, callErr)
+	}
+	return int64(freeToCaller / (1024 * 1024)), nil //nolint:gosec
+}
+
+// diskProbePath returns the root of the drive holding %ProgramData%\Multipass,
+// where multipass keeps its VM images on Windows
+func diskProbePath() (string, error) {
+	programData := os.Getenv("ProgramData")
+	if programData == "" {
+		return `C:\`, nil
+	}
+	return filepath.VolumeName(filepath.Join(programData, "Multipass")) + `\`, nil
+}
@@ -0,0 +1,27 @@
+//go:build windows
+
+package validator
+
+import "testing"
+
+func TestWindowsProbes(t *testing.T) {
+	memory, err := availableMemoryMB()
+	if err != nil {
+		t.Fatalf("availableMemoryMB: %v", err)
+	}
+	if memory <= 0 {
+		t.Errorf("expected positive available memory, got %d", memory)
+	}
+
+	path, err := diskProbePath()
+	if err != nil {
+		t.Fatalf("diskProbePath: %v", err)
+	}
+	disk, err := freeDiskMB(path)
+	if err != nil {
+		t.Fatalf("freeDiskMB(%s): %v", path, err)
+	}
+	if disk < 0 {
+		t.Errorf("expected non-negative free disk, got %d", disk)
+	}
+}
@@ -0,0 +1,223 @@
+package validator
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mrgb7/playground/types"
+)
+
+const (
+	// HostMemoryReserveMB is kept free for the host OS on top of the VMs
+	HostMemoryReserveMB = 1024
+	// HostDiskReserveMB is kept free on the host disk on top of the VM images
+	HostDiskReserveMB = 5 * 1024
+)
+
+// ResourceRequirements is what all nodes of a cluster need together
+type ResourceRequirements struct {
+	CPUs     int   `json:"cpus"`
+	MemoryMB int64 `json:"memoryMB"`
+	DiskMB   int64 `json:"diskMB"`
+}
+
+// SupportedArchs are the host CPU architectures multipass has Ubuntu images for
+var SupportedArchs = []string{"amd64", "arm64"}
+
+// HostResources is what the host has available
+type HostResources struct {
+	CPUs     int    `json:"cpus"`
+	MemoryMB int64  `json:"memoryMB"`
+	DiskMB   int64  `json:"diskMB"`
+	Arch     string `json:"arch,omitempty"`
+}
+
+// ResourceStatus compares what a cluster needs with what the host has
+type ResourceStatus struct {
+	Required  ResourceRequirements `json:"required"`
+	Available HostResources        `json:"available"`
+}
+
+// Check is the outcome of a single validation check
+type Check struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail"`
+}
+
+// ValidationResult collects the checks run, whether any blocks creation and
+// recommendations to fix them
+type ValidationResult struct {
+	Valid           bool            `json:"valid"`
+	Resources       *ResourceStatus `json:"resources,omitempty"`
+	Checks          []Check         `json:"checks"`
+	Recommendations []string        `json:"recommendations"`
+}
+
+// NewValidationResult returns a result without any checks, which is valid
+func NewValidationResult() *ValidationResult {
+	return &ValidationResult{Valid: true, Checks: []Check{}, Recommendations: []string{}}
+}
+
+func (r *ValidationResult) pass(name, detail string) {
+	r.Checks = append(r.Checks, Check{Name: name, OK: true, Detail: detail})
+}
+
+func (r *ValidationResult) fail(name, detail, recommendation string) {
+	r.Valid = false
+	r.Checks = append(r.Checks, Check{Name: name, OK: false, Detail: detail})
+	if recommendation != "" {
+		r.Recommendations = append(r.Recommendations, recommendation)
+	}
+}
+
+// Merge adds the checks of other to r
+func (r *ValidationResult) Merge(other *ValidationResult) {
+	if other == nil {
+		return
+	}
+	r.Valid = r.Valid && other.Valid
+	if other.Resources != nil {
+		r.Resources = other.Resources
+	}
+	r.Checks = append(r.Checks, other.Checks...)
+	r.Recommendations = append(r.Recommendations, other.Recommendations...)
+}
+
+// Failures returns the details of the failed checks
+func (r *ValidationResult) Failures() []string {
+	var failures []string
+	for _, check := range r.Checks {
+		if !check.OK {
+			failures = append(failures, check.Detail)
+		}
+	}
+	return failures
+}
+
+// String renders the failed checks followed by the recommendations, one per line
+func (r *ValidationResult) String() string {
+	lines := r.Failures()
+	for _, rec := range r.Recommendations {
+		lines = append(lines, "Recommendation: "+rec)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CalculateResourceRequirements sums the CPUs, memory and disk of the master and workers
+func CalculateResourceRequirements(config types.ClusterConfig) (ResourceRequirements, error) {
+	masterMemory, err := parseSizeMB(config.MasterMemory)
+	if err != nil {
+		return ResourceRequirements{}, fmt.Errorf("invalid master memory: %w", err)
+	}
+	masterDisk, err := parseSizeMB(config.MasterDisk)
+	if err != nil {
+		return ResourceRequirements{}, fmt.Errorf("invalid master disk: %w", err)
+	}
+
+	req := ResourceRequirements{
+		CPUs:     config.MasterCPUs,
+		MemoryMB: masterMemory,
+		DiskMB:   masterDisk,
+	}
+
+	workers := config.Size - 1
+	if workers <= 0 {
+		return req, nil
+	}
+	workerMemory, err := parseSizeMB(config.WorkerMemory)
+	if err != nil {
+		return ResourceRequirements{}, fmt.Errorf("invalid worker memory: %w", err)
+	}
+	workerDisk, err := parseSizeMB(config.WorkerDisk)
+	if err != nil {
+		return ResourceRequirements{}, fmt.Errorf("invalid worker disk: %w", err)
+	}
+	req.CPUs += workers * config.WorkerCPUs
+	req.MemoryMB += int64(workers) * workerMemory
+	req.DiskMB += int64(workers) * workerDisk
+	return req, nil
+}
+
+// ValidateResources checks the requirements fit on the host. Memory and disk must
+// fit with a reserve for the host; CPUs may be overcommitted, which only warrants
+// a recommendation. The nodes run the host's architecture, which must be supported.
+func ValidateResources(req ResourceRequirements, host HostResources) *ValidationResult {
+	result := NewValidationResult()
+	result.Resources = &ResourceStatus{Required: req, Available: host}
+
+	switch {
+	case host.MemoryMB <= 0:
+	case req.MemoryMB+HostMemoryReserveMB > host.MemoryMB:
+		result.fail("memory",
+			fmt.Sprintf("cluster needs %dMB memory but only %dMB is available (%dMB is kept for the host)",
+				req.MemoryMB, host.MemoryMB, HostMemoryReserveMB),
+			"reduce --size or lower --master-memory/--worker-memory")
+	default:
+		result.pass("memory", fmt.Sprintf("cluster needs %dMB memory of %dMB available", req.MemoryMB, host.MemoryMB))
+	}
+	switch {
+	case host.DiskMB <= 0:
+	case req.DiskMB+HostDiskReserveMB > host.DiskMB:
+		result.fail("disk",
+			fmt.Sprintf("cluster needs %dMB disk but only %dMB is free (%dMB is kept for the host)",
+				req.DiskMB, host.DiskMB, HostDiskReserveMB),
+			"reduce --size or lower --master-disk/--worker-disk")
+	default:
+		result.pass("disk", fmt.Sprintf("cluster needs %dMB disk of %dMB free", req.DiskMB, host.DiskMB))
+	}
+	// CPUs may be overcommitted, so this check passes either way
+	switch {
+	case host.CPUs <= 0:
+	case req.CPUs > host.CPUs:
+		result.pass("cpu", fmt.Sprintf("cluster overcommits %d vCPUs on %d CPUs", req.CPUs, host.CPUs))
+		result.Recommendations = append(result.Recommendations,
+			fmt.Sprintf("cluster uses %d vCPUs on a host with %d CPUs; nodes will be slow", req.CPUs, host.CPUs))
+	default:
+		result.pass("cpu", fmt.Sprintf("cluster uses %d vCPUs of %d CPUs", req.CPUs, host.CPUs))
+	}
+	switch {
+	case host.Arch == "":
+	case !slices.Contains(SupportedArchs, host.Arch):
+		result.fail("arch", fmt.Sprintf("multipass has no node images for %s hosts", host.Arch),
+			fmt.Sprintf("create the cluster on a %s host", strings.Join(SupportedArchs, " or ")))
+	default:
+		result.pass("arch", fmt.Sprintf("nodes run %s like the host", host.Arch))
+	}
+	return result
+}
+
+// IsPortInUse reports whether port is already taken on the host
+func IsPortInUse(port int) bool {
+	ln, err := net.Listen("tcp", net.JoinHostPort("", strconv.Itoa(port)))
+	if err != nil {
+		return true
+	}
+	_ = ln.Close()
+	return false
+}
+
+// parseSizeMB converts sizes like "512M", "2G" or "1T" to megabytes
+func parseSizeMB(size string) (int64, error) {
+	if len(size) < 2 {
+		return 0, fmt.Errorf("invalid size %q", size)
+	}
+	unit := strings.ToUpper(size[len(size)-1:])
+	value, err := strconv.ParseInt(size[:len(size)-1], 10, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size %q", size)
+	}
+	switch unit {
+	case "M":
+		return value, nil
+	case "G":
+		return value * 1024, nil
+	case "T":
+		return value * 1024 * 1024, nil
+	default:
+		return 0, fmt.Errorf("invalid size unit in %q", size)
+	}
+}
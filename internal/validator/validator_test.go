@@ -0,0 +1,169 @@
+package validator
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/mrgb7/playground/types"
+)
+
+func TestCalculateResourceRequirements(t *testing.T) {
+	config := types.ClusterConfig{
+		Size:       3,
+		MasterCPUs: 2, MasterMemory: "2G", MasterDisk: "20G",
+		WorkerCPUs: 1, WorkerMemory: "512M", WorkerDisk: "1T",
+	}
+
+	got, err := CalculateResourceRequirements(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := ResourceRequirements{
+		CPUs:     4,
+		MemoryMB: 2048 + 2*512,
+		DiskMB:   20*1024 + 2*1024*1024,
+	}
+	if got != expected {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+
+	config.WorkerMemory = "lots"
+	if _, err := CalculateResourceRequirements(config); err == nil {
+		t.Error("expected error for invalid worker memory")
+	}
+}
+
+func TestValidateResources(t *testing.T) {
+	req := ResourceRequirements{CPUs: 8, MemoryMB: 8192, DiskMB: 60 * 1024}
+
+	tests := []struct {
+		name      string
+		host      HostResources
+		wantValid bool
+		wantRecs  int
+	}{
+		{"enough of everything", HostResources{CPUs: 8, MemoryMB: 16384, DiskMB: 100 * 1024}, true, 0},
+		{"too little memory", HostResources{CPUs: 8, MemoryMB: 8192, DiskMB: 100 * 1024}, false, 1},
+		{"too little disk", HostResources{CPUs: 8, MemoryMB: 16384, DiskMB: 60 * 1024}, false, 1},
+		{"cpu overcommit only warns", HostResources{CPUs: 4, MemoryMB: 16384, DiskMB: 100 * 1024}, true, 1},
+		{"arm64 host", HostResources{CPUs: 8, MemoryMB: 16384, DiskMB: 100 * 1024, Arch: "arm64"}, true, 0},
+		{"unsupported arch", HostResources{CPUs: 8, MemoryMB: 16384, DiskMB: 100 * 1024, Arch: "riscv64"}, false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ValidateResources(req, tt.host)
+			if result.Valid != tt.wantValid {
+				t.Errorf("expected valid=%v, got %v (%v)", tt.wantValid, result.Valid, result.Failures())
+			}
+			if len(result.Recommendations) != tt.wantRecs {
+				t.Errorf("expected %d recommendations, got %v", tt.wantRecs, result.Recommendations)
+			}
+		})
+	}
+}
+
+func TestValidationResultJSON(t *testing.T) {
+	result := ValidateResources(
+		ResourceRequirements{CPUs: 8, MemoryMB: 8192, DiskMB: 60 * 1024},
+		HostResources{CPUs: 4, MemoryMB: 8192, DiskMB: 100 * 1024},
+	)
+	result.Merge(&ValidationResult{Valid: true,
+		Checks: []Check{{Name: "arch", OK: true, Detail: "nodes run arm64 like the host"}}})
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	expected := map[string]any{
+		"valid": false,
+		"resources": map[string]any{
+			"required":  map[string]any{"cpus": 8.0, "memoryMB": 8192.0, "diskMB": 61440.0},
+			"available": map[string]any{"cpus": 4.0, "memoryMB": 8192.0, "diskMB": 102400.0},
+		},
+		"checks": []any{
+			map[string]any{"name": "memory", "ok": false,
+				"detail": "cluster needs 8192MB memory but only 8192MB is available (1024MB is kept for the host)"},
+			map[string]any{"name": "disk", "ok": true, "detail": "cluster needs 61440MB disk of 102400MB free"},
+			map[string]any{"name": "cpu", "ok": true, "detail": "cluster overcommits 8 vCPUs on 4 CPUs"},
+			map[string]any{"name": "arch", "ok": true, "detail": "nodes run arm64 like the host"},
+		},
+		"recommendations": []any{
+			"reduce --size or lower --master-memory/--worker-memory",
+			"cluster uses 8 vCPUs on a host with 4 CPUs; nodes will be slow",
+		},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected JSON shape:\n got: %s", data)
+	}
+}
+
+func TestValidationResultString(t *testing.T) {
+	result := ValidateResources(
+		ResourceRequirements{CPUs: 2, MemoryMB: 2048, DiskMB: 60 * 1024},
+		HostResources{CPUs: 4, MemoryMB: 16384, DiskMB: 60 * 1024},
+	)
+	expected := "cluster needs 61440MB disk but only 61440MB is free (5120MB is kept for the host)\n" +
+		"Recommendation: reduce --size or lower --master-disk/--worker-disk"
+	if got := result.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	if got := NewValidationResult().String(); got != "" {
+		t.Errorf("expected an empty report for a clean result, got %q", got)
+	}
+}
+
+func TestIsPortInUse(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on a local port: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+	busy := ln.Addr().(*net.TCPAddr).Port
+
+	if !IsPortInUse(busy) {
+		t.Errorf("expected port %d to be reported in use", busy)
+	}
+}
+
+func TestParseSizeMB(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"512M", 512, false},
+		{"2G", 2048, false},
+		{"1T", 1024 * 1024, false},
+		{"2", 0, true},
+		{"2X", 0, true},
+		{"G", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseSizeMB(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseSizeMB(%q) = %d, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestGetHostResources(t *testing.T) {
+	host, err := GetHostResources()
+	if errors.Is(err, errUnsupportedPlatform) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatalf("GetHostResources: %v", err)
+	}
+	if host.CPUs <= 0 || host.MemoryMB < 0 || host.DiskMB < 0 {
+		t.Errorf("unexpected host resources %+v", host)
+	}
+}
@@ -1,13 +1,35 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/fatih/color"
 )
 
+// Format selects how log entries are written
+type Format int
+
+const (
+	FormatText Format = iota // colored, human readable lines
+	FormatJSON               // one JSON object per line
+)
+
+const (
+	levelInfo    = "info"
+	levelWarn    = "warn"
+	levelError   = "error"
+	levelDebug   = "debug"
+	levelSuccess = "success"
+)
+
 var (
 	infoColor    = color.New(color.FgGreen)
 	warnColor    = color.New(color.FgYellow)
@@ -16,15 +38,130 @@ var (
 	successColor = color.New(color.FgGreen, color.Bold)
 	enableDebug  = false // Flag to enable/disable debug messages
 
+	outputFormat           = FormatText
+	output       io.Writer = os.Stdout
+	console      io.Writer = os.Stdout
+	sinks        []io.Writer
+	mu           sync.Mutex
 )
 
+type jsonEntry struct {
+	Level string `json:"level"`
+	Msg   string `json:"msg"`
+	Ts    string `json:"ts"`
+}
+
 func init() {
 	enableDebug = os.Getenv("LOG_LEVEL") == "debug"
 }
 
+// SetFormat switches between colored text and JSON output
+func SetFormat(f Format) {
+	mu.Lock()
+	defer mu.Unlock()
+	outputFormat = f
+}
+
+// ParseFormat converts a --log-format value to a Format
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "", "text":
+		return FormatText, nil
+	case "json":
+		return FormatJSON, nil
+	default:
+		return FormatText, fmt.Errorf("unknown log format %q, expected text or json", s)
+	}
+}
+
+// SetOutput changes where log entries are written
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	console = w
+	sinks = nil
+	output = w
+}
+
+// SetConsole moves the terminal output to w, keeping any file sinks, e.g. to stderr when
+// stdout is reserved for machine-readable output
+func SetConsole(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	console = w
+	output = io.MultiWriter(append([]io.Writer{console}, sinks...)...)
+}
+
+// MaxLogFileSize is the size at which AddFileSink rotates an existing log file
+const MaxLogFileSize = 10 * 1024 * 1024
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// plainWriter strips color escape sequences before writing to w
+type plainWriter struct {
+	w io.Writer
+}
+
+func (p plainWriter) Write(b []byte) (int, error) {
+	if _, err := p.w.Write(ansiEscape.ReplaceAll(b, nil)); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// AddFileSink tees all log output to the file at path, without colors. The
+// file is appended to, and moved aside to path.1 once it grows past MaxLogFileSize.
+func AddFileSink(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() >= MaxLogFileSize {
+		if err := os.Rename(path, path+".1"); err != nil {
+			return fmt.Errorf("failed to rotate log file: %w", err)
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	sinks = append(sinks, plainWriter{w: f})
+	output = io.MultiWriter(append([]io.Writer{console}, sinks...)...)
+	return nil
+}
+
+// emit writes a single entry in the configured format. A nil c writes plain text.
+func emit(level string, c *color.Color, format string, args ...interface{}) {
+	if level == levelDebug && !enableDebug {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if outputFormat == FormatJSON {
+		entry := jsonEntry{
+			Level: level,
+			Msg:   strings.TrimRight(fmt.Sprintf(format, args...), "\n"),
+			Ts:    time.Now().UTC().Format(time.RFC3339Nano),
+		}
+		_ = json.NewEncoder(output).Encode(entry)
+		return
+	}
+
+	if c == nil {
+		_, _ = fmt.Fprintf(output, format+"\n", args...)
+		return
+	}
+	_, _ = c.Fprintf(output, format+"\n", args...)
+}
+
 // Info prints info message with format
 func Info(format string, args ...interface{}) {
-	_, _ = infoColor.Printf(format+"\n", args...)
+	emit(levelInfo, infoColor, format, args...)
 }
 
 // Infof is an alias for Info for consistency
@@ -34,12 +171,12 @@ func Infof(format string, args ...interface{}) {
 
 // Infoln prints info message with newline
 func Infoln(format string, args ...interface{}) {
-	_, _ = infoColor.Printf(format+"\n", args...)
+	emit(levelInfo, infoColor, format, args...)
 }
 
 // Warn prints warning message with format
 func Warn(format string, args ...interface{}) {
-	_, _ = warnColor.Printf(format+"\n", args...)
+	emit(levelWarn, warnColor, format, args...)
 }
 
 // Warnf is an alias for Warn for consistency
@@ -49,12 +186,12 @@ func Warnf(format string, args ...interface{}) {
 
 // Warnln prints warning message with newline
 func Warnln(format string, args ...interface{}) {
-	_, _ = warnColor.Printf(format+"\n", args...)
+	emit(levelWarn, warnColor, format, args...)
 }
 
 // Error prints error message with format (suppressed in silent mode)
 func Error(format string, args ...interface{}) {
-	_, _ = errorColor.Printf(format+"\n", args...)
+	emit(levelError, errorColor, format, args...)
 }
 
 // Errorf is an alias for Error for consistency
@@ -64,33 +201,27 @@ func Errorf(format string, args ...interface{}) {
 
 // Errorln prints error message with newline (suppressed in silent mode)
 func Errorln(format string, args ...interface{}) {
-	_, _ = errorColor.Printf(format+"\n", args...)
+	emit(levelError, errorColor, format, args...)
 }
 
 // Debug prints debug message with format (suppressed in silent mode)
 func Debug(format string, args ...interface{}) {
-	if enableDebug {
-		_, _ = debugColor.Printf(format+"\n", args...)
-	}
+	emit(levelDebug, debugColor, format, args...)
 }
 
 // Debugf is an alias for Debug for consistency
 func Debugf(format string, args ...interface{}) {
-	if enableDebug {
-		Debug(format, args...)
-	}
+	Debug(format, args...)
 }
 
 // Debugln prints debug message with newline (suppressed in silent mode)
 func Debugln(format string, args ...interface{}) {
-	if enableDebug {
-		_, _ = debugColor.Printf(format+"\n", args...)
-	}
+	emit(levelDebug, debugColor, format, args...)
 }
 
 // Success prints success message with format
 func Success(format string, args ...interface{}) {
-	_, _ = successColor.Printf(format+"\n", args...)
+	emit(levelSuccess, successColor, format, args...)
 }
 
 // Successf is an alias for Success for consistency
@@ -100,26 +231,28 @@ func Successf(format string, args ...interface{}) {
 
 // Successln prints success message with newline
 func Successln(format string, args ...interface{}) {
-	_, _ = successColor.Printf(format+"\n", args...)
+	emit(levelSuccess, successColor, format, args...)
 }
 
 // Fatal prints error message and exits
 func Fatal(format string, args ...interface{}) {
-	_, _ = errorColor.Printf(format+"\n", args...)
+	emit(levelError, errorColor, format, args...)
 	os.Exit(1)
 }
 
 // Print prints plain message with format
 func Print(format string, args ...interface{}) {
-	fmt.Printf(format+"\n", args...)
+	emit(levelInfo, nil, format, args...)
 }
 
 // Println prints plain message with newline
 func Println(format string, args ...interface{}) {
-	fmt.Printf(format+"\n", args...)
+	emit(levelInfo, nil, format, args...)
 }
 
 // GetWriter returns an io.Writer for use with external libraries
 func GetWriter() io.Writer {
-	return os.Stdout
+	mu.Lock()
+	defer mu.Unlock()
+	return output
 }
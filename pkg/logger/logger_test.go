@@ -1,7 +1,14 @@
 package logger
 
 import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/fatih/color"
 )
@@ -120,3 +127,191 @@ func TestLoggerFunctions(t *testing.T) {
 		Println("plain: %s", "test")
 	})
 }
+
+func captureOutput(t *testing.T, f Format) *bytes.Buffer {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	SetOutput(buf)
+	SetFormat(f)
+	t.Cleanup(func() {
+		SetOutput(os.Stdout)
+		SetFormat(FormatText)
+	})
+	return buf
+}
+
+func TestTextFormat(t *testing.T) {
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	buf := captureOutput(t, FormatText)
+	Infoln("hello %s", "world")
+	Warnln("careful")
+
+	want := "hello world\ncareful\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	buf := captureOutput(t, FormatJSON)
+
+	Infoln("hello %s", "world")
+	Errorln("boom: %d", 42)
+	Successf("done\n")
+	Println("plain")
+
+	tests := []struct {
+		level string
+		msg   string
+	}{
+		{"info", "hello world"},
+		{"error", "boom: 42"},
+		{"success", "done"},
+		{"info", "plain"},
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(tests) {
+		t.Fatalf("expected %d lines, got %d: %q", len(tests), len(lines), buf.String())
+	}
+	for i, tt := range tests {
+		var entry jsonEntry
+		if err := json.Unmarshal([]byte(lines[i]), &entry); err != nil {
+			t.Fatalf("line %d is not valid JSON: %v", i, err)
+		}
+		if entry.Level != tt.level || entry.Msg != tt.msg {
+			t.Errorf("line %d: got %s/%q, want %s/%q", i, entry.Level, entry.Msg, tt.level, tt.msg)
+		}
+		if _, err := time.Parse(time.RFC3339Nano, entry.Ts); err != nil {
+			t.Errorf("line %d: invalid timestamp %q", i, entry.Ts)
+		}
+	}
+}
+
+func TestDebugSuppressed(t *testing.T) {
+	buf := captureOutput(t, FormatJSON)
+	prev := enableDebug
+	enableDebug = false
+	defer func() { enableDebug = prev }()
+
+	Debugln("hidden")
+	if buf.Len() != 0 {
+		t.Errorf("expected no output, got %q", buf.String())
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Format
+		wantErr bool
+	}{
+		{"", FormatText, false},
+		{"text", FormatText, false},
+		{"JSON", FormatJSON, false},
+		{"yaml", FormatText, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseFormat(%q) = %v, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestAddFileSink(t *testing.T) {
+	prevNoColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = prevNoColor }()
+
+	buf := captureOutput(t, FormatText)
+	path := filepath.Join(t.TempDir(), "logs", "playground.log")
+	if err := AddFileSink(path); err != nil {
+		t.Fatalf("AddFileSink: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			Infoln("worker %d joined", i)
+		}(i)
+	}
+	wg.Wait()
+	Errorln("failed")
+
+	if !strings.Contains(buf.String(), "\x1b[") {
+		t.Errorf("expected colored terminal output, got %q", buf.String())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	content := string(data)
+	if strings.Contains(content, "\x1b[") {
+		t.Errorf("log file should not contain color codes: %q", content)
+	}
+	lines := strings.Split(strings.TrimSpace(content), "\n")
+	if len(lines) != 11 {
+		t.Fatalf("expected 11 lines, got %d: %q", len(lines), content)
+	}
+	for _, line := range lines[:10] {
+		if !strings.HasPrefix(line, "worker ") || !strings.HasSuffix(line, " joined") {
+			t.Errorf("interleaved or malformed line %q", line)
+		}
+	}
+	if lines[10] != "failed" {
+		t.Errorf("expected last line %q, got %q", "failed", lines[10])
+	}
+}
+
+func TestAddFileSinkRotates(t *testing.T) {
+	captureOutput(t, FormatText)
+	path := filepath.Join(t.TempDir(), "playground.log")
+	if err := os.WriteFile(path, make([]byte, MaxLogFileSize), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AddFileSink(path); err != nil {
+		t.Fatalf("AddFileSink: %v", err)
+	}
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Errorf("expected rotated file: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != 0 {
+		t.Errorf("expected a fresh log file, got %v, %v", info, err)
+	}
+}
+
+func TestSetConsole(t *testing.T) {
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	stdout := captureOutput(t, FormatText)
+	path := filepath.Join(t.TempDir(), "playground.log")
+	if err := AddFileSink(path); err != nil {
+		t.Fatalf("AddFileSink: %v", err)
+	}
+	stderr := &bytes.Buffer{}
+	SetConsole(stderr)
+
+	Infoln("creating cluster")
+
+	if stdout.Len() != 0 {
+		t.Errorf("expected nothing on the previous console, got %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "creating cluster") {
+		t.Errorf("expected the entry on the new console, got %q", stderr.String())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	if !strings.Contains(string(data), "creating cluster") {
+		t.Errorf("expected the file sink to be kept, got %q", data)
+	}
+}
@@ -0,0 +1,85 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// Options controls how Do retries a function
+type Options struct {
+	// Attempts is the maximum number of calls. Zero retries until the context is done.
+	Attempts int
+	// Backoff is the delay before the second attempt
+	Backoff time.Duration
+	// Multiplier grows the delay after every attempt. Values <= 1 keep it constant.
+	Multiplier float64
+	// MaxBackoff caps the delay. Zero means no cap.
+	MaxBackoff time.Duration
+	// Jitter randomizes every delay by up to this fraction in either direction, so
+	// clients retrying at the same time spread out. Zero waits the exact delay.
+	Jitter float64
+	// Retryable reports whether an error should be retried. Nil retries every error.
+	Retryable func(error) bool
+	// Sleep waits between attempts and returns ctx's error when it is done first.
+	// Nil waits on a timer; tests can inject a fake clock.
+	Sleep func(ctx context.Context, d time.Duration) error
+}
+
+// Do calls fn until it succeeds, returns a non-retryable error, runs out of attempts
+// or ctx is done. The last error from fn is returned.
+func Do(ctx context.Context, opts Options, fn func() error) error {
+	delay := opts.Backoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if opts.Retryable != nil && !opts.Retryable(err) {
+			return err
+		}
+		if opts.Attempts > 0 && attempt >= opts.Attempts {
+			return err
+		}
+
+		sleep := opts.Sleep
+		if sleep == nil {
+			sleep = timerSleep
+		}
+		if ctxErr := sleep(ctx, withJitter(delay, opts.Jitter)); ctxErr != nil {
+			return fmt.Errorf("%w: %w", ctxErr, err)
+		}
+
+		delay = nextDelay(delay, opts)
+	}
+}
+
+func timerSleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// withJitter returns delay scaled by a random factor in [1-jitter, 1+jitter]
+func withJitter(delay time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 {
+		return delay
+	}
+	return time.Duration(float64(delay) * (1 + jitter*(2*rand.Float64()-1)))
+}
+
+func nextDelay(delay time.Duration, opts Options) time.Duration {
+	if opts.Multiplier > 1 {
+		delay = time.Duration(float64(delay) * opts.Multiplier)
+	}
+	if opts.MaxBackoff > 0 && delay > opts.MaxBackoff {
+		delay = opts.MaxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,153 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errTest = errors.New("test error")
+
+func TestDoSucceedsAfterRetries(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), Options{Attempts: 5, Backoff: time.Millisecond}, func() error {
+		calls++
+		if calls < 3 {
+			return errTest
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("expected success, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestDoStopsAfterAttempts(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), Options{Attempts: 3, Backoff: time.Millisecond}, func() error {
+		calls++
+		return errTest
+	})
+	if !errors.Is(err, errTest) {
+		t.Errorf("expected last error to be returned, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestDoStopsOnNonRetryableError(t *testing.T) {
+	permanent := errors.New("permanent")
+	calls := 0
+	err := Do(context.Background(), Options{
+		Attempts:  5,
+		Backoff:   time.Millisecond,
+		Retryable: func(err error) bool { return errors.Is(err, errTest) },
+	}, func() error {
+		calls++
+		if calls == 2 {
+			return permanent
+		}
+		return errTest
+	})
+	if !errors.Is(err, permanent) {
+		t.Errorf("expected permanent error, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestDoStopsWhenContextDone(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := Do(ctx, Options{Backoff: 5 * time.Millisecond}, func() error {
+		return errTest
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if !errors.Is(err, errTest) {
+		t.Errorf("expected last error to be wrapped, got %v", err)
+	}
+}
+
+func TestNextDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		delay    time.Duration
+		opts     Options
+		expected time.Duration
+	}{
+		{"constant", time.Second, Options{}, time.Second},
+		{"exponential", time.Second, Options{Multiplier: 2}, 2 * time.Second},
+		{"capped", 4 * time.Second, Options{Multiplier: 2, MaxBackoff: 5 * time.Second}, 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextDelay(tt.delay, tt.opts); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDoSleepsWithJitteredBackoff(t *testing.T) {
+	var slept []time.Duration
+	opts := Options{
+		Attempts:   5,
+		Backoff:    100 * time.Millisecond,
+		Multiplier: 2,
+		Jitter:     0.5,
+		Sleep: func(ctx context.Context, d time.Duration) error {
+			slept = append(slept, d)
+			return nil
+		},
+	}
+	_ = Do(context.Background(), opts, func() error { return errTest })
+
+	if len(slept) != 4 {
+		t.Fatalf("expected 4 sleeps between 5 attempts, got %v", slept)
+	}
+	base := opts.Backoff
+	for i, d := range slept {
+		if d < base/2 || d > base*3/2 {
+			t.Errorf("sleep %d: expected %v within 50%% of %v", i, d, base)
+		}
+		base *= 2
+	}
+}
+
+func TestDoReturnsSleepError(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), Options{
+		Backoff: time.Second,
+		Sleep:   func(ctx context.Context, d time.Duration) error { return context.Canceled },
+	}, func() error {
+		calls++
+		return errTest
+	})
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, errTest) {
+		t.Errorf("expected cancellation wrapping the last error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestWithJitter(t *testing.T) {
+	if got := withJitter(time.Second, 0); got != time.Second {
+		t.Errorf("expected no jitter, got %v", got)
+	}
+	for i := 0; i < 100; i++ {
+		if got := withJitter(time.Second, 0.2); got < 800*time.Millisecond || got > 1200*time.Millisecond {
+			t.Fatalf("expected a delay within 20%% of 1s, got %v", got)
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package types
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -12,19 +15,37 @@ type Cluster struct {
 	Name       string
 	Nodes      []*Node
 	KubeConfig string
+	MasterIP   string
 }
 type ClusterConfig struct {
-	Name               string
-	Size               int
-	WithCoreComponents bool
-	MasterCPUs         int
-	MasterMemory       string
-	MasterDisk         string
-	WorkerCPUs         int
-	WorkerMemory       string
-	WorkerDisk         string
+	Name               string   `json:"name" yaml:"name"`
+	Size               int      `json:"size" yaml:"size"`
+	WithCoreComponents bool     `json:"withCoreComponents" yaml:"withCoreComponents"`
+	MasterCPUs         int      `json:"masterCPUs" yaml:"masterCPUs"`
+	MasterMemory       string   `json:"masterMemory" yaml:"masterMemory"`
+	MasterDisk         string   `json:"masterDisk" yaml:"masterDisk"`
+	WorkerCPUs         int      `json:"workerCPUs" yaml:"workerCPUs"`
+	WorkerMemory       string   `json:"workerMemory" yaml:"workerMemory"`
+	WorkerDisk         string   `json:"workerDisk" yaml:"workerDisk"`
+	WorkerLabels       []string `json:"workerLabels,omitempty" yaml:"workerLabels,omitempty"`
+	WorkerTaints       []string `json:"workerTaints,omitempty" yaml:"workerTaints,omitempty"`
+	K3sVersion         string   `json:"k3sVersion,omitempty" yaml:"k3sVersion,omitempty"`
+	K3sArgs            []string `json:"k3sArgs,omitempty" yaml:"k3sArgs,omitempty"`
+	MultipassArgs      []string `json:"multipassArgs,omitempty" yaml:"multipassArgs,omitempty"`
+	Mounts             []string `json:"mounts,omitempty" yaml:"mounts,omitempty"`
+	MountMasterOnly    bool     `json:"mountMasterOnly,omitempty" yaml:"mountMasterOnly,omitempty"`
+	// Domain is the domain ingress hostnames and certificates use, empty for <name>.local
+	Domain string `json:"domain,omitempty" yaml:"domain,omitempty"`
+	// Image is the multipass image nodes are launched from, empty for the latest Ubuntu LTS
+	Image string `json:"image,omitempty" yaml:"image,omitempty"`
+	// CloudInit is the cloud-init file nodes are launched with, empty for the default one
+	// and CloudInitNone for none
+	CloudInit string `json:"cloudInit,omitempty" yaml:"cloudInit,omitempty"`
 }
 
+// CloudInitNone launches nodes without cloud-init
+const CloudInitNone = "none"
+
 const (
 	MaxClusterSize       = 10 // maximum number of nodes allowed in cluster
 	MaxClusterNameLength = 63 // maximum length for cluster name (DNS label limit)
@@ -33,6 +54,16 @@ const (
 
 )
 
+var k3sVersionPattern = regexp.MustCompile(`^v1\.[0-9]+\.[0-9]+(-rc[0-9]+)?\+k3s[0-9]+$`)
+
+var domainLabelPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// MaxDomainLength is the longest domain name DNS allows
+const MaxDomainLength = 253
+
+// MaxDNSLabelLength is the longest label DNS allows in a domain name
+const MaxDNSLabelLength = 63
+
 func NewCluster(name string) *Cluster {
 	return &Cluster{
 		Name: name,
@@ -84,18 +115,91 @@ func (c *Cluster) GetMasterIP() string {
 	return masterIP
 }
 
+// ResolveCluster looks up an existing cluster and returns it with its master IP
+// and kubeconfig loaded, or an error describing which prerequisite is missing
+func ResolveCluster(name string) (*Cluster, error) {
+	if name == "" {
+		return nil, fmt.Errorf("cluster name is required")
+	}
+
+	c := NewCluster(name)
+	if _, err := multipass.NewMultipassClient().GetClusterInfo(name); err != nil {
+		return nil, describeLookupError(name, err)
+	}
+
+	c.MasterIP = c.GetMasterIP()
+	if c.MasterIP == "" {
+		return nil, fmt.Errorf("cluster '%s' is not running: master node has no IP", name)
+	}
+	if err := c.SetKubeConfig(); err != nil {
+		return nil, fmt.Errorf("cluster '%s' is not reachable: %w", name, err)
+	}
+	if strings.TrimSpace(c.KubeConfig) == "" {
+		return nil, fmt.Errorf("cluster '%s' is not reachable: empty kubeconfig", name)
+	}
+	return c, nil
+}
+
+// describeLookupError turns a failed cluster lookup into a message telling the user what to do next
+func describeLookupError(name string, err error) error {
+	switch {
+	case errors.Is(err, multipass.ErrInstanceNotFound):
+		return fmt.Errorf("cluster '%s' not found, did you mean to create it with "+
+			"'playground cluster create --name %s'? (%w)", name, name, err)
+	case errors.Is(err, multipass.ErrMultipassUnavailable):
+		return fmt.Errorf("cannot look up cluster '%s', is multipass installed and running? (%w)", name, err)
+	default:
+		return fmt.Errorf("failed to look up cluster '%s': %w", name, err)
+	}
+}
+
 func (c *Cluster) IsExists() bool {
 	cl := multipass.NewMultipassClient()
 	_, err := cl.GetClusterInfo(c.Name)
 	return err == nil
 }
 
+// Load fills Nodes with the cluster's multipass instances and their IPs, and MasterIP
+// with the master's, so GetMaster and GetWorkers reflect the running cluster
+func (c *Cluster) Load() error {
+	return c.load(multipass.NewMultipassClient())
+}
+
+func (c *Cluster) load(client multipass.Client) error {
+	instances, err := client.ListInstances()
+	if err != nil {
+		return fmt.Errorf("failed to list cluster nodes: %w", err)
+	}
+
+	nodes := make([]*Node, 0)
+	for _, instance := range instances {
+		if !strings.HasPrefix(instance.Name, c.Name+"-") || instance.State == "Deleted" {
+			continue
+		}
+		node := &Node{Name: instance.Name, Status: instance.State}
+		// stopped nodes have no IP
+		if instance.State == "Running" {
+			node.IP, err = client.GetNodeIP(instance.Name)
+			if err != nil {
+				return fmt.Errorf("failed to get IP of node '%s': %w", instance.Name, err)
+			}
+		}
+		nodes = append(nodes, node)
+	}
+
+	c.Nodes = nodes
+	if master := c.GetMaster(); master != nil {
+		c.MasterIP = master.IP
+	}
+	return nil
+}
+
 func (c *Cluster) Validate(config ClusterConfig) error {
-	if err := validateClusterName(config.Name); err != nil {
+	if err := ValidateClusterName(config.Name); err != nil {
 		return fmt.Errorf("invalid cluster name: %w", err)
 	}
 
-	if err := validateClusterSize(config.Size); err != nil {
+	if err := ValidateClusterSize(config.Size); err != nil {
 		return fmt.Errorf("invalid cluster size: %w", err)
 	}
 
@@ -123,10 +227,115 @@ func (c *Cluster) Validate(config ClusterConfig) error {
 		return fmt.Errorf("invalid worker disk format: %w", err)
 	}
 
+	if err := validateWorkerScheduling(config); err != nil {
+		return fmt.Errorf("invalid worker scheduling: %w", err)
+	}
+
+	if err := ValidateK3sVersion(config.K3sVersion); err != nil {
+		return fmt.Errorf("invalid k3s version: %w", err)
+	}
+
+	if err := ValidateK3sArgs(config.K3sArgs); err != nil {
+		return fmt.Errorf("invalid k3s argument: %w", err)
+	}
+
+	if err := multipass.ValidateLaunchArgs(config.MultipassArgs); err != nil {
+		return fmt.Errorf("invalid multipass argument: %w", err)
+	}
+
+	if err := multipass.ValidateImage(config.Image); err != nil {
+		return fmt.Errorf("invalid image: %w", err)
+	}
+
+	if err := ValidateDomain(config.Domain); err != nil {
+		return fmt.Errorf("invalid domain: %w", err)
+	}
+
+	if err := validateMounts(config.Mounts); err != nil {
+		return fmt.Errorf("invalid mount: %w", err)
+	}
+
+	if err := ValidateCloudInit(config.CloudInit); err != nil {
+		return fmt.Errorf("invalid cloud-init: %w", err)
+	}
+
+	return nil
+}
+
+// ValidateCloudInit checks a cloud-init setting is empty, CloudInitNone or an existing file
+func ValidateCloudInit(cloudInit string) error {
+	if cloudInit == "" || cloudInit == CloudInitNone {
+		return nil
+	}
+	info, err := os.Stat(cloudInit)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%q is a directory", cloudInit)
+	}
+	return nil
+}
+
+func validateWorkerScheduling(config ClusterConfig) error {
+	workers := config.Size - 1
+	if workers == 0 && (len(config.WorkerLabels) > 0 || len(config.WorkerTaints) > 0) {
+		return fmt.Errorf("worker labels and taints require a cluster with worker nodes")
+	}
+
+	for _, l := range config.WorkerLabels {
+		label, err := ParseNodeLabel(l)
+		if err != nil {
+			return err
+		}
+		if label.WorkerIndex > workers {
+			return fmt.Errorf("label %q targets worker %d but the cluster has %d workers", l, label.WorkerIndex, workers)
+		}
+	}
+
+	for _, t := range config.WorkerTaints {
+		taint, err := ParseNodeTaint(t)
+		if err != nil {
+			return err
+		}
+		if taint.WorkerIndex > workers {
+			return fmt.Errorf("taint %q targets worker %d but the cluster has %d workers", t, taint.WorkerIndex, workers)
+		}
+	}
+
+	return nil
+}
+
+func validateMounts(mounts []string) error {
+	for _, m := range mounts {
+		mount, err := ParseNodeMount(m)
+		if err != nil {
+			return err
+		}
+		if err := ValidateMountHostPath(mount.HostPath); err != nil {
+			return fmt.Errorf("mount %q: %w", m, err)
+		}
+	}
 	return nil
 }
 
-func validateClusterName(name string) error {
+// ValidateMountHostPath checks a host path is an absolute path to an existing directory
+func ValidateMountHostPath(hostPath string) error {
+	if !filepath.IsAbs(hostPath) {
+		return fmt.Errorf("host path %q must be absolute", hostPath)
+	}
+	info, err := os.Stat(hostPath)
+	if err != nil {
+		return fmt.Errorf("host path: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("host path %q is not a directory", hostPath)
+	}
+	return nil
+}
+
+// ValidateClusterName checks name can be used for the node and DNS names of a cluster
+func ValidateClusterName(name string) error {
 	if name == "" {
 		return fmt.Errorf("cluster name cannot be empty")
 	}
@@ -148,7 +357,8 @@ func validateClusterName(name string) error {
 	return nil
 }
 
-func validateClusterSize(size int) error {
+// ValidateClusterSize checks the number of nodes is within the supported range
+func ValidateClusterSize(size int) error {
 	if size < MinClusterSize {
 		return fmt.Errorf("cluster size must be at least %d", MinClusterSize)
 	}
@@ -160,6 +370,50 @@ func validateClusterSize(size int) error {
 	return nil
 }
 
+// ValidateDomain checks domain is a lowercase DNS name with at least two labels, such as
+// dev.example.com. An empty domain selects <cluster>.local.
+func ValidateDomain(domain string) error {
+	if domain == "" {
+		return nil
+	}
+	if len(domain) > MaxDomainLength {
+		return fmt.Errorf("domain must be %d characters or less", MaxDomainLength)
+	}
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return fmt.Errorf("%q is not a fully qualified domain, expected a name like dev.example.com", domain)
+	}
+	for _, label := range labels {
+		if len(label) > MaxDNSLabelLength || !domainLabelPattern.MatchString(label) {
+			return fmt.Errorf("%q is not a valid domain: labels must be lowercase letters, numbers and hyphens, "+
+				"at most %d characters long", domain, MaxDNSLabelLength)
+		}
+	}
+	return nil
+}
+
+// ValidateK3sVersion checks version looks like a k3s release tag such as v1.30.4+k3s1.
+// An empty version selects the latest stable release.
+func ValidateK3sVersion(version string) error {
+	if version == "" {
+		return nil
+	}
+	if !k3sVersionPattern.MatchString(version) {
+		return fmt.Errorf("%q is not a k3s release tag, expected a version like 'v1.30.4+k3s1'", version)
+	}
+	return nil
+}
+
+// ValidateK3sArgs checks every extra k3s server argument is a flag
+func ValidateK3sArgs(args []string) error {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "--") || len(arg) == 2 {
+			return fmt.Errorf("%q must be a flag like '--disable=metrics-server'", arg)
+		}
+	}
+	return nil
+}
+
 func ValidateCPUCount(cpus int, nodeType string) error {
 	if cpus < 1 {
 		return fmt.Errorf("%s CPU count must be at least 1", nodeType)
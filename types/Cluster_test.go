@@ -0,0 +1,84 @@
+package types
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mrgb7/playground/internal/multipass"
+)
+
+type fakeClient struct {
+	multipass.Client
+	instances []multipass.MultiPassListItem
+	ips       map[string]string
+}
+
+func (f *fakeClient) ListInstances() ([]multipass.MultiPassListItem, error) {
+	return f.instances, nil
+}
+
+func (f *fakeClient) GetNodeIP(name string) (string, error) {
+	ip, ok := f.ips[name]
+	if !ok {
+		return "", errors.New("no IP")
+	}
+	return ip, nil
+}
+
+func nodeNames(nodes []*Node) []string {
+	names := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	return names
+}
+
+func TestClusterLoad(t *testing.T) {
+	client := &fakeClient{
+		instances: []multipass.MultiPassListItem{
+			{Name: "dev-master", State: "Running"},
+			{Name: "dev-worker-1", State: "Running"},
+			{Name: "dev-worker-2", State: "Stopped"},
+			{Name: "dev-worker-3", State: "Deleted"},
+			{Name: "devbox", State: "Running"},
+			{Name: "other-master", State: "Running"},
+		},
+		ips: map[string]string{
+			"dev-master":   "10.0.0.1",
+			"dev-worker-1": "10.0.0.2",
+			"devbox":       "10.0.0.9",
+			"other-master": "10.0.0.10",
+		},
+	}
+
+	c := NewCluster("dev")
+	if err := c.load(client); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	master := c.GetMaster()
+	if master == nil || master.Name != "dev-master" || master.IP != "10.0.0.1" {
+		t.Fatalf("expected master dev-master with IP 10.0.0.1, got %+v", master)
+	}
+	if c.MasterIP != "10.0.0.1" {
+		t.Errorf("expected master IP 10.0.0.1, got %q", c.MasterIP)
+	}
+
+	workers := c.GetWorkers()
+	if expected := []string{"dev-worker-1", "dev-worker-2"}; !reflect.DeepEqual(nodeNames(workers), expected) {
+		t.Fatalf("expected workers %v, got %v", expected, nodeNames(workers))
+	}
+	if workers[0].IP != "10.0.0.2" || workers[1].IP != "" || workers[1].Status != "Stopped" {
+		t.Errorf("unexpected workers %+v, %+v", workers[0], workers[1])
+	}
+}
+
+func TestClusterLoadNodeIPError(t *testing.T) {
+	client := &fakeClient{
+		instances: []multipass.MultiPassListItem{{Name: "dev-master", State: "Running"}},
+	}
+	if err := NewCluster("dev").load(client); err == nil {
+		t.Error("expected an error when the node IP cannot be read")
+	}
+}
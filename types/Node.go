@@ -1,5 +1,13 @@
 package types
 
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
 type Node struct {
 	Name   string
 	Status string
@@ -8,3 +16,102 @@ type Node struct {
 	Memory string
 	Disk   string
 }
+
+// NodeLabel is a label to apply to a worker node. WorkerIndex 0 targets all workers.
+type NodeLabel struct {
+	WorkerIndex int
+	Key         string
+	Value       string
+}
+
+// NodeTaint is a taint to apply to a worker node. WorkerIndex 0 targets all workers.
+type NodeTaint struct {
+	WorkerIndex int
+	Key         string
+	Value       string
+	Effect      string
+}
+
+// NodeMount is a host directory mounted into nodes
+type NodeMount struct {
+	HostPath string
+	NodePath string
+}
+
+func (m NodeMount) String() string {
+	return m.HostPath + ":" + m.NodePath
+}
+
+var validTaintEffects = map[string]bool{
+	"NoSchedule":       true,
+	"PreferNoSchedule": true,
+	"NoExecute":        true,
+}
+
+// ParseNodeLabel parses a label in the form [index:]key=value
+func ParseNodeLabel(s string) (NodeLabel, error) {
+	index, rest, err := splitWorkerIndex(s)
+	if err != nil {
+		return NodeLabel{}, err
+	}
+	key, value, ok := strings.Cut(rest, "=")
+	if !ok || key == "" {
+		return NodeLabel{}, fmt.Errorf("label %q must be in format [index:]key=value", s)
+	}
+	return NodeLabel{WorkerIndex: index, Key: key, Value: value}, nil
+}
+
+// ParseNodeTaint parses a taint in the form [index:]key=value:Effect
+func ParseNodeTaint(s string) (NodeTaint, error) {
+	index, rest, err := splitWorkerIndex(s)
+	if err != nil {
+		return NodeTaint{}, err
+	}
+	kv, effect, ok := strings.Cut(rest, ":")
+	if !ok || !validTaintEffects[effect] {
+		return NodeTaint{}, fmt.Errorf("taint %q must be in format [index:]key=value:Effect "+
+			"with Effect one of NoSchedule, PreferNoSchedule, NoExecute", s)
+	}
+	key, value, _ := strings.Cut(kv, "=")
+	if key == "" {
+		return NodeTaint{}, fmt.Errorf("taint %q has an empty key", s)
+	}
+	return NodeTaint{WorkerIndex: index, Key: key, Value: value, Effect: effect}, nil
+}
+
+// ParseNodeMount parses a mount in the form host/path:node/path. The host path is made
+// absolute, the node path must already be absolute.
+func ParseNodeMount(s string) (NodeMount, error) {
+	// split on the last colon so Windows host paths like C:\src keep their drive
+	i := strings.LastIndex(s, ":")
+	if i <= 0 || i == len(s)-1 {
+		return NodeMount{}, fmt.Errorf("mount %q must be in format host/path:node/path", s)
+	}
+	hostPath, nodePath := s[:i], s[i+1:]
+	if !path.IsAbs(nodePath) {
+		return NodeMount{}, fmt.Errorf("node path %q in mount %q must be absolute", nodePath, s)
+	}
+	abs, err := filepath.Abs(hostPath)
+	if err != nil {
+		return NodeMount{}, fmt.Errorf("invalid host path in mount %q: %w", s, err)
+	}
+	return NodeMount{HostPath: abs, NodePath: path.Clean(nodePath)}, nil
+}
+
+// splitWorkerIndex strips an optional numeric "index:" prefix
+func splitWorkerIndex(s string) (int, string, error) {
+	prefix, rest, ok := strings.Cut(s, ":")
+	if !ok || prefix == "" || strings.Trim(prefix, "0123456789") != "" {
+		return 0, s, nil
+	}
+	index, err := strconv.Atoi(prefix)
+	if err != nil || index < 1 {
+		return 0, "", fmt.Errorf("invalid worker index %q in %q", prefix, s)
+	}
+	return index, rest, nil
+}
+
+// WorkerNodeName returns the node name of the worker at the given 1-based index
+func WorkerNodeName(clusterName string, index int) string {
+	return fmt.Sprintf("%s-worker-%d", clusterName, index)
+}